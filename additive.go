@@ -0,0 +1,128 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// SplitAdditive splits secret into n additive shares: random values that sum
+// to the secret modulo modulus. All n shares are needed to recover it, with
+// JoinAdditive. The shares have Additive set and X = 1, 2, ..., n to identify
+// the parties. If rand is nil, crypto/rand.Reader is used.
+func SplitAdditive(secret, modulus *big.Int, n int, rand io.Reader) ([]Share, error) {
+	if n < 1 {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(1, n); err != nil {
+		return nil, err
+	}
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+	rand = defaultRand(rand)
+
+	shares := make([]Share, n)
+	last := new(big.Int).Set(secret)
+	for i := range shares {
+		y := last
+		if i < n-1 {
+			var err error
+			if y, err = randomNumber(rand, modulus); err != nil {
+				return nil, err
+			}
+			last.Sub(last, y)
+			last.Mod(last, modulus)
+		}
+		shares[i] = Share{X: big.NewInt(int64(i + 1)), Y: y, Modulus: modulus, Additive: true}
+	}
+	return shares, nil
+}
+
+// JoinAdditive recovers the secret from all the shares that resulted from
+// SplitAdditive or ShamirToAdditive. Missing shares can't be detected and
+// result in a wrong secret.
+func JoinAdditive(shares []Share) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	modulus := shares[0].Modulus
+	if modulus == nil {
+		return nil, errors.New("shares don't record their modulus")
+	}
+
+	sum := new(big.Int)
+	for _, s := range shares {
+		if !s.Additive {
+			return nil, errors.New("share is not an additive share")
+		}
+		if s.Modulus == nil || s.Modulus.Cmp(modulus) != 0 {
+			return nil, errors.New("shares are from different splits")
+		}
+		if s.Y == nil {
+			return nil, errors.New("share is missing coordinates")
+		}
+		sum.Add(sum, s.Y)
+	}
+	return sum.Mod(sum, modulus), nil
+}
+
+// ShamirToAdditive converts a Shamir share, which must record its modulus,
+// into an additive share among the holders of the shares with x coordinates
+// xs, which must include share.X. The additive shares of any k such holders
+// sum to the secret. No communication is needed.
+func ShamirToAdditive(share Share, xs []*big.Int) (Share, error) {
+	if share.Modulus == nil || share.X == nil || share.Y == nil || share.Additive {
+		return Share{}, errors.New("share is not a Shamir share with a modulus")
+	}
+	if err := checkXs(xs, share.Modulus); err != nil {
+		return Share{}, err
+	}
+
+	i := -1
+	for j, x := range xs {
+		if x.Cmp(share.X) == 0 {
+			i = j
+		}
+	}
+	if i < 0 {
+		return Share{}, errors.New("share's x coordinate is not among xs")
+	}
+
+	y := lagrangeAtZero(xs, i, share.Modulus)
+	y.Mul(y, share.Y)
+	y.Mod(y, share.Modulus)
+	return Share{X: share.X, Y: y, Modulus: share.Modulus, Additive: true}, nil
+}
+
+// AdditiveToShamir completes the conversion of additive shares into Shamir
+// shares. Each additive shareholder deals their share with SplitShares to the
+// same parties, and each party passes the shares that it receives, which must
+// all have the same x coordinate, to AdditiveToShamir to get its Shamir share
+// of the secret.
+func AdditiveToShamir(received []Share) (Share, error) {
+	if len(received) == 0 {
+		return Share{}, errors.New("no shares given")
+	}
+
+	first := received[0]
+	if first.Modulus == nil || first.X == nil {
+		return Share{}, errors.New("shares don't record their modulus")
+	}
+
+	y := new(big.Int)
+	for _, s := range received {
+		if s.Additive || s.Modulus == nil || s.Modulus.Cmp(first.Modulus) != 0 || s.X == nil || s.X.Cmp(first.X) != 0 || s.Y == nil {
+			return Share{}, errors.New("shares are not Shamir shares at the same point")
+		}
+		y.Add(y, s.Y)
+	}
+	return Share{X: first.X, Y: y.Mod(y, first.Modulus), Modulus: first.Modulus}, nil
+}
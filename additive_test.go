@@ -0,0 +1,79 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAdditive(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, err := SplitAdditive(secret, MODP2048, 4, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	data, _ := shares[0].MarshalBinary()
+	if err := shares[0].UnmarshalBinary(data); err != nil || !shares[0].Additive {
+		t.Fatalf("additive flag didn't survive marshaling: %v", err)
+	}
+
+	result, err := JoinAdditive(shares)
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to join additive shares: %v", err)
+	}
+	if result, _ := JoinAdditive(shares[1:]); result.Cmp(secret) == 0 {
+		t.Errorf("joined too few additive shares")
+	}
+	if _, err := JoinShares(shares); err == nil {
+		t.Errorf("JoinShares accepted additive shares")
+	}
+}
+
+func TestAdditiveConversion(t *testing.T) {
+	secret := big.NewInt(42)
+	shamir, _ := SplitShares(secret, MODP2048, 3, 5, nil)
+
+	// Parties 1, 3 and 5 convert their Shamir shares to additive ones.
+	quorum := []Share{shamir[0], shamir[2], shamir[4]}
+	xs := []*big.Int{quorum[0].X, quorum[1].X, quorum[2].X}
+	var additive []Share
+	for _, s := range quorum {
+		a, err := ShamirToAdditive(s, xs)
+		if err != nil {
+			t.Fatalf("failed to convert to additive: %s", err)
+		}
+		additive = append(additive, a)
+	}
+	if result, err := JoinAdditive(additive); err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to join converted additive shares: %v", err)
+	}
+
+	// They then reshare to 2-of-4 Shamir shares.
+	received := make([][]Share, 4)
+	for _, a := range additive {
+		deal, err := SplitShares(a.Y, MODP2048, 2, 4, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, s := range deal {
+			received[j] = append(received[j], s)
+		}
+	}
+	var reshared []Share
+	for _, r := range received {
+		s, err := AdditiveToShamir(r)
+		if err != nil {
+			t.Fatalf("failed to convert to Shamir: %s", err)
+		}
+		reshared = append(reshared, s)
+	}
+	if result, err := JoinShares(reshared[2:]); err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to join reshared shares: %v", err)
+	}
+}
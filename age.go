@@ -0,0 +1,170 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// This file allows an age file key to be wrapped for a k-of-n quorum of
+// custodians. This package doesn't depend on filippo.io/age, so AgeStanza,
+// AgeRecipient and AgeIdentity mirror age.Stanza, age.Recipient and
+// age.Identity, and a small adapter is needed to use each custodian's
+// recipient or plugin, and to use a QuorumRecipient or QuorumIdentity, with
+// age itself.
+
+// An AgeStanza is a section of an age header. It has the same fields as
+// age.Stanza.
+type AgeStanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+// An AgeRecipient wraps a file key, like age.Recipient.
+type AgeRecipient interface {
+	Wrap(fileKey []byte) ([]*AgeStanza, error)
+}
+
+// An AgeIdentity unwraps a file key, like age.Identity. It returns
+// ErrAgeIncorrectIdentity if none of the stanzas are for it.
+type AgeIdentity interface {
+	Unwrap(stanzas []*AgeStanza) (fileKey []byte, err error)
+}
+
+// ErrAgeIncorrectIdentity plays the part of age.ErrIncorrectIdentity, which
+// an adapter should translate to and from it.
+var ErrAgeIncorrectIdentity = errors.New("incorrect identity for recipient block")
+
+// ageStanzaType is the type of the stanzas made by a QuorumRecipient. Their
+// arguments are the threshold and the share's x coordinate, followed by the
+// type and arguments of the custodian's stanza, whose body is used as is.
+const ageStanzaType = "shamirsplit"
+
+// A QuorumRecipient wraps a file key so that any Threshold of the custodians
+// are needed to unwrap it. The file key is split over GF(2^8) and each share
+// is wrapped to one of the Recipients.
+type QuorumRecipient struct {
+	Threshold  int
+	Recipients []AgeRecipient
+	// Rand is the source of randomness, or nil for crypto/rand.Reader.
+	Rand io.Reader
+}
+
+// Wrap implements AgeRecipient.
+func (q *QuorumRecipient) Wrap(fileKey []byte) ([]*AgeStanza, error) {
+	n := len(q.Recipients)
+	if q.Threshold < 1 || n < q.Threshold || n > 255 {
+		return nil, errors.New("invalid quorum parameters")
+	}
+	if len(fileKey) == 0 {
+		return nil, errors.New("empty file key")
+	}
+
+	xs := make([]byte, n)
+	for i := range xs {
+		xs[i] = byte(i + 1)
+	}
+	shares, err := gf256Split(fileKey, q.Threshold, xs, defaultRand(q.Rand))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, share := range shares {
+			clear(share)
+		}
+	}()
+
+	var stanzas []*AgeStanza
+	for i, r := range q.Recipients {
+		wrapped, err := r.Wrap(shares[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range wrapped {
+			args := []string{strconv.Itoa(q.Threshold), strconv.Itoa(int(xs[i])), s.Type}
+			stanzas = append(stanzas, &AgeStanza{
+				Type: ageStanzaType,
+				Args: append(args, s.Args...),
+				Body: s.Body,
+			})
+		}
+	}
+	return stanzas, nil
+}
+
+// A QuorumIdentity unwraps a file key wrapped by a QuorumRecipient using the
+// identities of the custodians who are present, at least the threshold number
+// of whom must be able to unwrap their shares.
+type QuorumIdentity struct {
+	Identities []AgeIdentity
+}
+
+// Unwrap implements AgeIdentity. It returns ErrAgeIncorrectIdentity if none
+// of the stanzas are from a QuorumRecipient and a *NotEnoughSharesError if
+// too few of them could be unwrapped.
+func (q *QuorumIdentity) Unwrap(stanzas []*AgeStanza) ([]byte, error) {
+	threshold := 0
+	var xs []byte
+	var ys [][]byte
+	defer func() {
+		for _, y := range ys {
+			clear(y)
+		}
+	}()
+
+	for _, s := range stanzas {
+		if s.Type != ageStanzaType {
+			continue
+		}
+		if len(s.Args) < 3 {
+			return nil, errors.New("malformed shamirsplit stanza")
+		}
+		k, err := strconv.Atoi(s.Args[0])
+		if err != nil || k < 1 || k > 255 || (threshold != 0 && k != threshold) {
+			return nil, errors.New("invalid threshold in shamirsplit stanza")
+		}
+		threshold = k
+		x, err := strconv.Atoi(s.Args[1])
+		if err != nil || x < 1 || x > 255 {
+			return nil, errors.New("invalid share number in shamirsplit stanza")
+		}
+		if bytes.IndexByte(xs, byte(x)) >= 0 {
+			continue
+		}
+
+		inner := []*AgeStanza{{Type: s.Args[2], Args: s.Args[3:], Body: s.Body}}
+		for _, id := range q.Identities {
+			y, err := id.Unwrap(inner)
+			if err == ErrAgeIncorrectIdentity {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(ys) > 0 && len(y) != len(ys[0]) {
+				return nil, errors.New("unwrapped shares have different lengths")
+			}
+			xs = append(xs, byte(x))
+			ys = append(ys, y)
+			break
+		}
+		if len(xs) == threshold {
+			fileKey := make([]byte, len(ys[0]))
+			gf256InterpolateAt(xs, ys, 0, fileKey)
+			return fileKey, nil
+		}
+	}
+
+	if threshold == 0 {
+		return nil, ErrAgeIncorrectIdentity
+	}
+	return nil, &NotEnoughSharesError{Need: threshold, Have: len(xs)}
+}
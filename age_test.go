@@ -0,0 +1,81 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+)
+
+// testCustodian is an AgeRecipient and AgeIdentity that encrypts with
+// AES-GCM under its own key.
+type testCustodian struct {
+	name string
+	key  [32]byte
+}
+
+func (c *testCustodian) aead() cipher.AEAD {
+	block, _ := aes.NewCipher(c.key[:])
+	aead, _ := cipher.NewGCM(block)
+	return aead
+}
+
+func (c *testCustodian) Wrap(fileKey []byte) ([]*AgeStanza, error) {
+	body := c.aead().Seal(nil, make([]byte, 12), fileKey, nil)
+	return []*AgeStanza{{Type: "test", Args: []string{c.name}, Body: body}}, nil
+}
+
+func (c *testCustodian) Unwrap(stanzas []*AgeStanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type != "test" || len(s.Args) != 1 || s.Args[0] != c.name {
+			continue
+		}
+		return c.aead().Open(nil, make([]byte, 12), s.Body, nil)
+	}
+	return nil, ErrAgeIncorrectIdentity
+}
+
+func TestAgeQuorum(t *testing.T) {
+	custodians := make([]*testCustodian, 4)
+	recipients := make([]AgeRecipient, len(custodians))
+	for i := range custodians {
+		custodians[i] = &testCustodian{name: string(rune('a' + i))}
+		custodians[i].key[0] = byte(i)
+		recipients[i] = custodians[i]
+	}
+
+	fileKey := []byte("0123456789abcdef")
+	q := &QuorumRecipient{Threshold: 3, Recipients: recipients}
+	stanzas, err := q.Wrap(fileKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stanzas) != 4 {
+		t.Fatalf("got %d stanzas, want 4", len(stanzas))
+	}
+
+	id := &QuorumIdentity{Identities: []AgeIdentity{custodians[3], custodians[0], custodians[2]}}
+	result, err := id.Unwrap(stanzas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, fileKey) {
+		t.Errorf("got %x, want %x", result, fileKey)
+	}
+
+	id = &QuorumIdentity{Identities: []AgeIdentity{custodians[1], custodians[2]}}
+	if _, err := id.Unwrap(stanzas); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("two custodians gave %v, want ErrNotEnoughShares", err)
+	}
+
+	if _, err := id.Unwrap([]*AgeStanza{{Type: "X25519"}}); err != ErrAgeIncorrectIdentity {
+		t.Errorf("foreign stanza gave %v, want ErrAgeIncorrectIdentity", err)
+	}
+}
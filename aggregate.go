@@ -0,0 +1,115 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// An Aggregator sums, for a single participant, the shares that several
+// independent dealers each dealt to the same set of participants. Once every
+// participant has added the shares from the same dealers, the results of
+// Share are a sharing of the sum of the dealers' secrets, and no dealer's
+// secret is ever reconstructed.
+type Aggregator struct {
+	// X is the participant's x coordinate, Modulus the field and Threshold
+	// the number of shares that the dealers agreed would be needed.
+	X         *big.Int
+	Modulus   *big.Int
+	Threshold int
+
+	sum     *big.Int
+	dealers map[string]bool
+}
+
+// NewAggregator returns an Aggregator for the participant with x coordinate
+// x.
+func NewAggregator(x, modulus *big.Int, threshold int) (*Aggregator, error) {
+	if modulus == nil || modulus.Sign() <= 0 || threshold < 1 {
+		return nil, errors.New("invalid aggregation parameters")
+	}
+	if x == nil || x.Sign() <= 0 || x.Cmp(modulus) >= 0 {
+		return nil, errors.New("invalid x coordinate")
+	}
+	return &Aggregator{
+		X:         x,
+		Modulus:   modulus,
+		Threshold: threshold,
+		sum:       new(big.Int),
+		dealers:   make(map[string]bool),
+	}, nil
+}
+
+// Add adds the share that the named dealer dealt to this participant. The
+// share must be for the aggregator's x coordinate and field and, if it
+// carries a threshold in its metadata, for the aggregator's threshold. Each
+// dealer may only contribute once.
+func (a *Aggregator) Add(dealer string, share Share) error {
+	if a.dealers[dealer] {
+		return errors.New("dealer has already contributed")
+	}
+	if share.Modulus == nil || share.Modulus.Cmp(a.Modulus) != 0 {
+		return errors.New("share is from a different field")
+	}
+	if share.X == nil || share.Y == nil {
+		return errors.New("share is missing coordinates")
+	}
+	if share.X.Cmp(a.X) != 0 {
+		return errors.New("share is for a different participant")
+	}
+	if share.Additive {
+		return errors.New("cannot aggregate additive shares")
+	}
+	if share.Metadata != nil && share.Metadata.Threshold != 0 && share.Metadata.Threshold != a.Threshold {
+		return errors.New("share has a different threshold")
+	}
+
+	a.sum.Add(a.sum, share.Y)
+	a.sum.Mod(a.sum, a.Modulus)
+	a.dealers[dealer] = true
+	return nil
+}
+
+// Dealers returns the names of the dealers that have contributed, in sorted
+// order. Participants must agree on the dealers before joining their
+// aggregated shares, otherwise the result will be wrong.
+func (a *Aggregator) Dealers() []string {
+	dealers := make([]string, 0, len(a.dealers))
+	for d := range a.dealers {
+		dealers = append(dealers, d)
+	}
+	sort.Strings(dealers)
+	return dealers
+}
+
+// Share returns the participant's share of the sum of the dealers' secrets.
+// Its metadata records the threshold and a SetID derived from the set of
+// dealers, so shares aggregated over different dealers can be told apart.
+func (a *Aggregator) Share() (Share, error) {
+	if len(a.dealers) == 0 {
+		return Share{}, errors.New("no dealers have contributed")
+	}
+
+	h := sha256.New()
+	for _, d := range a.Dealers() {
+		h.Write(binary.AppendUvarint(nil, uint64(len(d))))
+		h.Write([]byte(d))
+	}
+	m := &Metadata{Threshold: a.Threshold}
+	copy(m.SetID[:], h.Sum(nil))
+
+	return Share{
+		X:        a.X,
+		Y:        new(big.Int).Set(a.sum),
+		Modulus:  a.Modulus,
+		Metadata: m,
+	}, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAggregator(t *testing.T) {
+	const k, n = 3, 5
+	dealers := map[string]int64{"alice": 10, "bob": 20, "carol": 30}
+
+	aggregators := make([]*Aggregator, n)
+	for i := range aggregators {
+		var err error
+		if aggregators[i], err = NewAggregator(big.NewInt(int64(i+1)), P256Order, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, secret := range dealers {
+		shares, _ := SplitShares(big.NewInt(secret), P256Order, k, n, nil)
+		for i, s := range shares {
+			if err := aggregators[i].Add(name, s); err != nil {
+				t.Fatalf("failed to add share: %s", err)
+			}
+		}
+	}
+
+	if err := aggregators[0].Add("alice", Share{X: big.NewInt(1), Y: big.NewInt(1), Modulus: P256Order}); err == nil {
+		t.Errorf("dealer contributed twice")
+	}
+	if err := aggregators[0].Add("dave", Share{X: big.NewInt(2), Y: big.NewInt(1), Modulus: P256Order}); err == nil {
+		t.Errorf("added share for a different participant")
+	}
+
+	first, err := aggregators[0].Share()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shares []Share
+	for _, a := range aggregators[1:4] {
+		s, err := a.Share()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Metadata.SetID != first.Metadata.SetID {
+			t.Errorf("aggregated shares have different set IDs")
+		}
+		shares = append(shares, s)
+	}
+	if result, err := JoinShares(shares); err != nil || result.Int64() != 60 {
+		t.Errorf("failed to join aggregated shares: got %v, %v", result, err)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DealingParams describe a proposed dealing, for Analyze.
+type DealingParams struct {
+	Modulus *big.Int
+	// Threshold is the number of shares needed to recover the secret and
+	// Shares the number dealt.
+	Threshold, Shares int
+	// SecretBits is the size of the secret, or zero if it's a uniformly
+	// random element of the field.
+	SecretBits int
+}
+
+// A Report describes the strength of a dealing, as returned by Analyze.
+type Report struct {
+	// SecurityBits is the base 2 logarithm of the number of possible
+	// secrets: with fewer than the threshold number of shares, an attacker
+	// can do no better than guessing.
+	SecurityBits int
+	// SlackBits is the number of bits by which the field is larger than
+	// the secret. It's negative if the secret doesn't fit.
+	SlackBits int
+	// Prime is true if the modulus is prime. Sharing over a composite
+	// modulus isn't secure and Join may fail.
+	Prime bool
+	// SafePrime is true if the modulus is a safe prime, as from
+	// GenerateSafePrimeModulus.
+	SafePrime bool
+	// Valid is true if the dealing can be performed at all.
+	Valid bool
+	// Warnings describe each problem found, in English.
+	Warnings []string
+}
+
+// MinSecurityBits is the security level below which Analyze warns.
+const MinSecurityBits = 128
+
+// Analyze reports on the security of the dealing described by p, for
+// security review. It doesn't deal.
+func Analyze(p DealingParams) *Report {
+	r := &Report{Valid: true}
+	warn := func(valid bool, s string) {
+		r.Warnings = append(r.Warnings, s)
+		r.Valid = r.Valid && valid
+	}
+
+	if p.Modulus == nil || p.Modulus.Cmp(big.NewInt(2)) < 0 {
+		warn(false, "no usable modulus")
+		return r
+	}
+
+	fieldBits := p.Modulus.BitLen() - 1
+	secretBits := p.SecretBits
+	if secretBits <= 0 {
+		secretBits = fieldBits
+	}
+	r.SlackBits = fieldBits - secretBits
+	r.SecurityBits = min(secretBits, fieldBits)
+	r.Prime = p.Modulus.ProbablyPrime(20)
+	r.SafePrime = r.Prime && IsSafePrime(p.Modulus)
+
+	if !r.Prime {
+		warn(false, "modulus is not prime")
+	}
+	if r.SlackBits < 0 {
+		warn(false, "secret is larger than the field by "+strconv.Itoa(-r.SlackBits)+" bits")
+	}
+	if r.SecurityBits < MinSecurityBits {
+		warn(true, "security level of "+strconv.Itoa(r.SecurityBits)+" bits is below "+strconv.Itoa(MinSecurityBits))
+	}
+
+	k, n := p.Threshold, p.Shares
+	switch {
+	case k < 1 || n < k:
+		warn(false, "threshold must be between 1 and the number of shares")
+	case p.Modulus.Cmp(big.NewInt(int64(n))) <= 0:
+		warn(false, "field is too small for the number of shares")
+	case k > DefaultLimits.MaxThreshold || n > DefaultLimits.MaxShares:
+		warn(false, "threshold or number of shares exceeds DefaultLimits")
+	case k == 1:
+		warn(true, "threshold of one: every share reveals the secret")
+	case k == n:
+		warn(true, "threshold equals the number of shares: losing any share loses the secret")
+	}
+	return r
+}
+
+// MinimumModulusBits returns the size, in bits, of the smallest modulus that
+// can share a secret of secretLen bytes at a security level of securityBits,
+// which is the larger of the secret and the security level, plus one so that
+// every secret is less than the modulus.
+func MinimumModulusBits(secretLen, securityBits int) int {
+	return max(8*secretLen, securityBits) + 1
+}
+
+// RecommendModulus returns the name of the smallest standard modulus (see
+// NamedModulus) that can share a secret of secretLen bytes at a security
+// level of securityBits, or an error if the secret is too large for any of
+// them, in which case SplitChunked is suitable.
+func RecommendModulus(secretLen, securityBits int) (string, error) {
+	bits := MinimumModulusBits(secretLen, securityBits)
+	names := make([]string, 0, len(namedModuli))
+	for name := range namedModuli {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		bi, bj := namedModuli[names[i]].BitLen(), namedModuli[names[j]].BitLen()
+		if bi != bj {
+			return bi < bj
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		if namedModuli[name].BitLen() >= bits {
+			return name, nil
+		}
+	}
+	return "", errors.New("secret is too large for the standard moduli; use SplitChunked")
+}
+
+// CheckParams returns an error if a dealing of n shares over modulus with
+// threshold k can't be performed, listing the problems that Analyze finds.
+// Warnings that don't prevent the dealing are ignored.
+func CheckParams(modulus *big.Int, k, n int) error {
+	r := Analyze(DealingParams{Modulus: modulus, Threshold: k, Shares: n})
+	if r.Valid {
+		return nil
+	}
+	return errors.New("invalid dealing parameters: " + strings.Join(r.Warnings, "; "))
+}
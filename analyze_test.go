@@ -0,0 +1,74 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	p256 := NamedModulus("p256")
+	tests := []struct {
+		params   DealingParams
+		valid    bool
+		warnings int
+	}{
+		{DealingParams{Modulus: p256, Threshold: 3, Shares: 5, SecretBits: 128}, true, 0},
+		{DealingParams{Modulus: p256, Threshold: 1, Shares: 5}, true, 1},
+		{DealingParams{Modulus: p256, Threshold: 5, Shares: 5}, true, 1},
+		{DealingParams{Modulus: big.NewInt(257), Threshold: 2, Shares: 3}, true, 1},
+		{DealingParams{Modulus: big.NewInt(256), Threshold: 2, Shares: 3}, false, 2},
+		{DealingParams{Modulus: p256, Threshold: 3, Shares: 5, SecretBits: 512}, false, 1},
+		{DealingParams{Modulus: big.NewInt(7), Threshold: 2, Shares: 7}, false, 2},
+		{DealingParams{Threshold: 2, Shares: 3}, false, 1},
+	}
+	for i, test := range tests {
+		r := Analyze(test.params)
+		if r.Valid != test.valid || len(r.Warnings) != test.warnings {
+			t.Errorf("#%d: got valid %t with warnings %q, want %t with %d warnings", i, r.Valid, r.Warnings, test.valid, test.warnings)
+		}
+	}
+
+	r := Analyze(DealingParams{Modulus: p256, Threshold: 2, Shares: 3, SecretBits: 200})
+	if r.SecurityBits != 200 || r.SlackBits != p256.BitLen()-1-200 || !r.Prime {
+		t.Errorf("unexpected report: %+v", r)
+	}
+}
+
+func TestSizingHelpers(t *testing.T) {
+	if bits := MinimumModulusBits(16, 128); bits != 129 {
+		t.Errorf("MinimumModulusBits(16, 128) = %d, want 129", bits)
+	}
+	if bits := MinimumModulusBits(8, 128); bits != 129 {
+		t.Errorf("MinimumModulusBits(8, 128) = %d, want 129", bits)
+	}
+
+	tests := []struct {
+		secretLen int
+		name      string
+	}{
+		{16, "edwards25519"},
+		{32, "modp1536"},
+		{300, "modp3072"},
+	}
+	for _, test := range tests {
+		if name, err := RecommendModulus(test.secretLen, 128); err != nil || name != test.name {
+			t.Errorf("RecommendModulus(%d, 128) = %q, %v, want %q", test.secretLen, name, err, test.name)
+		}
+	}
+	if _, err := RecommendModulus(2000, 128); err == nil {
+		t.Error("recommended a modulus for a 2000 byte secret")
+	}
+
+	if err := CheckParams(NamedModulus("p256"), 3, 5); err != nil {
+		t.Errorf("valid parameters were rejected: %s", err)
+	}
+	if err := CheckParams(big.NewInt(15), 2, 3); err == nil {
+		t.Error("composite modulus was accepted")
+	}
+}
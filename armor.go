@@ -0,0 +1,167 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Armored shares follow the ASCII armor of OpenPGP (RFC 4880, section 6.2):
+//
+//	-----BEGIN SHAMIRSPLIT SHARE-----
+//	Set-ID: <set ID in hex>
+//	Share: <x>
+//	Threshold: <k>
+//
+//	<binary encoding of the share in base64, 64 columns>
+//	=<CRC-24 of the binary encoding in base64>
+//	-----END SHAMIRSPLIT SHARE-----
+//
+// The headers, other than Share, are omitted if the share doesn't record
+// them. As with share URIs, they're for the benefit of people and the share
+// itself is authoritative.
+const (
+	armorBegin = "-----BEGIN SHAMIRSPLIT SHARE-----"
+	armorEnd   = "-----END SHAMIRSPLIT SHARE-----"
+)
+
+// Armor returns the ASCII-armored form of s, which can be pasted into emails,
+// tickets and password managers and recognized on sight.
+func Armor(s *Share) ([]byte, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString(armorBegin + "\n")
+	for _, h := range armorHeaders(s) {
+		b.WriteString(h[0] + ": " + h[1] + "\n")
+	}
+	b.WriteString("\n")
+	body := base64.StdEncoding.EncodeToString(data)
+	for len(body) > 64 {
+		b.WriteString(body[:64] + "\n")
+		body = body[64:]
+	}
+	b.WriteString(body + "\n")
+	crc := crc24(data)
+	b.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}) + "\n")
+	b.WriteString(armorEnd + "\n")
+	return b.Bytes(), nil
+}
+
+// Dearmor parses the first armored share in text, which may be surrounded by
+// other text and have had its line endings changed. It returns an error if
+// the checksum, or any header, doesn't match the share.
+func Dearmor(text []byte) (s Share, err error) {
+	lines := strings.Split(strings.ReplaceAll(string(text), "\r\n", "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	start := -1
+	for i, line := range lines {
+		if line == armorBegin {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return s, errors.New("no armored share found")
+	}
+
+	headers := make(map[string]string)
+	i := start
+	for ; i < len(lines) && lines[i] != ""; i++ {
+		name, value, ok := strings.Cut(lines[i], ":")
+		if !ok {
+			// There's no blank line after the headers if there
+			// are none, and base64 has no colons.
+			break
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+
+	var body, checksum string
+	for ; ; i++ {
+		if i >= len(lines) {
+			return s, errors.New("truncated armored share")
+		}
+		line := lines[i]
+		if line == armorEnd {
+			break
+		}
+		if strings.HasPrefix(line, "=") {
+			checksum = line[1:]
+			continue
+		}
+		body += line
+	}
+
+	data, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return s, errors.New("malformed armored share")
+	}
+	crc, err := base64.StdEncoding.DecodeString(checksum)
+	if err != nil || len(crc) != 3 {
+		return s, errors.New("armored share has no valid checksum")
+	}
+	if uint32(crc[0])<<16|uint32(crc[1])<<8|uint32(crc[2]) != crc24(data) {
+		return s, errors.New("armored share failed its checksum")
+	}
+
+	if err = s.UnmarshalBinary(data); err != nil {
+		return
+	}
+	want := armorHeaders(&s)
+	if len(want) != len(headers) {
+		return Share{}, errors.New("armor headers don't match the share")
+	}
+	for _, h := range want {
+		if headers[h[0]] != h[1] {
+			return Share{}, errors.New("armor headers don't match the share")
+		}
+	}
+	return
+}
+
+// armorHeaders returns the armor headers, as name and value, for s.
+func armorHeaders(s *Share) (headers [][2]string) {
+	if m := s.Metadata; m != nil && m.SetID != [16]byte{} {
+		headers = append(headers, [2]string{"Set-ID", hex.EncodeToString(m.SetID[:])})
+	}
+	x := s.X
+	if x == nil {
+		x = new(big.Int)
+	}
+	headers = append(headers, [2]string{"Share", x.String()})
+	if m := s.Metadata; m != nil && m.Threshold > 0 {
+		headers = append(headers, [2]string{"Threshold", strconv.Itoa(m.Threshold)})
+	}
+	return
+}
+
+// crc24 returns the CRC-24 of data, as used by OpenPGP.
+func crc24(data []byte) uint32 {
+	crc := uint32(0xb704ce)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864cfb
+			}
+		}
+	}
+	return crc & 0xffffff
+}
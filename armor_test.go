@@ -0,0 +1,58 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestCRC24(t *testing.T) {
+	// The CRC-24 of "123456789", the standard check value for
+	// CRC-24/OPENPGP.
+	if got := crc24([]byte("123456789")); got != 0x21cf02 {
+		t.Errorf("got %06x, want 21cf02", got)
+	}
+}
+
+func TestArmor(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitShares(big.NewInt(5), modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	armored, err := Armor(&shares[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(armored, []byte(armorBegin+"\n")) || !bytes.Contains(armored, []byte("\nShare: 2\n")) {
+		t.Errorf("unexpected armor:\n%s", armored)
+	}
+
+	// Pasting into an email adds surrounding text and CRLFs.
+	pasted := "Here's my share:\r\n\r\n" + strings.ReplaceAll(string(armored), "\n", "\r\n") + "\r\nThanks\r\n"
+	s, err := Dearmor([]byte(pasted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.X.Cmp(shares[1].X) != 0 || s.Y.Cmp(shares[1].Y) != 0 {
+		t.Error("dearmored share doesn't match")
+	}
+
+	lines := strings.Split(string(armored), "\n")
+	body := len(lines) - 5
+	corrupt := strings.Join(lines[:body], "\n") + "\n" + strings.ToUpper(lines[body]) + "\n" + strings.Join(lines[body+1:], "\n")
+	if _, err := Dearmor([]byte(corrupt)); err == nil {
+		t.Error("corrupt armor was accepted")
+	}
+	if _, err := Dearmor([]byte(strings.Replace(string(armored), "Share: 2", "Share: 3", 1))); err == nil {
+		t.Error("armor with the wrong share number was accepted")
+	}
+}
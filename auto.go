@@ -0,0 +1,125 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// SplitAuto splits secret into n shares, any k of which can be combined by
+// JoinAuto to recover it. The field is chosen automatically: the smallest
+// standard modulus that is larger than any secret of the same length. The
+// modulus and the length of the secret are recorded in each share.
+//
+// Secrets longer than 1023 bytes are too large for every standard modulus.
+// Generating a prime that size would take minutes and give shares that
+// ParseShare rejects by default, so they're rejected instead; split them
+// with SplitChunked over a standard modulus.
+func SplitAuto(secret []byte, k, n int) ([]Share, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+
+	modulus, err := autoModulus(8 * len(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := SplitShares(new(big.Int).SetBytes(secret), modulus, k, n, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range shares {
+		shares[i].SecretLen = len(secret)
+	}
+	return shares, nil
+}
+
+// JoinAuto recovers the secret from at least k shares that resulted from
+// SplitAuto.
+func JoinAuto(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	secretLen := shares[0].SecretLen
+	if secretLen <= 0 {
+		return nil, errors.New("shares don't record the secret length")
+	}
+	for _, s := range shares {
+		if s.SecretLen != secretLen {
+			return nil, errors.New("shares are from different splits")
+		}
+	}
+
+	secret, err := JoinShares(shares)
+	if err != nil {
+		return nil, err
+	}
+	if secret.BitLen() > 8*secretLen {
+		return nil, errors.New("recovered value is too large: too few or corrupt shares")
+	}
+	return secret.FillBytes(make([]byte, secretLen)), nil
+}
+
+// JoinInto is like JoinAuto, but writes the secret into dst, such as memory
+// that the application has locked, rather than allocating for it, and
+// returns its length. dst must be at least as long as the secret. The
+// temporaries used in recovering the secret are wiped before JoinInto
+// returns, although math/big may make internal allocations, which can't be.
+// If an error is returned, nothing has been written to dst.
+func JoinInto(dst []byte, shares []Share) (n int, err error) {
+	if len(shares) == 0 {
+		return 0, errors.New("no shares given")
+	}
+
+	secretLen := shares[0].SecretLen
+	if secretLen <= 0 {
+		return 0, errors.New("shares don't record the secret length")
+	}
+	for _, s := range shares {
+		if s.SecretLen != secretLen {
+			return 0, errors.New("shares are from different splits")
+		}
+	}
+	if len(dst) < secretLen {
+		return 0, errors.New("buffer is too short for the secret")
+	}
+
+	secret, err := JoinShares(shares)
+	if err != nil {
+		return 0, err
+	}
+	defer clear(secret.Bits())
+	if secret.BitLen() > 8*secretLen {
+		return 0, errors.New("recovered value is too large: too few or corrupt shares")
+	}
+	secret.FillBytes(dst[:secretLen])
+	return secretLen, nil
+}
+
+// autoModulus returns the smallest standard modulus that exceeds 2^bits.
+func autoModulus(bits int) (*big.Int, error) {
+	var candidates []*big.Int
+	for _, m := range namedModuli {
+		if m.BitLen() > bits {
+			candidates = append(candidates, m)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, errors.New("secret is too large for SplitAuto; use SplitChunked")
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Cmp(candidates[j]) < 0
+	})
+	return candidates[0], nil
+}
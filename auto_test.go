@@ -0,0 +1,122 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSplitAuto(t *testing.T) {
+	for _, secret := range [][]byte{
+		[]byte("\x00\x00short"),
+		bytes.Repeat([]byte{0xff}, 32),
+		bytes.Repeat([]byte{0x01}, 200),
+		// The largest secret that MODP8192 can hold.
+		bytes.Repeat([]byte{0xff}, 1023),
+	} {
+		shares, err := SplitAuto(secret, 3, 5)
+		if err != nil {
+			t.Fatalf("error while splitting: %s", err)
+		}
+		if shares[0].Modulus.BitLen() <= 8*len(secret) {
+			t.Errorf("modulus is too small")
+		}
+
+		// Round trip the shares through their binary encoding so
+		// that the field has to be recovered from it.
+		parsed := make([]Share, 3)
+		for i := range parsed {
+			data, err := shares[4-i].MarshalBinary()
+			if err != nil {
+				t.Fatalf("error while marshaling: %s", err)
+			}
+			if err := parsed[i].UnmarshalBinary(data); err != nil {
+				t.Fatalf("error while unmarshaling: %s", err)
+			}
+		}
+
+		result, err := JoinAuto(parsed)
+		if err != nil {
+			t.Fatalf("failed to join shares: %s", err)
+		}
+		if !bytes.Equal(result, secret) {
+			t.Errorf("JoinAuto returned %x, want %x", result, secret)
+		}
+	}
+}
+
+func TestSplitAutoTooLarge(t *testing.T) {
+	secret := make([]byte, 1100)
+	rand.Read(secret)
+	if _, err := SplitAuto(secret, 2, 3); err == nil {
+		t.Fatal("secret larger than every standard modulus was split")
+	}
+
+	// Such a secret can be split with SplitChunked over the largest
+	// standard modulus instead.
+	shares, err := SplitChunked(secret, MODP8192, 2, 3, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	parsed := make([]ChunkedShare, 2)
+	for i := range parsed {
+		data, err := shares[2*i].MarshalBinary()
+		if err != nil {
+			t.Fatalf("error while marshaling: %s", err)
+		}
+		if parsed[i], err = ParseChunkedShare(data, nil); err != nil {
+			t.Fatalf("error while parsing: %s", err)
+		}
+	}
+	result, err := JoinChunked(parsed)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if !bytes.Equal(result, secret) {
+		t.Errorf("JoinChunked didn't return the secret")
+	}
+}
+
+func TestJoinAutoMixedShares(t *testing.T) {
+	a, _ := SplitAuto([]byte("secret a"), 2, 3)
+	b, _ := SplitAuto([]byte("secret number b"), 2, 3)
+
+	if _, err := JoinAuto([]Share{a[0], b[1]}); err == nil {
+		t.Errorf("shares from different splits were joined")
+	}
+}
+
+func TestJoinInto(t *testing.T) {
+	secret := []byte("\x00correct horse battery staple")
+	shares, err := SplitAuto(secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := bytes.Repeat([]byte{0xaa}, len(secret)+8)
+	n, err := JoinInto(dst, shares[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(secret) || !bytes.Equal(dst[:n], secret) {
+		t.Errorf("JoinInto wrote %x, want %x", dst[:n], secret)
+	}
+	if !bytes.Equal(dst[n:], bytes.Repeat([]byte{0xaa}, 8)) {
+		t.Errorf("JoinInto wrote past the secret: %x", dst[n:])
+	}
+
+	short := make([]byte, len(secret)-1)
+	if _, err := JoinInto(short, shares); err == nil {
+		t.Error("JoinInto accepted a short buffer")
+	}
+	b, _ := SplitAuto([]byte("another secret"), 2, 3)
+	if _, err := JoinInto(dst, []Share{shares[0], b[1]}); err == nil {
+		t.Error("shares from different splits were joined")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"io"
+	"math/big"
+)
+
+// batchSecurityBits is the size of the random coefficients used by
+// VerifyBatch, and so the security level of the check.
+const batchSecurityBits = 128
+
+// VerifyBatch returns the indices of the shares that don't lie on the
+// polynomial committed to by c, as calling Verify for each would, but checks
+// them all at once, with about k+1 exponentiations instead of k per share.
+//
+// It uses the random linear combination test of Bellare, Garay and Rabin:
+// the shares are valid iff g^(Σ r_i y_i) = Π_j C_j^(Σ_i r_i x_i^j) for
+// random r_i, except with probability 2^-128, given that the commitments
+// are in the subgroup generated by g. Only if the combined check fails are
+// the shares checked individually to find the invalid ones. Exponents are
+// reduced modulo p-1, so the order of g isn't needed. If rand is nil,
+// crypto/rand.Reader is used.
+func (c *Commitments) VerifyBatch(shares []Share, rand io.Reader) (invalid []int, err error) {
+	if len(shares) == 0 {
+		return nil, nil
+	}
+	if len(c.Values) == 0 {
+		for i := range shares {
+			invalid = append(invalid, i)
+		}
+		return invalid, nil
+	}
+
+	order := new(big.Int).Sub(c.P, big.NewInt(1))
+	bound := new(big.Int).Lsh(big.NewInt(1), batchSecurityBits)
+	rand = defaultRand(rand)
+
+	ySum := new(big.Int)
+	exps := make([]*big.Int, len(c.Values))
+	for j := range exps {
+		exps[j] = new(big.Int)
+	}
+	batchable := true
+	t := new(big.Int)
+	for _, s := range shares {
+		if s.X == nil || s.Y == nil || s.X.Sign() < 0 || s.Y.Sign() < 0 || shareEpoch(&s) != c.Epoch {
+			batchable = false
+			break
+		}
+		r, err := randomNumber(rand, bound)
+		if err != nil {
+			return nil, err
+		}
+		ySum.Add(ySum, t.Mul(r, s.Y))
+		ySum.Mod(ySum, order)
+
+		// r·x^j for each j.
+		for j := range exps {
+			exps[j].Add(exps[j], r)
+			exps[j].Mod(exps[j], order)
+			r.Mul(r, s.X)
+			r.Mod(r, order)
+		}
+	}
+
+	if batchable {
+		lhs := new(big.Int).Exp(c.G, ySum, c.P)
+		rhs := big.NewInt(1)
+		for j, v := range c.Values {
+			rhs.Mul(rhs, t.Exp(v, exps[j], c.P))
+			rhs.Mod(rhs, c.P)
+		}
+		if lhs.Cmp(rhs) == 0 {
+			return nil, nil
+		}
+	}
+
+	for i := range shares {
+		if !c.Verify(shares[i]) {
+			invalid = append(invalid, i)
+		}
+	}
+	return invalid, nil
+}
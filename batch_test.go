@@ -0,0 +1,62 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	set, err := SplitVerifiable(big.NewInt(42), q, p, big.NewInt(4), 3, 20, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := set.Commitments
+
+	invalid, err := c.VerifyBatch(set.Shares, rand.Reader)
+	if err != nil || invalid != nil {
+		t.Errorf("valid shares gave %v, %v", invalid, err)
+	}
+
+	shares := append([]Share(nil), set.Shares...)
+	shares[3].Y = new(big.Int).Add(shares[3].Y, big.NewInt(1))
+	shares[17].X = big.NewInt(100)
+	invalid, err = c.VerifyBatch(shares, rand.Reader)
+	if err != nil || !reflect.DeepEqual(invalid, []int{3, 17}) {
+		t.Errorf("corrupt shares gave %v, %v", invalid, err)
+	}
+}
+
+func TestCombinerAddBatch(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	set, err := Deal(big.NewInt(42), 6, WithThreshold(3), WithField(q), WithCommitments(p, big.NewInt(4)), WithRand(rand.Reader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := append([]Share(nil), set.Shares[:4]...)
+	shares[1].Y = new(big.Int).Add(shares[1].Y, big.NewInt(1))
+	shares = append(shares, set.Shares[0])
+
+	comb, _ := NewCombiner(0, set.Commitments)
+	errs := comb.AddBatch(shares)
+	if len(errs) != len(shares) || errs[0] != nil || errs[1] != ErrCorruptShare || errs[2] != nil || errs[4] == nil {
+		t.Fatalf("got errors %v", errs)
+	}
+	st := comb.Status()
+	if st.Received != 5 || st.Accepted != 3 || st.Invalid != 1 || st.Duplicates != 1 {
+		t.Errorf("got status %+v", st)
+	}
+	if got, err := comb.Combine(); err != nil || got.Int64() != 42 {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
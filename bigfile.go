@@ -0,0 +1,597 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// A file share uses the encoding of binary shares (see wire.go) with its own
+// magic and tags. The shared data is the value of the last record, so that
+// it can be used in place in a mapping of the file. Each byte of the file is
+// shared over GF(2^8), as by SplitVaultCompatible, so a file share is the
+// size of the file plus a short header.
+//
+// The data is followed by a hash tree, as in merkle.go, over the file a
+// window at a time, so that JoinFiles can check each chunk as it recovers
+// it. Its leaves are keyed with a random key that is shared along with the
+// file, so that the hashes in a single share reveal nothing about the file.
+const fileShareMagic = "SHMF"
+
+const (
+	tagFileSetID       = 1
+	tagFileThreshold   = 2
+	tagFileX           = 3
+	tagFileLen         = 4
+	tagFileData        = 5
+	tagFileHashKey     = 6
+	tagFileRoot        = 7
+	tagFileChunkHashes = 8
+)
+
+// fileWindow is the number of bytes of a file that are processed at a time.
+// The scratch space needed is k times this, whatever the size of the file.
+const fileWindow = 1 << 20
+
+// SplitFile splits the file at src into a share for each of dsts, any k of
+// which can be combined by JoinFiles to recover it, while fewer reveal
+// nothing about it beyond its length. There can be at most 255 shares. The
+// destination files must not exist and are created with mode 0600.
+//
+// Files are memory mapped, where the system supports it, and processed a
+// window at a time, so that very large files can be split without
+// correspondingly large allocations. On error, the destination files are
+// removed. If rand is nil, crypto/rand.Reader is used.
+//
+// Each share records a hash of every window of the file, so that JoinFiles
+// can detect corruption at the chunk where it occurs.
+func SplitFile(src string, dsts []string, k int, rand io.Reader) (err error) {
+	n := len(dsts)
+	if k < 1 || n < k || n > 255 {
+		return errors.New("invalid split parameters")
+	}
+	rand = defaultRand(rand)
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() > math.MaxInt {
+		return errors.New("file is too large")
+	}
+	size := int(fi.Size())
+	input, err := mapFile(in, size, false)
+	if err != nil {
+		return err
+	}
+	defer input.unmap()
+
+	var setID [16]byte
+	if _, err := io.ReadFull(rand, setID[:]); err != nil {
+		return err
+	}
+
+	var created []string
+	defer func() {
+		if err != nil {
+			for _, path := range created {
+				os.Remove(path)
+			}
+		}
+	}()
+
+	xs := make([]byte, n)
+	for i := range xs {
+		xs[i] = byte(i + 1)
+	}
+	random := make([]byte, (k-1)*max(min(size, fileWindow), sha256.Size))
+	defer clear(random)
+
+	var hashKey [sha256.Size]byte
+	defer clear(hashKey[:])
+	if _, err := io.ReadFull(rand, hashKey[:]); err != nil {
+		return err
+	}
+	keyShares := make([][]byte, n)
+	for i := range keyShares {
+		keyShares[i] = make([]byte, len(hashKey))
+	}
+	if err := gf256SplitWindow(hashKey[:], k, xs, rand, random, keyShares); err != nil {
+		return err
+	}
+
+	var root [sha256.Size]byte
+	leaves := make([][sha256.Size]byte, fileChunks(size))
+	outputs := make([]*mapping, n)
+	bodies := make([][]byte, n)
+	trailers := make([][]byte, n)
+	defer func() {
+		for _, m := range outputs {
+			if m != nil {
+				m.unmap()
+			}
+		}
+	}()
+	for i, dst := range dsts {
+		var r wireRecords
+		r.add(tagFileSetID, setID[:])
+		r.addUint(tagFileThreshold, uint64(k))
+		r.addUint(tagFileX, uint64(xs[i]))
+		r.addUint(tagFileLen, uint64(size))
+		header := appendRecordHeader(r.marshalAs(fileShareMagic), tagFileData, size)
+		trailerLen := len(appendFileTrailer(nil, keyShares[i], root, leaves))
+
+		if outputs[i], err = createMappedFile(dst, header, size+trailerLen, &created); err != nil {
+			return err
+		}
+		bodies[i] = outputs[i].data[len(header) : len(header)+size]
+		trailers[i] = outputs[i].data[len(header)+size:]
+	}
+
+	ys := make([][]byte, n)
+	for c := range leaves {
+		start, end := fileChunk(c, size)
+		for i := range ys {
+			ys[i] = bodies[i][start:end]
+		}
+		if err := gf256SplitWindow(input.data[start:end], k, xs, rand, random, ys); err != nil {
+			return err
+		}
+		leaves[c] = fileChunkHash(hashKey[:], c, input.data[start:end])
+	}
+	root = merkleRoot(leaves)
+	for i := range trailers {
+		copy(trailers[i], appendFileTrailer(nil, keyShares[i], root, leaves))
+	}
+
+	for _, m := range outputs {
+		if err := m.unmap(); err != nil {
+			return err
+		}
+	}
+	for _, dst := range dsts {
+		if err := syncFile(dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JoinFiles recovers a file split by SplitFile from at least k of its shares,
+// writing it to dst, which must not exist and is created with mode 0600. As
+// with SplitFile, the files are memory mapped where possible. On error, dst
+// is removed.
+//
+// Each chunk is checked against the hash tree recorded by SplitFile as it
+// is recovered and, if it doesn't match, JoinFiles stops with a *ChunkError
+// naming the first such chunk. Shares written before SplitFile recorded a
+// hash tree are joined without this check. Chunks are recovered in parallel.
+// To read only part of the file, use OpenFileShares.
+func JoinFiles(dst string, srcs []string) (err error) {
+	r, err := OpenFileShares(srcs)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var created []string
+	defer func() {
+		if err != nil {
+			for _, path := range created {
+				os.Remove(path)
+			}
+		}
+	}()
+	out, err := createMappedFile(dst, nil, r.size, &created)
+	if err != nil {
+		return err
+	}
+	defer out.unmap()
+
+	if err := r.recoverAll(out.data); err != nil {
+		return err
+	}
+	if err := out.unmap(); err != nil {
+		return err
+	}
+	return syncFile(dst)
+}
+
+// A FileReader reads a file split by SplitFile from at least k of its
+// shares, recovering only the parts that are read. This allows, for
+// example, a single file to be extracted from an archive that was split,
+// without recovering all of it. Each chunk that's read is recovered in full
+// and checked against the hash tree, as by JoinFiles. It's safe for
+// concurrent use.
+type FileReader struct {
+	mappings []*mapping
+	k, size  int
+	xs       []byte
+	ys       [][]byte
+	// hashKey and leaves are nil for shares without a hash tree.
+	hashKey []byte
+	leaves  [][sha256.Size]byte
+}
+
+// OpenFileShares maps the file shares at srcs, which must be from the same
+// split and include at least k of them, and returns a FileReader for the
+// file. It must be closed after use.
+func OpenFileShares(srcs []string) (*FileReader, error) {
+	if len(srcs) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	fr := new(FileReader)
+	ok := false
+	defer func() {
+		if !ok {
+			fr.Close()
+		}
+	}()
+
+	var header []byte
+	var keyShares [][]byte
+	for _, src := range srcs {
+		f, err := os.Open(src)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := f.Stat()
+		if err != nil || fi.Size() > math.MaxInt {
+			f.Close()
+			return nil, errors.New("can't map " + src)
+		}
+		m, err := mapFile(f, int(fi.Size()), false)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		fr.mappings = append(fr.mappings, m)
+
+		var r wireRecords
+		var x uint64
+		var data, keyShare, hashes []byte
+		err = parseWireAs(fileShareMagic, m.data, func(tag uint64, value []byte) error {
+			switch tag {
+			case tagFileSetID, tagFileThreshold, tagFileLen, tagFileRoot:
+				r.add(tag, value)
+			case tagFileX:
+				v, err := parseWireUint(value)
+				if err != nil || v < 1 || v > 255 {
+					return errors.New("invalid share number")
+				}
+				x = v
+			case tagFileData:
+				data = value
+			case tagFileHashKey:
+				keyShare = value
+			case tagFileChunkHashes:
+				hashes = value
+			default:
+				return errUnknownRecord
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if x == 0 || data == nil {
+			return nil, errors.New("truncated file share")
+		}
+
+		h := r.marshalAs(fileShareMagic)
+		if header == nil {
+			header = h
+			threshold, err := parseWireUint(r.get(tagFileThreshold))
+			if err != nil || threshold < 1 || threshold > 255 {
+				return nil, errors.New("invalid threshold")
+			}
+			l, err := parseWireUint(r.get(tagFileLen))
+			if err != nil || l > math.MaxInt {
+				return nil, errors.New("invalid file length")
+			}
+			fr.k, fr.size = int(threshold), int(l)
+		} else if !bytes.Equal(h, header) {
+			return nil, errors.New("file shares are from different splits")
+		}
+		if len(data) != fr.size {
+			return nil, errors.New("file share has the wrong length")
+		}
+		if root := r.get(tagFileRoot); root != nil {
+			if len(keyShare) != sha256.Size || len(hashes) != fileChunks(fr.size)*sha256.Size {
+				return nil, errors.New("truncated file share")
+			}
+			l := make([][sha256.Size]byte, fileChunks(fr.size))
+			for c := range l {
+				copy(l[c][:], hashes[c*sha256.Size:])
+			}
+			if r := merkleRoot(l); !bytes.Equal(r[:], root) {
+				return nil, errors.New("corrupt hash tree in " + src)
+			}
+			fr.leaves = l
+			keyShares = append(keyShares, keyShare)
+		}
+		if bytes.IndexByte(fr.xs, byte(x)) >= 0 {
+			return nil, errors.New("found duplicate share")
+		}
+		fr.xs = append(fr.xs, byte(x))
+		fr.ys = append(fr.ys, data)
+	}
+	if len(fr.xs) < fr.k {
+		return nil, &NotEnoughSharesError{Need: fr.k, Have: len(fr.xs)}
+	}
+	fr.xs, fr.ys = fr.xs[:fr.k], fr.ys[:fr.k]
+
+	if fr.leaves != nil {
+		fr.hashKey = make([]byte, sha256.Size)
+		gf256InterpolateAt(fr.xs, keyShares[:fr.k], 0, fr.hashKey)
+	}
+	ok = true
+	return fr, nil
+}
+
+// Size returns the length of the file.
+func (fr *FileReader) Size() int64 {
+	return int64(fr.size)
+}
+
+// ReadAt implements io.ReaderAt. If a chunk that's read is corrupt, it
+// returns a *ChunkError.
+func (fr *FileReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= int64(fr.size) {
+		return 0, io.EOF
+	}
+	start := int(off)
+	end := start + min(len(p), fr.size-start)
+
+	var scratch []byte
+	defer clear(scratch)
+	for c := start / fileWindow; n < end-start; c++ {
+		chunkStart, chunkEnd := fileChunk(c, fr.size)
+		from, to := max(start, chunkStart), min(end, chunkEnd)
+		if fr.leaves == nil {
+			fr.interpolate(p[n:n+to-from], from, to)
+		} else {
+			if scratch == nil {
+				scratch = make([]byte, fileWindow)
+			}
+			chunk := scratch[:chunkEnd-chunkStart]
+			if err := fr.recoverChunk(c, chunk); err != nil {
+				return n, err
+			}
+			copy(p[n:], chunk[from-chunkStart:to-chunkStart])
+		}
+		n += to - from
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close unmaps the shares.
+func (fr *FileReader) Close() error {
+	var err error
+	for _, m := range fr.mappings {
+		if unmapErr := m.unmap(); err == nil {
+			err = unmapErr
+		}
+	}
+	clear(fr.hashKey)
+	return err
+}
+
+// interpolate writes bytes [start, end) of the file into dst.
+func (fr *FileReader) interpolate(dst []byte, start, end int) {
+	window := make([][]byte, fr.k)
+	for i := range window {
+		window[i] = fr.ys[i][start:end]
+	}
+	gf256InterpolateAt(fr.xs, window, 0, dst)
+}
+
+// recoverChunk writes chunk c of the file into dst, which must be its
+// length, and checks it against the hash tree, if any.
+func (fr *FileReader) recoverChunk(c int, dst []byte) error {
+	start, end := fileChunk(c, fr.size)
+	fr.interpolate(dst, start, end)
+	if fr.leaves != nil {
+		h := fileChunkHash(fr.hashKey, c, dst)
+		if !hmac.Equal(h[:], fr.leaves[c][:]) {
+			return &ChunkError{Chunk: c, Offset: int64(start)}
+		}
+	}
+	return nil
+}
+
+// recoverAll writes the whole file into dst, recovering chunks in parallel
+// on up to GOMAXPROCS goroutines. If any chunks are corrupt, it returns the
+// *ChunkError for the first.
+func (fr *FileReader) recoverAll(dst []byte) error {
+	chunks := fileChunks(fr.size)
+	workers := min(runtime.GOMAXPROCS(0), chunks)
+
+	// Chunks are claimed in order, and no more once one has failed, so
+	// every chunk before a failure is checked and the lowest failure is
+	// the first corrupt chunk.
+	var next atomic.Int64
+	var failed atomic.Bool
+	errs := make([]*ChunkError, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				c := int(next.Add(1) - 1)
+				if c >= chunks {
+					return
+				}
+				start, end := fileChunk(c, fr.size)
+				if err := fr.recoverChunk(c, dst[start:end]); err != nil {
+					errs[w] = err.(*ChunkError)
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var first *ChunkError
+	for _, e := range errs {
+		if e != nil && (first == nil || e.Chunk < first.Chunk) {
+			first = e
+		}
+	}
+	if first != nil {
+		return first
+	}
+	return nil
+}
+
+// ErrCorruptChunk matches, with errors.Is, any *ChunkError.
+var ErrCorruptChunk = errors.New("corrupt chunk")
+
+// A ChunkError is returned by JoinFiles when a recovered chunk of the file
+// doesn't match the hash recorded for it by SplitFile, meaning that at least
+// one of the shares is corrupt.
+type ChunkError struct {
+	// Chunk is the index of the chunk, and Offset that of its first byte
+	// in the file.
+	Chunk  int
+	Offset int64
+}
+
+func (e *ChunkError) Error() string {
+	return "corrupt chunk " + strconv.Itoa(e.Chunk) + " at offset " + strconv.FormatInt(e.Offset, 10)
+}
+
+func (e *ChunkError) Is(target error) bool {
+	return target == ErrCorruptChunk
+}
+
+// fileChunks returns the number of windows that a file of the given size is
+// processed in. An empty file has a single, empty chunk so that its hash
+// tree isn't empty.
+func fileChunks(size int) int {
+	return max(1, (size+fileWindow-1)/fileWindow)
+}
+
+// fileChunk returns the bounds of chunk c of a file of the given size.
+func fileChunk(c, size int) (start, end int) {
+	start = min(c*fileWindow, size)
+	return start, min(start+fileWindow, size)
+}
+
+// fileChunkHash returns the leaf of the hash tree for chunk c, with contents
+// data.
+func fileChunkHash(key []byte, c int, data []byte) (leaf [sha256.Size]byte) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(binary.BigEndian.AppendUint64(nil, uint64(c)))
+	mac.Write(data)
+	mac.Sum(leaf[:0])
+	return leaf
+}
+
+// appendFileTrailer appends the records that follow the data of a file
+// share: its share of the hash key and the hash tree.
+func appendFileTrailer(b, keyShare []byte, root [sha256.Size]byte, leaves [][sha256.Size]byte) []byte {
+	b = appendRecordHeader(b, tagFileHashKey, len(keyShare))
+	b = append(b, keyShare...)
+	b = appendRecordHeader(b, tagFileRoot, len(root))
+	b = append(b, root[:]...)
+	b = appendRecordHeader(b, tagFileChunkHashes, len(leaves)*sha256.Size)
+	for _, l := range leaves {
+		b = append(b, l[:]...)
+	}
+	return b
+}
+
+// gf256SplitWindow is like gf256Split, but writes the y values into ys,
+// which must each be as long as secret, and uses random, which must be at
+// least k-1 times as long, as scratch space.
+func gf256SplitWindow(secret []byte, k int, xs []byte, rand io.Reader, random []byte, ys [][]byte) error {
+	l := len(secret)
+	random = random[:(k-1)*l]
+	if _, err := io.ReadFull(rand, random); err != nil {
+		return err
+	}
+	for i, x := range xs {
+		y := ys[i]
+		copy(y, secret)
+		xj := byte(1)
+		for j := 0; j < k-1; j++ {
+			xj = gf256Mul(xj, x)
+			gf256MulXorSlice(xj, random[j*l:(j+1)*l], y)
+		}
+	}
+	return nil
+}
+
+// appendRecordHeader appends the tag and length of a record with a value of
+// length l, which the caller follows with the value.
+func appendRecordHeader(b []byte, tag uint64, l int) []byte {
+	b = binary.AppendUvarint(b, tag)
+	return binary.AppendUvarint(b, uint64(l))
+}
+
+// createMappedFile creates path, which must not exist, with mode 0600 and
+// the given header followed by size bytes, and maps it. If the file was
+// created, path is appended to *created. The mapping owns the file, which
+// unmap closes.
+func createMappedFile(path string, header []byte, size int, created *[]string) (m *mapping, err error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	*created = append(*created, path)
+	defer func() {
+		if err != nil {
+			f.Close()
+		}
+	}()
+
+	if _, err := f.Write(header); err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(int64(len(header) + size)); err != nil {
+		return nil, err
+	}
+	return mapFile(f, len(header)+size, true)
+}
+
+// syncFile syncs the file at path to disk.
+func syncFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,226 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, size := range []int{0, 1, fileWindow + 12345} {
+		data := make([]byte, size)
+		rand.Read(data)
+		src := filepath.Join(dir, "secret")
+		if err := os.WriteFile(src, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		dsts := make([]string, 4)
+		for i := range dsts {
+			dsts[i] = filepath.Join(dir, "share"+string(rune('a'+i)))
+		}
+		if err := SplitFile(src, dsts, 3, nil); err != nil {
+			t.Fatalf("%d bytes: %s", size, err)
+		}
+
+		out := filepath.Join(dir, "recovered")
+		if err := JoinFiles(out, []string{dsts[3], dsts[0], dsts[2]}); err != nil {
+			t.Fatalf("%d bytes: %s", size, err)
+		}
+		got, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%d bytes: recovered file differs", size)
+		}
+
+		os.Remove(out)
+		if err := JoinFiles(out, dsts[:2]); !errors.Is(err, ErrNotEnoughShares) {
+			t.Errorf("%d bytes: joining two shares gave %v", size, err)
+		}
+		if _, err := os.Stat(out); err == nil {
+			t.Errorf("%d bytes: output of failed join wasn't removed", size)
+		}
+
+		for _, path := range append(dsts, src, out) {
+			os.Remove(path)
+		}
+	}
+}
+
+func TestJoinFilesCorruptChunk(t *testing.T) {
+	dir := t.TempDir()
+	size := 2*fileWindow + 100
+	data := make([]byte, size)
+	rand.Read(data)
+	src := filepath.Join(dir, "secret")
+	if err := os.WriteFile(src, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	dsts := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")}
+	if err := SplitFile(src, dsts, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	share, err := os.ReadFile(dsts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	trailerLen := len(appendFileTrailer(nil, make([]byte, 32), [32]byte{}, make([][32]byte, fileChunks(size))))
+	dataStart := len(share) - trailerLen - size
+
+	// A share from before hash trees were recorded still joins.
+	legacy := filepath.Join(dir, "legacy")
+	if err := os.WriteFile(legacy, share[:len(share)-trailerLen], 0600); err != nil {
+		t.Fatal(err)
+	}
+	legacy0 := filepath.Join(dir, "legacy0")
+	a, _ := os.ReadFile(dsts[0])
+	if err := os.WriteFile(legacy0, a[:len(a)-trailerLen], 0600); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "recovered")
+	if err := JoinFiles(out, []string{legacy0, legacy}); err != nil {
+		t.Fatalf("joining shares without hash trees: %s", err)
+	}
+	if got, _ := os.ReadFile(out); !bytes.Equal(got, data) {
+		t.Errorf("recovered file differs")
+	}
+	os.Remove(out)
+
+	corrupt := bytes.Clone(share)
+	corrupt[dataStart+fileWindow+7] ^= 1
+	if err := os.WriteFile(dsts[1], corrupt, 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = JoinFiles(out, dsts[:2])
+	var chunkErr *ChunkError
+	if !errors.As(err, &chunkErr) || !errors.Is(err, ErrCorruptChunk) {
+		t.Fatalf("joining a corrupt share gave %v", err)
+	}
+	if chunkErr.Chunk != 1 || chunkErr.Offset != fileWindow {
+		t.Errorf("got corruption at chunk %d, offset %d; want chunk 1, offset %d", chunkErr.Chunk, chunkErr.Offset, fileWindow)
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Errorf("output of failed join wasn't removed")
+	}
+	if err := JoinFiles(out, []string{dsts[0], dsts[2]}); err != nil {
+		t.Errorf("joining the good shares: %s", err)
+	}
+	os.Remove(out)
+
+	corrupt = bytes.Clone(share)
+	corrupt[len(corrupt)-1] ^= 1
+	if err := os.WriteFile(dsts[1], corrupt, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := JoinFiles(out, dsts[:2]); err == nil {
+		t.Errorf("share with a corrupt hash tree was accepted")
+	}
+}
+
+func TestFileReader(t *testing.T) {
+	dir := t.TempDir()
+	size := 2*fileWindow + 100
+	data := make([]byte, size)
+	rand.Read(data)
+	src := filepath.Join(dir, "secret")
+	if err := os.WriteFile(src, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	dsts := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")}
+	if err := SplitFile(src, dsts, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenFileShares(dsts[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Size() != int64(size) {
+		t.Errorf("got size %d, want %d", r.Size(), size)
+	}
+	for _, test := range []struct{ off, n int }{
+		{0, 10},
+		{fileWindow - 5, 10},
+		{fileWindow - 1, fileWindow + 2},
+		{size - 50, 50},
+	} {
+		buf := make([]byte, test.n)
+		n, err := r.ReadAt(buf, int64(test.off))
+		if err != nil || n != test.n || !bytes.Equal(buf, data[test.off:test.off+test.n]) {
+			t.Errorf("ReadAt(%d bytes at %d) gave %d, %v or the wrong data", test.n, test.off, n, err)
+		}
+	}
+	buf := make([]byte, 100)
+	if n, err := r.ReadAt(buf, int64(size-10)); n != 10 || err != io.EOF || !bytes.Equal(buf[:n], data[size-10:]) {
+		t.Errorf("ReadAt past the end gave %d, %v", n, err)
+	}
+
+	got, err := io.ReadAll(io.NewSectionReader(r, 0, r.Size()))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("reading the whole file gave %v", err)
+	}
+
+	share, _ := os.ReadFile(dsts[2])
+	trailerLen := len(appendFileTrailer(nil, make([]byte, 32), [32]byte{}, make([][32]byte, fileChunks(size))))
+	share[len(share)-trailerLen-size+2*fileWindow+3] ^= 1
+	if err := os.WriteFile(dsts[2], share, 0600); err != nil {
+		t.Fatal(err)
+	}
+	corrupt, err := OpenFileShares(dsts[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer corrupt.Close()
+	if _, err := corrupt.ReadAt(buf, 0); err != nil {
+		t.Errorf("reading an intact chunk gave %v", err)
+	}
+	if _, err := corrupt.ReadAt(buf, int64(2*fileWindow)); !errors.Is(err, ErrCorruptChunk) {
+		t.Errorf("reading a corrupt chunk gave %v", err)
+	}
+}
+
+// TestCreateMappedFile checks that a writable mapping writes its data back
+// and closes its file on unmap, both with mmap and, with the nommap tag,
+// without it.
+func TestCreateMappedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapped")
+	var created []string
+	m, err := createMappedFile(path, []byte("header"), 4, &created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(m.data[len("header"):], "body")
+	if err := m.unmap(); err != nil {
+		t.Fatalf("failed to unmap: %s", err)
+	}
+	if err := m.unmap(); err != nil {
+		t.Errorf("second unmap failed: %s", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "headerbody" {
+		t.Errorf("got %q, want %q", got, "headerbody")
+	}
+	// The file must be closed, or it couldn't be removed on Windows.
+	if err := os.Remove(path); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// A BinaryField is GF(2^m) for arbitrary m, represented as polynomials over
+// GF(2) modulo an irreducible polynomial of degree m. Elements are
+// big.Ints whose bit i is the coefficient of x^i. It implements
+// Field[*big.Int].
+//
+// Multiplication is a carry-less multiplication of 64-bit words followed by
+// reduction. Go provides no portable access to carry-less multiply
+// instructions, so it's performed in constant-time software.
+type BinaryField struct {
+	m     int
+	words int
+	poly  []uint64
+	// low is poly without its x^m term, and folds is the number of times
+	// that the high part of a product must be folded into its low part
+	// for the result to be reduced.
+	low   []uint64
+	folds int
+}
+
+var _ Field[*big.Int] = (*BinaryField)(nil)
+
+// NewBinaryField returns GF(2^m) with the reducing polynomial poly, which
+// must include the x^m term. It returns an error if poly doesn't have degree
+// m or isn't irreducible.
+func NewBinaryField(m int, poly *big.Int) (*BinaryField, error) {
+	if m < 1 || poly.Sign() <= 0 || poly.BitLen() != m+1 {
+		return nil, errors.New("reducing polynomial must have degree m")
+	}
+
+	f := newBinaryField(m, poly)
+	if !f.irreducible() {
+		return nil, errors.New("reducing polynomial is not irreducible")
+	}
+	return f, nil
+}
+
+func newBinaryField(m int, poly *big.Int) *BinaryField {
+	f := &BinaryField{m: m, words: (m + 63) / 64}
+	f.poly = f.toWords(poly, f.words+1)
+	low := new(big.Int).SetBit(poly, m, 0)
+	f.low = f.toWords(low, f.words)
+
+	// Since x^m = low, folding the bits of a product of degree d above
+	// x^m reduces its degree to at most d - m + deg(low).
+	for d := 2*m - 2; d >= m; d = d - m + low.BitLen() - 1 {
+		f.folds++
+	}
+	return f
+}
+
+// toWords returns the n least significant 64-bit words of a, least
+// significant first.
+func (f *BinaryField) toWords(a *big.Int, n int) []uint64 {
+	b := a.FillBytes(make([]byte, 8*n))
+	w := make([]uint64, n)
+	for i := range w {
+		w[i] = binary.BigEndian.Uint64(b[len(b)-8*(i+1):])
+	}
+	return w
+}
+
+func fromWords(w []uint64) *big.Int {
+	b := make([]byte, 8*len(w))
+	for i, v := range w {
+		binary.BigEndian.PutUint64(b[len(b)-8*(i+1):], v)
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+// clmul returns the 128-bit carry-less product of a and b.
+func clmul(a, b uint64) (hi, lo uint64) {
+	for i := uint(0); i < 64; i++ {
+		mask := -(b >> i & 1)
+		lo ^= a << i & mask
+		hi ^= a >> (64 - i) & mask
+	}
+	return
+}
+
+// clmulWords returns the carry-less product of a and b.
+func clmulWords(a, b []uint64) []uint64 {
+	p := make([]uint64, len(a)+len(b))
+	for i, x := range a {
+		for j, y := range b {
+			hi, lo := clmul(x, y)
+			p[i+j] ^= lo
+			p[i+j+1] ^= hi
+		}
+	}
+	return p
+}
+
+// mulWords returns a*b mod poly, where a and b have f.words words.
+func (f *BinaryField) mulWords(a, b []uint64) []uint64 {
+	return f.reduce(clmulWords(a, b))
+}
+
+// sqrWords returns a^2 mod poly. Squaring is linear over GF(2) and just
+// interleaves zero bits between those of a.
+func (f *BinaryField) sqrWords(a []uint64) []uint64 {
+	p := make([]uint64, 2*len(a))
+	for i, v := range a {
+		p[2*i] = spreadBits(uint32(v))
+		p[2*i+1] = spreadBits(uint32(v >> 32))
+	}
+	return f.reduce(p)
+}
+
+// spreadBits moves bit i of x to bit 2i of the result.
+func spreadBits(x uint32) uint64 {
+	v := uint64(x)
+	v = (v | v<<16) & 0x0000ffff0000ffff
+	v = (v | v<<8) & 0x00ff00ff00ff00ff
+	v = (v | v<<4) & 0x0f0f0f0f0f0f0f0f
+	v = (v | v<<2) & 0x3333333333333333
+	v = (v | v<<1) & 0x5555555555555555
+	return v
+}
+
+// reduce returns p mod poly, where p has 2*f.words words.
+func (f *BinaryField) reduce(p []uint64) []uint64 {
+	ws, bs := f.m/64, uint(f.m%64)
+	for i := 0; i < f.folds; i++ {
+		// Split p into high*x^m + low and replace it with
+		// high*f.low + low.
+		high := make([]uint64, f.words)
+		for j := range high {
+			if ws+j < len(p) {
+				high[j] = p[ws+j] >> bs
+			}
+			if bs > 0 && ws+j+1 < len(p) {
+				high[j] |= p[ws+j+1] << (64 - bs)
+			}
+		}
+		for j := ws; j < len(p); j++ {
+			if j == ws {
+				p[j] &= 1<<bs - 1
+			} else {
+				p[j] = 0
+			}
+		}
+
+		// The reducing polynomial is public, so its zero words can be
+		// skipped.
+		for k, y := range f.low {
+			if y == 0 {
+				continue
+			}
+			for j, x := range high {
+				hi, lo := clmul(x, y)
+				p[j+k] ^= lo
+				if j+k+1 < len(p) {
+					p[j+k+1] ^= hi
+				}
+			}
+		}
+	}
+
+	return p[:f.words]
+}
+
+func (f *BinaryField) Zero() *big.Int { return new(big.Int) }
+func (f *BinaryField) One() *big.Int  { return big.NewInt(1) }
+
+func (f *BinaryField) Add(a, b *big.Int) *big.Int { return new(big.Int).Xor(a, b) }
+func (f *BinaryField) Sub(a, b *big.Int) *big.Int { return new(big.Int).Xor(a, b) }
+
+func (f *BinaryField) Mul(a, b *big.Int) *big.Int {
+	return fromWords(f.mulWords(f.toWords(a, f.words), f.toWords(b, f.words)))
+}
+
+// Inv returns a^-1, computed as a^(2^m - 2).
+func (f *BinaryField) Inv(a *big.Int) (*big.Int, error) {
+	if a.Sign() == 0 {
+		return nil, errors.New("element has no inverse")
+	}
+
+	x := f.toWords(a, f.words)
+	r := x
+	for i := 0; i < f.m-2; i++ {
+		r = f.sqrWords(r)
+		r = f.mulWords(r, x)
+	}
+	return fromWords(f.sqrWords(r)), nil
+}
+
+func (f *BinaryField) Equal(a, b *big.Int) bool { return a.Cmp(b) == 0 }
+
+func (f *BinaryField) Element(i uint64) (*big.Int, error) {
+	e := new(big.Int).SetUint64(i)
+	if e.BitLen() > f.m {
+		return nil, errors.New("field is too small")
+	}
+	return e, nil
+}
+
+func (f *BinaryField) Random(rand io.Reader) (*big.Int, error) {
+	b := make([]byte, (f.m+7)/8)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		return nil, err
+	}
+	if r := f.m % 8; r != 0 {
+		b[0] &= 1<<uint(r) - 1
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// Encode returns a as a big-endian integer of (m+7)/8 bytes.
+func (f *BinaryField) Encode(a *big.Int) []byte {
+	return a.FillBytes(make([]byte, (f.m+7)/8))
+}
+
+func (f *BinaryField) Decode(b []byte) (*big.Int, error) {
+	if len(b) != (f.m+7)/8 {
+		return nil, errors.New("encoded element has the wrong length")
+	}
+	a := new(big.Int).SetBytes(b)
+	if a.BitLen() > f.m {
+		return nil, errors.New("encoded element is out of range")
+	}
+	return a, nil
+}
+
+// irreducible runs Rabin's irreducibility test on the reducing polynomial:
+// it's irreducible iff x^(2^m) = x and, for each prime p dividing m,
+// gcd(x^(2^(m/p)) - x, poly) = 1.
+func (f *BinaryField) irreducible() bool {
+	x := make([]uint64, f.words)
+	if f.m == 1 {
+		// Both polynomials of degree one are irreducible.
+		return true
+	}
+	x[0] = 2
+
+	// frobenius returns x^(2^n).
+	frobenius := func(n int) []uint64 {
+		r := x
+		for i := 0; i < n; i++ {
+			r = f.sqrWords(r)
+		}
+		return r
+	}
+
+	if fromWords(frobenius(f.m)).Cmp(big.NewInt(2)) != 0 {
+		return false
+	}
+
+	poly := fromWords(f.poly)
+	n := f.m
+	for p := 2; p <= n; p++ {
+		if n%p != 0 {
+			continue
+		}
+		for n%p == 0 {
+			n /= p
+		}
+		t := fromWords(frobenius(f.m / p))
+		t.Xor(t, big.NewInt(2))
+		if polyGCD(t, poly).Cmp(big.NewInt(1)) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// polyGCD returns the greatest common divisor of two polynomials over GF(2).
+func polyGCD(a, b *big.Int) *big.Int {
+	a = new(big.Int).Set(a)
+	b = new(big.Int).Set(b)
+	for b.Sign() != 0 {
+		// a = a mod b
+		for a.BitLen() >= b.BitLen() {
+			a.Xor(a, new(big.Int).Lsh(b, uint(a.BitLen()-b.BitLen())))
+		}
+		a, b = b, a
+	}
+	return a
+}
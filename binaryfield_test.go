@@ -0,0 +1,46 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBinaryField(t *testing.T) {
+	aes, err := NewBinaryField(8, big.NewInt(0x11b))
+	if err != nil {
+		t.Fatalf("AES polynomial was rejected: %s", err)
+	}
+	for a := 0; a < 256; a += 7 {
+		for b := 0; b < 256; b += 5 {
+			got := aes.Mul(big.NewInt(int64(a)), big.NewInt(int64(b)))
+			if want := gf256Mul(byte(a), byte(b)); got.Int64() != int64(want) {
+				t.Fatalf("%#x * %#x = %#x, want %#x", a, b, got, want)
+			}
+		}
+	}
+
+	// x^8 + 1 = (x + 1)^8.
+	if _, err := NewBinaryField(8, big.NewInt(0x101)); err == nil {
+		t.Errorf("reducible polynomial was accepted")
+	}
+
+	// x^127 + x + 1, which spans two words.
+	poly := new(big.Int).Lsh(big.NewInt(1), 127)
+	poly.Or(poly, big.NewInt(3))
+	f, err := NewBinaryField(127, poly)
+	if err != nil {
+		t.Fatalf("irreducible polynomial was rejected: %s", err)
+	}
+	a := new(big.Int).Lsh(big.NewInt(0xdeadbeef), 90)
+	inv, _ := f.Inv(a)
+	if one := f.Mul(a, inv); one.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("a * a^-1 = %s", one)
+	}
+	testField[*big.Int](t, f, a)
+}
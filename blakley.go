@@ -0,0 +1,207 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// SplitBlakley splits secret into n shares, any k of which can be combined to
+// recover it, using Blakley's scheme rather than Shamir's. The secret is the
+// first coordinate of a point in k-dimensional space over the field of the
+// given prime modulus; the other coordinates are random. Each share is a
+// random hyperplane through the point, and k of them intersect at just that
+// point.
+//
+// The shares are self-describing Shares, with Hyperplane set, and carry
+// Metadata like those from SplitShares. JoinShares, and so Combiner, accept
+// them. Each share is k times the size of a Shamir share, and fewer than k
+// shares do reveal something about the point, if not about the secret, so
+// Blakley's scheme is mainly for interoperability and research.
+//
+// Because the hyperplanes are random, k of them are linearly dependent, and
+// so can't recover the secret, with probability about k/modulus, which is
+// negligible for moduli of cryptographic size. If rand is nil,
+// crypto/rand.Reader is used.
+func SplitBlakley(secret, modulus *big.Int, k, n int, rand io.Reader) ([]Share, error) {
+	if k < 1 || n < k {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+	rand = defaultRand(rand)
+
+	m, err := newSetMetadata(k, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	point := make([]*big.Int, k)
+	point[0] = secret
+	for j := 1; j < k; j++ {
+		if point[j], err = randomNumber(rand, modulus); err != nil {
+			return nil, err
+		}
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		a := make([]*big.Int, k)
+		y := new(big.Int)
+		for j := range a {
+			if a[j], err = randomNumber(rand, modulus); err != nil {
+				return nil, err
+			}
+			y.Add(y, new(big.Int).Mul(a[j], point[j]))
+		}
+		shares[i] = Share{
+			X:          big.NewInt(int64(i + 1)),
+			Y:          y.Mod(y, modulus),
+			Modulus:    modulus,
+			Hyperplane: a,
+			Metadata:   m,
+		}
+	}
+
+	for _, v := range point[1:] {
+		clear(v.Bits())
+	}
+	return shares, nil
+}
+
+// JoinBlakley recovers the secret from at least k shares that resulted from
+// SplitBlakley, by solving for the point where their hyperplanes intersect.
+// It returns ErrCorruptShare if any shares beyond the first k that are
+// independent don't pass through that point.
+func JoinBlakley(shares []Share) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+	if err := checkLimits(len(shares), len(shares)); err != nil {
+		return nil, err
+	}
+
+	first := &shares[0]
+	modulus, k := first.Modulus, len(first.Hyperplane)
+	if modulus == nil {
+		return nil, errors.New("shares don't record their modulus")
+	}
+	if k == 0 {
+		return nil, errors.New("not a Blakley share")
+	}
+	for i := range shares {
+		s := &shares[i]
+		if err := checkSameDealing(i, first, s); err != nil {
+			return nil, err
+		}
+		if s.Y == nil || s.Y.Sign() < 0 || s.Y.Cmp(modulus) >= 0 {
+			return nil, errors.New("share is out of range")
+		}
+		for j := 0; j < i; j++ {
+			if s.X != nil && shares[j].X != nil && s.X.Cmp(shares[j].X) == 0 {
+				return nil, errors.New("found duplicate share")
+			}
+		}
+	}
+	if len(shares) < k {
+		return nil, &NotEnoughSharesError{Need: k, Have: len(shares)}
+	}
+
+	// Gaussian elimination on the augmented matrix [a | y], picking the
+	// first k independent rows. Rows past those must then reduce to zero.
+	rows := make([][]*big.Int, len(shares))
+	for i := range shares {
+		row := make([]*big.Int, k+1)
+		for j, a := range shares[i].Hyperplane {
+			row[j] = new(big.Int).Set(a)
+		}
+		row[k] = new(big.Int).Set(shares[i].Y)
+		rows[i] = row
+	}
+	defer func() {
+		for _, row := range rows {
+			for _, v := range row {
+				clear(v.Bits())
+			}
+		}
+	}()
+
+	t := new(big.Int)
+	for col := 0; col < k; col++ {
+		pivot := -1
+		for i := col; i < len(rows); i++ {
+			if rows[i][col].Sign() != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, errors.New("shares are linearly dependent")
+		}
+		rows[col], rows[pivot] = rows[pivot], rows[col]
+
+		inv := new(big.Int).ModInverse(rows[col][col], modulus)
+		if inv == nil {
+			return nil, errors.New("modulus is not prime")
+		}
+		for j := col; j <= k; j++ {
+			rows[col][j].Mul(rows[col][j], inv).Mod(rows[col][j], modulus)
+		}
+		for i := range rows {
+			if i == col || rows[i][col].Sign() == 0 {
+				continue
+			}
+			f := new(big.Int).Set(rows[i][col])
+			for j := col; j <= k; j++ {
+				t.Mul(f, rows[col][j])
+				rows[i][j].Sub(rows[i][j], t).Mod(rows[i][j], modulus)
+			}
+		}
+	}
+	for _, row := range rows[k:] {
+		if row[k].Sign() != 0 {
+			return nil, ErrCorruptShare
+		}
+	}
+
+	secret := new(big.Int).Set(rows[0][k])
+	if f := first.Fingerprint; f != nil {
+		if err := f.Check(secret, modulus); err != nil {
+			return nil, err
+		}
+	}
+	return secret, nil
+}
+
+// parseHyperplane parses the coefficients of a Blakley share, each encoded
+// in the width of the modulus.
+func parseHyperplane(data []byte, modulus *big.Int, limits *ParseLimits) ([]*big.Int, error) {
+	if modulus == nil || modulus.Sign() == 0 {
+		return nil, errors.New("Blakley share doesn't record its modulus")
+	}
+	width := (modulus.BitLen() + 7) / 8
+	if len(data) == 0 || len(data)%width != 0 {
+		return nil, errors.New("invalid hyperplane")
+	}
+	if len(data)/width > limits.MaxLimbs {
+		return nil, errors.New("hyperplane has too many dimensions")
+	}
+	a := make([]*big.Int, len(data)/width)
+	for j := range a {
+		a[j] = new(big.Int).SetBytes(data[j*width : (j+1)*width])
+		if a[j].Cmp(modulus) >= 0 {
+			return nil, errors.New("hyperplane coefficient out of range")
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestBlakley(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(0x5ec2e7)
+	shares, err := SplitBlakley(secret, modulus, 3, 5, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shares survive encoding and any three, or more, recover the secret
+	// through JoinShares.
+	for i := range shares {
+		data, err := shares[i].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := shares[i].UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if len(shares[i].Hyperplane) != 3 {
+			t.Fatalf("share %d has %d coefficients after decoding", i, len(shares[i].Hyperplane))
+		}
+	}
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var sub []Share
+		for _, i := range subset {
+			sub = append(sub, shares[i])
+		}
+		got, err := JoinShares(sub)
+		if err != nil {
+			t.Fatalf("%v: %v", subset, err)
+		}
+		if got.Cmp(secret) != 0 {
+			t.Errorf("%v: got %v", subset, got)
+		}
+	}
+
+	if _, err := JoinBlakley(shares[:2]); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("two shares gave %v", err)
+	}
+	if _, err := JoinBlakley([]Share{shares[0], shares[1], shares[0]}); err == nil {
+		t.Error("duplicate share was accepted")
+	}
+
+	corrupt := append([]Share(nil), shares...)
+	corrupt[4].Y = new(big.Int).Add(shares[4].Y, big.NewInt(1))
+	if _, err := JoinBlakley(corrupt); err != ErrCorruptShare {
+		t.Errorf("corrupt extra share gave %v", err)
+	}
+
+	shamir, err := SplitShares(secret, modulus, 3, 3, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mismatch *MismatchError
+	if _, err := JoinShares([]Share{shares[0], shamir[1], shamir[2]}); !errors.As(err, &mismatch) {
+		t.Errorf("mixing schemes gave %v", err)
+	}
+}
+
+func TestBlakleyCombiner(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitBlakley(big.NewInt(99), modulus, 2, 3, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCombiner(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range shares[1:] {
+		if err := c.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := c.Combine(); err != nil || got.Int64() != 99 {
+		t.Errorf("got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// Blind reconstruction lets shares be combined by an untrusted aggregator,
+// or sent over an untrusted transport, so that only a designated recipient
+// learns the secret. The recipient picks a quorum of shareholders and a
+// fresh random session, and sends both to each of them. Each shareholder
+// calls MaskShare with a key that it shares with the recipient, agreed, for
+// example, with X25519, and the aggregator sums the results with
+// CombineMasked. Since each masked share is the shareholder's Lagrange
+// term plus a one-time pad, neither the masked shares nor their sum reveal
+// anything, until the recipient removes the pads with UnmaskSecret.
+
+// A MaskedShare is a shareholder's contribution to a blind reconstruction.
+type MaskedShare struct {
+	// X is the x coordinate of the shareholder's share.
+	X *big.Int
+	// Value is the shareholder's term of the secret, masked.
+	Value *big.Int
+}
+
+// MaskShare returns s's contribution to the blind reconstruction, in the
+// given session, by the shareholders whose x coordinates are quorum, which
+// must include s's. The key is a secret shared only with the recipient, of
+// at least 16 bytes, and the session must never be reused with it, or the
+// mask is no longer a one-time pad. The pad also depends on the quorum, so
+// answering a replayed session with an altered quorum doesn't reveal s.
+func MaskShare(s *Share, quorum []*big.Int, key []byte, session [16]byte) (MaskedShare, error) {
+	if s.X == nil || s.Y == nil || s.Modulus == nil {
+		return MaskedShare{}, errors.New("share is missing coordinates or modulus")
+	}
+	if s.Additive || s.Hyperplane != nil || s.Mandatory != nil {
+		return MaskedShare{}, errors.New("share isn't from a plain Shamir dealing")
+	}
+	if len(key) < 16 {
+		return MaskedShare{}, errors.New("masking key is too short")
+	}
+	if err := checkXs(quorum, s.Modulus); err != nil {
+		return MaskedShare{}, err
+	}
+	i := -1
+	for j, x := range quorum {
+		if x.Cmp(s.X) == 0 {
+			i = j
+		}
+	}
+	if i < 0 {
+		return MaskedShare{}, errors.New("share isn't in the quorum")
+	}
+
+	v := lagrangeAtZero(quorum, i, s.Modulus)
+	v.Mul(v, s.Y)
+	v.Add(v, blindMask(key, session, quorum, s.X, s.Modulus))
+	return MaskedShare{X: new(big.Int).Set(s.X), Value: v.Mod(v, s.Modulus)}, nil
+}
+
+// CombineMasked returns the sum of the masked shares, which is the secret
+// masked by the sum of their pads. It needs no secrets, so can be run by
+// anyone.
+func CombineMasked(masked []MaskedShare, modulus *big.Int) (*big.Int, error) {
+	if len(masked) == 0 {
+		return nil, errors.New("no masked shares given")
+	}
+	xs := make([]*big.Int, len(masked))
+	for i, m := range masked {
+		if m.X == nil || m.Value == nil {
+			return nil, errors.New("masked share is incomplete")
+		}
+		xs[i] = m.X
+	}
+	if err := checkXs(xs, modulus); err != nil {
+		return nil, err
+	}
+
+	sum := new(big.Int)
+	for _, m := range masked {
+		sum.Add(sum, m.Value)
+	}
+	return sum.Mod(sum, modulus), nil
+}
+
+// UnmaskSecret removes the pads from masked, the result of CombineMasked,
+// and returns the secret. There must be a key for each shareholder in the
+// quorum, identified by the x coordinate of its share. If any shareholder
+// used a different quorum, key or session, the result is garbage.
+func UnmaskSecret(masked *big.Int, keys []PairwiseSeed, session [16]byte, modulus *big.Int) (*big.Int, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no masking keys given")
+	}
+	xs := make([]*big.Int, len(keys))
+	for i, k := range keys {
+		if k.X == nil || len(k.Seed) < 16 {
+			return nil, errors.New("invalid masking key")
+		}
+		xs[i] = k.X
+	}
+	if err := checkXs(xs, modulus); err != nil {
+		return nil, err
+	}
+
+	secret := new(big.Int).Set(masked)
+	for _, k := range keys {
+		secret.Sub(secret, blindMask(k.Seed, session, xs, k.X, modulus))
+	}
+	return secret.Mod(secret, modulus), nil
+}
+
+// blindMask returns the pad, modulo modulus, for the share at x in session
+// with the given quorum: HMAC-SHA256 in counter mode, as for refreshMask.
+// Otherwise, a shareholder that answered the same session for two quorums
+// would reveal the difference of its Lagrange terms times its share.
+func blindMask(key []byte, session [16]byte, quorum []*big.Int, x, modulus *big.Int) *big.Int {
+	sorted := append([]*big.Int(nil), quorum...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	var input []byte
+	input = append(input, session[:]...)
+	input = binary.AppendUvarint(input, uint64(len(sorted)))
+	for _, v := range append([]*big.Int{x}, sorted...) {
+		b := v.Bytes()
+		input = binary.AppendUvarint(input, uint64(len(b)))
+		input = append(input, b...)
+	}
+
+	n := (modulus.BitLen()+7)/8 + 16
+	var stream []byte
+	for counter := uint32(0); len(stream) < n; counter++ {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte("shamirsplit blind mask"))
+		h.Write(binary.BigEndian.AppendUint32(nil, counter))
+		h.Write(input)
+		stream = h.Sum(stream)
+	}
+	mask := new(big.Int).SetBytes(stream[:n])
+	clear(stream)
+	return mask.Mod(mask, modulus)
+}
@@ -0,0 +1,97 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestBlindReconstruction(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, _ := SplitShares(secret, P256Order, 3, 5, nil)
+	participants := []Share{shares[4], shares[0], shares[2]}
+
+	var session [16]byte
+	rand.Read(session[:])
+	quorum := make([]*big.Int, len(participants))
+	keys := make([]PairwiseSeed, len(participants))
+	for i, s := range participants {
+		quorum[i] = s.X
+		keys[i] = PairwiseSeed{X: s.X, Seed: make([]byte, 32)}
+		rand.Read(keys[i].Seed)
+	}
+
+	masked := make([]MaskedShare, len(participants))
+	for i := range participants {
+		var err error
+		if masked[i], err = MaskShare(&participants[i], quorum, keys[i].Seed, session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum, err := CombineMasked(masked, P256Order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Cmp(secret) == 0 {
+		t.Error("combined masked shares revealed the secret")
+	}
+	if got, err := UnmaskSecret(sum, keys, session, P256Order); err != nil || got.Cmp(secret) != 0 {
+		t.Errorf("got %v, %v, want %v", got, err, secret)
+	}
+
+	session[0] ^= 1
+	if got, _ := UnmaskSecret(sum, keys, session, P256Order); got.Cmp(secret) == 0 {
+		t.Error("unmasking with the wrong session recovered the secret")
+	}
+	if _, err := MaskShare(&shares[1], quorum, keys[0].Seed, session); err == nil {
+		t.Error("share outside the quorum was masked")
+	}
+	if _, err := MaskShare(&shares[0], quorum, keys[0].Seed[:8], session); err == nil {
+		t.Error("short key was accepted")
+	}
+}
+
+func TestBlindMaskDependsOnQuorum(t *testing.T) {
+	shares, _ := SplitShares(big.NewInt(42), P256Order, 2, 5, nil)
+	s := &shares[0]
+	key := make([]byte, 32)
+	rand.Read(key)
+	var session [16]byte
+	rand.Read(session[:])
+
+	// The same session answered for two quorums that both include s.
+	q1 := []*big.Int{shares[0].X, shares[1].X}
+	q2 := []*big.Int{shares[3].X, shares[0].X}
+	m1, err := MaskShare(s, q1, key, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := MaskShare(s, q2, key, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With the same pad, the difference would be (L1-L2)*y.
+	diff := new(big.Int).Sub(m1.Value, m2.Value)
+	diff.Mod(diff, P256Order)
+	l := new(big.Int).Sub(lagrangeAtZero(q1, 0, P256Order), lagrangeAtZero(q2, 1, P256Order))
+	l.Mul(l, s.Y).Mod(l, P256Order)
+	if diff.Cmp(l) == 0 {
+		t.Error("masks for two quorums are the same, revealing the share")
+	}
+
+	// The order in which the quorum is listed doesn't matter.
+	m3, err := MaskShare(s, []*big.Int{q1[1], q1[0]}, key, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m3.Value.Cmp(m1.Value) != 0 {
+		t.Error("mask depends on the order of the quorum")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+)
+
+// This file implements threshold BLS signatures: a key split over the group
+// order lets any k shareholders sign, since the signature is linear in the
+// key. The pairing group, such as G2 of BLS12-381, is supplied by the caller
+// as a BLSGroup, as is signature verification, which needs the pairing.
+
+// A BLSGroup is the group of a pairing-friendly curve in which BLS signatures
+// are elements.
+type BLSGroup[E any] interface {
+	Group[E]
+	// HashToGroup hashes message to an element, as the signature scheme
+	// specifies, including its domain separation tag.
+	HashToGroup(message []byte) E
+}
+
+// A BLSPartialSignature is a shareholder's contribution to a signature.
+type BLSPartialSignature[E any] struct {
+	X   *big.Int
+	Sig E
+}
+
+// BLSPartialSign returns the partial signature of message by the holder of
+// share, which must be over the order of g.
+func BLSPartialSign[E any](g BLSGroup[E], share Share, message []byte) (*BLSPartialSignature[E], error) {
+	if share.X == nil || share.Y == nil {
+		return nil, errors.New("share is missing coordinates")
+	}
+	if share.Modulus == nil || share.Modulus.Cmp(g.Order()) != 0 {
+		return nil, errors.New("share is not over the group order")
+	}
+	if share.Y.Sign() < 0 || share.Y.Cmp(g.Order()) >= 0 {
+		return nil, errors.New("share is out of range")
+	}
+	return &BLSPartialSignature[E]{X: share.X, Sig: g.ScalarMult(share.Y, g.HashToGroup(message))}, nil
+}
+
+// BLSAggregate combines partial signatures of the same message from at least
+// k shareholders into the signature under the split key. Too few or invalid
+// partial signatures give an invalid signature, so the result should be
+// verified, and partial signatures can be checked individually against the
+// public shares, y_i*G, of their signers.
+func BLSAggregate[E any](g BLSGroup[E], partials []BLSPartialSignature[E]) (sig E, err error) {
+	xs := make([]*big.Int, len(partials))
+	sigs := make([]E, len(partials))
+	for i, p := range partials {
+		if p.X == nil {
+			return sig, errors.New("partial signature is missing its x coordinate")
+		}
+		xs[i], sigs[i] = p.X, p.Sig
+	}
+	return JoinPoints[E](g, xs, sigs)
+}
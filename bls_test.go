@@ -0,0 +1,60 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// testBLSGroup stands in for a pairing group. Signatures in it can't be
+// verified without the secret key, but the threshold arithmetic is the same.
+type testBLSGroup struct {
+	SchnorrGroup
+}
+
+func (g *testBLSGroup) HashToGroup(message []byte) *big.Int {
+	h := sha256.Sum256(message)
+	// Squares mod a safe prime form the subgroup of order Q.
+	x := new(big.Int).SetBytes(h[:])
+	return x.Exp(x, big.NewInt(2), g.P)
+}
+
+func TestBLSThreshold(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	g := &testBLSGroup{SchnorrGroup{P: p, Q: new(big.Int).Rsh(p, 1), G: big.NewInt(4)}}
+
+	key := big.NewInt(0x5ec7e7)
+	shares, err := SplitShares(key, g.Q, 3, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("attestation")
+	var partials []BLSPartialSignature[*big.Int]
+	for _, i := range []int{1, 4, 2} {
+		ps, err := BLSPartialSign[*big.Int](g, shares[i], message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partials = append(partials, *ps)
+	}
+
+	sig, err := BLSAggregate[*big.Int](g, partials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := g.ScalarMult(key, g.HashToGroup(message)); sig.Cmp(want) != 0 {
+		t.Errorf("aggregate signature isn't the signature under the key")
+	}
+
+	shares[0].Modulus = p
+	if _, err := BLSPartialSign[*big.Int](g, shares[0], message); err == nil {
+		t.Errorf("share over the wrong modulus was used")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// SplitBytes is like Split, but for a secret that is a byte string. Since
+// converting bytes to a big.Int loses any leading zeros, the secret is
+// prefixed with a single 0x01 byte before conversion, so the modulus must be
+// greater than 2^(8*(len(secret)+1)).
+func SplitBytes(secret []byte, modulus *big.Int, k, n int, rand io.Reader) ([]*big.Int, error) {
+	if modulus.BitLen() <= 8*(len(secret)+1) {
+		return nil, errors.New("secret is too long for split modulus")
+	}
+
+	v := make([]byte, len(secret)+1)
+	v[0] = 1
+	copy(v[1:], secret)
+	return Split(new(big.Int).SetBytes(v), modulus, k, n, rand)
+}
+
+// JoinBytes is like Join, but recovers a secret that was split with
+// SplitBytes. The result is exactly the byte string that was split.
+func JoinBytes(shares []*big.Int, shareNumbers []int, modulus *big.Int) ([]byte, error) {
+	v, err := Join(shares, shareNumbers, modulus)
+	if err != nil {
+		return nil, err
+	}
+
+	b := v.Bytes()
+	if len(b) == 0 || b[0] != 1 {
+		return nil, errors.New("recovered value isn't a byte string: too few or corrupt shares")
+	}
+	return b[1:], nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitBytes(t *testing.T) {
+	for _, secret := range [][]byte{
+		{},
+		{0},
+		{0, 0, 0, 42},
+		bytes.Repeat([]byte{0xff}, 254),
+	} {
+		shares, err := SplitBytes(secret, MODP2048, 3, 5, nil)
+		if err != nil {
+			t.Fatalf("error while splitting: %s", err)
+		}
+
+		result, err := JoinBytes(shares[1:4], []int{1, 2, 3}, MODP2048)
+		if err != nil {
+			t.Fatalf("failed to join shares: %s", err)
+		}
+		if !bytes.Equal(result, secret) {
+			t.Errorf("JoinBytes returned %x, want %x", result, secret)
+		}
+	}
+
+	if _, err := SplitBytes(make([]byte, 255), MODP2048, 3, 5, nil); err == nil {
+		t.Errorf("secret too long for modulus was accepted")
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// Encodings in this package are canonical: records are in order of tag,
+// integers are big-endian without leading zeros, times are whole seconds
+// since the Unix epoch and nothing depends on the iteration order of maps.
+// So the same value always has the same encoding, and hashes and signatures
+// over encodings are stable across machines and versions of Go.
+
+// A share set uses the encoding of binary shares (see wire.go) with its own
+// magic. The shares are included in their binary encoding.
+const shareSetMagic = "SHMA"
+
+// SortShares sorts shares in place by x coordinate, which is the canonical
+// order of shares in a set. Shares with no x coordinate sort last.
+func SortShares(shares []Share) {
+	sort.SliceStable(shares, func(i, j int) bool {
+		a, b := shares[i].X, shares[j].X
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Cmp(b) < 0
+	})
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is
+// canonical: in particular, the shares are encoded in order of x coordinate,
+// whatever their order in s. Shares that don't record their modulus are
+// encoded with that of the set.
+func (s *ShareSet) MarshalBinary() ([]byte, error) {
+	if s.Modulus == nil || s.Modulus.Sign() <= 0 || len(s.Shares) == 0 {
+		return nil, errors.New("share set is incomplete")
+	}
+	if s.Threshold < 1 || s.Threshold > len(s.Shares) {
+		return nil, errors.New("invalid threshold")
+	}
+
+	var r wireRecords
+	if name := modulusName(s.Modulus); len(name) > 0 {
+		r.add(tagModulusName, []byte(name))
+	} else {
+		r.addInt(tagModulus, s.Modulus)
+	}
+	r.addUint(tagThreshold, uint64(s.Threshold))
+	if s.Commitments != nil {
+		b, err := s.Commitments.marshal()
+		if err != nil {
+			return nil, err
+		}
+		r.add(tagCommitments, b)
+		if s.Commitments.Epoch > 0 {
+			r.addUint(tagEpoch, s.Commitments.Epoch)
+		}
+	}
+
+	shares := append([]Share(nil), s.Shares...)
+	SortShares(shares)
+	var b []byte
+	for i := range shares {
+		if shares[i].Modulus == nil {
+			shares[i].Modulus = s.Modulus
+		} else if shares[i].Modulus.Cmp(s.Modulus) != 0 {
+			return nil, errors.New("share has a different modulus to the set")
+		}
+		data, err := shares[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && shares[i].X.Cmp(shares[i-1].X) == 0 {
+			return nil, errors.New("found duplicate share")
+		}
+		b = binary.AppendUvarint(b, uint64(len(data)))
+		b = append(b, data...)
+	}
+	r.add(tagShares, b)
+	return r.marshalAs(shareSetMagic), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It only accepts
+// the canonical encoding, with the shares in increasing order of x
+// coordinate, using DefaultParseLimits for each share.
+func (s *ShareSet) UnmarshalBinary(data []byte) error {
+	var set ShareSet
+	err := parseWireAs(shareSetMagic, data, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagModulus:
+			set.Modulus = new(big.Int).SetBytes(value)
+		case tagModulusName:
+			if set.Modulus = NamedModulus(string(value)); set.Modulus == nil {
+				return errors.New("share set uses an unknown modulus")
+			}
+		case tagThreshold:
+			k, err := parseWireUint(value)
+			if err != nil || k < 1 || k > uint64(DefaultLimits.MaxThreshold) {
+				return errors.New("invalid threshold")
+			}
+			set.Threshold = int(k)
+		case tagCommitments:
+			c, err := parseCommitments(value)
+			set.Commitments = c
+			return err
+		case tagEpoch:
+			// Commitments come before the epoch, in tag order.
+			e, err := parseWireUint(value)
+			if err != nil || set.Commitments == nil {
+				return errors.New("invalid epoch")
+			}
+			set.Commitments.Epoch = e
+		case tagShares:
+			for len(value) > 0 {
+				l, n := binary.Uvarint(value)
+				if n <= 0 || l > uint64(len(value)-n) {
+					return errors.New("truncated share")
+				}
+				share, err := ParseShare(value[n:n+int(l)], nil)
+				if err != nil {
+					return err
+				}
+				if len(set.Shares) >= DefaultLimits.MaxShares {
+					return ErrLimitExceeded
+				}
+				set.Shares = append(set.Shares, share)
+				value = value[n+int(l):]
+			}
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if set.Modulus == nil || set.Modulus.Sign() <= 0 || set.Threshold == 0 || len(set.Shares) < set.Threshold {
+		return errors.New("share set is incomplete")
+	}
+	for i, share := range set.Shares {
+		if share.Modulus == nil || share.Modulus.Cmp(set.Modulus) != 0 {
+			return errors.New("share has a different modulus to the set")
+		}
+		if i > 0 && share.X.Cmp(set.Shares[i-1].X) <= 0 {
+			return errors.New("shares are not in canonical order")
+		}
+	}
+	*s = set
+	return nil
+}
+
+// marshal returns the encoding of c used in transcripts and share sets: p,
+// g and then the values, each preceded by its length as a uvarint.
+func (c *Commitments) marshal() ([]byte, error) {
+	var b []byte
+	for _, v := range append([]*big.Int{c.P, c.G}, c.Values...) {
+		if v == nil || v.Sign() < 0 {
+			return nil, errors.New("invalid commitment")
+		}
+		b = binary.AppendUvarint(b, uint64(len(v.Bytes())))
+		b = append(b, v.Bytes()...)
+	}
+	return b, nil
+}
+
+func parseCommitments(value []byte) (*Commitments, error) {
+	var values []*big.Int
+	for len(value) > 0 {
+		l, n := binary.Uvarint(value)
+		if n <= 0 || l > uint64(len(value)-n) {
+			return nil, errors.New("invalid commitments")
+		}
+		values = append(values, new(big.Int).SetBytes(value[n:n+int(l)]))
+		value = value[n+int(l):]
+	}
+	if len(values) < 3 {
+		return nil, errors.New("invalid commitments")
+	}
+	return &Commitments{P: values[0], G: values[1], Values: values[2:]}, nil
+}
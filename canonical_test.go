@@ -0,0 +1,86 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"testing"
+)
+
+func TestShareSetCanonical(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	set, err := SplitVerifiable(big.NewInt(42), q, p, big.NewInt(4), 2, 4, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := set.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTranscript(set, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reordering the shares changes neither encoding.
+	shuffled := *set
+	shuffled.Shares = []Share{set.Shares[2], set.Shares[0], set.Shares[3], set.Shares[1]}
+	got, err := shuffled.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("share set encoding depends on the order of the shares")
+	}
+	tr2, err := NewTranscript(&shuffled, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr2.Metadata.Created = tr.Metadata.Created
+	a, _ := tr.MarshalBinary()
+	b, _ := tr2.MarshalBinary()
+	if !bytes.Equal(a, b) {
+		t.Error("transcript depends on the order of the shares")
+	}
+
+	var parsed ShareSet
+	if err := parsed.UnmarshalBinary(want); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Threshold != 2 || len(parsed.Shares) != 4 || parsed.Commitments == nil {
+		t.Fatalf("parsed set is %+v", parsed)
+	}
+	again, err := parsed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, want) {
+		t.Error("round trip changed the encoding")
+	}
+	if v, err := JoinShares(parsed.Shares[2:]); err != nil || v.Int64() != 42 {
+		t.Errorf("got %v, %v from parsed shares", v, err)
+	}
+
+	// Encodings with the shares out of order are rejected.
+	var r wireRecords
+	r.add(tagModulus, q.Bytes())
+	r.addUint(tagThreshold, 2)
+	var b2 []byte
+	for _, i := range []int{1, 0} {
+		data, _ := set.Shares[i].MarshalBinary()
+		b2 = binary.AppendUvarint(b2, uint64(len(data)))
+		b2 = append(b2, data...)
+	}
+	r.add(tagShares, b2)
+	if err := parsed.UnmarshalBinary(r.marshalAs(shareSetMagic)); err == nil {
+		t.Error("shares out of order were accepted")
+	}
+}
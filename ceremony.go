@@ -0,0 +1,243 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Ceremony messages are exchanged between a coordinator, who collects shares,
+// and each custodian over a stream such as a TLS connection. Each message is
+// a 4 byte, big-endian length followed by the message in the encoding of
+// binary shares (see wire.go) with its own magic and tags.
+//
+// The coordinator sends a request, with a fresh nonce, and the custodian
+// replies with a submission of their share and a proof that binds it to the
+// nonce, such as a signature. The coordinator then replies with an ack, if it
+// accepts the share, or an abort. Either side may abort at any point.
+const ceremonyMagic = "SHMC"
+
+const (
+	tagCeremonyType   = 1
+	tagCeremonySetID  = 2
+	tagCeremonyNonce  = 3
+	tagCeremonyShare  = 4
+	tagCeremonyProof  = 5
+	tagCeremonyReason = 6
+)
+
+// maxCeremonyMessage limits the size of messages that will be read.
+const maxCeremonyMessage = 1 << 20
+
+// A CeremonyMessageType identifies the kind of a CeremonyMessage.
+type CeremonyMessageType int
+
+const (
+	CeremonyRequest CeremonyMessageType = iota + 1
+	CeremonySubmission
+	CeremonyAck
+	CeremonyAbort
+)
+
+// A CeremonyMessage is a single message of the share collection protocol.
+// Which fields are used depends on the Type.
+type CeremonyMessage struct {
+	Type CeremonyMessageType
+	// SetID, in a request, is the dealing whose shares are wanted, or
+	// all zeros for any dealing.
+	SetID [16]byte
+	// Nonce is chosen by the coordinator for a request and echoed in the
+	// submission.
+	Nonce [32]byte
+	// Share is the binary encoding of the share in a submission and
+	// Proof is the custodian's proof, the format of which is up to the
+	// application.
+	Share []byte
+	Proof []byte
+	// Reason explains an abort.
+	Reason string
+}
+
+// WriteCeremonyMessage writes m to w.
+func WriteCeremonyMessage(w io.Writer, m *CeremonyMessage) error {
+	if m.Type < CeremonyRequest || m.Type > CeremonyAbort {
+		return errors.New("invalid ceremony message type")
+	}
+
+	var r wireRecords
+	r.addUint(tagCeremonyType, uint64(m.Type))
+	switch m.Type {
+	case CeremonyRequest:
+		r.add(tagCeremonySetID, m.SetID[:])
+		r.add(tagCeremonyNonce, m.Nonce[:])
+	case CeremonySubmission:
+		r.add(tagCeremonyNonce, m.Nonce[:])
+		r.add(tagCeremonyShare, m.Share)
+		r.add(tagCeremonyProof, m.Proof)
+	case CeremonyAbort:
+		r.add(tagCeremonyReason, []byte(m.Reason))
+	}
+	data := r.marshalAs(ceremonyMagic)
+	if len(data) > maxCeremonyMessage {
+		return errors.New("ceremony message is too large")
+	}
+
+	_, err := w.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(data))), data...))
+	return err
+}
+
+// ReadCeremonyMessage reads a message from r.
+func ReadCeremonyMessage(r io.Reader) (*CeremonyMessage, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	l := binary.BigEndian.Uint32(length[:])
+	if l > maxCeremonyMessage {
+		return nil, errors.New("ceremony message is too large")
+	}
+	data := make([]byte, l)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	m := new(CeremonyMessage)
+	err := parseWireAs(ceremonyMagic, data, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagCeremonyType:
+			t, err := parseWireUint(value)
+			if err != nil || t < uint64(CeremonyRequest) || t > uint64(CeremonyAbort) {
+				return errors.New("invalid ceremony message type")
+			}
+			m.Type = CeremonyMessageType(t)
+		case tagCeremonySetID:
+			if len(value) != len(m.SetID) {
+				return errors.New("invalid set ID")
+			}
+			copy(m.SetID[:], value)
+		case tagCeremonyNonce:
+			if len(value) != len(m.Nonce) {
+				return errors.New("invalid nonce")
+			}
+			copy(m.Nonce[:], value)
+		case tagCeremonyShare:
+			m.Share = append([]byte(nil), value...)
+		case tagCeremonyProof:
+			m.Proof = append([]byte(nil), value...)
+		case tagCeremonyReason:
+			m.Reason = string(value)
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m.Type == 0 {
+		return nil, errors.New("ceremony message has no type")
+	}
+	return m, nil
+}
+
+// CollectShare runs the coordinator's side of the protocol with a single
+// custodian over rw and returns their share. If check is not nil, it's called
+// with the share, the custodian's proof and the request's nonce, and the
+// share is rejected unless it returns nil; a Combiner's Add can be called from
+// it. If rand is nil, crypto/rand.Reader is used.
+func CollectShare(rw io.ReadWriter, setID [16]byte, check func(s *Share, proof []byte, nonce [32]byte) error, rand io.Reader) (Share, error) {
+	req := &CeremonyMessage{Type: CeremonyRequest, SetID: setID}
+	if _, err := io.ReadFull(defaultRand(rand), req.Nonce[:]); err != nil {
+		return Share{}, err
+	}
+	if err := WriteCeremonyMessage(rw, req); err != nil {
+		return Share{}, err
+	}
+
+	m, err := ReadCeremonyMessage(rw)
+	if err != nil {
+		return Share{}, err
+	}
+	switch {
+	case m.Type == CeremonyAbort:
+		return Share{}, errors.New("custodian aborted: " + m.Reason)
+	case m.Type != CeremonySubmission:
+		err = errors.New("unexpected ceremony message")
+	case m.Nonce != req.Nonce:
+		err = errors.New("submission is for a different request")
+	}
+
+	var s Share
+	if err == nil {
+		err = s.UnmarshalBinary(m.Share)
+	}
+	if err == nil && setID != [16]byte{} && (s.Metadata == nil || s.Metadata.SetID != setID) {
+		err = errors.New("share is from a different dealing")
+	}
+	if err == nil && check != nil {
+		err = check(&s, m.Proof, req.Nonce)
+	}
+	if err != nil {
+		WriteCeremonyMessage(rw, &CeremonyMessage{Type: CeremonyAbort, Reason: err.Error()})
+		return Share{}, err
+	}
+
+	if err := WriteCeremonyMessage(rw, &CeremonyMessage{Type: CeremonyAck}); err != nil {
+		return Share{}, err
+	}
+	return s, nil
+}
+
+// SubmitShare runs a custodian's side of the protocol over rw, submitting s.
+// The proof is the result of calling prove, if not nil, with the request's
+// nonce and the binary encoding of s. It returns nil once the coordinator has
+// acknowledged the share.
+func SubmitShare(rw io.ReadWriter, s *Share, prove func(nonce [32]byte, share []byte) ([]byte, error)) error {
+	m, err := ReadCeremonyMessage(rw)
+	if err != nil {
+		return err
+	}
+	if m.Type == CeremonyAbort {
+		return errors.New("coordinator aborted: " + m.Reason)
+	}
+
+	var data, proof []byte
+	switch {
+	case m.Type != CeremonyRequest:
+		err = errors.New("unexpected ceremony message")
+	case m.SetID != [16]byte{} && (s.Metadata == nil || s.Metadata.SetID != m.SetID):
+		err = errors.New("coordinator requested a different dealing")
+	}
+	if err == nil {
+		data, err = s.MarshalBinary()
+	}
+	if err == nil && prove != nil {
+		proof, err = prove(m.Nonce, data)
+	}
+	if err != nil {
+		WriteCeremonyMessage(rw, &CeremonyMessage{Type: CeremonyAbort, Reason: err.Error()})
+		return err
+	}
+
+	sub := &CeremonyMessage{Type: CeremonySubmission, Nonce: m.Nonce, Share: data, Proof: proof}
+	if err := WriteCeremonyMessage(rw, sub); err != nil {
+		return err
+	}
+
+	if m, err = ReadCeremonyMessage(rw); err != nil {
+		return err
+	}
+	switch m.Type {
+	case CeremonyAck:
+		return nil
+	case CeremonyAbort:
+		return errors.New("coordinator rejected share: " + m.Reason)
+	}
+	return errors.New("unexpected ceremony message")
+}
@@ -0,0 +1,78 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+)
+
+func TestCeremonyMessage(t *testing.T) {
+	m := &CeremonyMessage{Type: CeremonySubmission, Share: []byte("share"), Proof: []byte("proof")}
+	m.Nonce[0] = 1
+
+	var buf bytes.Buffer
+	if err := WriteCeremonyMessage(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	m2, err := ReadCeremonyMessage(&buf)
+	if err != nil {
+		t.Fatalf("error reading message: %s", err)
+	}
+	if m2.Type != m.Type || m2.Nonce != m.Nonce || !bytes.Equal(m2.Share, m.Share) || !bytes.Equal(m2.Proof, m.Proof) {
+		t.Errorf("got %+v, want %+v", m2, m)
+	}
+}
+
+func TestCeremony(t *testing.T) {
+	shares, _ := SplitShares(big.NewInt(42), P256Order, 2, 3, nil)
+	meta, _ := NewMetadata(2, "", nil)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+
+	prove := func(nonce [32]byte, share []byte) ([]byte, error) {
+		return ed25519.Sign(priv, append(nonce[:], share...)), nil
+	}
+	check := func(s *Share, proof []byte, nonce [32]byte) error {
+		data, _ := s.MarshalBinary()
+		if !ed25519.Verify(pub, append(nonce[:], data...), proof) {
+			return errors.New("bad proof")
+		}
+		return nil
+	}
+
+	for _, wrongDealing := range []bool{false, true} {
+		s := shares[0]
+		s.Metadata = meta
+		setID := meta.SetID
+		if wrongDealing {
+			setID[0] ^= 1
+		}
+
+		coordinator, custodian := net.Pipe()
+		done := make(chan error)
+		go func() { done <- SubmitShare(custodian, &s, prove) }()
+
+		got, err := CollectShare(coordinator, setID, check, nil)
+		custodianErr := <-done
+		if wrongDealing {
+			if err == nil || custodianErr == nil {
+				t.Errorf("share for the wrong dealing was collected")
+			}
+			continue
+		}
+		if err != nil || custodianErr != nil {
+			t.Fatalf("ceremony failed: %v, %v", err, custodianErr)
+		}
+		if got.Y.Cmp(s.Y) != 0 {
+			t.Errorf("collected the wrong share")
+		}
+	}
+}
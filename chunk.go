@@ -0,0 +1,275 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/big"
+)
+
+// A ChunkedShare is a share of a secret that was too large for the field. The
+// secret was cut into limbs, each of which was shared separately, and the
+// share holds the value of each limb's polynomial at X.
+type ChunkedShare struct {
+	X         *big.Int
+	Ys        []*big.Int
+	Modulus   *big.Int
+	SecretLen int
+	// Packing, if greater than one, is the number of limbs packed into
+	// each polynomial by SplitRamp, and Ys holds one value for each
+	// polynomial.
+	Packing int
+}
+
+// limbLen returns the number of bytes of secret that fit in each limb when
+// sharing over modulus.
+func limbLen(modulus *big.Int) int {
+	return (modulus.BitLen() - 1) / 8
+}
+
+// SplitChunked splits a secret of any length into n shares, any k of which
+// can be combined by JoinChunked to recover it. The secret is cut into limbs
+// of (modulus.BitLen()-1)/8 bytes, each of which is shared with an
+// independent polynomial, so the modulus must be at least 2^8. If rand is
+// nil, crypto/rand.Reader is used.
+func SplitChunked(secret []byte, modulus *big.Int, k, n int, rand io.Reader) ([]ChunkedShare, error) {
+	l := limbLen(modulus)
+	if l < 1 {
+		return nil, errors.New("modulus is too small for chunking")
+	}
+
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+
+	shares := make([]ChunkedShare, n)
+	for i := range shares {
+		shares[i] = ChunkedShare{
+			X:         big.NewInt(int64(i + 1)),
+			Modulus:   modulus,
+			SecretLen: len(secret),
+		}
+	}
+
+	for len(secret) > 0 {
+		limb := secret
+		if len(limb) > l {
+			limb = limb[:l]
+		}
+		secret = secret[len(limb):]
+
+		ys, err := Split(new(big.Int).SetBytes(limb), modulus, k, n, rand)
+		if err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			shares[i].Ys = append(shares[i].Ys, ys[i])
+		}
+	}
+
+	return shares, nil
+}
+
+// JoinChunked recovers the secret from at least k shares that resulted from
+// SplitChunked.
+func JoinChunked(shares []ChunkedShare) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	modulus := shares[0].Modulus
+	secretLen := shares[0].SecretLen
+	if modulus == nil || limbLen(modulus) < 1 || secretLen <= 0 {
+		return nil, errors.New("shares don't record their modulus and secret length")
+	}
+	l := limbLen(modulus)
+	limbs := (secretLen + l - 1) / l
+	packing := max(shares[0].Packing, 1)
+	polys := (limbs + packing - 1) / packing
+
+	xs := make([]*big.Int, len(shares))
+	for i, s := range shares {
+		if s.Modulus == nil || s.Modulus.Cmp(modulus) != 0 || s.SecretLen != secretLen || max(s.Packing, 1) != packing {
+			return nil, errors.New("shares are from different splits")
+		}
+		if len(s.Ys) != polys {
+			return nil, errors.New("share has the wrong number of limbs")
+		}
+		if s.X.Sign() <= 0 || s.X.Cmp(modulus) >= 0 {
+			return nil, errors.New("share has invalid x coordinate")
+		}
+		for j := 0; j < i; j++ {
+			if xs[j].Cmp(s.X) == 0 {
+				return nil, errors.New("found duplicate share")
+			}
+		}
+		xs[i] = s.X
+	}
+
+	values := make([]*big.Int, 0, polys*packing)
+	ys := make([]*big.Int, len(shares))
+	for poly := 0; poly < polys; poly++ {
+		for i, s := range shares {
+			ys[i] = s.Ys[poly]
+		}
+
+		if packing == 1 {
+			v, err := interpolate(xs, ys, modulus)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			continue
+		}
+		points := make([]Share, len(shares))
+		for i := range points {
+			points[i] = Share{X: xs[i], Y: ys[i], Modulus: modulus}
+		}
+		v, err := JoinMulti(points, packing)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v...)
+	}
+
+	secret := make([]byte, secretLen)
+	for limb := 0; limb < limbs; limb++ {
+		out := secret[limb*l:]
+		if len(out) > l {
+			out = out[:l]
+		}
+		v := values[limb]
+		if v.BitLen() > 8*len(out) {
+			return nil, errors.New("recovered value is too large: too few or corrupt shares")
+		}
+		v.FillBytes(out)
+	}
+
+	return secret, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the binary share
+// format described in wire.go. The values of the limbs are stored in a single
+// record, each padded to the length of the modulus.
+func (s *ChunkedShare) MarshalBinary() ([]byte, error) {
+	if s.X == nil || s.Modulus == nil || s.X.Sign() < 0 || s.Modulus.Sign() <= 0 || s.SecretLen <= 0 {
+		return nil, errors.New("chunked share is incomplete")
+	}
+
+	width := (s.Modulus.BitLen() + 7) / 8
+	ys := make([]byte, 0, width*len(s.Ys))
+	for _, y := range s.Ys {
+		if y == nil || y.Sign() < 0 || y.Cmp(s.Modulus) >= 0 {
+			return nil, errors.New("chunked share has an invalid limb")
+		}
+		ys = append(ys, make([]byte, width)...)
+		y.FillBytes(ys[len(ys)-width:])
+	}
+
+	var r wireRecords
+	r.addInt(tagX, s.X)
+	if name := modulusName(s.Modulus); len(name) > 0 {
+		r.add(tagModulusName, []byte(name))
+	} else {
+		r.addInt(tagModulus, s.Modulus)
+	}
+	r.addUint(tagSecretLen, uint64(s.SecretLen))
+	r.add(tagLimbs, ys)
+	if s.Packing > 1 {
+		r.addUint(tagPacking, uint64(s.Packing))
+	}
+	return r.marshal(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, using
+// DefaultParseLimits.
+func (s *ChunkedShare) UnmarshalBinary(data []byte) error {
+	share, err := ParseChunkedShare(data, nil)
+	if err != nil {
+		return err
+	}
+	*s = share
+	return nil
+}
+
+// ParseChunkedShare is like UnmarshalBinary, but with the given limits, or
+// DefaultParseLimits if nil. Invalid encodings result in a *ParseError.
+func ParseChunkedShare(data []byte, limits *ParseLimits) (share ChunkedShare, err error) {
+	limits = limitsOrDefault(limits)
+	if err := limits.checkSize(data); err != nil {
+		return share, err
+	}
+
+	var ys []byte
+	err = parseWire(data, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagX, tagModulus:
+			if err := limits.checkInt(value); err != nil {
+				return err
+			}
+		}
+
+		switch tag {
+		case tagX:
+			share.X = new(big.Int).SetBytes(value)
+		case tagModulus:
+			share.Modulus = new(big.Int).SetBytes(value)
+		case tagModulusName:
+			if share.Modulus = NamedModulus(string(value)); share.Modulus == nil {
+				return errors.New("share uses an unknown modulus")
+			}
+		case tagSecretLen:
+			l, err := parseWireUint(value)
+			if err != nil || l > math.MaxInt32 {
+				return errors.New("invalid secret length")
+			}
+			share.SecretLen = int(l)
+		case tagLimbs:
+			ys = value
+		case tagPacking:
+			p, err := parseWireUint(value)
+			if err != nil || p > uint64(limits.MaxLimbs) {
+				return errors.New("invalid packing")
+			}
+			share.Packing = int(p)
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return ChunkedShare{}, err
+	}
+
+	if share.X == nil || share.Modulus == nil || share.Modulus.Sign() == 0 || ys == nil {
+		return ChunkedShare{}, &ParseError{Offset: -1, Err: errors.New("chunked share is incomplete")}
+	}
+
+	width := (share.Modulus.BitLen() + 7) / 8
+	if len(ys)%width != 0 {
+		return ChunkedShare{}, &ParseError{Offset: -1, Err: errors.New("chunked share has a truncated limb")}
+	}
+	if len(ys)/width > limits.MaxLimbs {
+		return ChunkedShare{}, &ParseError{Offset: -1, Err: errors.New("chunked share has too many limbs")}
+	}
+	// The secret length determines the allocations in JoinChunked, so it
+	// mustn't exceed what the limbs can hold.
+	if share.SecretLen > len(ys)/width*max(share.Packing, 1)*limbLen(share.Modulus) {
+		return ChunkedShare{}, &ParseError{Offset: -1, Err: errors.New("chunked share is too short for its secret")}
+	}
+	share.Ys = make([]*big.Int, 0, len(ys)/width)
+	for len(ys) > 0 {
+		share.Ys = append(share.Ys, new(big.Int).SetBytes(ys[:width]))
+		ys = ys[width:]
+	}
+	return share, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestSplitChunked(t *testing.T) {
+	secret := make([]byte, 1000)
+	rand.Read(secret)
+	secret[0] = 0
+
+	// A 61-bit modulus gives seven byte limbs and a short final limb.
+	modulus := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+
+	shares, err := SplitChunked(secret, modulus, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	if len(shares[0].Ys) != 143 {
+		t.Errorf("got %d limbs, want 143", len(shares[0].Ys))
+	}
+
+	parsed := make([]ChunkedShare, 3)
+	for i := range parsed {
+		data, err := shares[2*i].MarshalBinary()
+		if err != nil {
+			t.Fatalf("error while marshaling: %s", err)
+		}
+		if err := parsed[i].UnmarshalBinary(data); err != nil {
+			t.Fatalf("error while unmarshaling: %s", err)
+		}
+	}
+
+	result, err := JoinChunked(parsed)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if !bytes.Equal(result, secret) {
+		t.Errorf("JoinChunked didn't return the secret")
+	}
+
+	// A record that this version doesn't understand, like the packing
+	// record once was, may change the meaning of the limbs.
+	data, _ := shares[0].MarshalBinary()
+	extended := append(data, 0x7f, 0x02, 0xaa, 0xbb)
+	var s ChunkedShare
+	if err := s.UnmarshalBinary(extended); err == nil {
+		t.Errorf("chunked share with unknown record was accepted")
+	}
+
+	if _, err := SplitChunked(secret, big.NewInt(251), 2, 3, nil); err == nil {
+		t.Errorf("modulus smaller than a byte was accepted")
+	}
+}
+
+func TestParseChunkedShareLimits(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitChunked(make([]byte, 100), modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	data, err := shares[0].MarshalBinary()
+	if err != nil {
+		t.Fatalf("error while marshaling: %s", err)
+	}
+
+	limits := DefaultParseLimits
+	limits.MaxLimbs = len(shares[0].Ys) - 1
+	if _, err := ParseChunkedShare(data, &limits); err == nil {
+		t.Errorf("share with too many limbs was accepted")
+	}
+
+	// A secret length that the limbs can't hold would make JoinChunked
+	// allocate without bound.
+	s := shares[0]
+	s.SecretLen = 1 << 30
+	data, err = s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("error while marshaling: %s", err)
+	}
+	var pe *ParseError
+	if _, err := ParseChunkedShare(data, nil); !errors.As(err, &pe) {
+		t.Errorf("share with an excessive secret length gave %v, want a *ParseError", err)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/agl/shamirsplit"
+)
+
+// ceremonyResult is the JSON output of ceremony. The secret is encoded in
+// base64 unless it was written to a file.
+type ceremonyResult struct {
+	jsonResult
+	SetID    string `json:"set_id,omitempty"`
+	Accepted int    `json:"accepted"`
+	Rejected int    `json:"rejected"`
+	Path     string `json:"path,omitempty"`
+	Secret   []byte `json:"secret,omitempty"`
+}
+
+func ceremony(args []string, stdin io.Reader, stdout, stderr io.Writer) (err error) {
+	fs := flag.NewFlagSet("ceremony", flag.ContinueOnError)
+	out := fs.String("o", "", "file to write the secret to, instead of standard output")
+	k := fs.Int("k", 0, "number of shares needed, if the shares don't record it")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	res := &ceremonyResult{jsonResult: jsonResult{Command: "ceremony"}}
+	var c *shamirsplit.Combiner
+	defer func() {
+		if c != nil {
+			res.Accepted = c.Status().Accepted
+		}
+		err = writeJSON(stdout, *asJSON, res, err)
+		clear(res.Secret)
+	}()
+	if fs.NArg() != 0 {
+		return errors.New("ceremony takes no arguments")
+	}
+
+	if f, ok := stdin.(*os.File); ok && isTerminal(f) {
+		restore, err := disableEcho(f)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	} else {
+		fmt.Fprintln(stderr, "warning: input isn't a terminal; entries won't be masked")
+	}
+
+	c, err = shamirsplit.NewCombiner(*k, nil)
+	if err != nil {
+		return err
+	}
+	var secretLen int
+	r := bufio.NewReader(stdin)
+	for {
+		status := c.Status()
+		if status.Needed == 0 {
+			break
+		}
+		if status.Needed < 0 {
+			fmt.Fprintf(stderr, "Enter share %d: ", status.Accepted+1)
+		} else {
+			fmt.Fprintf(stderr, "Enter share %d of %d: ", status.Accepted+1, status.Accepted+status.Needed)
+		}
+
+		entry, err := readEntry(r)
+		// The terminal doesn't echo the newline either.
+		fmt.Fprintln(stderr)
+		if len(entry) == 0 && err != nil {
+			if err == io.EOF {
+				return &shamirsplit.NotEnoughSharesError{Need: status.Accepted + status.Needed, Have: status.Accepted}
+			}
+			return err
+		}
+
+		s, err := parseShare(entry)
+		clear(entry)
+		if err == nil && s.SecretLen <= 0 {
+			err = errors.New("share doesn't record the secret length")
+		} else if err == nil && status.Accepted > 0 && s.SecretLen != secretLen {
+			err = errors.New("share is from a different dealing")
+		}
+		if err == nil {
+			err = c.Add(s)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Share rejected: %v. Try again.\n", err)
+			res.Rejected++
+			continue
+		}
+		secretLen = s.SecretLen
+		res.SetID = setIDString(&s)
+
+		status = c.Status()
+		label := ""
+		if s.Participant != "" {
+			label = " from " + s.Participant
+		}
+		if s.Metadata != nil && s.Metadata.Label != "" {
+			label += " of " + s.Metadata.Label
+		}
+		if status.Needed < 0 {
+			fmt.Fprintf(stderr, "Accepted share %v%s.\n", s.X, label)
+		} else {
+			fmt.Fprintf(stderr, "Accepted share %v%s: %d of %d entered.\n", s.X, label, status.Accepted, status.Accepted+status.Needed)
+		}
+	}
+
+	v, err := c.Combine()
+	if err != nil {
+		return err
+	}
+	defer clear(v.Bits())
+	if v.BitLen() > 8*secretLen {
+		return errors.New("recovered value is too large: corrupt shares")
+	}
+	secret := v.FillBytes(make([]byte, secretLen))
+	defer clear(secret)
+
+	if *out == "" {
+		if *asJSON {
+			// secret is cleared before the result is written, so
+			// it's copied.
+			res.Secret = bytes.Clone(secret)
+			return nil
+		}
+		_, err = stdout.Write(secret)
+		return err
+	}
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(secret)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		fmt.Fprintln(stderr, "Secret written to", *out)
+		res.Path = *out
+	}
+	return err
+}
+
+// readEntry reads a share from r: a line holding a share URI or, if the
+// line begins an armored share, the lines up to the end of the armor.
+// Blank lines are skipped.
+func readEntry(r *bufio.Reader) ([]byte, error) {
+	var entry []byte
+	for {
+		line, err := r.ReadBytes('\n')
+		trimmed := bytes.TrimSpace(line)
+		if len(entry) == 0 && len(trimmed) == 0 {
+			clear(line)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		end := bytes.HasPrefix(trimmed, []byte("-----END"))
+		entry = append(entry, line...)
+		clear(line)
+		if err != nil || end || !bytes.HasPrefix(entry, []byte("-----BEGIN")) {
+			return entry, err
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agl/shamirsplit"
+)
+
+// splitForTest returns three shares of secret, threshold two, in format.
+func splitForTest(t *testing.T, secret, format string) []string {
+	var out bytes.Buffer
+	if err := run([]string{"split", "-k", "2", "-n", "3", "-label", "root key", "-format", format}, strings.NewReader(secret), &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if format == "uri" {
+		return strings.Fields(out.String())
+	}
+	parts := strings.SplitAfter(out.String(), "-----END SHAMIRSPLIT SHARE-----\n")
+	return parts[:len(parts)-1]
+}
+
+func TestCeremony(t *testing.T) {
+	armored := splitForTest(t, "hunter2", "armor")
+	other := splitForTest(t, "hunter2", "armor")
+	s, err := shamirsplit.Dearmor([]byte(armored[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, err := shamirsplit.EncodeShareURI(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A garbled entry, then the same share twice, then one from another
+	// dealing and finally an armored share.
+	input := "not a share\n\n" + uri + "\n" + uri + "\n" + other[1] + armored[2]
+	var out, prompts bytes.Buffer
+	if err := run([]string{"ceremony"}, strings.NewReader(input), &out, &prompts); err != nil {
+		t.Fatalf("%v\n%s", err, prompts.String())
+	}
+	if out.String() != "hunter2" {
+		t.Errorf("got secret %q", out.String())
+	}
+	log := prompts.String()
+	for _, want := range []string{"Share rejected", "duplicate", "set ID differs", "Accepted share 1 of root key: 1 of 2 entered.", "Enter share 2 of 2"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("output lacks %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "hunter2") || strings.Contains(log, uri) {
+		t.Errorf("output reveals a secret:\n%s", log)
+	}
+}
+
+func TestCeremonyNotEnough(t *testing.T) {
+	uris := splitForTest(t, "hunter2", "uri")
+	var out, prompts bytes.Buffer
+	err := run([]string{"ceremony"}, strings.NewReader(uris[1]+"\n"), &out, &prompts)
+	if !errors.Is(err, shamirsplit.ErrNotEnoughShares) {
+		t.Errorf("got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Error("secret written without enough shares")
+	}
+}
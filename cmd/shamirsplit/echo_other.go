@@ -0,0 +1,19 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !unix && !nobig
+
+package main
+
+import "os"
+
+// isTerminal reports false: input is never masked on these systems, and
+// ceremony warns about it.
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+func disableEcho(f *os.File) (restore func(), err error) {
+	return func() {}, nil
+}
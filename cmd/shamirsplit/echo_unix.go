@@ -0,0 +1,34 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix && !nobig
+
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// disableEcho stops the terminal f from echoing input, using stty so as not
+// to depend on the termios layout of each system, and returns a function
+// that restores it.
+func disableEcho(f *os.File) (restore func(), err error) {
+	if err := stty(f, "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(f, "echo") }, nil
+}
+
+func stty(f *os.File, arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = f
+	return cmd.Run()
+}
@@ -0,0 +1,164 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/agl/shamirsplit"
+)
+
+// inspection is what inspect reports about a share, and its JSON form.
+type inspection struct {
+	jsonResult
+	Format      string   `json:"format,omitempty"`
+	Version     int      `json:"version,omitempty"`
+	X           string   `json:"x,omitempty"`
+	Participant string   `json:"participant,omitempty"`
+	Hint        string   `json:"hint,omitempty"`
+	Threshold   int      `json:"threshold,omitempty"`
+	SetID       string   `json:"set_id,omitempty"`
+	Epoch       uint64   `json:"epoch,omitempty"`
+	Field       string   `json:"field,omitempty"`
+	Created     string   `json:"created,omitempty"`
+	NotAfter    string   `json:"not_after,omitempty"`
+	Label       string   `json:"label,omitempty"`
+	Records     []string `json:"records,omitempty"`
+	// SafetyNumber and SetSafetyNumber are reported for plain shares, so
+	// that custodians can compare them.
+	SafetyNumber    string `json:"safety_number,omitempty"`
+	SetSafetyNumber string `json:"set_safety_number,omitempty"`
+	// Y is only reported with -unsafe.
+	Y string `json:"y,omitempty"`
+}
+
+// inspect prints what the share in the file named by args records, without
+// its value unless -unsafe is given.
+func inspect(args []string, stdout io.Writer) (err error) {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	unsafe := fs.Bool("unsafe", false, "also print the share's value")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &inspection{jsonResult: jsonResult{Command: "inspect"}}
+	defer func() { err = writeJSON(stdout, *asJSON, r, err) }()
+	if fs.NArg() != 1 {
+		return errors.New("usage: shamirsplit inspect [-json] [-unsafe] share")
+	}
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("apiVersion:")) {
+		// Describe doesn't know about Kubernetes manifests.
+		s, err := parseShare(data)
+		if err != nil {
+			return errors.New(path + ": " + err.Error())
+		}
+		if data, err = s.MarshalBinary(); err != nil {
+			return err
+		}
+	}
+	d, err := shamirsplit.Describe(data)
+	if err != nil {
+		return errors.New(path + ": " + err.Error())
+	}
+
+	describeShare(r, d)
+	if d.X != nil && (*unsafe || !strings.Contains(d.Format, "chunked")) {
+		s, err := parseShare(data)
+		if err != nil {
+			return errors.New(path + ": " + err.Error())
+		}
+		if n, err := s.SafetyNumber(); err == nil {
+			r.SafetyNumber = n.String()
+		}
+		if n, err := s.SetSafetyNumber(); err == nil {
+			r.SetSafetyNumber = n.String()
+		}
+		if *unsafe {
+			r.Y = s.Y.String()
+		}
+	}
+
+	if *asJSON {
+		return nil
+	}
+	w := tabwriter.NewWriter(stdout, 0, 8, 1, ' ', 0)
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", name, value)
+		}
+	}
+	field("format", r.Format)
+	field("version", fmt.Sprint(r.Version))
+	field("x", r.X)
+	field("participant", quoteNonEmpty(r.Participant))
+	field("hint", quoteNonEmpty(r.Hint))
+	if r.Threshold > 0 {
+		field("threshold", fmt.Sprint(r.Threshold))
+	}
+	field("set ID", r.SetID)
+	if r.SetID != "" {
+		field("epoch", fmt.Sprint(r.Epoch))
+	}
+	field("field", r.Field)
+	field("created", r.Created)
+	field("expires", r.NotAfter)
+	field("label", quoteNonEmpty(r.Label))
+	field("records", strings.Join(r.Records, ", "))
+	field("safety number", r.SafetyNumber)
+	field("set safety number", r.SetSafetyNumber)
+	field("y", r.Y)
+	return w.Flush()
+}
+
+// quoteNonEmpty returns s quoted, so that odd characters in it are visible,
+// or "" if it's empty.
+func quoteNonEmpty(s string) string {
+	if s == "" {
+		return ""
+	}
+	return strconv.Quote(s)
+}
+
+// describeShare fills in r from d.
+func describeShare(r *inspection, d *shamirsplit.Description) {
+	r.Format, r.Version = d.Format, d.Version
+	r.Participant, r.Hint, r.Field, r.Records = d.Participant, d.Hint, d.ModulusName, d.Records
+	if d.X != nil {
+		r.X = d.X.String()
+	}
+	if r.Field == "" && d.Modulus != nil {
+		r.Field = d.Modulus.Text(16)
+	}
+	if m := d.Metadata; m != nil {
+		r.Threshold = m.Threshold
+		r.SetID = hex.EncodeToString(m.SetID[:])
+		r.Epoch = m.Epoch
+		r.Label = m.Label
+		if !m.Created.IsZero() {
+			r.Created = m.Created.UTC().Format(time.RFC3339)
+		}
+		if !m.NotAfter.IsZero() {
+			r.NotAfter = m.NotAfter.UTC().Format(time.RFC3339)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/agl/shamirsplit"
+)
+
+func TestInspect(t *testing.T) {
+	data := splitForTest(t, "secret", "armor")[1]
+	share, err := shamirsplit.Dearmor([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := writeShares(t, []string{data})
+
+	var out bytes.Buffer
+	if err := run([]string{"inspect", paths[0]}, nil, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"armored share", "x:", " 2\n", "threshold:", `"root key"`, "created:", "set safety number:"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), share.Y.String()) {
+		t.Errorf("share value was printed:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"inspect", "-json", "-unsafe", paths[0]}, nil, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	var r inspection
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	setID, _ := share.SetID()
+	if r.X != "2" || r.Threshold != 2 || r.Label != "root key" || r.Y != share.Y.String() || r.SetID != hex.EncodeToString(setID[:]) {
+		t.Errorf("unexpected result: %+v", r)
+	}
+	n, _ := share.SetSafetyNumber()
+	if r.SetSafetyNumber != n.String() || r.SafetyNumber == "" {
+		t.Errorf("got safety numbers %q and %q, want set %q", r.SafetyNumber, r.SetSafetyNumber, n)
+	}
+
+	if err := run([]string{"inspect", paths[0], paths[0]}, nil, &out, nil); err == nil {
+		t.Error("inspect of two shares succeeded")
+	}
+}
+
+func TestInspectHint(t *testing.T) {
+	share, err := shamirsplit.Dearmor([]byte(splitForTest(t, "secret", "armor")[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	share.Hint = "stored with lawyer"
+	data, err := shamirsplit.Armor(&share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := writeShares(t, []string{string(data)})
+
+	var out bytes.Buffer
+	if err := run([]string{"inspect", paths[0]}, nil, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"stored with lawyer"`) {
+		t.Errorf("output doesn't contain the hint:\n%s", out.String())
+	}
+}
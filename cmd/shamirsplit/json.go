@@ -0,0 +1,51 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	"github.com/agl/shamirsplit"
+)
+
+// jsonResult is the part of the JSON output that every command has. Each
+// command embeds it in a struct with its own results.
+type jsonResult struct {
+	Command string `json:"command"`
+	// Error is set if the command failed, in which case the other
+	// results may be incomplete.
+	Error string `json:"error,omitempty"`
+}
+
+func (r *jsonResult) result() *jsonResult { return r }
+
+// writeJSON writes r to w as a single line of JSON, with err recorded in it,
+// if asJSON is true. It returns err or, failing that, any error writing r.
+func writeJSON(w io.Writer, asJSON bool, r interface{ result() *jsonResult }, err error) error {
+	if !asJSON {
+		return err
+	}
+	if err != nil {
+		r.result().Error = err.Error()
+	}
+	if writeErr := json.NewEncoder(w).Encode(r); err == nil {
+		err = writeErr
+	}
+	return err
+}
+
+// setIDString returns the set ID of s in hex, or "" if it doesn't record
+// one.
+func setIDString(s *shamirsplit.Share) string {
+	id, ok := s.SetID()
+	if !ok {
+		return ""
+	}
+	return hex.EncodeToString(id[:])
+}
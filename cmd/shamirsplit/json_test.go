@@ -0,0 +1,94 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	const secret = "correct horse battery staple"
+	dir := t.TempDir()
+	var out bytes.Buffer
+	err := run([]string{"split", "-k", "2", "-n", "3", "-o", dir, "-json"}, strings.NewReader(secret), &out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var split splitResult
+	if err := json.Unmarshal(out.Bytes(), &split); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if split.Command != "split" || len(split.SetID) != 32 || split.Threshold != 2 || len(split.Shares) != 3 {
+		t.Fatalf("unexpected split result: %s", out.String())
+	}
+	var paths []string
+	for _, s := range split.Shares {
+		data, err := os.ReadFile(s.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha256.Sum256(data)
+		if s.SHA256 != hex.EncodeToString(digest[:]) {
+			t.Errorf("%s: digest doesn't match", s.Path)
+		}
+		paths = append(paths, s.Path)
+	}
+
+	out.Reset()
+	if err := run(append([]string{"join", "-json"}, paths[:2]...), nil, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	var join joinResult
+	if err := json.Unmarshal(out.Bytes(), &join); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if string(join.Secret) != secret || join.SetID != split.SetID {
+		t.Errorf("unexpected join result: %s", out.String())
+	}
+
+	out.Reset()
+	if err := run(append([]string{"verify", "-json"}, paths...), nil, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	var verify verifyResult
+	if err := json.Unmarshal(out.Bytes(), &verify); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if !verify.Recovers || verify.SetID != split.SetID || len(verify.Shares) != 3 || strings.Contains(out.String(), secret) {
+		t.Errorf("unexpected verify result: %s", out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"join", "-json", paths[0]}, nil, &out, nil); err == nil {
+		t.Error("join of one share succeeded")
+	}
+	if err := json.Unmarshal(out.Bytes(), &join); err != nil || join.Error == "" {
+		t.Errorf("failure wasn't reported in JSON: %s", out.String())
+	}
+}
+
+func TestCeremonyJSON(t *testing.T) {
+	shares := splitForTest(t, "hunter2", "uri")
+	var out bytes.Buffer
+	input := "not a share\n" + shares[0] + "\n" + shares[2] + "\n"
+	if err := run([]string{"ceremony", "-json"}, strings.NewReader(input), &out, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	var r ceremonyResult
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if string(r.Secret) != "hunter2" || r.Accepted != 2 || r.Rejected != 1 || r.SetID == "" {
+		t.Errorf("unexpected ceremony result: %s", out.String())
+	}
+}
@@ -0,0 +1,375 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+// Shamirsplit splits a secret into shares, any k of which can recover it,
+// and joins shares back into the secret.
+//
+// Usage:
+//
+//	shamirsplit split -k 3 -n 5 [-format armor|uri|binary|kubernetes] [-o dir] [-json] < secret
+//	shamirsplit join [-json] share...
+//	shamirsplit ceremony [-o file] [-json]
+//	shamirsplit verify [-k threshold] [-json] share...
+//	shamirsplit inspect [-json] [-unsafe] share
+//
+// Split reads the secret from standard input. Armored shares and share
+// URIs are written to standard output unless -o names a directory; binary
+// shares always need -o. The kubernetes format writes a Secret manifest per
+// share, separated by "---" lines, for distribution through GitOps
+// pipelines; with -seal, SealedSecret manifests are written instead,
+// encrypted to the certificate of a Sealed Secrets controller.
+//
+// Join reads shares in any of the formats and writes the secret to standard
+// output.
+//
+// Ceremony is for recovering the secret on an air-gapped terminal: it
+// prompts custodians to type or paste their shares, as share URIs or armored
+// shares, one at a time, without echoing them. Each share is checked as it's
+// entered, progress towards the threshold is shown and, once enough shares
+// have been entered, the secret is written to standard output, or the file
+// named by -o, and wiped from memory.
+//
+// Verify checks, without printing the secret, that the given shares are
+// from a single dealing, consistent with each other and enough to recover
+// the secret. It reports the set, epoch and threshold of the dealing and
+// exits with an error if any share is unusable.
+//
+// Inspect prints what a single share, or envelope, records about its
+// dealing: its x coordinate, threshold, set ID, field, creation time, label
+// and so on. The value of the share is only printed with -unsafe. It also
+// prints safety numbers for the share and its dealing, which custodians can
+// read to each other to check that they hold shares of the same dealing.
+//
+// With -json, every command writes a single JSON object to standard output
+// instead, for provisioning scripts and for archiving: the command, its
+// results, such as the set ID and the paths and SHA-256 digests of the
+// shares written by split, and the error, if it failed. Secrets are encoded
+// in base64.
+package main
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/agl/shamirsplit"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "shamirsplit:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command given by args. Prompts and progress go to stderr.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: shamirsplit split|join|ceremony|verify|inspect [flags]")
+	}
+	switch args[0] {
+	case "split":
+		return split(args[1:], stdin, stdout)
+	case "join":
+		return join(args[1:], stdout)
+	case "ceremony":
+		return ceremony(args[1:], stdin, stdout, stderr)
+	case "verify":
+		return verify(args[1:], stdout)
+	case "inspect":
+		return inspect(args[1:], stdout)
+	}
+	return errors.New("unknown command " + strconv.Quote(args[0]))
+}
+
+// splitResult is the JSON output of split.
+type splitResult struct {
+	jsonResult
+	SetID     string       `json:"set_id,omitempty"`
+	Threshold int          `json:"threshold,omitempty"`
+	Field     string       `json:"field,omitempty"`
+	Shares    []splitShare `json:"shares,omitempty"`
+}
+
+// splitShare describes one share written by split: the file it was written
+// to or, if none, the share itself, and the SHA-256 digest of what was
+// written.
+type splitShare struct {
+	X      string `json:"x"`
+	Path   string `json:"path,omitempty"`
+	Data   string `json:"data,omitempty"`
+	SHA256 string `json:"sha256"`
+}
+
+func split(args []string, stdin io.Reader, stdout io.Writer) (err error) {
+	fs := flag.NewFlagSet("split", flag.ContinueOnError)
+	k := fs.Int("k", 0, "number of shares needed to recover the secret")
+	n := fs.Int("n", 0, "number of shares")
+	label := fs.String("label", "", "description of the secret, recorded in each share")
+	format := fs.String("format", "armor", "output format: armor, uri, binary or kubernetes")
+	dir := fs.String("o", "", "directory to write a file per share to")
+	name := fs.String("name", "shamirsplit-share", "Secret name prefix, for the kubernetes format")
+	namespace := fs.String("namespace", "", "Secret namespace, for the kubernetes format")
+	seal := fs.String("seal", "", "Sealed Secrets controller certificate, for the kubernetes format")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	res := &splitResult{jsonResult: jsonResult{Command: "split"}}
+	defer func() { err = writeJSON(stdout, *asJSON, res, err) }()
+	if fs.NArg() != 0 {
+		return errors.New("split takes no arguments")
+	}
+
+	secret, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	if len(secret) == 0 {
+		return errors.New("empty secret")
+	}
+
+	modulusName, err := shamirsplit.RecommendModulus(len(secret), shamirsplit.MinSecurityBits)
+	if err != nil {
+		return err
+	}
+	metadata, err := shamirsplit.NewMetadata(*k, *label, nil)
+	if err != nil {
+		return err
+	}
+	set, err := shamirsplit.Deal(new(big.Int).SetBytes(secret), *n,
+		shamirsplit.WithThreshold(*k),
+		shamirsplit.WithField(shamirsplit.NamedModulus(modulusName)),
+		shamirsplit.WithMetadata(metadata))
+	if err != nil {
+		return err
+	}
+	for i := range set.Shares {
+		set.Shares[i].SecretLen = len(secret)
+	}
+	res.SetID, res.Threshold, res.Field = setIDString(&set.Shares[0]), *k, modulusName
+
+	var outputs [][]byte
+	var ext, sep string
+	switch *format {
+	case "armor":
+		ext = ".asc"
+		for i := range set.Shares {
+			out, err := shamirsplit.Armor(&set.Shares[i])
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs, out)
+		}
+	case "uri":
+		ext = ".txt"
+		for i := range set.Shares {
+			uri, err := shamirsplit.EncodeShareURI(&set.Shares[i])
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs, []byte(uri+"\n"))
+		}
+	case "binary":
+		if *dir == "" {
+			return errors.New("binary shares need -o")
+		}
+		for i := range set.Shares {
+			out, err := set.Shares[i].MarshalBinary()
+			if err != nil {
+				return err
+			}
+			outputs = append(outputs, out)
+		}
+	case "kubernetes":
+		ext, sep = ".yaml", "---\n"
+		opts := &shamirsplit.KubernetesOptions{Name: *name, Namespace: *namespace}
+		if *seal != "" {
+			if opts.SealingKey, err = readSealingKey(*seal); err != nil {
+				return err
+			}
+		}
+		if outputs, err = shamirsplit.KubernetesSecrets(set.Shares, opts); err != nil {
+			return err
+		}
+	default:
+		return errors.New("unknown format " + strconv.Quote(*format))
+	}
+
+	for i, out := range outputs {
+		digest := sha256.Sum256(out)
+		res.Shares = append(res.Shares, splitShare{X: set.Shares[i].X.String(), SHA256: hex.EncodeToString(digest[:])})
+	}
+
+	if *dir == "" {
+		if *asJSON {
+			for i, out := range outputs {
+				res.Shares[i].Data = string(out)
+			}
+			return nil
+		}
+		for i, out := range outputs {
+			if i > 0 {
+				io.WriteString(stdout, sep)
+			}
+			if _, err := stdout.Write(out); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, out := range outputs {
+		path := filepath.Join(*dir, "share-"+strconv.Itoa(i+1)+ext)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(out)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		res.Shares[i].Path = path
+	}
+	return nil
+}
+
+// readSealingKey returns the RSA public key from a PEM certificate, as
+// printed by kubeseal --fetch-cert, or a PEM public key.
+func readSealingKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New(path + ": no PEM block found")
+	}
+	var pub any
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub = cert.PublicKey
+	case "PUBLIC KEY":
+		if pub, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(path + ": unexpected PEM block " + strconv.Quote(block.Type))
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New(path + ": not an RSA key")
+	}
+	return rsaPub, nil
+}
+
+// joinResult is the JSON output of join. The secret is encoded in base64.
+type joinResult struct {
+	jsonResult
+	SetID  string `json:"set_id,omitempty"`
+	Secret []byte `json:"secret,omitempty"`
+}
+
+func join(args []string, stdout io.Writer) (err error) {
+	fs := flag.NewFlagSet("join", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	res := &joinResult{jsonResult: jsonResult{Command: "join"}}
+	defer func() {
+		err = writeJSON(stdout, *asJSON, res, err)
+		clear(res.Secret)
+	}()
+	if fs.NArg() == 0 {
+		return errors.New("usage: shamirsplit join [-json] share...")
+	}
+	shares := make([]shamirsplit.Share, fs.NArg())
+	for i, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if shares[i], err = parseShare(data); err != nil {
+			return errors.New(path + ": " + err.Error())
+		}
+	}
+
+	res.SetID = setIDString(&shares[0])
+	secret, err := shamirsplit.JoinAuto(shares)
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	if *asJSON {
+		// secret is cleared before the result is written, so it's
+		// copied.
+		res.Secret = bytes.Clone(secret)
+		return nil
+	}
+	_, err = stdout.Write(secret)
+	return err
+}
+
+// parseShare parses a share in any of the formats that split writes.
+func parseShare(data []byte) (shamirsplit.Share, error) {
+	text := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(text, []byte("-----BEGIN")):
+		return shamirsplit.Dearmor(data)
+	case bytes.HasPrefix(text, []byte("shamir://")):
+		return shamirsplit.ParseShareURI(string(text))
+	case bytes.HasPrefix(text, []byte("apiVersion:")):
+		return parseKubernetesSecret(string(text))
+	}
+	var s shamirsplit.Share
+	err := s.UnmarshalBinary(data)
+	return s, err
+}
+
+// parseKubernetesSecret returns the share in a Secret manifest written by
+// split. SealedSecrets can't be read: the controller must unseal them.
+func parseKubernetesSecret(manifest string) (s shamirsplit.Share, err error) {
+	for _, line := range strings.Split(manifest, "\n") {
+		if strings.TrimSpace(line) == "kind: SealedSecret" {
+			return s, errors.New("sealed secrets must be unsealed by the controller")
+		}
+	}
+	for _, line := range strings.Split(manifest, "\n") {
+		v, ok := strings.CutPrefix(line, "  "+shamirsplit.KubernetesSecretKey+": ")
+		if !ok {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+		if err != nil {
+			return s, err
+		}
+		err = s.UnmarshalBinary(data)
+		return s, err
+	}
+	return s, errors.New("no share in Secret")
+}
@@ -0,0 +1,78 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitJoin(t *testing.T) {
+	secret := "correct horse battery staple"
+	for _, format := range []string{"armor", "uri", "binary", "kubernetes"} {
+		dir := t.TempDir()
+		err := run([]string{"split", "-k", "2", "-n", "3", "-format", format, "-o", dir}, strings.NewReader(secret), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		paths, _ := filepath.Glob(filepath.Join(dir, "share-*"))
+		if len(paths) != 3 {
+			t.Fatalf("%s: got %d share files", format, len(paths))
+		}
+
+		var out bytes.Buffer
+		if err := run(append([]string{"join"}, paths[1:]...), nil, &out, nil); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if out.String() != secret {
+			t.Errorf("%s: got %q", format, out.String())
+		}
+	}
+}
+
+func TestSplitKubernetesStdout(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"split", "-k", "2", "-n", "3", "-format", "kubernetes", "-name", "vault-unseal", "-namespace", "vault"},
+		strings.NewReader("secret"), &out, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := strings.Split(out.String(), "---\n")
+	if len(docs) != 3 {
+		t.Fatalf("got %d documents:\n%s", len(docs), out.String())
+	}
+	if !strings.Contains(docs[2], "name: vault-unseal-3\n") {
+		t.Errorf("unexpected manifest:\n%s", docs[2])
+	}
+
+	dir := t.TempDir()
+	var paths []string
+	for i, doc := range docs[:2] {
+		path := filepath.Join(dir, "share"+string(rune('a'+i)))
+		if err := os.WriteFile(path, []byte(doc), 0600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	out.Reset()
+	if err := run(append([]string{"join"}, paths...), nil, &out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "secret" {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestSplitBinaryNeedsDir(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"split", "-k", "2", "-n", "3", "-format", "binary"}, strings.NewReader("secret"), &out, nil); err == nil {
+		t.Error("binary shares were written to standard output")
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/agl/shamirsplit"
+)
+
+// verifyResult is the JSON output of verify.
+type verifyResult struct {
+	jsonResult
+	Shares    []verifyShare `json:"shares"`
+	SetID     string        `json:"set_id,omitempty"`
+	Epoch     uint64        `json:"epoch"`
+	Threshold int           `json:"threshold,omitempty"`
+	Label     string        `json:"label,omitempty"`
+	// Needed is the number of further shares needed, if any.
+	Needed int `json:"needed"`
+	// Recovers is true if the shares recover a secret and Fingerprint is
+	// true if it was checked against the fingerprint in the shares.
+	Recovers    bool `json:"recovers"`
+	Fingerprint bool `json:"fingerprint"`
+}
+
+// verifyShare is the result of verify for one file.
+type verifyShare struct {
+	Path        string `json:"path"`
+	X           string `json:"x,omitempty"`
+	Participant string `json:"participant,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// verify checks that the shares in the files named by args are from a
+// single dealing and that they recover a secret, without printing it. It
+// reports on each file and on the dealing, and fails if any share is
+// unusable or the secret can't be recovered.
+func verify(args []string, stdout io.Writer) (err error) {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	k := fs.Int("k", 0, "number of shares needed, if the shares don't record it")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	res := &verifyResult{jsonResult: jsonResult{Command: "verify"}}
+	defer func() { err = writeJSON(stdout, *asJSON, res, err) }()
+	text := stdout
+	if *asJSON {
+		text = io.Discard
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: shamirsplit verify [-json] [-k threshold] share...")
+	}
+
+	c, err := shamirsplit.NewCombiner(*k, nil)
+	if err != nil {
+		return err
+	}
+	var shares []shamirsplit.Share
+	var paths []string
+	var entries []int // the index in res.Shares of each share
+	problems := 0
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		var s shamirsplit.Share
+		if err == nil {
+			s, err = parseShare(data)
+		}
+		if err == nil {
+			err = c.Add(s)
+		}
+		if err != nil {
+			fmt.Fprintf(text, "%s: %v\n", path, err)
+			res.Shares = append(res.Shares, verifyShare{Path: path, Error: err.Error()})
+			problems++
+			continue
+		}
+		fmt.Fprintf(text, "%s: ok, x=%s%s\n", path, s.X, participantSuffix(&s))
+		res.Shares = append(res.Shares, verifyShare{Path: path, X: s.X.String(), Participant: s.Participant})
+		shares = append(shares, s)
+		paths = append(paths, path)
+		entries = append(entries, len(res.Shares)-1)
+	}
+	if len(shares) == 0 {
+		return errors.New("no usable shares")
+	}
+
+	fmt.Fprintln(text, dealingSummary(&shares[0]))
+	res.SetID = setIDString(&shares[0])
+	if m := shares[0].Metadata; m != nil {
+		res.Epoch, res.Threshold, res.Label = m.Epoch, m.Threshold, m.Label
+	}
+	status := c.Status()
+	res.Needed = status.Needed
+	if status.Needed > 0 {
+		fmt.Fprintf(text, "not enough shares: %d more needed\n", status.Needed)
+		return errors.New("shares can't recover the secret")
+	}
+
+	// The Combiner has checked that the threshold is known.
+	threshold := *k
+	if m := shares[0].Metadata; m != nil && m.Threshold > 0 {
+		threshold = m.Threshold
+	}
+	if len(shares) > threshold && shares[0].Mandatory == nil {
+		if err := shamirsplit.CheckShares(shares, threshold); err != nil {
+			bad, identifyErr := shamirsplit.IdentifyBadShares(shares, threshold)
+			if identifyErr != nil {
+				fmt.Fprintf(text, "shares are inconsistent: %v\n", err)
+			}
+			for _, i := range bad {
+				res.Shares[entries[i]].Error = "inconsistent with the other shares"
+				fmt.Fprintf(text, "%s: inconsistent with the other shares\n", paths[i])
+			}
+			return errors.New("shares are inconsistent")
+		}
+	}
+
+	secret, err := shamirsplit.JoinShares(shares)
+	if err != nil {
+		fmt.Fprintf(text, "shares don't recover the secret: %v\n", err)
+		return errors.New("shares can't recover the secret")
+	}
+	clear(secret.Bits())
+	res.Recovers, res.Fingerprint = true, shares[0].Fingerprint != nil
+	if res.Fingerprint {
+		fmt.Fprintln(text, "shares recover the secret, which matches its fingerprint")
+	} else {
+		fmt.Fprintln(text, "shares recover a secret; they carry no fingerprint to check it against")
+	}
+	if problems > 0 {
+		return errors.New(strconv.Itoa(problems) + " unusable share files")
+	}
+	return nil
+}
+
+// participantSuffix returns the participant of s, formatted to follow other
+// details of it, or "" if it's not recorded.
+func participantSuffix(s *shamirsplit.Share) string {
+	if s.Participant == "" {
+		return ""
+	}
+	return " participant=" + strconv.Quote(s.Participant)
+}
+
+// dealingSummary describes the dealing that s is from.
+func dealingSummary(s *shamirsplit.Share) string {
+	id, ok := s.SetID()
+	summary := "set ID unknown"
+	if ok {
+		summary = fmt.Sprintf("set %x", id)
+	}
+	m := s.Metadata
+	if m == nil {
+		return summary
+	}
+	summary += ", epoch " + strconv.FormatUint(m.Epoch, 10)
+	if m.Threshold > 0 {
+		summary += ", threshold " + strconv.Itoa(m.Threshold)
+	}
+	if m.Label != "" {
+		summary += ", label " + strconv.Quote(m.Label)
+	}
+	return summary
+}
@@ -0,0 +1,90 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agl/shamirsplit"
+)
+
+// writeShares writes each of shares to its own file in a temporary
+// directory and returns their paths.
+func writeShares(t *testing.T, shares []string) []string {
+	dir := t.TempDir()
+	var paths []string
+	for i, s := range shares {
+		path := filepath.Join(dir, "share-"+string(rune('1'+i)))
+		if err := os.WriteFile(path, []byte(s), 0600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "correct horse battery staple"
+	paths := writeShares(t, splitForTest(t, secret, "armor"))
+
+	var out bytes.Buffer
+	if err := run(append([]string{"verify"}, paths...), nil, &out, nil); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	if strings.Contains(out.String(), secret) {
+		t.Fatal("verify printed the secret")
+	}
+	for _, want := range []string{"ok, x=3", "threshold 2", `label "root key"`, "shares recover a secret"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := run([]string{"verify", paths[0]}, nil, &out, nil); err == nil {
+		t.Errorf("single share verified:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "1 more needed") {
+		t.Errorf("output doesn't say how many are needed:\n%s", out.String())
+	}
+
+	other := writeShares(t, splitForTest(t, secret, "armor"))
+	out.Reset()
+	if err := run([]string{"verify", paths[0], paths[1], other[2]}, nil, &out, nil); err == nil {
+		t.Errorf("shares from different dealings verified:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "different dealing") {
+		t.Errorf("output doesn't report the mismatch:\n%s", out.String())
+	}
+
+	set, err := shamirsplit.Deal(big.NewInt(42), 4, shamirsplit.WithThreshold(2), shamirsplit.WithField(shamirsplit.NamedModulus("p256")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.Shares[3].Y.Add(set.Shares[3].Y, big.NewInt(1))
+	var armored []string
+	for i := range set.Shares {
+		a, err := shamirsplit.Armor(&set.Shares[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		armored = append(armored, string(a))
+	}
+	paths = writeShares(t, armored)
+	out.Reset()
+	if err := run(append([]string{"verify"}, paths...), nil, &out, nil); err == nil {
+		t.Errorf("inconsistent shares verified:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), paths[3]+": inconsistent") {
+		t.Errorf("output doesn't identify the bad share:\n%s", out.String())
+	}
+}
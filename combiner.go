@@ -0,0 +1,276 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// A Combiner collects shares one at a time, as in a reconstruction ceremony
+// where custodians arrive over hours, and recovers the secret once enough
+// have been added. It's safe for concurrent use.
+type Combiner struct {
+	mu          sync.Mutex
+	threshold   int
+	commitments *Commitments
+	minEpoch    uint64
+	policy      func(m *Metadata, participants []string) error
+	events      EventSink
+	shares      []Share
+
+	received, duplicates, invalid int
+}
+
+// NewCombiner returns a Combiner that needs k shares. If k is zero, it's
+// taken from the metadata of the first share added. If commitments is not
+// nil, each share is checked against it as it's added.
+func NewCombiner(k int, commitments *Commitments) (*Combiner, error) {
+	if k < 0 {
+		return nil, errors.New("invalid threshold")
+	}
+	return &Combiner{threshold: k, commitments: commitments}, nil
+}
+
+// ErrStaleEpoch is returned by Combiner.Add for a share from an epoch before
+// the Combiner's minimum, set with SetMinEpoch.
+var ErrStaleEpoch = errors.New("share is from an earlier epoch")
+
+// SetMinEpoch causes shares from epochs before epoch to be rejected with
+// ErrStaleEpoch. Recording the latest epoch that has been seen, and setting
+// it here, stops shares from before a refresh from being replayed, even as
+// a complete set. It doesn't affect shares already added.
+func (c *Combiner) SetMinEpoch(epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.minEpoch = epoch
+}
+
+// SetPolicy sets a function that Combine calls once there are enough shares
+// but before the secret is recovered, so that a deployment can require an
+// approval, log the recovery or limit its rate. It's passed the metadata of
+// the dealing, which may be nil, and the participant of each share, which is
+// empty for shares that don't name one. If it returns an error, Combine
+// returns that error without recovering the secret. It's called with the
+// Combiner locked, so mustn't call its methods.
+func (c *Combiner) SetPolicy(policy func(m *Metadata, participants []string) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.policy = policy
+}
+
+// SetEventSink causes shares that are added, or rejected, and the recovery
+// of the secret to be reported to sink. Its methods are called with the
+// Combiner locked, so mustn't call the Combiner's.
+func (c *Combiner) SetEventSink(sink EventSink) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.events = sink
+}
+
+// Add validates s and adds it to the shares collected so far. A share that
+// is rejected leaves the Combiner unchanged. A share from a different dealing
+// to the first results in a *MismatchError, whose Index is the number of
+// shares already added. Errors for shares that name their participant are
+// wrapped in a *ParticipantError.
+func (c *Combiner) Add(s Share) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.count(s, c.add(s, true))
+}
+
+// AddBatch is like calling Add for each of shares, but checks them against
+// the Combiner's commitments, if any, together with VerifyBatch, which is
+// much faster for many shares. It returns nil if every share was added, or
+// else the error for each share, which is nil for those that were added.
+func (c *Combiner) AddBatch(shares []Share) []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	corrupt := make([]bool, len(shares))
+	if c.commitments != nil {
+		invalid, err := c.commitments.VerifyBatch(shares, nil)
+		if err != nil {
+			errs := make([]error, len(shares))
+			for i := range errs {
+				errs[i] = err
+			}
+			return errs
+		}
+		for _, i := range invalid {
+			corrupt[i] = true
+		}
+	}
+
+	var errs []error
+	for i, s := range shares {
+		err := ErrCorruptShare
+		if !corrupt[i] {
+			err = c.add(s, false)
+		}
+		if err = c.count(s, err); err != nil {
+			if errs == nil {
+				errs = make([]error, len(shares))
+			}
+			errs[i] = err
+		}
+	}
+	return errs
+}
+
+// count records the result, err, of adding s and returns err, wrapped in a
+// *ParticipantError if s names its participant.
+func (c *Combiner) count(s Share, err error) error {
+	if c.events != nil {
+		if err == nil {
+			c.events.ShareReceived(shareEvent(&s))
+		} else {
+			c.events.ShareRejected(shareEvent(&s), err)
+		}
+	}
+	c.received++
+	if err == errDuplicateShare {
+		c.duplicates++
+	} else if err != nil {
+		c.invalid++
+	}
+	if err != nil && s.Participant != "" {
+		err = &ParticipantError{Participant: s.Participant, Err: err}
+	}
+	return err
+}
+
+// errDuplicateShare is returned by Combiner.Add for a share with the same x
+// coordinate as one already added.
+var errDuplicateShare = errors.New("found duplicate share")
+
+// add is Add without the locking and counting. Shares are only checked
+// against the commitments if verify is true.
+func (c *Combiner) add(s Share, verify bool) error {
+	if s.X == nil || s.Y == nil {
+		return errors.New("share is missing coordinates")
+	}
+	if s.Modulus == nil {
+		return errors.New("share doesn't record its modulus")
+	}
+	if s.Additive {
+		return errors.New("additive shares must be joined with JoinAdditive")
+	}
+	if s.X.Sign() <= 0 || s.X.Cmp(s.Modulus) >= 0 || s.Y.Sign() < 0 || s.Y.Cmp(s.Modulus) >= 0 {
+		return errors.New("share is out of range")
+	}
+	if shareEpoch(&s) < c.minEpoch {
+		return ErrStaleEpoch
+	}
+
+	threshold := c.threshold
+	if m := s.Metadata; m != nil && m.Threshold != 0 {
+		if threshold != 0 && m.Threshold != threshold {
+			return errors.New("share has a different threshold")
+		}
+		threshold = m.Threshold
+	}
+	if threshold == 0 {
+		return errors.New("threshold is unknown")
+	}
+
+	if len(c.shares) > 0 {
+		if err := checkSameDealing(len(c.shares), &c.shares[0], &s); err != nil {
+			return err
+		}
+	}
+	for _, t := range c.shares {
+		if t.X.Cmp(s.X) == 0 {
+			return errDuplicateShare
+		}
+	}
+	if verify && c.commitments != nil && !c.commitments.Verify(s) {
+		return ErrCorruptShare
+	}
+
+	c.threshold = threshold
+	c.shares = append(c.shares, s)
+	return nil
+}
+
+// Needed returns the number of further shares that are needed, or -1 if the
+// threshold isn't yet known.
+func (c *Combiner) Needed() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.threshold == 0 {
+		return -1
+	}
+	return max(c.threshold-len(c.shares), 0)
+}
+
+// CombinerStatus reports the progress of a Combiner, for guiding the
+// participants in a recovery session.
+type CombinerStatus struct {
+	// Received is the number of calls to Add, of which Accepted added a
+	// share, Duplicates repeated a share already added and Invalid were
+	// rejected for any other reason.
+	Received, Accepted, Duplicates, Invalid int
+	// Needed is as returned by Needed.
+	Needed int
+}
+
+// Status returns the progress of c.
+func (c *Combiner) Status() CombinerStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	needed := -1
+	if c.threshold != 0 {
+		needed = max(c.threshold-len(c.shares), 0)
+	}
+	return CombinerStatus{
+		Received:   c.received,
+		Accepted:   len(c.shares),
+		Duplicates: c.duplicates,
+		Invalid:    c.invalid,
+		Needed:     needed,
+	}
+}
+
+// Combine recovers the secret from the shares added so far, which must be at
+// least the threshold.
+func (c *Combiner) Combine() (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.threshold == 0 {
+		return nil, errors.New("threshold is unknown")
+	}
+	if len(c.shares) < c.threshold {
+		return nil, &NotEnoughSharesError{Need: c.threshold, Have: len(c.shares)}
+	}
+	participants := make([]string, len(c.shares))
+	for i, s := range c.shares {
+		participants[i] = s.Participant
+	}
+	if c.policy != nil {
+		if err := c.policy(c.shares[0].Metadata, participants); err != nil {
+			return nil, err
+		}
+	}
+	secret, err := JoinShares(c.shares)
+	if err != nil {
+		return nil, err
+	}
+	if c.events != nil {
+		first := shareEvent(&c.shares[0])
+		c.events.SecretReconstructed(ReconstructionEvent{SetID: first.SetID, Epoch: first.Epoch, Participants: participants})
+	}
+	return secret, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestCombiner(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, _ := SplitShares(secret, P256Order, 3, 5, nil)
+	m, _ := NewMetadata(3, "", nil)
+	for i := range shares {
+		shares[i].Metadata = m
+	}
+
+	c, err := NewCombiner(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := c.Needed(); n != -1 {
+		t.Errorf("Needed returned %d before any shares were added", n)
+	}
+	if err := c.Add(shares[0]); err != nil {
+		t.Fatalf("failed to add share: %s", err)
+	}
+	if err := c.Add(shares[0]); err == nil {
+		t.Errorf("added duplicate share")
+	}
+	if n := c.Needed(); n != 2 {
+		t.Errorf("Needed returned %d, want 2", n)
+	}
+	if _, err := c.Combine(); err == nil {
+		t.Errorf("combined too few shares")
+	}
+	others, _ := SplitShares(secret, P256Order, 3, 5, nil)
+	if err := c.Add(others[1]); !errors.As(err, new(*MismatchError)) {
+		t.Errorf("share from another dealing gave %v, want a *MismatchError", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range shares[2:] {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Add(s); err != nil {
+				t.Errorf("failed to add share: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := c.Needed(); n != 0 {
+		t.Errorf("Needed returned %d, want 0", n)
+	}
+	if result, err := c.Combine(); err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to combine: got %v, %v", result, err)
+	}
+}
+
+func TestCombinerCommitments(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	set, _ := SplitVerifiable(big.NewInt(42), q, p, big.NewInt(4), 2, 3, nil)
+
+	c, _ := NewCombiner(2, set.Commitments)
+	bad := Share{X: set.Shares[0].X, Y: new(big.Int).Add(set.Shares[0].Y, big.NewInt(1)), Modulus: q}
+	if err := c.Add(bad); err != ErrCorruptShare {
+		t.Errorf("corrupt share was added: %v", err)
+	}
+	for _, s := range set.Shares[1:] {
+		s.Modulus = q
+		if err := c.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result, err := c.Combine(); err != nil || result.Int64() != 42 {
+		t.Errorf("failed to combine: got %v, %v", result, err)
+	}
+}
+
+func TestCombinerStatus(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	set, _ := SplitVerifiable(big.NewInt(42), q, p, big.NewInt(4), 3, 4, nil)
+	for i := range set.Shares {
+		set.Shares[i].Modulus = q
+	}
+
+	c, _ := NewCombiner(3, set.Commitments)
+	if s := c.Status(); s != (CombinerStatus{Needed: 3}) {
+		t.Errorf("initial status is %+v", s)
+	}
+
+	c.Add(set.Shares[0])
+	c.Add(set.Shares[0])
+	c.Add(Share{X: set.Shares[1].X, Y: big.NewInt(1), Modulus: q})
+	c.Add(set.Shares[1])
+
+	want := CombinerStatus{Received: 4, Accepted: 2, Duplicates: 1, Invalid: 1, Needed: 1}
+	if s := c.Status(); s != want {
+		t.Errorf("got status %+v, want %+v", s, want)
+	}
+}
+
+func TestCombinerPolicy(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, _ := SplitShares(secret, P256Order, 2, 3, nil)
+	m, _ := NewMetadata(2, "root key", nil)
+	for i := range shares {
+		shares[i].Metadata = m
+	}
+	shares[0].Participant = "alice"
+
+	c, _ := NewCombiner(0, nil)
+	var approved bool
+	var gotLabel string
+	var gotParticipants []string
+	c.SetPolicy(func(m *Metadata, participants []string) error {
+		gotLabel = m.Label
+		gotParticipants = participants
+		if !approved {
+			return errors.New("not approved")
+		}
+		return nil
+	})
+	c.Add(shares[0])
+	c.Add(shares[1])
+
+	if _, err := c.Combine(); err == nil || err.Error() != "not approved" {
+		t.Errorf("Combine returned %v despite the policy", err)
+	}
+	if gotLabel != "root key" || len(gotParticipants) != 2 || gotParticipants[0] != "alice" || gotParticipants[1] != "" {
+		t.Errorf("policy was passed %q, %q", gotLabel, gotParticipants)
+	}
+
+	approved = true
+	if result, err := c.Combine(); err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to combine: got %v, %v", result, err)
+	}
+}
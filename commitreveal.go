@@ -0,0 +1,119 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// In a high-value dealing ceremony, the coefficients of the sharing
+// polynomial needn't depend on the random number generator of the dealer's
+// machine alone. Instead, each operator makes an EntropyContribution and
+// publishes its commitment. Once every commitment has been received, the
+// operators reveal their seeds and CombineEntropy checks them against the
+// commitments and seeds a DRBG with all of them, together with a private
+// seed from the dealer's machine, to be used as the dealer's source of
+// randomness with WithRand.
+//
+// The revealed seeds are public, so the dealing is only secret so long as
+// the dealer's private seed is, and that seed is never revealed. The
+// operators' seeds ensure that, so long as any one of them is random, the
+// coefficients differ from those of any other dealing and can't have been
+// chosen in advance by whoever controls the dealer's generator. The
+// commitments stop an operator from choosing their seed after seeing the
+// others.
+//
+// The last operator to reveal can still abort the ceremony after seeing the
+// others' seeds, so a ceremony that is aborted at that point should be
+// investigated rather than silently rerun.
+
+// EntropySeedLen is the length of each operator's seed.
+const EntropySeedLen = 32
+
+// entropyCommitmentContext separates entropy commitments from other uses of
+// SHA-256.
+const entropyCommitmentContext = "shamirsplit entropy commitment\x00"
+
+// An EntropyContribution is one operator's part in combining entropy for a
+// dealing.
+type EntropyContribution struct {
+	seed [EntropySeedLen]byte
+}
+
+// NewEntropyContribution returns a contribution with a fresh seed read from
+// rand. If rand is nil, crypto/rand.Reader is used.
+func NewEntropyContribution(rand io.Reader) (*EntropyContribution, error) {
+	c := new(EntropyContribution)
+	if _, err := io.ReadFull(defaultRand(rand), c.seed[:]); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Commitment returns the commitment to c's seed, which is published before
+// any seed is revealed.
+func (c *EntropyContribution) Commitment() [sha256.Size]byte {
+	return entropyCommitment(c.seed[:])
+}
+
+// Reveal returns c's seed, which is published once every operator's
+// commitment has been received.
+func (c *EntropyContribution) Reveal() []byte {
+	return append([]byte(nil), c.seed[:]...)
+}
+
+func entropyCommitment(seed []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(entropyCommitmentContext))
+	h.Write(seed)
+	return [sha256.Size]byte(h.Sum(nil))
+}
+
+// CombineEntropy checks that each of the revealed seeds matches the
+// commitment at the same index and returns a DRBGReader, over an HMACDRBG,
+// seeded with a private seed read from rand and all of the revealed seeds,
+// and bound to all of the commitments. Every operator can check the
+// commitments and seeds, but only the dealer can use the reader. If the
+// DRBG needs reseeding, fresh entropy is also read from rand. If rand is
+// nil, crypto/rand.Reader is used.
+func CombineEntropy(commitments [][sha256.Size]byte, reveals [][]byte, rand io.Reader) (*DRBGReader, error) {
+	if len(commitments) == 0 {
+		return nil, errors.New("no entropy contributions given")
+	}
+	if len(commitments) != len(reveals) {
+		return nil, errors.New("lengths of commitments and reveals must match")
+	}
+
+	// The dealer's seed comes first and never leaves this function, so
+	// that the public reveals alone don't determine the output.
+	entropy := make([]byte, EntropySeedLen, EntropySeedLen*(len(reveals)+1))
+	defer clear(entropy)
+	if _, err := io.ReadFull(defaultRand(rand), entropy); err != nil {
+		return nil, err
+	}
+
+	var nonce []byte
+	for i, seed := range reveals {
+		if len(seed) != EntropySeedLen {
+			return nil, errors.New("revealed seed " + strconv.Itoa(i) + " has the wrong length")
+		}
+		c := entropyCommitment(seed)
+		if subtle.ConstantTimeCompare(c[:], commitments[i][:]) != 1 {
+			return nil, errors.New("revealed seed " + strconv.Itoa(i) + " doesn't match its commitment")
+		}
+		entropy = append(entropy, seed...)
+		nonce = append(nonce, commitments[i][:]...)
+	}
+
+	d, err := NewHMACDRBG(entropy, nonce, []byte("shamirsplit combined entropy"))
+	if err != nil {
+		return nil, err
+	}
+	return &DRBGReader{DRBG: d, Entropy: rand}, nil
+}
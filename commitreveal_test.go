@@ -0,0 +1,104 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestCombineEntropy(t *testing.T) {
+	var commitments [][sha256.Size]byte
+	var reveals [][]byte
+	for i := 0; i < 3; i++ {
+		c, err := NewEntropyContribution(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitments = append(commitments, c.Commitment())
+		reveals = append(reveals, c.Reveal())
+	}
+
+	dealerSeed := make([]byte, EntropySeedLen)
+	read := func(commitments [][sha256.Size]byte, reveals [][]byte) []byte {
+		r, err := CombineEntropy(commitments, reveals, bytes.NewReader(dealerSeed))
+		if err != nil {
+			t.Fatalf("failed to combine entropy: %s", err)
+		}
+		out := make([]byte, 64)
+		if _, err := r.Read(out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	a := read(commitments, reveals)
+	if b := read(commitments, reveals); !bytes.Equal(a, b) {
+		t.Error("combined entropy isn't deterministic in the seeds")
+	}
+
+	dealerSeed[0] = 1
+	if b := read(commitments, reveals); bytes.Equal(a, b) {
+		t.Error("combined entropy doesn't depend on the dealer's seed")
+	}
+	dealerSeed[0] = 0
+
+	other, _ := NewEntropyContribution(nil)
+	changed := [][]byte{reveals[0], reveals[1], other.Reveal()}
+	changedCommitments := [][sha256.Size]byte{commitments[0], commitments[1], other.Commitment()}
+	if b := read(changedCommitments, changed); bytes.Equal(a, b) {
+		t.Error("combined entropy doesn't depend on every seed")
+	}
+
+	if _, err := CombineEntropy(commitments, changed, nil); err == nil {
+		t.Error("seed that doesn't match its commitment was accepted")
+	}
+	if _, err := CombineEntropy(commitments, reveals[:2], nil); err == nil {
+		t.Error("missing reveal was accepted")
+	}
+	if _, err := CombineEntropy(nil, nil, nil); err == nil {
+		t.Error("no contributions were accepted")
+	}
+}
+
+func TestCombineEntropyIsPrivate(t *testing.T) {
+	var commitments [][sha256.Size]byte
+	var reveals [][]byte
+	for i := 0; i < 3; i++ {
+		c, err := NewEntropyContribution(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		commitments = append(commitments, c.Commitment())
+		reveals = append(reveals, c.Reveal())
+	}
+
+	r, err := CombineEntropy(commitments, reveals, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const secret = 0x1234567890
+	shares, err := SplitUint64(secret, 2, 3, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Anyone who saw the reveals can replay the dealing, but without the
+	// dealer's seed they get a different polynomial, so the first share
+	// doesn't give them the secret.
+	replay, err := CombineEntropy(commitments, reveals, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slope, err := SplitUint64(0, 2, 3, replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewMersenne61()
+	if f.Sub(shares[0], slope[0]) == secret {
+		t.Error("the reveals and one share gave the secret")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// The compact encoding packs a share into a small, fixed number of bytes for
+// media with very little space, such as NTAG215 NFC tags and YubiKey static
+// slots. It's
+//
+//	version and modulus (1 byte)
+//	threshold (1 byte)
+//	x (1 byte)
+//	secret length (1 byte)
+//	set ID (16 bytes)
+//	y (the length of the modulus)
+//	checksum (4 bytes)
+//
+// where the high four bits of the first byte are the version, one, and the
+// low four bits identify one of compactModuli. The checksum is the start of
+// the SHA-256 hash of everything before it. Thresholds and secret lengths of
+// zero mean that they aren't recorded. Only the fields above are kept:
+// labels, participants, MACs, signatures and the like are dropped.
+
+// CompactMaxLen is the default byte budget of MarshalCompact. Shares over
+// the edwards25519, p256 and secp256k1 moduli fit in it.
+const CompactMaxLen = 64
+
+// ErrCompactTooLarge is returned when a share doesn't fit in the requested
+// byte budget of the compact encoding, which happens when the secret needs a
+// larger modulus.
+var ErrCompactTooLarge = errors.New("share is too large for the compact encoding")
+
+const (
+	compactVersion     = 1
+	compactHeaderLen   = 4 + 16
+	compactChecksumLen = 4
+)
+
+// compactModuli are the moduli that the compact encoding can use. The
+// position of each, plus one, is its identifier in the encoding.
+var compactModuli = []string{
+	"edwards25519",
+	"p256",
+	"secp256k1",
+	"modp1536",
+	"modp2048",
+	"modp3072",
+	"modp4096",
+	"modp6144",
+	"modp8192",
+}
+
+// CompactLen returns the length of the compact encoding of shares over
+// modulus, or zero if modulus can't be used with it.
+func CompactLen(modulus *big.Int) int {
+	if compactModulusID(modulus) == 0 {
+		return 0
+	}
+	return compactHeaderLen + (modulus.BitLen()+7)/8 + compactChecksumLen
+}
+
+func compactModulusID(modulus *big.Int) byte {
+	if modulus == nil {
+		return 0
+	}
+	name := modulusName(modulus)
+	for i, n := range compactModuli {
+		if n == name {
+			return byte(i + 1)
+		}
+	}
+	return 0
+}
+
+// MarshalCompact returns the compact encoding of s, or ErrCompactTooLarge
+// if it would be longer than maxLen bytes. If maxLen is zero, CompactMaxLen
+// is used. The share must be over one of the standard moduli (see
+// NamedModulus), with x and threshold less than 256.
+func MarshalCompact(s *Share, maxLen int) ([]byte, error) {
+	if maxLen == 0 {
+		maxLen = CompactMaxLen
+	}
+	if s.X == nil || s.Y == nil || s.X.Sign() < 0 || s.Y.Sign() < 0 {
+		return nil, errors.New("share has missing or negative coordinates")
+	}
+	if s.Additive || s.Hyperplane != nil || s.Mandatory != nil {
+		return nil, errors.New("compact encoding only supports plain Shamir shares")
+	}
+	id := compactModulusID(s.Modulus)
+	if id == 0 {
+		return nil, errors.New("compact encoding requires a standard modulus")
+	}
+	if CompactLen(s.Modulus) > maxLen {
+		return nil, ErrCompactTooLarge
+	}
+	if s.Y.Cmp(s.Modulus) >= 0 {
+		return nil, errors.New("share is out of range")
+	}
+	if !s.X.IsUint64() || s.X.Uint64() == 0 || s.X.Uint64() > 255 {
+		return nil, errors.New("compact encoding requires x between 1 and 255")
+	}
+	if s.SecretLen < 0 || s.SecretLen > 255 {
+		return nil, errors.New("secret length is out of range for the compact encoding")
+	}
+
+	threshold := 0
+	var setID [16]byte
+	if m := s.Metadata; m != nil {
+		threshold, setID = m.Threshold, m.SetID
+	}
+	if threshold < 0 || threshold > 255 {
+		return nil, errors.New("threshold is out of range for the compact encoding")
+	}
+
+	width := (s.Modulus.BitLen() + 7) / 8
+	out := make([]byte, compactHeaderLen+width+compactChecksumLen)
+	out[0] = compactVersion<<4 | id
+	out[1] = byte(threshold)
+	out[2] = byte(s.X.Uint64())
+	out[3] = byte(s.SecretLen)
+	copy(out[4:compactHeaderLen], setID[:])
+	s.Y.FillBytes(out[compactHeaderLen : compactHeaderLen+width])
+	h := sha256.Sum256(out[:compactHeaderLen+width])
+	copy(out[compactHeaderLen+width:], h[:])
+	return out, nil
+}
+
+// ParseCompact parses a share from MarshalCompact. It returns
+// ErrCorruptShare if the checksum doesn't match.
+func ParseCompact(data []byte) (s Share, err error) {
+	if len(data) < compactHeaderLen+compactChecksumLen {
+		return s, errors.New("compact share is too short")
+	}
+	if data[0]>>4 != compactVersion {
+		return s, errors.New("unknown compact share version")
+	}
+	id := int(data[0] & 0xf)
+	if id == 0 || id > len(compactModuli) {
+		return s, errors.New("unknown modulus in compact share")
+	}
+	modulus := NamedModulus(compactModuli[id-1])
+	if len(data) != CompactLen(modulus) {
+		return s, errors.New("compact share has the wrong length")
+	}
+	body := data[:len(data)-compactChecksumLen]
+	h := sha256.Sum256(body)
+	if !bytes.Equal(h[:compactChecksumLen], data[len(body):]) {
+		return s, ErrCorruptShare
+	}
+	if data[2] == 0 {
+		return s, errors.New("compact share has x of zero")
+	}
+
+	s.X = big.NewInt(int64(data[2]))
+	s.Y = new(big.Int).SetBytes(body[compactHeaderLen:])
+	if s.Y.Cmp(modulus) >= 0 {
+		return s, errors.New("share is out of range")
+	}
+	s.Modulus = modulus
+	s.SecretLen = int(data[3])
+
+	m := &Metadata{Threshold: int(data[1])}
+	copy(m.SetID[:], data[4:compactHeaderLen])
+	if m.Threshold != 0 || m.SetID != ([16]byte{}) {
+		s.Metadata = m
+	}
+	return s, nil
+}
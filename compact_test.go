@@ -0,0 +1,76 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	secret := new(big.Int).SetBytes([]byte("correct horse battery staple"))
+	set, err := Deal(secret, 5, WithThreshold(3), WithField(NamedModulus("p256")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var shares []Share
+	for i := range set.Shares {
+		set.Shares[i].SecretLen = 28
+		data, err := MarshalCompact(&set.Shares[i], 0)
+		if err != nil {
+			t.Fatalf("failed to marshal share %d: %s", i, err)
+		}
+		if len(data) != 56 || len(data) > CompactMaxLen {
+			t.Errorf("compact share is %d bytes", len(data))
+		}
+		s, err := ParseCompact(data)
+		if err != nil {
+			t.Fatalf("failed to parse share %d: %s", i, err)
+		}
+		if s.X.Cmp(set.Shares[i].X) != 0 || s.Y.Cmp(set.Shares[i].Y) != 0 || s.SecretLen != 28 ||
+			s.Metadata.SetID != set.Shares[i].Metadata.SetID || s.Metadata.Threshold != 3 {
+			t.Errorf("share %d didn't round trip", i)
+		}
+		shares = append(shares, s)
+	}
+
+	result, err := JoinShares(shares[2:])
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("got %v, %v, want %s", result, err, secret)
+	}
+
+	data, _ := MarshalCompact(&set.Shares[0], 0)
+	data[30] ^= 1
+	if _, err := ParseCompact(data); err != ErrCorruptShare {
+		t.Errorf("corrupted share gave %v, want ErrCorruptShare", err)
+	}
+	if _, err := MarshalCompact(&set.Shares[0], 48); err != ErrCompactTooLarge {
+		t.Errorf("share over budget gave %v, want ErrCompactTooLarge", err)
+	}
+
+	large, err := Deal(secret, 2, WithThreshold(2), WithField(NamedModulus("modp1536")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MarshalCompact(&large.Shares[0], 0); err != ErrCompactTooLarge {
+		t.Errorf("large share gave %v, want ErrCompactTooLarge", err)
+	}
+	if data, err := MarshalCompact(&large.Shares[0], 256); err != nil || len(data) != CompactLen(large.Modulus) {
+		t.Errorf("large share with a larger budget failed: %v", err)
+	}
+
+	s := Share{X: big.NewInt(1), Y: big.NewInt(2), Modulus: big.NewInt(101)}
+	if _, err := MarshalCompact(&s, 0); err == nil {
+		t.Errorf("marshaled a share over a non-standard modulus")
+	}
+	s = set.Shares[0]
+	s.X = big.NewInt(256)
+	if _, err := MarshalCompact(&s, 0); err == nil {
+		t.Errorf("marshaled a share with a large x")
+	}
+}
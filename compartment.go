@@ -0,0 +1,164 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// A Policy is a compartmented access structure: the secret can be recovered
+// by any group that has at least a given number of members from each
+// compartment and at least a global threshold of members in total.
+type Policy struct {
+	threshold    int
+	compartments []compartment
+}
+
+type compartment struct {
+	name               string
+	members, threshold int
+}
+
+// NewPolicy returns a policy with the given global threshold and no
+// compartments.
+func NewPolicy(threshold int) *Policy {
+	return &Policy{threshold: threshold}
+}
+
+// AddCompartment adds a compartment with the given number of members, at
+// least threshold of whom must take part in recovering the secret. It returns
+// p to allow chaining.
+func (p *Policy) AddCompartment(name string, members, threshold int) *Policy {
+	p.compartments = append(p.compartments, compartment{name, members, threshold})
+	return p
+}
+
+// Validate returns an error if p can never be satisfied or is malformed.
+func (p *Policy) Validate() error {
+	if len(p.compartments) == 0 {
+		return errors.New("policy has no compartments")
+	}
+	total := 0
+	seen := make(map[string]bool)
+	for _, c := range p.compartments {
+		if seen[c.name] {
+			return fmt.Errorf("duplicate compartment %q", c.name)
+		}
+		seen[c.name] = true
+		if c.threshold < 1 || c.members < c.threshold {
+			return fmt.Errorf("invalid threshold for compartment %q", c.name)
+		}
+		total += c.members
+	}
+	if p.threshold < 1 || p.threshold > total {
+		return errors.New("invalid global threshold")
+	}
+	return nil
+}
+
+// A CompartmentedShare is a participant's share under a Policy. Each holds a
+// share of the compartment's part of the secret and a share of the global
+// part.
+type CompartmentedShare struct {
+	Compartment string
+	Local       Share
+	Global      Share
+}
+
+// SplitCompartmented splits secret according to policy. The secret is split
+// additively into a global part and a part for each compartment, which are
+// then shared with the global threshold among all participants and with the
+// compartment threshold among its members, respectively. The shares are
+// returned in the order of the compartments. If rand is nil,
+// crypto/rand.Reader is used.
+func SplitCompartmented(secret, modulus *big.Int, policy *Policy, rand io.Reader) ([]CompartmentedShare, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	rand = defaultRand(rand)
+
+	parts, err := SplitAdditive(secret, modulus, len(policy.compartments)+1, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// Participants are numbered consecutively across compartments, and
+	// that number is the x coordinate of both of their shares.
+	var xs []*big.Int
+	for _, c := range policy.compartments {
+		for i := 0; i < c.members; i++ {
+			xs = append(xs, big.NewInt(int64(len(xs)+1)))
+		}
+	}
+
+	global, err := SplitAt(parts[0].Y, modulus, policy.threshold, xs, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	var shares []CompartmentedShare
+	for i, c := range policy.compartments {
+		cxs := xs[len(shares) : len(shares)+c.members]
+		local, err := SplitAt(parts[i+1].Y, modulus, c.threshold, cxs, rand)
+		if err != nil {
+			return nil, err
+		}
+		for j, x := range cxs {
+			shares = append(shares, CompartmentedShare{
+				Compartment: c.name,
+				Local:       Share{X: x, Y: local[j], Modulus: modulus},
+				Global:      Share{X: x, Y: global[len(shares)], Modulus: modulus},
+			})
+		}
+	}
+	return shares, nil
+}
+
+// JoinCompartmented recovers the secret from shares that satisfy policy. If
+// they don't, the error names the compartments that lack shares.
+func JoinCompartmented(policy *Policy, shares []CompartmentedShare) (*big.Int, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	byCompartment := make(map[string][]Share)
+	var global []Share
+	for _, s := range shares {
+		byCompartment[s.Compartment] = append(byCompartment[s.Compartment], s.Local)
+		global = append(global, s.Global)
+	}
+
+	var unsatisfied []string
+	for _, c := range policy.compartments {
+		if len(byCompartment[c.name]) < c.threshold {
+			unsatisfied = append(unsatisfied, fmt.Sprintf("%s (%d of %d)", c.name, len(byCompartment[c.name]), c.threshold))
+		}
+	}
+	if len(unsatisfied) > 0 {
+		return nil, errors.New("too few shares from compartments: " + strings.Join(unsatisfied, ", "))
+	}
+	if len(global) < policy.threshold {
+		return nil, fmt.Errorf("too few shares in total: %d of %d", len(global), policy.threshold)
+	}
+
+	secret, err := JoinShares(global)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range policy.compartments {
+		part, err := JoinShares(byCompartment[c.name])
+		if err != nil {
+			return nil, err
+		}
+		secret.Add(secret, part)
+	}
+	return secret.Mod(secret, global[0].Modulus), nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCompartmented(t *testing.T) {
+	secret := big.NewInt(42)
+	policy := NewPolicy(4).AddCompartment("legal", 3, 1).AddCompartment("ops", 4, 2)
+
+	shares, err := SplitCompartmented(secret, MODP2048, policy, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	legal, ops := shares[:3], shares[3:]
+
+	for _, test := range []struct {
+		name  string
+		group []CompartmentedShare
+		ok    bool
+	}{
+		{"1 legal, 3 ops", []CompartmentedShare{legal[0], ops[0], ops[1], ops[3]}, true},
+		{"2 legal, 2 ops", []CompartmentedShare{legal[2], legal[1], ops[2], ops[1]}, true},
+		{"3 legal, 1 ops", []CompartmentedShare{legal[0], legal[1], legal[2], ops[0]}, false},
+		{"4 ops", []CompartmentedShare{ops[0], ops[1], ops[2], ops[3]}, false},
+		{"1 legal, 2 ops", []CompartmentedShare{legal[0], ops[0], ops[1]}, false},
+	} {
+		result, err := JoinCompartmented(policy, test.group)
+		if ok := err == nil && result.Cmp(secret) == 0; ok != test.ok {
+			t.Errorf("%s: got %t, want %t (%v)", test.name, ok, test.ok, err)
+		}
+	}
+
+	if err := NewPolicy(2).AddCompartment("a", 1, 2).Validate(); err == nil {
+		t.Errorf("unsatisfiable policy was accepted")
+	}
+}
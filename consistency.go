@@ -0,0 +1,174 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+)
+
+// CheckShares returns nil iff shares, which must record their modulus, all
+// lie on a single polynomial of degree less than k, and so any k of them
+// recover the same secret. With a complete, freshly dealt set, this checks
+// the dealing before the original secret is destroyed.
+func CheckShares(shares []Share, k int) error {
+	xs, ys, modulus, err := shareCoordinates(shares)
+	if err != nil {
+		return err
+	}
+	if k < 1 || len(shares) < k {
+		return errors.New("fewer shares than the threshold")
+	}
+
+	// The first k shares determine the polynomial, and every other share
+	// must agree with it.
+	for i := k; i < len(shares); i++ {
+		if interpolateAt(xs[:k], ys[:k], xs[i], modulus).Cmp(ys[i]) != 0 {
+			return errors.New("shares don't lie on a single polynomial")
+		}
+	}
+	return nil
+}
+
+// shareCoordinates returns the coordinates and the common modulus of shares,
+// which must be distinct Shamir shares that record their modulus.
+func shareCoordinates(shares []Share) (xs, ys []*big.Int, modulus *big.Int, err error) {
+	if len(shares) == 0 {
+		return nil, nil, nil, errors.New("no shares given")
+	}
+	modulus = shares[0].Modulus
+	if modulus == nil {
+		return nil, nil, nil, errors.New("shares don't record their modulus")
+	}
+
+	xs = make([]*big.Int, len(shares))
+	ys = make([]*big.Int, len(shares))
+	for i, s := range shares {
+		if s.Modulus == nil || s.Modulus.Cmp(modulus) != 0 {
+			return nil, nil, nil, errors.New("shares are from different splits")
+		}
+		if s.X == nil || s.Y == nil {
+			return nil, nil, nil, errors.New("share is missing coordinates")
+		}
+		if s.Additive {
+			return nil, nil, nil, errors.New("additive shares must be joined with JoinAdditive")
+		}
+		xs[i], ys[i] = s.X, s.Y
+	}
+	if err := checkXs(xs, modulus); err != nil {
+		return nil, nil, nil, err
+	}
+	return
+}
+
+// maxIdentifySubsets limits the work done by IdentifyBadShares, which
+// grows as n choose k.
+const maxIdentifySubsets = 1 << 16
+
+// IdentifyBadShares takes at least k+1 shares, which must record their
+// modulus, and returns the indexes, in increasing order, of those that don't
+// lie on the polynomial of degree less than k that the majority agree on. It
+// fails if more than (len(shares)-k)/2 shares are bad, since the majority
+// polynomial is then ambiguous. Unlike a full error-correcting join, it's
+// meant for diagnosing which custodian's share was damaged.
+func IdentifyBadShares(shares []Share, k int) ([]int, error) {
+	xs, ys, modulus, err := shareCoordinates(shares)
+	if err != nil {
+		return nil, err
+	}
+	n := len(shares)
+	if k < 1 || n < k+1 {
+		return nil, errors.New("need more shares than the threshold")
+	}
+	// A polynomial that agrees with this many shares is the unique one
+	// within the error-correcting capacity.
+	needed := n - (n-k)/2
+
+	// Try the polynomial through each k-subset of the shares.
+	subset := make([]int, k)
+	for i := range subset {
+		subset[i] = i
+	}
+	sxs := make([]*big.Int, k)
+	sys := make([]*big.Int, k)
+	for tries := 0; ; tries++ {
+		if tries == maxIdentifySubsets {
+			return nil, errors.New("too many shares to search")
+		}
+
+		for i, j := range subset {
+			sxs[i], sys[i] = xs[j], ys[j]
+		}
+		var bad []int
+		for i := range shares {
+			if interpolateAt(sxs, sys, xs[i], modulus).Cmp(ys[i]) != 0 {
+				if bad = append(bad, i); n-len(bad) < needed {
+					break
+				}
+			}
+		}
+		if n-len(bad) >= needed {
+			return bad, nil
+		}
+
+		// Advance to the next subset in lexicographic order.
+		i := k - 1
+		for i >= 0 && subset[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return nil, errors.New("too many bad shares to identify them")
+		}
+		subset[i]++
+		for j := i + 1; j < k; j++ {
+			subset[j] = subset[j-1] + 1
+		}
+	}
+}
+
+// ErrCandidateMismatch is returned by VerifyCandidate for a secret that the
+// shares don't recover.
+var ErrCandidateMismatch = errors.New("secret isn't consistent with the shares")
+
+// VerifyCandidate returns nil iff shares, which must record their modulus
+// and number at least k, recover secret, or else ErrCandidateMismatch. If k
+// is zero, it's taken from the shares' metadata. It's for confirming that
+// shares on hand still match a secret that's in use, such as a deployed key,
+// when migrating them. The secret is checked as a point on the polynomial
+// through the shares, along with them, so the secret isn't computed and a
+// share that's inconsistent with the others also gives ErrCandidateMismatch.
+func VerifyCandidate(secret *big.Int, shares []Share, k int) error {
+	xs, ys, modulus, err := shareCoordinates(shares)
+	if err != nil {
+		return err
+	}
+	if k == 0 && shares[0].Metadata != nil {
+		k = shares[0].Metadata.Threshold
+	}
+	if k < 1 || len(shares) < k {
+		return errors.New("fewer shares than the threshold")
+	}
+	for _, s := range shares {
+		if s.Mandatory != nil || s.Hyperplane != nil {
+			return errors.New("shares aren't from a plain Shamir dealing")
+		}
+	}
+	if secret.Sign() < 0 || secret.Cmp(modulus) >= 0 {
+		return ErrCandidateMismatch
+	}
+
+	// The candidate and the first k-1 shares determine the polynomial, and
+	// every other share must agree with it.
+	pxs := append([]*big.Int{new(big.Int)}, xs[:k-1]...)
+	pys := append([]*big.Int{secret}, ys[:k-1]...)
+	for i := k - 1; i < len(shares); i++ {
+		if interpolateAt(pxs, pys, xs[i], modulus).Cmp(ys[i]) != 0 {
+			return ErrCandidateMismatch
+		}
+	}
+	return nil
+}
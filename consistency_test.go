@@ -0,0 +1,78 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCheckShares(t *testing.T) {
+	shares, _ := SplitShares(big.NewInt(42), P256Order, 3, 6, nil)
+	if err := CheckShares(shares, 3); err != nil {
+		t.Errorf("valid shares failed the check: %s", err)
+	}
+	if err := CheckShares(shares, 2); err == nil {
+		t.Errorf("degree 2 polynomial passed as degree 1")
+	}
+	if err := CheckShares(shares[:2], 3); err == nil {
+		t.Errorf("too few shares passed the check")
+	}
+
+	for i := range shares {
+		corrupt := append([]Share(nil), shares...)
+		corrupt[i].Y = new(big.Int).Add(corrupt[i].Y, big.NewInt(1))
+		if err := CheckShares(corrupt, 3); err == nil {
+			t.Errorf("corrupt share %d passed the check", i)
+		}
+	}
+}
+
+func TestIdentifyBadShares(t *testing.T) {
+	shares, _ := SplitShares(big.NewInt(42), P256Order, 3, 7, nil)
+	if bad, err := IdentifyBadShares(shares, 3); err != nil || len(bad) != 0 {
+		t.Errorf("found bad shares in a valid set: %v, %v", bad, err)
+	}
+
+	corrupt := append([]Share(nil), shares...)
+	for _, i := range []int{1, 4} {
+		corrupt[i].Y = new(big.Int).Add(corrupt[i].Y, big.NewInt(1))
+	}
+	bad, err := IdentifyBadShares(corrupt, 3)
+	if err != nil || len(bad) != 2 || bad[0] != 1 || bad[1] != 4 {
+		t.Errorf("got bad shares %v, %v, want [1 4]", bad, err)
+	}
+
+	corrupt[6].Y = new(big.Int).Add(corrupt[6].Y, big.NewInt(1))
+	if bad, err := IdentifyBadShares(corrupt, 3); err == nil {
+		t.Errorf("identified %v with too many bad shares", bad)
+	}
+	if _, err := IdentifyBadShares(shares[:3], 3); err == nil {
+		t.Errorf("accepted only k shares")
+	}
+}
+
+func TestVerifyCandidate(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, _ := SplitShares(secret, P256Order, 3, 5, nil)
+	for _, subset := range [][]Share{shares[:3], shares[2:], shares} {
+		if err := VerifyCandidate(secret, subset, 3); err != nil {
+			t.Errorf("correct secret failed: %s", err)
+		}
+	}
+	if err := VerifyCandidate(big.NewInt(43), shares[:3], 3); err != ErrCandidateMismatch {
+		t.Errorf("wrong secret gave %v", err)
+	}
+	if err := VerifyCandidate(secret, shares[:2], 3); err == nil || err == ErrCandidateMismatch {
+		t.Errorf("too few shares gave %v", err)
+	}
+
+	shares[4].Y = new(big.Int).Add(shares[4].Y, big.NewInt(1))
+	if err := VerifyCandidate(secret, shares, 3); err != ErrCandidateMismatch {
+		t.Errorf("corrupt share gave %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// A Dealer holds the random polynomial used to share a secret, so that
+// shares can be computed at any point rather than just at 1, 2, ..., n as
+// with Split. It's the building block for protocols, such as share recovery
+// and resharing, that need values of the polynomial beyond the original
+// shares. A Dealer contains the secret and must be treated as such.
+type Dealer struct {
+	Modulus      *big.Int
+	coefficients []*big.Int
+}
+
+// NewDealer returns a Dealer for a fresh degree k-1 polynomial whose value at
+// zero is secret. If rand is nil, crypto/rand.Reader is used.
+func NewDealer(secret, modulus *big.Int, k int, rand io.Reader) (*Dealer, error) {
+	if k < 1 {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, k); err != nil {
+		return nil, err
+	}
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+
+	a, err := randomPolynomial(secret, modulus, k, defaultRand(rand))
+	if err != nil {
+		return nil, err
+	}
+	return &Dealer{Modulus: modulus, coefficients: a}, nil
+}
+
+// Threshold returns the number of shares needed to recover the secret.
+func (d *Dealer) Threshold() int {
+	return len(d.coefficients)
+}
+
+// EvaluateAt returns the value of the dealer's polynomial at x. Since the
+// value at zero is the secret, x must not be zero modulo the field.
+func (d *Dealer) EvaluateAt(x *big.Int) (*big.Int, error) {
+	if new(big.Int).Mod(x, d.Modulus).Sign() == 0 {
+		return nil, errors.New("cannot evaluate at zero")
+	}
+	return evaluatePolynomial(d.coefficients, x, d.Modulus), nil
+}
+
+// Share returns the share for x coordinate x, which must be in [1, modulus).
+func (d *Dealer) Share(x *big.Int) (Share, error) {
+	if x.Sign() <= 0 || x.Cmp(d.Modulus) >= 0 {
+		return Share{}, errors.New("invalid x coordinate")
+	}
+	y, err := d.EvaluateAt(x)
+	if err != nil {
+		return Share{}, err
+	}
+	return Share{X: new(big.Int).Set(x), Y: y, Modulus: d.Modulus}, nil
+}
+
+// EvaluatePolynomial returns the value at x, modulo modulus, of the
+// polynomial whose coefficients, starting with the constant term, are given.
+func EvaluatePolynomial(coefficients []*big.Int, x, modulus *big.Int) *big.Int {
+	return evaluatePolynomial(coefficients, x, modulus)
+}
@@ -0,0 +1,50 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDealer(t *testing.T) {
+	secret := big.NewInt(42)
+	d, err := NewDealer(secret, P256Order, 3, nil)
+	if err != nil {
+		t.Fatalf("error creating dealer: %s", err)
+	}
+	if d.Threshold() != 3 {
+		t.Errorf("got threshold %d, want 3", d.Threshold())
+	}
+
+	var shares []Share
+	for _, x := range []int64{7, 1000, 123456789} {
+		s, err := d.Share(big.NewInt(x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, s)
+	}
+	if result, err := JoinShares(shares); err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to join dealer's shares: got %v, %v", result, err)
+	}
+
+	if _, err := d.EvaluateAt(P256Order); err == nil {
+		t.Errorf("evaluated at zero")
+	}
+}
+
+func TestEvaluatePolynomial(t *testing.T) {
+	// 3 + 2x + x^2 at 5 is 38, which is 3 mod 5.
+	a := []*big.Int{big.NewInt(3), big.NewInt(2), big.NewInt(1)}
+	if v := EvaluatePolynomial(a, big.NewInt(5), big.NewInt(101)); v.Int64() != 38 {
+		t.Errorf("got %s, want 38", v)
+	}
+	if v := EvaluatePolynomial(a, big.NewInt(5), big.NewInt(5)); v.Int64() != 3 {
+		t.Errorf("got %s, want 3", v)
+	}
+}
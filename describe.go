@@ -0,0 +1,183 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// A Description is what can be learnt about a serialized share, or other
+// artifact of this package, on its own. See Describe.
+type Description struct {
+	// Format is the kind of artifact, for example "share", "chunked
+	// share", "armored share", "share URI" or "passphrase envelope".
+	Format string
+	// Version is the version of the binary encoding.
+	Version int
+	// X is the x coordinate of the share, if known.
+	X *big.Int
+	// Modulus is the modulus of the field, if known, and ModulusName its
+	// name, if it's one of the standard moduli.
+	Modulus     *big.Int
+	ModulusName string
+	// Participant is who the share was dealt to, if recorded.
+	Participant string
+	// Hint is the share's hint, if any.
+	Hint string
+	// Metadata is the metadata recorded in the share, if any.
+	Metadata *Metadata
+	// Records lists the optional parts of the encoding present, such as
+	// "mac", "signature" and "fingerprint".
+	Records []string
+}
+
+// optionalRecords names the records that Describe reports.
+var optionalRecords = []struct {
+	tag  uint64
+	name string
+}{
+	{tagModulusName, "named modulus"},
+	{tagMAC, "mac"},
+	{tagFingerprint, "fingerprint"},
+	{tagDealerKey, "dealer key"},
+	{tagSignature, "signature"},
+	{tagAdditive, "additive"},
+	{tagPacking, "packing"},
+	{tagHyperplane, "hyperplane"},
+	{tagMandatory, "mandatory"},
+}
+
+// envelopeFormats are the formats, with their own magic, whose contents
+// Describe can't see into.
+var envelopeFormats = []struct {
+	magic, format string
+}{
+	{passphraseMagic, "passphrase envelope"},
+	{sealedMagic, "sealed share"},
+	{timelockMagic, "timelocked share"},
+	{storageMagic, "storage shard"},
+	{transcriptMagic, "transcript"},
+	{ceremonyMagic, "ceremony"},
+	{logEntryMagic, "log entry"},
+	{shareSetMagic, "share set"},
+	{refreshMagic, "refresh packet"},
+}
+
+// Describe parses a single share, in binary, armored or URI form, without
+// needing any others, and returns what it records. For envelopes, whose
+// contents are encrypted or sealed, only the format is returned. Describe
+// doesn't check MACs or signatures, which need keys.
+func Describe(data []byte) (*Description, error) {
+	text := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(text, []byte(uriScheme+"://")):
+		s, err := ParseShareURI(string(text))
+		if err != nil {
+			return nil, err
+		}
+		return describeEncoded(&s, "share URI")
+	case bytes.Contains(text, []byte(armorBegin)):
+		s, err := Dearmor(text)
+		if err != nil {
+			return nil, err
+		}
+		return describeEncoded(&s, "armored share")
+	case isBinaryShare(data):
+		return describeBinary(data)
+	}
+
+	for _, e := range envelopeFormats {
+		if !bytes.HasPrefix(data, []byte(e.magic)) {
+			continue
+		}
+		if err := parseWireAs(e.magic, data, func(uint64, []byte) error { return nil }); err != nil {
+			return nil, err
+		}
+		return &Description{Format: e.format, Version: int(data[len(e.magic)])}, nil
+	}
+	return nil, errors.New("unrecognized format")
+}
+
+// describeEncoded describes s, which was found in the given format.
+func describeEncoded(s *Share, format string) (*Description, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	d, err := describeBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	d.Format = format
+	return d, nil
+}
+
+// describeBinary describes a share in the binary encoding.
+func describeBinary(data []byte) (*Description, error) {
+	present := make(map[uint64]bool)
+	if err := parseWire(data, func(tag uint64, value []byte) error {
+		present[tag] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	d := &Description{Version: int(data[len(wireMagic)])}
+	if present[tagLimbs] {
+		s, err := ParseChunkedShare(data, nil)
+		if err != nil {
+			return nil, err
+		}
+		d.Format, d.X, d.Modulus = "chunked share", s.X, s.Modulus
+	} else {
+		s, err := ParseShare(data, nil)
+		if err != nil {
+			return nil, err
+		}
+		d.Format, d.X, d.Modulus, d.Metadata = "share", s.X, s.Modulus, s.Metadata
+		d.Participant, d.Hint = s.Participant, s.Hint
+	}
+	if d.Modulus != nil {
+		d.ModulusName = modulusName(d.Modulus)
+	}
+	for _, r := range optionalRecords {
+		if present[r.tag] {
+			d.Records = append(d.Records, r.name)
+		}
+	}
+	return d, nil
+}
+
+// String returns a summary of d on a single line.
+func (d *Description) String() string {
+	parts := []string{d.Format}
+	if d.X != nil {
+		parts = append(parts, "x="+d.X.String())
+	}
+	if d.Participant != "" {
+		parts = append(parts, "participant="+strconv.Quote(d.Participant))
+	}
+	if d.Hint != "" {
+		parts = append(parts, "hint="+strconv.Quote(d.Hint))
+	}
+	if d.ModulusName != "" {
+		parts = append(parts, "field="+d.ModulusName)
+	} else if d.Modulus != nil {
+		parts = append(parts, "field="+d.Modulus.Text(16))
+	}
+	if m := d.Metadata; m != nil && m.Threshold > 0 {
+		parts = append(parts, "k="+strconv.Itoa(m.Threshold))
+	}
+	if len(d.Records) > 0 {
+		parts = append(parts, "records="+strings.Join(d.Records, ","))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,85 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	modulus := NamedModulus("p256")
+	shares, err := SplitShares(big.NewInt(5), modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AuthenticateShares(shares, nil); err != nil {
+		t.Fatal(err)
+	}
+	s := &shares[2]
+
+	binary, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	armored, err := Armor(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, err := EncodeShareURI(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		data   []byte
+		format string
+	}{
+		{binary, "share"},
+		{armored, "armored share"},
+		{[]byte(uri + "\n"), "share URI"},
+	} {
+		d, err := Describe(test.data)
+		if err != nil {
+			t.Errorf("%s: %s", test.format, err)
+			continue
+		}
+		if d.Format != test.format || d.Version != 1 || d.X.Int64() != 3 || d.ModulusName != "p256" ||
+			d.Metadata.Threshold != 2 || d.Metadata.SetID != s.Metadata.SetID {
+			t.Errorf("%s: unexpected description %s", test.format, d)
+		}
+		if !slices.Equal(d.Records, []string{"named modulus", "mac"}) {
+			t.Errorf("%s: got records %q", test.format, d.Records)
+		}
+	}
+
+	chunked, err := SplitChunked([]byte("hello"), modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := chunked[0].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := Describe(data); err != nil || d.Format != "chunked share" {
+		t.Errorf("chunked share described as %v, %v", d, err)
+	}
+
+	envelope, err := LockShares(shares[:1], &testTimelock{}, time.Unix(2000000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := Describe(envelope[0]); err != nil || d.Format != "timelocked share" {
+		t.Errorf("timelocked share described as %v, %v", d, err)
+	}
+
+	if _, err := Describe([]byte("hello")); err == nil {
+		t.Error("unrecognized data was described")
+	}
+}
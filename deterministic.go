@@ -0,0 +1,71 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// SplitDeterministic is like Split, but the polynomial coefficients are
+// derived from a 32-byte seed rather than read from a random source, so that
+// the same split can be reproduced later given the same secret, seed and
+// parameters. The seed must be uniformly random and kept as secret as the
+// secret itself: anyone with the seed and k-1 shares can recover the secret.
+//
+// An AES-256-CTR key is derived with HKDF-SHA256 from the seed, the secret and
+// the split parameters, and coefficients are drawn from its keystream exactly
+// as Split draws them from rand. Because the secret is an input to the
+// derivation, reusing a seed for different secrets doesn't produce related
+// polynomials.
+func SplitDeterministic(secret, modulus *big.Int, k, n int, seed []byte) (shares []*big.Int, err error) {
+	if len(seed) != 32 {
+		return nil, errors.New("seed must be 32 bytes long")
+	}
+
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+
+	ikm := make([]byte, 0, len(seed)+(modulus.BitLen()+7)/8)
+	ikm = append(ikm, seed...)
+	ikm = append(ikm, secret.FillBytes(make([]byte, (modulus.BitLen()+7)/8))...)
+	info := "shamirsplit deterministic dealing " + modulus.Text(16) + " " + strconv.Itoa(k)
+
+	key, err := hkdf.Key(sha256.New, ikm, nil, info, 32)
+	if err != nil {
+		return
+	}
+
+	return Split(secret, modulus, k, n, newKeystreamReader(key))
+}
+
+// newKeystreamReader returns the AES-CTR keystream for key with an all-zero
+// IV.
+func newKeystreamReader(key []byte) io.Reader {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic("shamirsplit: bad keystream key length")
+	}
+	stream := cipher.NewCTR(block, make([]byte, aes.BlockSize))
+	return cipher.StreamReader{S: stream, R: zeroReader{}}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
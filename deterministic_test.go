@@ -0,0 +1,50 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSplitDeterministic(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	seed := make([]byte, 32)
+	secret := big.NewInt(42)
+
+	shares, err := SplitDeterministic(secret, modulus, 3, 5, seed)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	again, _ := SplitDeterministic(secret, modulus, 3, 5, seed)
+	other, _ := SplitDeterministic(big.NewInt(43), modulus, 3, 5, seed)
+	for i := range shares {
+		if shares[i].Cmp(again[i]) != 0 {
+			t.Errorf("share %d differs between identical splits", i)
+		}
+	}
+
+	// If the polynomials for different secrets shared coefficients, every
+	// share would differ by exactly one.
+	diff := new(big.Int).Sub(other[1], shares[1])
+	if diff.Cmp(big.NewInt(1)) == 0 {
+		t.Errorf("coefficients don't depend on the secret")
+	}
+
+	result, err := Join(shares[2:], []int{2, 3, 4}, modulus)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("Join returned %s, want %s", result, secret)
+	}
+
+	if _, err := SplitDeterministic(secret, modulus, 3, 5, seed[:16]); err == nil {
+		t.Errorf("short seed was accepted")
+	}
+}
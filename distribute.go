@@ -0,0 +1,93 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A DistributionError is returned by Distribute when any share couldn't be
+// delivered.
+type DistributionError struct {
+	// Errs holds, for each destination, the error in writing or closing
+	// it, or nil if its share was delivered.
+	Errs []error
+}
+
+func (e *DistributionError) Error() string {
+	var failed []string
+	for i, err := range e.Errs {
+		if err != nil {
+			failed = append(failed, "share "+strconv.Itoa(i)+": "+err.Error())
+		}
+	}
+	return "shamirsplit: failed to distribute " + strconv.Itoa(len(failed)) + " of " +
+		strconv.Itoa(len(e.Errs)) + " shares: " + strings.Join(failed, "; ")
+}
+
+// Unwrap returns the errors for the destinations that failed.
+func (e *DistributionError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Distribute writes the binary encoding of shares[i] to writers[i], closing
+// each writer that is an io.Closer afterwards, and returns nil only if every
+// write and close succeeded. Otherwise it returns a *DistributionError that
+// records which destinations failed. Those that succeeded still hold their
+// shares, but the set must be treated as undelivered: with too few shares
+// delivered the secret may be lost, and a retry should deal afresh rather
+// than resend the failed shares, which may have been partly written.
+//
+// Every share is encoded before anything is written, so an invalid share
+// results in an ordinary error and no writes, although the writers are still
+// closed.
+func Distribute(shares []Share, writers []io.Writer) error {
+	if len(shares) != len(writers) {
+		return errors.New("lengths of shares and writers must match")
+	}
+
+	encoded := make([][]byte, len(shares))
+	for i := range shares {
+		var err error
+		if encoded[i], err = shares[i].MarshalBinary(); err != nil {
+			for _, w := range writers {
+				if c, ok := w.(io.Closer); ok {
+					c.Close()
+				}
+			}
+			return err
+		}
+	}
+
+	errs := make([]error, len(writers))
+	failed := false
+	for i, w := range writers {
+		_, err := w.Write(encoded[i])
+		if c, ok := w.(io.Closer); ok {
+			if cerr := c.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			errs[i] = err
+			failed = true
+		}
+	}
+	if failed {
+		return &DistributionError{Errs: errs}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+)
+
+// testWriter records what's written to it and fails as configured.
+type testWriter struct {
+	bytes.Buffer
+	writeErr, closeErr error
+	closed             bool
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	if w.writeErr != nil {
+		return 0, w.writeErr
+	}
+	return w.Buffer.Write(p)
+}
+
+func (w *testWriter) Close() error {
+	w.closed = true
+	return w.closeErr
+}
+
+func TestDistribute(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitShares(big.NewInt(42), modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ws := []*testWriter{{}, {}, {}}
+	if err := Distribute(shares, []io.Writer{ws[0], ws[1], ws[2]}); err != nil {
+		t.Fatalf("distribution failed: %s", err)
+	}
+	for i, w := range ws {
+		var s Share
+		if err := s.UnmarshalBinary(w.Bytes()); err != nil || s.X.Cmp(shares[i].X) != 0 {
+			t.Errorf("destination %d didn't get share %d", i, i)
+		}
+		if !w.closed {
+			t.Errorf("destination %d wasn't closed", i)
+		}
+	}
+
+	writeErr, closeErr := errors.New("disk full"), errors.New("upload failed")
+	ws = []*testWriter{{writeErr: writeErr}, {}, {closeErr: closeErr}}
+	err = Distribute(shares, []io.Writer{ws[0], ws[1], ws[2]})
+	var de *DistributionError
+	if !errors.As(err, &de) {
+		t.Fatalf("got %v, want a *DistributionError", err)
+	}
+	if de.Errs[0] != writeErr || de.Errs[1] != nil || de.Errs[2] != closeErr {
+		t.Errorf("got errors %v", de.Errs)
+	}
+	if !errors.Is(err, closeErr) {
+		t.Errorf("error doesn't wrap the close error")
+	}
+	if ws[1].Len() == 0 || !ws[0].closed {
+		t.Errorf("a failure stopped distribution to other destinations")
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/agl/shamirsplit/internal/edwards25519"
+)
+
+// This file implements a Pedersen (joint Feldman) distributed key generation
+// over edwards25519, with the proofs of knowledge of RFC 9591, appendix C. It
+// produces shares of an Ed25519 key, suitable for FROST signing, without any
+// single party ever knowing the key.
+//
+// Each of n participants, numbered 1 to n, acts as a Feldman dealer of a
+// random secret, and the key is the sum of the secrets of the dealers who
+// behave. The protocol has three rounds:
+//
+//  1. Each participant broadcasts the DKGCommitment from NewDKGParticipant,
+//     and passes everyone else's to ReceiveCommitments.
+//  2. Each participant sends each DKGShare from Shares to its recipient over
+//     a private, authenticated channel, and passes the shares that it receives
+//     to ReceiveShares, which returns complaints about invalid ones.
+//  3. All complaints are broadcast. Each accused dealer broadcasts the
+//     share in question from AnswerComplaint, and everyone passes each
+//     complaint and answer to ResolveComplaint, which disqualifies the dealer
+//     if the answer is missing or invalid.
+//
+// Finally, Finish returns each participant's share and the public key.
+//
+// All decisions depend only on broadcast messages, so every honest participant
+// disqualifies the same dealers, as long as the broadcast channel ensures that
+// everyone receives the same messages.
+
+// A DKGCommitment is a participant's broadcast commitment to their
+// polynomial, with a proof of knowledge of its constant term.
+type DKGCommitment struct {
+	From        int
+	Commitments [][]byte
+	Proof       []byte
+}
+
+// A DKGShare is the value of a dealer's polynomial at a participant.
+type DKGShare struct {
+	From, To int
+	Value    []byte
+}
+
+// A DKGComplaint is a participant's accusation that a dealer sent them an
+// invalid share, or none at all.
+type DKGComplaint struct {
+	From, Against int
+}
+
+type dkgState int
+
+const (
+	dkgCommit dkgState = iota
+	dkgShare
+	dkgReceive
+	dkgComplain
+	dkgDone
+)
+
+// A DKGParticipant is one party's state in a distributed key generation.
+type DKGParticipant struct {
+	id, k, n     int
+	state        dkgState
+	coeffs       []*edwards25519.Scalar
+	commitments  map[int][]*edwards25519.Point
+	shares       map[int]*edwards25519.Scalar
+	disqualified map[int]bool
+}
+
+// NewDKGParticipant starts a distributed key generation for participant id,
+// in [1, n], of a key that any k participants will be able to use. It returns
+// the participant's commitment, which must be broadcast. If rand is nil,
+// crypto/rand.Reader is used.
+func NewDKGParticipant(id, k, n int, rand io.Reader) (*DKGParticipant, *DKGCommitment, error) {
+	if k < 1 || n < k || id < 1 || id > n {
+		return nil, nil, errors.New("invalid DKG parameters")
+	}
+	rand = defaultRand(rand)
+
+	p := &DKGParticipant{
+		id:           id,
+		k:            k,
+		n:            n,
+		coeffs:       make([]*edwards25519.Scalar, k),
+		commitments:  make(map[int][]*edwards25519.Point),
+		shares:       make(map[int]*edwards25519.Scalar),
+		disqualified: make(map[int]bool),
+	}
+
+	msg := &DKGCommitment{From: id}
+	points := make([]*edwards25519.Point, k)
+	for j := range p.coeffs {
+		var err error
+		if p.coeffs[j], err = randomEd25519Scalar(rand); err != nil {
+			return nil, nil, err
+		}
+		points[j] = new(edwards25519.Point).ScalarBaseMult(p.coeffs[j])
+		msg.Commitments = append(msg.Commitments, points[j].Bytes())
+	}
+	p.commitments[id] = points
+
+	// Prove knowledge of the constant term so that a participant can't
+	// choose their commitment to cancel out those of others.
+	nonce, err := randomEd25519Scalar(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	R := new(edwards25519.Point).ScalarBaseMult(nonce)
+	c := dkgChallenge(id, points[0], R)
+	mu := edwards25519.NewScalar().MultiplyAdd(p.coeffs[0], c, nonce)
+	msg.Proof = append(R.Bytes(), mu.Bytes()...)
+
+	p.shares[id] = dkgEvaluate(p.coeffs, id)
+	return p, msg, nil
+}
+
+// ReceiveCommitments processes the commitments broadcast by the other
+// participants. Participants whose commitment is missing or invalid are
+// disqualified.
+func (p *DKGParticipant) ReceiveCommitments(msgs []DKGCommitment) error {
+	if p.state != dkgCommit {
+		return errors.New("commitments received out of order")
+	}
+
+	for _, m := range msgs {
+		if m.From < 1 || m.From > p.n || m.From == p.id || p.commitments[m.From] != nil {
+			return fmt.Errorf("unexpected commitment from participant %d", m.From)
+		}
+		points, ok := dkgParseCommitment(m, p.k)
+		if !ok {
+			p.disqualified[m.From] = true
+			continue
+		}
+		p.commitments[m.From] = points
+	}
+
+	for i := 1; i <= p.n; i++ {
+		if p.commitments[i] == nil {
+			p.disqualified[i] = true
+		}
+	}
+	p.state = dkgShare
+	return nil
+}
+
+// Shares returns the share of this participant's secret for each other
+// participant. Each must be sent to its recipient privately.
+func (p *DKGParticipant) Shares() ([]DKGShare, error) {
+	if p.state != dkgShare {
+		return nil, errors.New("shares requested out of order")
+	}
+
+	var out []DKGShare
+	for i := 1; i <= p.n; i++ {
+		if i == p.id || p.disqualified[i] {
+			continue
+		}
+		out = append(out, DKGShare{From: p.id, To: i, Value: dkgEvaluate(p.coeffs, i).Bytes()})
+	}
+	p.state = dkgReceive
+	return out, nil
+}
+
+// ReceiveShares processes the shares sent to this participant and returns a
+// complaint against each dealer who sent an invalid share, or none.
+// Complaints must be broadcast.
+func (p *DKGParticipant) ReceiveShares(shares []DKGShare) ([]DKGComplaint, error) {
+	if p.state != dkgReceive {
+		return nil, errors.New("shares received out of order")
+	}
+
+	for _, s := range shares {
+		if s.To != p.id || s.From < 1 || s.From > p.n || s.From == p.id || p.shares[s.From] != nil {
+			return nil, fmt.Errorf("unexpected share from participant %d", s.From)
+		}
+		if p.disqualified[s.From] {
+			continue
+		}
+		if v, ok := p.checkShare(s); ok {
+			p.shares[s.From] = v
+		}
+	}
+
+	var complaints []DKGComplaint
+	for i := 1; i <= p.n; i++ {
+		if !p.disqualified[i] && p.shares[i] == nil {
+			complaints = append(complaints, DKGComplaint{From: p.id, Against: i})
+		}
+	}
+	p.state = dkgComplain
+	return complaints, nil
+}
+
+// AnswerComplaint returns the share in question for a complaint against this
+// participant. It must be broadcast.
+func (p *DKGParticipant) AnswerComplaint(c DKGComplaint) (*DKGShare, error) {
+	if p.state < dkgReceive || c.Against != p.id || c.From < 1 || c.From > p.n {
+		return nil, errors.New("invalid complaint")
+	}
+	return &DKGShare{From: p.id, To: c.From, Value: dkgEvaluate(p.coeffs, c.From).Bytes()}, nil
+}
+
+// ResolveComplaint processes a broadcast complaint and the accused dealer's
+// answer, which is nil if they didn't give one. The dealer is disqualified
+// unless the answer is valid. Every participant must resolve every complaint,
+// including their own and those against them.
+func (p *DKGParticipant) ResolveComplaint(c DKGComplaint, answer *DKGShare) error {
+	if p.state != dkgComplain {
+		return errors.New("complaint resolved out of order")
+	}
+	if p.disqualified[c.Against] {
+		return nil
+	}
+
+	if answer != nil && answer.From == c.Against && answer.To == c.From {
+		if v, ok := p.checkShare(*answer); ok {
+			if c.From == p.id {
+				p.shares[c.Against] = v
+			}
+			return nil
+		}
+	}
+	p.disqualified[c.Against] = true
+	return nil
+}
+
+// Finish ends the key generation and returns this participant's share of the
+// key and the public key. The share can be used with FROSTCommit and
+// FROSTSign.
+func (p *DKGParticipant) Finish() (Share, ed25519.PublicKey, error) {
+	if p.state != dkgComplain {
+		return Share{}, nil, errors.New("key generation isn't complete")
+	}
+
+	if p.disqualified[p.id] {
+		return Share{}, nil, errors.New("this participant was disqualified")
+	}
+
+	s := edwards25519.NewScalar()
+	A := edwards25519.NewIdentityPoint()
+	for i := 1; i <= p.n; i++ {
+		if p.disqualified[i] {
+			continue
+		}
+		if p.shares[i] == nil {
+			return Share{}, nil, fmt.Errorf("complaint against participant %d is unresolved", i)
+		}
+		s.Add(s, p.shares[i])
+		A.Add(A, p.commitments[i][0])
+	}
+
+	p.state = dkgDone
+	for _, c := range p.coeffs {
+		c.Set(edwards25519.NewScalar())
+	}
+	share := Share{X: big.NewInt(int64(p.id)), Y: ed25519ScalarToInt(s), Modulus: Edwards25519Order}
+	return share, A.Bytes(), nil
+}
+
+// Disqualified returns the participants who were disqualified, in order.
+func (p *DKGParticipant) Disqualified() []int {
+	var out []int
+	for i := 1; i <= p.n; i++ {
+		if p.disqualified[i] {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// checkShare returns the value of s if it's consistent with its dealer's
+// commitments.
+func (p *DKGParticipant) checkShare(s DKGShare) (*edwards25519.Scalar, bool) {
+	points := p.commitments[s.From]
+	if points == nil {
+		return nil, false
+	}
+	v, err := edwards25519.NewScalar().SetCanonicalBytes(s.Value)
+	if err != nil {
+		return nil, false
+	}
+
+	// Evaluate the committed polynomial at s.To in the exponent.
+	x := dkgScalar(s.To)
+	expected := new(edwards25519.Point).Set(points[len(points)-1])
+	for j := len(points) - 2; j >= 0; j-- {
+		expected.ScalarMult(x, expected)
+		expected.Add(expected, points[j])
+	}
+
+	if new(edwards25519.Point).ScalarBaseMult(v).Equal(expected) != 1 {
+		return nil, false
+	}
+	return v, true
+}
+
+// dkgParseCommitment parses and checks a commitment.
+func dkgParseCommitment(m DKGCommitment, k int) ([]*edwards25519.Point, bool) {
+	if len(m.Commitments) != k || len(m.Proof) != 64 {
+		return nil, false
+	}
+
+	points := make([]*edwards25519.Point, k)
+	for j, b := range m.Commitments {
+		var err error
+		if points[j], err = new(edwards25519.Point).SetBytes(b); err != nil {
+			return nil, false
+		}
+	}
+
+	R, err := new(edwards25519.Point).SetBytes(m.Proof[:32])
+	if err != nil {
+		return nil, false
+	}
+	mu, err := edwards25519.NewScalar().SetCanonicalBytes(m.Proof[32:])
+	if err != nil {
+		return nil, false
+	}
+
+	// Check that R = mu*B - c*C_0.
+	c := dkgChallenge(m.From, points[0], R)
+	check := new(edwards25519.Point).ScalarMult(c, points[0])
+	check.Subtract(new(edwards25519.Point).ScalarBaseMult(mu), check)
+	return points, check.Equal(R) == 1
+}
+
+// dkgChallenge returns the challenge for participant id's proof of knowledge.
+func dkgChallenge(id int, C0, R *edwards25519.Point) *edwards25519.Scalar {
+	h := sha512.New()
+	h.Write([]byte(frostContext + "dkg"))
+	h.Write(dkgScalar(id).Bytes())
+	h.Write(C0.Bytes())
+	h.Write(R.Bytes())
+	c, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// dkgEvaluate returns the value of the polynomial with coefficients a at x.
+func dkgEvaluate(a []*edwards25519.Scalar, x int) *edwards25519.Scalar {
+	xs := dkgScalar(x)
+	v := edwards25519.NewScalar().Set(a[len(a)-1])
+	for j := len(a) - 2; j >= 0; j-- {
+		v.MultiplyAdd(v, xs, a[j])
+	}
+	return v
+}
+
+func dkgScalar(x int) *edwards25519.Scalar {
+	s, err := ed25519IntToScalar(big.NewInt(int64(x)))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// randomEd25519Scalar returns a uniform random scalar.
+func randomEd25519Scalar(rand io.Reader) (*edwards25519.Scalar, error) {
+	var b [64]byte
+	if _, err := io.ReadFull(rand, b[:]); err != nil {
+		return nil, err
+	}
+	return edwards25519.NewScalar().SetUniformBytes(b[:])
+}
@@ -0,0 +1,143 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"slices"
+	"testing"
+)
+
+// runDKG runs a key generation between n participants. Participant cheater,
+// if not zero, sends a corrupt share to participant 1 and answers the
+// complaint about it with answer.
+func runDKG(t *testing.T, k, n, cheater int, answer func(*DKGShare)) ([]Share, ed25519.PublicKey, []*DKGParticipant) {
+	parties := make([]*DKGParticipant, n)
+	commitments := make([]DKGCommitment, n)
+	for i := range parties {
+		p, c, err := NewDKGParticipant(i+1, k, n, nil)
+		if err != nil {
+			t.Fatalf("failed to start DKG: %s", err)
+		}
+		parties[i], commitments[i] = p, *c
+	}
+
+	for i, p := range parties {
+		others := slices.Delete(slices.Clone(commitments), i, i+1)
+		if err := p.ReceiveCommitments(others); err != nil {
+			t.Fatalf("participant %d: %s", i+1, err)
+		}
+	}
+
+	inbox := make([][]DKGShare, n)
+	for _, p := range parties {
+		shares, err := p.Shares()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range shares {
+			if s.From == cheater && s.To == 1 {
+				s.Value = slices.Clone(s.Value)
+				s.Value[0] ^= 1
+			}
+			inbox[s.To-1] = append(inbox[s.To-1], s)
+		}
+	}
+
+	var complaints []DKGComplaint
+	for i, p := range parties {
+		c, err := p.ReceiveShares(inbox[i])
+		if err != nil {
+			t.Fatalf("participant %d: %s", i+1, err)
+		}
+		complaints = append(complaints, c...)
+	}
+
+	for _, c := range complaints {
+		a, err := parties[c.Against-1].AnswerComplaint(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Against == cheater && answer != nil {
+			answer(a)
+		}
+		for _, p := range parties {
+			if err := p.ResolveComplaint(c, a); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var shares []Share
+	var pub ed25519.PublicKey
+	for i, p := range parties {
+		s, pk, err := p.Finish()
+		if err != nil {
+			if i+1 == cheater {
+				continue
+			}
+			t.Fatalf("participant %d: %s", i+1, err)
+		}
+		if pub != nil && !slices.Equal(pub, pk) {
+			t.Fatalf("participants disagree about the public key")
+		}
+		pub = pk
+		shares = append(shares, s)
+	}
+	return shares, pub, parties
+}
+
+// checkDKGKey checks that the shares can be used to sign for pub.
+func checkDKGKey(t *testing.T, shares []Share, pub ed25519.PublicKey) {
+	message := []byte("hello")
+	var nonces []*FROSTNonces
+	var commitments []FROSTCommitment
+	for _, s := range shares {
+		n, c, err := FROSTCommit(s, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonces, commitments = append(nonces, n), append(commitments, c)
+	}
+	var sigShares []FROSTSignatureShare
+	for i, s := range shares {
+		z, err := FROSTSign(s, nonces[i], commitments, message, pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigShares = append(sigShares, *z)
+	}
+	if _, err := FROSTAggregate(commitments, sigShares, message, pub); err != nil {
+		t.Errorf("failed to sign with the generated key: %s", err)
+	}
+}
+
+func TestDKG(t *testing.T) {
+	shares, pub, _ := runDKG(t, 3, 5, 0, nil)
+	checkDKGKey(t, shares[1:4], pub)
+}
+
+func TestDKGComplaint(t *testing.T) {
+	// A dealer who answers a complaint correctly stays in.
+	shares, pub, parties := runDKG(t, 2, 4, 3, nil)
+	if d := parties[0].Disqualified(); len(d) != 0 {
+		t.Errorf("honest dealers were disqualified: %v", d)
+	}
+	checkDKGKey(t, shares[:2], pub)
+
+	// A dealer who answers with a bad share is disqualified by everyone.
+	shares, pub, parties = runDKG(t, 2, 4, 3, func(a *DKGShare) { a.Value[0] ^= 1 })
+	for i, p := range parties {
+		if d := p.Disqualified(); !slices.Equal(d, []int{3}) {
+			t.Errorf("participant %d disqualified %v", i+1, d)
+		}
+	}
+	if len(shares) != 3 {
+		t.Fatalf("got %d shares, want 3", len(shares))
+	}
+	checkDKGKey(t, shares[1:], pub)
+}
@@ -0,0 +1,16 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package shamirsplit implements Shamir's cryptographic secret sharing
+// algorithm.
+//
+// Most of the package works over prime fields with math/big. For embedded
+// targets, such as TinyGo firmware, that can't afford math/big, building
+// with the nobig tag leaves only the code that doesn't need it: the Field
+// interface, GF(2^8) sharing in the format of SplitVaultCompatible, GF(2^16)
+// sharing and the uint64 field Mersenne61. The GF(2^8) assembly is left out
+// under TinyGo, which doesn't support it, and with the purego tag. The
+// nommap tag makes SplitFile and JoinFiles read and write files as they do
+// on systems without mmap, so that that path can be tested anywhere.
+package shamirsplit
@@ -0,0 +1,184 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"sync"
+)
+
+// A DRBG is a deterministic random bit generator, such as those of NIST SP
+// 800-90A, that can be used as the source of randomness for splitting by way
+// of a DRBGReader. This allows deployments that must use an approved
+// generator to supply their own.
+type DRBG interface {
+	// Generate fills out with pseudorandom bytes, mixing in
+	// additionalInput, which may be nil. It returns ErrReseedRequired if
+	// the DRBG must be reseeded first.
+	Generate(out, additionalInput []byte) error
+	// Reseed mixes fresh entropy, and additionalInput, into the state.
+	Reseed(entropy, additionalInput []byte) error
+}
+
+// ErrReseedRequired is returned by a DRBG that has produced as much output
+// as it may without fresh entropy.
+var ErrReseedRequired = errors.New("DRBG must be reseeded")
+
+// drbgSeedLen is the number of bytes of entropy used to reseed.
+const drbgSeedLen = 32
+
+// A DRBGReader is an io.Reader that reads from a DRBG, reseeding it from
+// Entropy when it requires. It's safe for concurrent use.
+type DRBGReader struct {
+	DRBG DRBG
+	// Entropy is the source of reseeding material, such as a hardware
+	// generator. If nil, crypto/rand.Reader is used.
+	Entropy io.Reader
+	// OnReseed, if not nil, is called after each attempt to reseed, with
+	// its result, so that reseeding can be logged or audited.
+	OnReseed func(err error)
+
+	mu sync.Mutex
+}
+
+func (r *DRBGReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.DRBG.Generate(p, nil)
+	if err == ErrReseedRequired {
+		if err = r.reseed(); err == nil {
+			err = r.DRBG.Generate(p, nil)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Reseed reseeds the DRBG from Entropy now, rather than waiting for it to
+// require it.
+func (r *DRBGReader) Reseed() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reseed()
+}
+
+func (r *DRBGReader) reseed() error {
+	var entropy [drbgSeedLen]byte
+	_, err := io.ReadFull(defaultRand(r.Entropy), entropy[:])
+	if err == nil {
+		err = r.DRBG.Reseed(entropy[:], nil)
+	}
+	if r.OnReseed != nil {
+		r.OnReseed(err)
+	}
+	return err
+}
+
+// HMACDRBG is the HMAC_DRBG of NIST SP 800-90A, section 10.1.2, with
+// SHA-256 and without prediction resistance.
+type HMACDRBG struct {
+	// ReseedInterval is the number of calls to Generate allowed between
+	// reseeds. If zero, 2^16 is used.
+	ReseedInterval uint64
+
+	k, v    [sha256.Size]byte
+	counter uint64
+}
+
+// hmacDRBGMaxRequest is the maximum number of bytes that can be generated by
+// one call to Generate: 2^19 bits.
+const hmacDRBGMaxRequest = 1 << 16
+
+// NewHMACDRBG instantiates an HMACDRBG from at least 32 bytes of entropy, a
+// nonce and an optional personalization string.
+func NewHMACDRBG(entropy, nonce, personalization []byte) (*HMACDRBG, error) {
+	if len(entropy) < drbgSeedLen {
+		return nil, errors.New("too little entropy to instantiate DRBG")
+	}
+
+	d := new(HMACDRBG)
+	for i := range d.v {
+		d.v[i] = 1
+	}
+	d.update(entropy, nonce, personalization)
+	d.counter = 1
+	return d, nil
+}
+
+// update is the HMAC_DRBG_Update function, with the provided data given as
+// the concatenation of the arguments.
+func (d *HMACDRBG) update(provided ...[]byte) {
+	empty := true
+	for _, p := range provided {
+		empty = empty && len(p) == 0
+	}
+
+	for _, b := range []byte{0, 1} {
+		h := hmac.New(sha256.New, d.k[:])
+		h.Write(d.v[:])
+		h.Write([]byte{b})
+		for _, p := range provided {
+			h.Write(p)
+		}
+		h.Sum(d.k[:0])
+
+		h = hmac.New(sha256.New, d.k[:])
+		h.Write(d.v[:])
+		h.Sum(d.v[:0])
+
+		if empty {
+			return
+		}
+	}
+}
+
+// Generate implements DRBG.
+func (d *HMACDRBG) Generate(out, additionalInput []byte) error {
+	interval := d.ReseedInterval
+	if interval == 0 {
+		interval = 1 << 16
+	}
+	if d.counter > interval {
+		return ErrReseedRequired
+	}
+
+	if len(additionalInput) > 0 {
+		d.update(additionalInput)
+	}
+	for len(out) > 0 {
+		// Long requests are split to respect the maximum request size.
+		n := min(len(out), hmacDRBGMaxRequest)
+		h := hmac.New(sha256.New, d.k[:])
+		for i := 0; i < n; i += sha256.Size {
+			h.Reset()
+			h.Write(d.v[:])
+			h.Sum(d.v[:0])
+			copy(out[i:n], d.v[:])
+		}
+		out = out[n:]
+		d.update(additionalInput)
+		d.counter++
+		if len(out) > 0 && d.counter > interval {
+			return ErrReseedRequired
+		}
+	}
+	return nil
+}
+
+// Reseed implements DRBG.
+func (d *HMACDRBG) Reseed(entropy, additionalInput []byte) error {
+	if len(entropy) < drbgSeedLen {
+		return errors.New("too little entropy to reseed DRBG")
+	}
+	d.update(entropy, additionalInput)
+	d.counter = 1
+	return nil
+}
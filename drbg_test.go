@@ -0,0 +1,74 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func fromHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// TestHMACDRBG checks the first HMAC_DRBG SHA-256 test vector from NIST's
+// CAVP, without prediction resistance, reseeding, personalization or
+// additional input.
+func TestHMACDRBG(t *testing.T) {
+	entropy := fromHex("ca851911349384bffe89de1cbdc46e6831e44d34a4fb935ee285dd14b71a7488")
+	nonce := fromHex("659ba96c601dc69fc902940805ec0ca8")
+	want := fromHex("e528e9abf2dece54d47c7e75e5fe302149f817ea9fb4bee6f4199697d04d5b89d54fbb978a15b5c443c9ec21036d2460b6f73ebad0dc2aba6e624abf07745bc107694bb7547bb0995f70de25d6b29e2d3011bb19d27676c07162c8b5ccde0668961df86803482cb37ed6d5c0bb8d50cf1f50d476aa0458bdaba806f48be9dcb8")
+
+	d, err := NewHMACDRBG(entropy, nonce, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, len(want))
+	for i := 0; i < 2; i++ {
+		if err := d.Generate(out, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(out, want) {
+		t.Errorf("got %x, want %x", out, want)
+	}
+}
+
+func TestDRBGReader(t *testing.T) {
+	d, err := NewHMACDRBG(make([]byte, 32), nil, []byte("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.ReseedInterval = 2
+
+	reseeds := 0
+	r := &DRBGReader{
+		DRBG:     d,
+		Entropy:  bytes.NewReader(make([]byte, 2*drbgSeedLen)),
+		OnReseed: func(err error) { reseeds++ },
+	}
+	buf := make([]byte, 100)
+	for i := 0; i < 5; i++ {
+		if _, err := r.Read(buf); err != nil {
+			t.Fatalf("read %d failed: %s", i, err)
+		}
+	}
+	if reseeds != 2 {
+		t.Errorf("got %d reseeds, want 2", reseeds)
+	}
+
+	// The entropy source is now exhausted, so the next reseed fails.
+	if _, err := r.Read(buf); err != nil {
+		t.Fatalf("read failed: %s", err)
+	}
+	if _, err := r.Read(buf); err == nil {
+		t.Errorf("read succeeded without entropy for reseeding")
+	}
+}
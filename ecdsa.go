@@ -0,0 +1,52 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+)
+
+// SplitECDSAKey splits the private scalar of key over the order of its curve
+// into n shares, any k of which can be combined by JoinECDSAKey. Only the
+// curves supported by crypto/ecdsa (P-224, P-256, P-384 and P-521) can be
+// used: secp256k1 keys can be split with SplitShares and Secp256k1Order.
+func SplitECDSAKey(key *ecdsa.PrivateKey, k, n int) ([]Share, error) {
+	d, err := key.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	defer clear(d)
+
+	return SplitShares(new(big.Int).SetBytes(d), key.Curve.Params().N, k, n, nil)
+}
+
+// JoinECDSAKey recovers a private key from at least k shares that resulted
+// from SplitECDSAKey. The public key of the result is checked against pub, so
+// too few or corrupt shares result in an error.
+func JoinECDSAKey(shares []Share, pub *ecdsa.PublicKey) (*ecdsa.PrivateKey, error) {
+	n := pub.Curve.Params().N
+	for _, s := range shares {
+		if s.Modulus == nil || s.Modulus.Cmp(n) != 0 {
+			return nil, errors.New("shares are not over the order of the curve")
+		}
+	}
+
+	d, err := JoinShares(shares)
+	if err != nil {
+		return nil, err
+	}
+
+	b := d.FillBytes(make([]byte, (n.BitLen()+7)/8))
+	defer clear(b)
+	key, err := ecdsa.ParseRawPrivateKey(pub.Curve, b)
+	if err != nil || !key.PublicKey.Equal(pub) {
+		return nil, errors.New("recovered key doesn't match the public key: too few or corrupt shares")
+	}
+	return key, nil
+}
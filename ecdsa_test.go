@@ -0,0 +1,46 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestECDSAKey(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P521()} {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		shares, err := SplitECDSAKey(key, 3, 5)
+		if err != nil {
+			t.Fatalf("%s: failed to split key: %s", curve.Params().Name, err)
+		}
+
+		result, err := JoinECDSAKey(shares[2:], &key.PublicKey)
+		if err != nil {
+			t.Fatalf("%s: failed to join key: %s", curve.Params().Name, err)
+		}
+		if !result.Equal(key) {
+			t.Errorf("%s: recovered key doesn't match", curve.Params().Name)
+		}
+
+		if _, err := JoinECDSAKey(shares[:2], &key.PublicKey); err == nil {
+			t.Errorf("%s: recovered a key from too few shares", curve.Params().Name)
+		}
+
+		shares[0].Y = new(big.Int).Add(shares[0].Y, big.NewInt(1))
+		if _, err := JoinECDSAKey(shares[:3], &key.PublicKey); err == nil {
+			t.Errorf("%s: recovered a key from a corrupt share", curve.Params().Name)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"math/big"
+	"slices"
+
+	"github.com/agl/shamirsplit/internal/edwards25519"
+)
+
+// SplitEd25519 splits the private scalar of key over Edwards25519Order into n
+// shares, any k of which can be combined by JoinEd25519. The scalar is derived
+// from the seed of key, and clamped, exactly as when signing, so the shares are
+// Shamir shares of the discrete logarithm of the public key and can also be
+// used for threshold signing.
+func SplitEd25519(key ed25519.PrivateKey, k, n int) ([]Share, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid Ed25519 private key")
+	}
+
+	h := sha512.Sum512(key.Seed())
+	defer clear(h[:])
+	s, err := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	if err != nil {
+		return nil, err
+	}
+
+	return SplitShares(ed25519ScalarToInt(s), Edwards25519Order, k, n, nil)
+}
+
+// An Ed25519Key is an Ed25519 private key that was recovered from its scalar
+// rather than its seed, which can't be recovered. It produces standard Ed25519
+// signatures for the same public key, but the deterministic nonces differ from
+// those of the original key.
+type Ed25519Key struct {
+	s      *edwards25519.Scalar
+	prefix [32]byte
+	pub    ed25519.PublicKey
+}
+
+// JoinEd25519 recovers an Ed25519 private key from at least k shares that
+// resulted from SplitEd25519. The result is checked against pub, so too few or
+// corrupt shares result in an error.
+func JoinEd25519(shares []Share, pub ed25519.PublicKey) (*Ed25519Key, error) {
+	for _, s := range shares {
+		if s.Modulus == nil || s.Modulus.Cmp(Edwards25519Order) != 0 {
+			return nil, errors.New("shares are not over the Ed25519 group order")
+		}
+	}
+
+	d, err := JoinShares(shares)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ed25519IntToScalar(d)
+	if err != nil {
+		return nil, err
+	}
+
+	A := new(edwards25519.Point).ScalarBaseMult(s)
+	if len(pub) != ed25519.PublicKeySize || !slices.Equal(A.Bytes(), pub) {
+		return nil, errors.New("recovered key doesn't match the public key: too few or corrupt shares")
+	}
+
+	key := &Ed25519Key{s: s, pub: slices.Clone(pub)}
+	// The nonce prefix is derived from the scalar since the original one
+	// was derived from the seed.
+	h := sha512.New()
+	h.Write([]byte("shamirsplit Ed25519 prefix"))
+	h.Write(s.Bytes())
+	copy(key.prefix[:], h.Sum(nil))
+	return key, nil
+}
+
+// Public returns the public key corresponding to k.
+func (k *Ed25519Key) Public() crypto.PublicKey {
+	return k.pub
+}
+
+// Sign signs message, which must not be hashed, with k. It implements
+// crypto.Signer and, like ed25519.PrivateKey, opts.HashFunc() must be zero.
+// The signature is deterministic and rand is ignored.
+func (k *Ed25519Key) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errors.New("Ed25519 cannot sign hashed messages")
+	}
+
+	h := sha512.New()
+	h.Write(k.prefix[:])
+	h.Write(message)
+	r, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	R := new(edwards25519.Point).ScalarBaseMult(r)
+
+	S := edwards25519.NewScalar().MultiplyAdd(ed25519Challenge(R.Bytes(), k.pub, message), k.s, r)
+	return append(R.Bytes(), S.Bytes()...), nil
+}
+
+// ed25519Challenge returns the Ed25519 challenge scalar H(R || A || M).
+func ed25519Challenge(R, A, message []byte) *edwards25519.Scalar {
+	h := sha512.New()
+	h.Write(R)
+	h.Write(A)
+	h.Write(message)
+	c, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ed25519ScalarToInt converts a scalar, which is little-endian, to an integer.
+func ed25519ScalarToInt(s *edwards25519.Scalar) *big.Int {
+	b := s.Bytes()
+	slices.Reverse(b)
+	return new(big.Int).SetBytes(b)
+}
+
+// ed25519IntToScalar converts an integer in [0, Edwards25519Order) to a scalar.
+func ed25519IntToScalar(x *big.Int) (*edwards25519.Scalar, error) {
+	if x.Sign() < 0 || x.Cmp(Edwards25519Order) >= 0 {
+		return nil, errors.New("value is not a valid Ed25519 scalar")
+	}
+	b := x.FillBytes(make([]byte, 32))
+	slices.Reverse(b)
+	return edwards25519.NewScalar().SetCanonicalBytes(b)
+}
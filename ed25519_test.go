@@ -0,0 +1,46 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"math/big"
+	"testing"
+)
+
+func TestEd25519(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+
+	shares, err := SplitEd25519(priv, 3, 5)
+	if err != nil {
+		t.Fatalf("failed to split key: %s", err)
+	}
+
+	key, err := JoinEd25519(shares[2:], pub)
+	if err != nil {
+		t.Fatalf("failed to join key: %s", err)
+	}
+
+	message := []byte("hello")
+	sig, err := key.Sign(nil, message, crypto.Hash(0))
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+	if !ed25519.Verify(pub, message, sig) {
+		t.Errorf("signature from recovered key didn't verify")
+	}
+
+	if _, err := JoinEd25519(shares[:2], pub); err == nil {
+		t.Errorf("recovered a key from too few shares")
+	}
+
+	shares[0].Y = new(big.Int).Add(shares[0].Y, big.NewInt(1))
+	if _, err := JoinEd25519(shares[:3], pub); err == nil {
+		t.Errorf("recovered a key from a corrupt share")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// An ElGamalPublicKey is an ElGamal key, H = G^x mod P, whose private key, x,
+// has been split over Q, the order of G. It is usually obtained from a
+// ShareSet dealt by SplitVerifiable, so that the shareholders can decrypt
+// without x ever being reconstructed.
+type ElGamalPublicKey struct {
+	P, G, Q, H *big.Int
+}
+
+// An ElGamalCiphertext is an encryption of M: (G^r, M*H^r).
+type ElGamalCiphertext struct {
+	C1, C2 *big.Int
+}
+
+// A DecryptionShare is a shareholder's partial decryption: C1^Y mod P, for
+// the share (X, Y).
+type DecryptionShare struct {
+	X, D *big.Int
+}
+
+// ElGamalPublicKey returns the ElGamal public key whose private key is the
+// secret that was split to make s, which must have been dealt by
+// SplitVerifiable.
+func (s *ShareSet) ElGamalPublicKey() (*ElGamalPublicKey, error) {
+	c := s.Commitments
+	if c == nil || len(c.Values) == 0 {
+		return nil, errors.New("share set has no commitments")
+	}
+	return &ElGamalPublicKey{P: c.P, G: c.G, Q: s.Modulus, H: c.Values[0]}, nil
+}
+
+// Encrypt encrypts m, which must be in [1, P). For the encryption to hide m,
+// it should be in the subgroup generated by G. If rand is nil,
+// crypto/rand.Reader is used.
+func (k *ElGamalPublicKey) Encrypt(m *big.Int, rand io.Reader) (*ElGamalCiphertext, error) {
+	if m.Sign() <= 0 || m.Cmp(k.P) >= 0 {
+		return nil, errors.New("message must be in the range [1, P)")
+	}
+
+	r, err := randomNumber(defaultRand(rand), k.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &ElGamalCiphertext{C1: new(big.Int).Exp(k.G, r, k.P)}
+	c.C2 = new(big.Int).Exp(k.H, r, k.P)
+	c.C2.Mul(c.C2, m)
+	c.C2.Mod(c.C2, k.P)
+	return c, nil
+}
+
+// PartialDecrypt returns the decryption share of c for the holder of share.
+// C1 must be in the subgroup generated by G: otherwise, since the decryption
+// share is C1 raised to the share, a ciphertext chosen with C1 in a small
+// subgroup would reveal the share modulo that subgroup's order, as in the
+// attack of Lim and Lee.
+func (k *ElGamalPublicKey) PartialDecrypt(share Share, c *ElGamalCiphertext) (*DecryptionShare, error) {
+	if share.X == nil || share.Y == nil {
+		return nil, errors.New("share is missing coordinates")
+	}
+	if c.C1.Sign() <= 0 || c.C1.Cmp(k.P) >= 0 {
+		return nil, errors.New("invalid ciphertext")
+	}
+	if new(big.Int).Exp(c.C1, k.Q, k.P).Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("ciphertext isn't in the subgroup")
+	}
+	return &DecryptionShare{X: share.X, D: new(big.Int).Exp(c.C1, share.Y, k.P)}, nil
+}
+
+// Combine recovers the plaintext of c from at least k decryption shares by
+// Lagrange interpolation in the exponent. Too few or corrupt decryption
+// shares result in a wrong plaintext rather than an error.
+func (k *ElGamalPublicKey) Combine(c *ElGamalCiphertext, shares []DecryptionShare) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	xs := make([]*big.Int, len(shares))
+	for i, s := range shares {
+		if s.X == nil || s.D == nil {
+			return nil, errors.New("decryption share is incomplete")
+		}
+		xs[i] = s.X
+	}
+	if err := checkXs(xs, k.Q); err != nil {
+		return nil, err
+	}
+
+	// C1^x = product of D_i^lambda_i.
+	c1x := big.NewInt(1)
+	for i, s := range shares {
+		c1x.Mul(c1x, new(big.Int).Exp(s.D, lagrangeAtZero(xs, i, k.Q), k.P))
+		c1x.Mod(c1x, k.P)
+	}
+
+	if c1x.ModInverse(c1x, k.P) == nil {
+		return nil, errors.New("invalid decryption shares")
+	}
+	m := c1x.Mul(c1x, c.C2)
+	return m.Mod(m, k.P), nil
+}
+
+// lagrangeAtZero returns the Lagrange coefficient of xs[i] for evaluating at
+// zero the polynomial through points at xs.
+func lagrangeAtZero(xs []*big.Int, i int, modulus *big.Int) *big.Int {
+	num, den := big.NewInt(1), big.NewInt(1)
+	for j, x := range xs {
+		if j == i {
+			continue
+		}
+		num.Mul(num, x)
+		num.Mod(num, modulus)
+		den.Mul(den, new(big.Int).Sub(x, xs[i]))
+		den.Mod(den, modulus)
+	}
+	den.ModInverse(den, modulus)
+	num.Mul(num, den)
+	return num.Mod(num, modulus)
+}
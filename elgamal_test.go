@@ -0,0 +1,60 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestThresholdElGamal(t *testing.T) {
+	p := MODP2048
+	q := new(big.Int).Rsh(p, 1)
+	g := big.NewInt(4)
+
+	x, _ := randomNumber(defaultRand(nil), q)
+	set, err := SplitVerifiable(x, q, p, g, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	key, err := set.ElGamalPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := new(big.Int).Exp(g, big.NewInt(1234), p)
+	c, err := key.Encrypt(m, nil)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+
+	var shares []DecryptionShare
+	for _, i := range []int{4, 1, 2} {
+		d, err := key.PartialDecrypt(set.Shares[i], c)
+		if err != nil {
+			t.Fatalf("failed to partially decrypt: %s", err)
+		}
+		shares = append(shares, *d)
+	}
+
+	result, err := key.Combine(c, shares)
+	if err != nil || result.Cmp(m) != 0 {
+		t.Errorf("failed to decrypt: %v", err)
+	}
+
+	result, err = key.Combine(c, shares[:2])
+	if err == nil && result.Cmp(m) == 0 {
+		t.Errorf("decrypted with too few shares")
+	}
+
+	// P-1 has order two, so its decryption share would reveal the parity
+	// of the share.
+	small := &ElGamalCiphertext{C1: new(big.Int).Sub(p, big.NewInt(1)), C2: c.C2}
+	if _, err := key.PartialDecrypt(set.Shares[0], small); err == nil {
+		t.Errorf("ciphertext outside the subgroup was decrypted")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/subtle"
+	"math/big"
+	"math/bits"
+)
+
+// ConstantTimeEqual returns true iff s and t are the same share, that is they
+// have the same coordinates and modulus. Unlike comparing them with
+// big.Int.Cmp, the time taken depends only on the sizes of the values, not on
+// their contents. Metadata and other fields aren't compared.
+func (s *Share) ConstantTimeEqual(t *Share) bool {
+	if s.X == nil || s.Y == nil || t.X == nil || t.Y == nil {
+		return false
+	}
+	if (s.Modulus == nil) != (t.Modulus == nil) {
+		return false
+	}
+	eq := 1
+	if s.Modulus != nil {
+		eq &= constantTimeEqual(s.Modulus, t.Modulus, s.Modulus)
+	}
+	eq &= constantTimeEqual(s.X, t.X, s.Modulus)
+	eq &= constantTimeEqual(s.Y, t.Y, s.Modulus)
+	return eq == 1
+}
+
+// ConstantTimeEqualSecrets returns true iff a and b, which are secrets modulo
+// modulus, such as a reconstructed secret and a known value, are equal. The
+// time taken depends only on the size of the modulus, provided that a and b
+// are less than it, and not on their values.
+func ConstantTimeEqualSecrets(a, b, modulus *big.Int) bool {
+	return constantTimeEqual(a, b, modulus) == 1
+}
+
+// constantTimeEqual returns 1 if a and b are equal and non-negative, and 0
+// otherwise. They are compared as big-endian byte strings as long as the
+// largest of a, b and size, which may be nil.
+func constantTimeEqual(a, b, size *big.Int) int {
+	if a.Sign() < 0 || b.Sign() < 0 {
+		return 0
+	}
+	l := max(len(a.Bits()), len(b.Bits()))
+	if size != nil {
+		l = max(l, len(size.Bits()))
+	}
+	l *= bits.UintSize / 8
+	return subtle.ConstantTimeCompare(a.FillBytes(make([]byte, l)), b.FillBytes(make([]byte, l)))
+}
@@ -0,0 +1,47 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestConstantTimeEqual(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitShares(big.NewInt(5), modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := shares[0]
+	copied := Share{X: new(big.Int).Set(s.X), Y: new(big.Int).Set(s.Y), Modulus: new(big.Int).Set(modulus)}
+	if !s.ConstantTimeEqual(&copied) {
+		t.Error("equal shares compared unequal")
+	}
+	if s.ConstantTimeEqual(&shares[1]) {
+		t.Error("different shares compared equal")
+	}
+	copied.Y.Add(copied.Y, big.NewInt(1))
+	if s.ConstantTimeEqual(&copied) {
+		t.Error("shares with different y values compared equal")
+	}
+
+	secret, err := JoinShares(shares[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ConstantTimeEqualSecrets(secret, big.NewInt(5), modulus) {
+		t.Error("recovered secret compared unequal")
+	}
+	if ConstantTimeEqualSecrets(secret, big.NewInt(6), modulus) {
+		t.Error("different secrets compared equal")
+	}
+	if ConstantTimeEqualSecrets(big.NewInt(-5), big.NewInt(-5), modulus) {
+		t.Error("negative secrets compared equal")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+)
+
+// An EventSink is told of shares being dealt, received, refreshed and
+// combined, for audit logs and metrics. It's only given values that aren't
+// secret: never a share's y coordinate, MAC key or the secret itself.
+// Methods are called synchronously, so should return quickly.
+type EventSink interface {
+	// ShareDealt is called by Deal for each share that it returns.
+	ShareDealt(e ShareEvent)
+	// ShareReceived is called when a Combiner accepts a share.
+	ShareReceived(e ShareEvent)
+	// ShareRejected is called when a Combiner rejects a share, with the
+	// error that Add returned for it.
+	ShareRejected(e ShareEvent, err error)
+	// SecretReconstructed is called when a Combiner recovers the secret.
+	SecretReconstructed(e ReconstructionEvent)
+	// RefreshApplied is called by ApplyRefreshWithSink for the refreshed
+	// share, with its new epoch.
+	RefreshApplied(e ShareEvent)
+}
+
+// A ShareEvent describes the share that an event concerns.
+type ShareEvent struct {
+	// SetID and Epoch are from the share's metadata, or zero if it has
+	// none.
+	SetID [16]byte
+	Epoch uint64
+	// X is the share's x coordinate, which may be nil for a share that
+	// was rejected.
+	X           *big.Int
+	Participant string
+}
+
+// A ReconstructionEvent describes the recovery of a secret.
+type ReconstructionEvent struct {
+	SetID [16]byte
+	Epoch uint64
+	// Participants are those of the shares that were combined, with empty
+	// strings for shares that don't name one.
+	Participants []string
+}
+
+// shareEvent returns the ShareEvent for s, which doesn't alias it.
+func shareEvent(s *Share) ShareEvent {
+	e := ShareEvent{Participant: s.Participant}
+	if s.X != nil {
+		e.X = new(big.Int).Set(s.X)
+	}
+	if m := s.Metadata; m != nil {
+		e.SetID, e.Epoch = m.SetID, m.Epoch
+	}
+	return e
+}
+
+// ApplyRefreshWithSink is ApplyRefresh, but reports the refreshed share to
+// sink.
+func ApplyRefreshWithSink(sink EventSink, s *Share, epoch uint64, packets []*RefreshPacket, seeds []PairwiseSeed, c *Commitments) (Share, error) {
+	refreshed, err := ApplyRefresh(s, epoch, packets, seeds, c)
+	if err != nil {
+		return Share{}, err
+	}
+	sink.RefreshApplied(shareEvent(&refreshed))
+	return refreshed, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+type recordingSink struct {
+	dealt, received, refreshed []ShareEvent
+	rejected                   []error
+	reconstructed              []ReconstructionEvent
+}
+
+func (r *recordingSink) ShareDealt(e ShareEvent)    { r.dealt = append(r.dealt, e) }
+func (r *recordingSink) ShareReceived(e ShareEvent) { r.received = append(r.received, e) }
+func (r *recordingSink) ShareRejected(e ShareEvent, err error) {
+	r.rejected = append(r.rejected, err)
+}
+func (r *recordingSink) SecretReconstructed(e ReconstructionEvent) {
+	r.reconstructed = append(r.reconstructed, e)
+}
+func (r *recordingSink) RefreshApplied(e ShareEvent) { r.refreshed = append(r.refreshed, e) }
+
+func TestEventSink(t *testing.T) {
+	var sink recordingSink
+	secret := big.NewInt(42)
+	set, err := Deal(secret, 3, WithThreshold(2), WithField(P256Order), WithParticipants([]string{"alice", "bob", "carol"}), WithEventSink(&sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setID := set.Shares[0].Metadata.SetID
+	if len(sink.dealt) != 3 || sink.dealt[1].Participant != "bob" || sink.dealt[1].X.Int64() != 2 || sink.dealt[1].SetID != setID {
+		t.Errorf("dealt events: %+v", sink.dealt)
+	}
+
+	c, _ := NewCombiner(0, nil)
+	c.SetEventSink(&sink)
+	c.Add(set.Shares[0])
+	c.Add(set.Shares[0])
+	c.Add(set.Shares[2])
+	if len(sink.received) != 2 || sink.received[1].Participant != "carol" || len(sink.rejected) != 1 {
+		t.Errorf("got %d received and %d rejected events", len(sink.received), len(sink.rejected))
+	}
+	if result, err := c.Combine(); err != nil || result.Cmp(secret) != 0 {
+		t.Fatalf("failed to combine: got %v, %v", result, err)
+	}
+	if len(sink.reconstructed) != 1 {
+		t.Fatalf("got %d reconstruction events", len(sink.reconstructed))
+	}
+	if e := sink.reconstructed[0]; e.SetID != setID || len(e.Participants) != 2 || e.Participants[0] != "alice" || e.Participants[1] != "carol" {
+		t.Errorf("reconstruction event: %+v", e)
+	}
+}
+
+func TestApplyRefreshWithSink(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, _ := SplitShares(big.NewInt(1234), modulus, 2, 2, rand.Reader)
+	seeds := pairwiseSeeds(t, shares)
+	var packets []*RefreshPacket
+	for i := range shares {
+		p, err := NewRefreshPacket(&shares[i], 1, seeds[i], rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, p)
+	}
+
+	var sink recordingSink
+	if _, err := ApplyRefreshWithSink(&sink, &shares[0], 1, packets, seeds[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyRefreshWithSink(&sink, &shares[1], 1, packets[:1], seeds[1], nil); err == nil {
+		t.Error("refresh without the shareholder's own packet was accepted")
+	}
+	if len(sink.refreshed) != 1 || sink.refreshed[0].Epoch != 1 || sink.refreshed[0].X.Cmp(shares[0].X) != 0 {
+		t.Errorf("refresh events: %+v", sink.refreshed)
+	}
+}
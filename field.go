@@ -0,0 +1,169 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+)
+
+// A Field is a finite field with elements of type E. SplitField and JoinField
+// share secrets over any Field, so that different representations of fields,
+// or accelerated implementations of them, don't each need their own
+// implementation of secret sharing. Arguments to the methods must be elements
+// of the field.
+type Field[E any] interface {
+	Zero() E
+	One() E
+	Add(a, b E) E
+	Sub(a, b E) E
+	Mul(a, b E) E
+	// Inv returns a^-1, or an error if a is zero.
+	Inv(a E) (E, error)
+	Equal(a, b E) bool
+	// Element returns the element corresponding to the integer i, or an
+	// error if the field is too small to have one. This is used to
+	// compute the x coordinates of shares.
+	Element(i uint64) (E, error)
+	// Random returns a uniformly random element using bytes from rand.
+	Random(rand io.Reader) (E, error)
+	// Encode returns a fixed-length encoding of a and Decode inverts it.
+	Encode(a E) []byte
+	Decode(b []byte) (E, error)
+}
+
+// SplitField is like Split, but works over any field. It returns n shares,
+// the ith of which is the value of a random polynomial with constant term
+// secret at the element corresponding to i+1. If rand is nil,
+// crypto/rand.Reader is used.
+func SplitField[E any](f Field[E], secret E, k, n int, rand io.Reader) (shares []E, err error) {
+	if k < 1 || n < k {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+
+	rand = defaultRand(rand)
+	a := make([]E, k)
+	a[0] = secret
+	for j := 1; j < k; j++ {
+		if a[j], err = f.Random(rand); err != nil {
+			return nil, err
+		}
+	}
+
+	shares = make([]E, n)
+	for i := range shares {
+		x, err := f.Element(uint64(i + 1))
+		if err != nil {
+			return nil, err
+		}
+
+		y := f.Zero()
+		for j := k - 1; j >= 0; j-- {
+			y = f.Add(f.Mul(y, x), a[j])
+		}
+		shares[i] = y
+	}
+
+	return shares, nil
+}
+
+// JoinField is like Join, but works over any field. It recovers the secret
+// from shares that resulted from SplitField.
+func JoinField[E any](f Field[E], shares []E, shareNumbers []int) (secret E, err error) {
+	if len(shares) != len(shareNumbers) {
+		return secret, errors.New("lengths of shares and shareNumbers must match")
+	}
+	if len(shares) > DefaultLimits.MaxShares {
+		return secret, ErrLimitExceeded
+	}
+
+	xs := make([]E, len(shares))
+	for i, number := range shareNumbers {
+		if number < 0 {
+			return secret, errors.New("found negative share number")
+		}
+		if xs[i], err = f.Element(uint64(number) + 1); err != nil {
+			return secret, err
+		}
+		for j := 0; j < i; j++ {
+			if f.Equal(xs[j], xs[i]) {
+				return secret, errors.New("found duplicate share")
+			}
+		}
+	}
+
+	secret = f.Zero()
+	for i := range xs {
+		num, den := f.One(), f.One()
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			num = f.Mul(num, xs[j])
+			den = f.Mul(den, f.Sub(xs[j], xs[i]))
+		}
+
+		inv, err := f.Inv(den)
+		if err != nil {
+			return secret, err
+		}
+		secret = f.Add(secret, f.Mul(shares[i], f.Mul(num, inv)))
+	}
+
+	return secret, nil
+}
+
+var (
+	_ Field[byte]   = (*GF256)(nil)
+	_ Field[uint64] = (*Mersenne61)(nil)
+)
+
+// GF256 is GF(2^8) with the AES reducing polynomial, as used by
+// SplitVaultCompatible. It implements Field[byte].
+type GF256 struct{}
+
+// NewGF256 returns GF(2^8).
+func NewGF256() *GF256 {
+	return new(GF256)
+}
+
+func (*GF256) Zero() byte           { return 0 }
+func (*GF256) One() byte            { return 1 }
+func (*GF256) Add(a, b byte) byte   { return a ^ b }
+func (*GF256) Sub(a, b byte) byte   { return a ^ b }
+func (*GF256) Mul(a, b byte) byte   { return gf256Mul(a, b) }
+func (*GF256) Equal(a, b byte) bool { return a == b }
+
+func (*GF256) Inv(a byte) (byte, error) {
+	if a == 0 {
+		return 0, errors.New("element has no inverse")
+	}
+	return gf256Inv(a), nil
+}
+
+func (*GF256) Element(i uint64) (byte, error) {
+	if i > 255 {
+		return 0, errors.New("field is too small")
+	}
+	return byte(i), nil
+}
+
+func (*GF256) Random(rand io.Reader) (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(rand, b[:])
+	return b[0], err
+}
+
+func (*GF256) Encode(a byte) []byte { return []byte{a} }
+
+func (*GF256) Decode(b []byte) (byte, error) {
+	if len(b) != 1 {
+		return 0, errors.New("encoded element has the wrong length")
+	}
+	return b[0], nil
+}
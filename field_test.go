@@ -0,0 +1,35 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import "testing"
+
+func testField[E any](t *testing.T, f Field[E], secret E) {
+	shares, err := SplitField(f, secret, 3, 7, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	result, err := JoinField(f, []E{shares[6], shares[0], shares[3]}, []int{6, 0, 3})
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if !f.Equal(result, secret) {
+		t.Errorf("JoinField returned %v, want %v", result, secret)
+	}
+
+	decoded, err := f.Decode(f.Encode(secret))
+	if err != nil || !f.Equal(decoded, secret) {
+		t.Errorf("encoding didn't round trip")
+	}
+
+	if _, err := JoinField(f, shares[:2], []int{1, 1}); err == nil {
+		t.Errorf("duplicate share numbers were accepted")
+	}
+}
+
+func TestFields(t *testing.T) {
+	testField[byte](t, NewGF256(), 42)
+}
@@ -0,0 +1,104 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ManifestName is the name of the manifest written by DealToFiles.
+const ManifestName = "MANIFEST"
+
+// DealToFiles writes the binary encoding of each share to its own file in
+// dir, named share-1, share-2 and so on, followed by a manifest listing the
+// SHA-256 digest of each file in the format of sha256sum. Files are created
+// with mode 0600, never overwrite existing files and are synced to disk
+// before the manifest is atomically linked into place, so that a complete
+// manifest implies complete shares. On error, any files that were created
+// are removed. It returns the paths of the share files.
+func DealToFiles(dir string, shares []Share) (paths []string, err error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	var created []string
+	defer func() {
+		if err != nil {
+			for _, path := range created {
+				os.Remove(path)
+			}
+			paths = nil
+		}
+	}()
+
+	var manifest []byte
+	for i := range shares {
+		data, err := shares[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		name := "share-" + strconv.Itoa(i+1)
+		path := filepath.Join(dir, name)
+		if err := writeFileExclusive(path, data, &created); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+
+		digest := sha256.Sum256(data)
+		manifest = append(manifest, hex.EncodeToString(digest[:])+"  "+name+"\n"...)
+	}
+
+	tmp := filepath.Join(dir, "."+ManifestName+".tmp")
+	if err := writeFileExclusive(tmp, manifest, &created); err != nil {
+		return nil, err
+	}
+	// Unlike a rename, a hard link fails rather than replacing an existing
+	// manifest.
+	final := filepath.Join(dir, ManifestName)
+	if err := os.Link(tmp, final); err != nil {
+		return nil, err
+	}
+	created = append(created, final)
+	os.Remove(tmp)
+
+	// Sync the directory so that the new entries are durable too.
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// writeFileExclusive creates path, which must not exist, with mode 0600 and
+// writes data to it, syncing it to disk. If the file was created, path is
+// appended to *created.
+func writeFileExclusive(path string, data []byte, created *[]string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	*created = append(*created, path)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
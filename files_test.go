@@ -0,0 +1,58 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDealToFiles(t *testing.T) {
+	dir := t.TempDir()
+	shares, _ := SplitShares(big.NewInt(42), P256Order, 2, 3, nil)
+
+	paths, err := DealToFiles(dir, shares)
+	if err != nil {
+		t.Fatalf("error writing shares: %s", err)
+	}
+
+	var read []Share
+	for _, path := range paths[1:] {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s has mode %v", path, info.Mode().Perm())
+		}
+		data, _ := os.ReadFile(path)
+		var s Share
+		if err := s.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		read = append(read, s)
+	}
+	if result, err := JoinShares(read); err != nil || result.Int64() != 42 {
+		t.Errorf("failed to join shares read from files: %v", err)
+	}
+
+	manifest, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil || strings.Count(string(manifest), "\n") != 3 {
+		t.Errorf("bad manifest: %q, %v", manifest, err)
+	}
+
+	// Writing again must fail without touching the existing files.
+	if _, err := DealToFiles(dir, shares); err == nil {
+		t.Errorf("overwrote existing shares")
+	}
+	if _, err := os.Stat(paths[0]); err != nil {
+		t.Errorf("failed deal removed existing share: %s", err)
+	}
+}
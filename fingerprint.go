@@ -0,0 +1,89 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// ErrWrongSecret is returned by JoinShares when the recovered secret doesn't
+// match the fingerprint in the shares, which happens when too few shares are
+// given or when some of them are corrupt.
+var ErrWrongSecret = errors.New("recovered secret doesn't match its fingerprint")
+
+// A Fingerprint is a salted hash of a secret that is distributed with every
+// share so that the result of joining them can be checked.
+//
+// A fingerprint allows anyone holding a single share to test guesses of the
+// secret, so it must only be used when the secret has high entropy, such as
+// a key.
+type Fingerprint struct {
+	Salt   [16]byte
+	Digest [sha256.Size]byte
+}
+
+// FingerprintShares computes a fingerprint of secret and sets it as the
+// Fingerprint of each share, which must all have the same modulus. JoinShares
+// then returns ErrWrongSecret instead of a wrong secret. If rand is nil,
+// crypto/rand.Reader is used.
+func FingerprintShares(shares []Share, secret *big.Int, rand io.Reader) error {
+	if len(shares) == 0 || shares[0].Modulus == nil {
+		return errors.New("shares don't record their modulus")
+	}
+
+	f := new(Fingerprint)
+	if _, err := io.ReadFull(defaultRand(rand), f.Salt[:]); err != nil {
+		return err
+	}
+	copy(f.Digest[:], fingerprintDigest(f.Salt[:], secret, shares[0].Modulus))
+
+	for i := range shares {
+		if shares[i].Modulus == nil || shares[i].Modulus.Cmp(shares[0].Modulus) != 0 {
+			return errors.New("shares are from different splits")
+		}
+		shares[i].Fingerprint = f
+	}
+	return nil
+}
+
+// Check returns ErrWrongSecret unless f is a fingerprint of secret, which was
+// split using modulus.
+func (f *Fingerprint) Check(secret, modulus *big.Int) error {
+	if secret.Sign() < 0 || secret.Cmp(modulus) >= 0 ||
+		!hmac.Equal(f.Digest[:], fingerprintDigest(f.Salt[:], secret, modulus)) {
+		return ErrWrongSecret
+	}
+	return nil
+}
+
+// fingerprintDigest hashes secret, encoded with the width of modulus so that
+// the fingerprint doesn't depend on its length.
+func fingerprintDigest(salt []byte, secret, modulus *big.Int) []byte {
+	h := hmac.New(sha256.New, salt)
+	b := secret.FillBytes(make([]byte, (modulus.BitLen()+7)/8))
+	defer clear(b)
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+func (f *Fingerprint) marshal() []byte {
+	return append(f.Salt[:len(f.Salt):len(f.Salt)], f.Digest[:]...)
+}
+
+func parseFingerprint(value []byte) (*Fingerprint, error) {
+	f := new(Fingerprint)
+	if len(value) != len(f.Salt)+len(f.Digest) {
+		return nil, errors.New("invalid fingerprint")
+	}
+	copy(f.Salt[:], value)
+	copy(f.Digest[:], value[len(f.Salt):])
+	return f, nil
+}
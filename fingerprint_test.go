@@ -0,0 +1,58 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, _ := SplitShares(secret, MODP2048, 3, 5, nil)
+	if err := FingerprintShares(shares, secret, nil); err != nil {
+		t.Fatalf("failed to fingerprint shares: %s", err)
+	}
+
+	var parsed []Share
+	for i := range shares {
+		data, err := shares[i].MarshalBinary()
+		if err != nil {
+			t.Fatalf("error while marshaling: %s", err)
+		}
+		var s Share
+		if err := s.UnmarshalBinary(data); err != nil {
+			t.Fatalf("error while unmarshaling: %s", err)
+		}
+		parsed = append(parsed, s)
+	}
+
+	result, err := JoinShares(parsed[2:])
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to join fingerprinted shares: %v", err)
+	}
+
+	if _, err := JoinShares(parsed[:2]); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("joining too few shares gave %v, want ErrNotEnoughShares", err)
+	}
+	// Without the threshold, the fingerprint still catches too few shares.
+	tooFew := []Share{parsed[0], parsed[1]}
+	for i := range tooFew {
+		m := *tooFew[i].Metadata
+		m.Threshold = 0
+		tooFew[i].Metadata = &m
+	}
+	if _, err := JoinShares(tooFew); err != ErrWrongSecret {
+		t.Errorf("joining too few shares gave %v, want ErrWrongSecret", err)
+	}
+
+	parsed[0].Y = new(big.Int).Add(parsed[0].Y, big.NewInt(1))
+	if _, err := JoinShares(parsed[:3]); err != ErrWrongSecret {
+		t.Errorf("joining a corrupt share gave %v, want ErrWrongSecret", err)
+	}
+}
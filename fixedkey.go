@@ -0,0 +1,65 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+)
+
+// A Key is a fixed-size secret, such as an AES-128 or AES-256 key, an
+// Ed25519 seed or an HMAC key. Defined types, such as a [32]byte key type,
+// can be used too.
+type Key interface {
+	~[16]byte | ~[24]byte | ~[32]byte | ~[48]byte | ~[64]byte
+}
+
+// SplitKey is like SplitAuto, but for a fixed-size key, so that JoinKey can
+// recover it as the same type. The field is chosen by the size of K.
+func SplitKey[K Key](key K, k, n int) ([]Share, error) {
+	b := make([]byte, len(key))
+	defer clear(b)
+	for i := range b {
+		b[i] = key[i]
+	}
+
+	modulus, err := autoModulus(8 * len(b))
+	if err != nil {
+		return nil, err
+	}
+	secret := new(big.Int).SetBytes(b)
+	defer clear(secret.Bits())
+	shares, err := SplitShares(secret, modulus, k, n, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range shares {
+		shares[i].SecretLen = len(b)
+	}
+	return shares, nil
+}
+
+// JoinKey recovers a key of type K from at least k shares that resulted
+// from SplitKey with the same size of key. As with JoinInto, the
+// temporaries used are wiped.
+func JoinKey[K Key](shares []Share) (key K, err error) {
+	if len(shares) > 0 && shares[0].SecretLen != len(key) {
+		return key, errors.New("shares are of a " + strconv.Itoa(shares[0].SecretLen) + "-byte secret, not a " + strconv.Itoa(len(key)) + "-byte key")
+	}
+
+	b := make([]byte, len(key))
+	defer clear(b)
+	if _, err := JoinInto(b, shares); err != nil {
+		return key, err
+	}
+	for i := range b {
+		key[i] = b[i]
+	}
+	return key, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+type testAESKey [16]byte
+
+func TestSplitKey(t *testing.T) {
+	var key [32]byte
+	rand.Read(key[1:])
+	shares, err := SplitKey(key, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shares[0].Modulus.BitLen() <= 256 {
+		t.Errorf("%d-bit modulus is too small for a 32-byte key", shares[0].Modulus.BitLen())
+	}
+	got, err := JoinKey[[32]byte](shares[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != key {
+		t.Errorf("JoinKey returned %x, want %x", got, key)
+	}
+	if _, err := JoinKey[[16]byte](shares); err == nil {
+		t.Error("32-byte key was joined as a 16-byte one")
+	}
+
+	var aesKey testAESKey
+	rand.Read(aesKey[:])
+	shares, err = SplitKey(aesKey, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := JoinKey[testAESKey](shares[2:]); err != nil || got != aesKey {
+		t.Errorf("JoinKey returned %x, %v; want %x", got, err, aesKey)
+	}
+	if _, err := JoinKey[testAESKey](shares[:2]); err == nil {
+		t.Error("key was joined from too few shares")
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// An AccessFormula is a monotone boolean formula over named participants,
+// such as "(A AND B) OR (C AND D AND E)" or "2 OF (A, B, C AND D)", that
+// describes which groups can recover a secret. AND binds more tightly than
+// OR, and the keywords are case insensitive. A participant may appear more
+// than once.
+//
+// Secrets are shared by iterating Shamir's scheme down the formula (the
+// Benaloh-Leichter construction): each "k OF" gate splits its value k-of-n
+// among its children, AND is n-of-n and OR is 1-of-n, and each participant
+// receives the values of the leaves that name them.
+type AccessFormula struct {
+	root   *formulaNode
+	leaves int
+}
+
+type formulaNode struct {
+	// name is set for leaves, which have no children.
+	name     string
+	leaf     int
+	k        int
+	children []*formulaNode
+}
+
+// A FormulaShare is the value of one leaf of an AccessFormula.
+type FormulaShare struct {
+	Leaf    int
+	Y       *big.Int
+	Modulus *big.Int
+}
+
+// ParseAccessFormula parses a formula.
+func ParseAccessFormula(s string) (*AccessFormula, error) {
+	p := &formulaParser{tokens: tokenizeFormula(s)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.tokens) > 0 {
+		return nil, fmt.Errorf("unexpected %q in access formula", p.tokens[0])
+	}
+	return &AccessFormula{root: root, leaves: p.leaves}, nil
+}
+
+// String returns the formula in canonical form.
+func (f *AccessFormula) String() string {
+	var b strings.Builder
+	f.root.format(&b)
+	return b.String()
+}
+
+// Participants returns the names of the participants in f, in order of first
+// appearance.
+func (f *AccessFormula) Participants() []string {
+	var names []string
+	seen := make(map[string]bool)
+	f.root.walk(func(n *formulaNode) {
+		if !seen[n.name] {
+			seen[n.name] = true
+			names = append(names, n.name)
+		}
+	})
+	return names
+}
+
+// Satisfied returns true iff the participants named can recover the secret.
+func (f *AccessFormula) Satisfied(participants []string) bool {
+	present := make(map[string]bool)
+	for _, p := range participants {
+		present[p] = true
+	}
+
+	var eval func(n *formulaNode) bool
+	eval = func(n *formulaNode) bool {
+		if n.children == nil {
+			return present[n.name]
+		}
+		count := 0
+		for _, c := range n.children {
+			if eval(c) {
+				count++
+			}
+		}
+		return count >= n.k
+	}
+	return eval(f.root)
+}
+
+// Split shares secret according to f and returns each participant's shares,
+// keyed by name. If rand is nil, crypto/rand.Reader is used.
+func (f *AccessFormula) Split(secret, modulus *big.Int, rand io.Reader) (map[string][]FormulaShare, error) {
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+	rand = defaultRand(rand)
+
+	shares := make(map[string][]FormulaShare)
+	var split func(n *formulaNode, v *big.Int) error
+	split = func(n *formulaNode, v *big.Int) error {
+		if n.children == nil {
+			shares[n.name] = append(shares[n.name], FormulaShare{Leaf: n.leaf, Y: v, Modulus: modulus})
+			return nil
+		}
+		ys, err := Split(v, modulus, n.k, len(n.children), rand)
+		if err != nil {
+			return err
+		}
+		for i, c := range n.children {
+			if err := split(c, ys[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := split(f.root, secret); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// Join recovers the secret from the shares of a group of participants, keyed
+// by name, who satisfy f.
+func (f *AccessFormula) Join(shares map[string][]FormulaShare) (*big.Int, error) {
+	var modulus *big.Int
+	leaves := make(map[int]*big.Int)
+	for _, ss := range shares {
+		for _, s := range ss {
+			if s.Modulus == nil || s.Y == nil {
+				return nil, errors.New("share doesn't record its modulus")
+			}
+			if modulus == nil {
+				modulus = s.Modulus
+			} else if modulus.Cmp(s.Modulus) != 0 {
+				return nil, errors.New("shares are from different splits")
+			}
+			if s.Leaf < 0 || s.Leaf >= f.leaves {
+				return nil, errors.New("share is not for this formula")
+			}
+			leaves[s.Leaf] = s.Y
+		}
+	}
+
+	var err error
+	var join func(n *formulaNode) *big.Int
+	join = func(n *formulaNode) *big.Int {
+		if n.children == nil {
+			return leaves[n.leaf]
+		}
+		var xs, ys []*big.Int
+		for i, c := range n.children {
+			if len(xs) == n.k {
+				break
+			}
+			if v := join(c); v != nil {
+				xs = append(xs, big.NewInt(int64(i+1)))
+				ys = append(ys, v)
+			}
+		}
+		if len(xs) < n.k {
+			return nil
+		}
+		v, ierr := interpolate(xs, ys, modulus)
+		if ierr != nil && err == nil {
+			err = ierr
+		}
+		return v
+	}
+
+	secret := join(f.root)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, errors.New("shares don't satisfy the access formula")
+	}
+	return secret, nil
+}
+
+func (n *formulaNode) walk(f func(*formulaNode)) {
+	if n.children == nil {
+		f(n)
+	}
+	for _, c := range n.children {
+		c.walk(f)
+	}
+}
+
+func (n *formulaNode) format(b *strings.Builder) {
+	if n.children == nil {
+		b.WriteString(n.name)
+		return
+	}
+
+	sep := ", "
+	switch n.k {
+	case len(n.children):
+		sep = " AND "
+	case 1:
+		sep = " OR "
+	default:
+		fmt.Fprintf(b, "%d OF ", n.k)
+	}
+	b.WriteByte('(')
+	for i, c := range n.children {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		c.format(b)
+	}
+	b.WriteByte(')')
+}
+
+func tokenizeFormula(s string) []string {
+	var tokens []string
+	for len(s) > 0 {
+		r := rune(s[0])
+		switch {
+		case unicode.IsSpace(r):
+			s = s[1:]
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, s[:1])
+			s = s[1:]
+		default:
+			i := strings.IndexFunc(s, func(r rune) bool {
+				return unicode.IsSpace(r) || r == '(' || r == ')' || r == ','
+			})
+			if i < 0 {
+				i = len(s)
+			}
+			tokens = append(tokens, s[:i])
+			s = s[i:]
+		}
+	}
+	return tokens
+}
+
+type formulaParser struct {
+	tokens []string
+	leaves int
+}
+
+func (p *formulaParser) peek() string {
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	return p.tokens[0]
+}
+
+func (p *formulaParser) next() string {
+	t := p.peek()
+	if len(p.tokens) > 0 {
+		p.tokens = p.tokens[1:]
+	}
+	return t
+}
+
+func (p *formulaParser) expect(t string) error {
+	if got := p.next(); got != t {
+		if got == "" {
+			return fmt.Errorf("expected %q at end of access formula", t)
+		}
+		return fmt.Errorf("expected %q but found %q in access formula", t, got)
+	}
+	return nil
+}
+
+// parseOr parses a disjunction of conjunctions.
+func (p *formulaParser) parseOr() (*formulaNode, error) {
+	return p.parseGate("OR", p.parseAnd, func(n int) int { return 1 })
+}
+
+// parseAnd parses a conjunction of terms.
+func (p *formulaParser) parseAnd() (*formulaNode, error) {
+	return p.parseGate("AND", p.parseTerm, func(n int) int { return n })
+}
+
+func (p *formulaParser) parseGate(op string, operand func() (*formulaNode, error), k func(int) int) (*formulaNode, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	children := []*formulaNode{first}
+	for strings.EqualFold(p.peek(), op) {
+		p.next()
+		c, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, c)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &formulaNode{k: k(len(children)), children: children}, nil
+}
+
+// parseTerm parses a participant, a parenthesized formula or a threshold
+// gate.
+func (p *formulaParser) parseTerm() (*formulaNode, error) {
+	t := p.next()
+	switch {
+	case t == "":
+		return nil, errors.New("unexpected end of access formula")
+	case t == "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case t == ")" || t == "," || strings.EqualFold(t, "AND") || strings.EqualFold(t, "OR") || strings.EqualFold(t, "OF"):
+		return nil, fmt.Errorf("unexpected %q in access formula", t)
+	}
+
+	if k, err := strconv.Atoi(t); err == nil && strings.EqualFold(p.peek(), "OF") {
+		p.next()
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		n := &formulaNode{k: k}
+		for {
+			c, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, c)
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		if k < 1 || k > len(n.children) {
+			return nil, fmt.Errorf("invalid threshold %d of %d in access formula", k, len(n.children))
+		}
+		return n, nil
+	}
+
+	n := &formulaNode{name: t, leaf: p.leaves}
+	p.leaves++
+	return n, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+)
+
+func TestParseAccessFormula(t *testing.T) {
+	for _, test := range []struct{ in, out string }{
+		{"(A AND B) OR (C AND D AND E)", "((A AND B) OR (C AND D AND E))"},
+		{"a and b or c", "((a AND b) OR c)"},
+		{"2 of (alice, bob, carol and dave)", "2 OF (alice, bob, (carol AND dave))"},
+		{"A", "A"},
+	} {
+		f, err := ParseAccessFormula(test.in)
+		if err != nil {
+			t.Errorf("%q: %s", test.in, err)
+			continue
+		}
+		if s := f.String(); s != test.out {
+			t.Errorf("%q: got %q, want %q", test.in, s, test.out)
+		}
+	}
+
+	for _, bad := range []string{"", "A AND", "(A OR B", "A B", "3 OF (A, B)", "AND", "0 OF (A)"} {
+		if _, err := ParseAccessFormula(bad); err == nil {
+			t.Errorf("%q: parsed successfully", bad)
+		}
+	}
+}
+
+func TestAccessFormula(t *testing.T) {
+	secret := big.NewInt(42)
+	f, _ := ParseAccessFormula("(A AND B) OR (C AND D AND E) OR 2 OF (A, F, G)")
+	if p := f.Participants(); !slices.Equal(p, []string{"A", "B", "C", "D", "E", "F", "G"}) {
+		t.Errorf("got participants %v", p)
+	}
+
+	shares, err := f.Split(secret, MODP2048, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	for _, test := range []struct {
+		group []string
+		ok    bool
+	}{
+		{[]string{"A", "B"}, true},
+		{[]string{"C", "D", "E"}, true},
+		{[]string{"A", "G"}, true},
+		{[]string{"F", "G"}, true},
+		{[]string{"A", "C", "D"}, false},
+		{[]string{"B", "C", "D", "G"}, false},
+	} {
+		if f.Satisfied(test.group) != test.ok {
+			t.Errorf("%v: Satisfied returned %t", test.group, !test.ok)
+		}
+
+		subset := make(map[string][]FormulaShare)
+		for _, name := range test.group {
+			subset[name] = shares[name]
+		}
+		result, err := f.Join(subset)
+		if ok := err == nil && result.Cmp(secret) == 0; ok != test.ok {
+			t.Errorf("%v: got %t, want %t (%v)", test.group, ok, test.ok, err)
+		}
+	}
+}
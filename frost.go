@@ -0,0 +1,255 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"math/big"
+	"slices"
+
+	"github.com/agl/shamirsplit/internal/edwards25519"
+)
+
+// This file implements FROST threshold signing (RFC 9591) with the
+// FROST(Ed25519, SHA-512) ciphersuite, so that k holders of shares from
+// SplitEd25519 can produce an Ed25519 signature without the private key ever
+// being reconstructed.
+//
+// Signing has two rounds. First, each signer calls FROSTCommit and sends the
+// resulting commitment to a coordinator, keeping the nonces secret. Then the
+// coordinator sends the message and all the commitments to the signers, who
+// each call FROSTSign, and the coordinator combines their signature shares
+// with FROSTAggregate.
+
+const frostContext = "FROST-ED25519-SHA512-v1"
+
+// FROSTNonces are a signer's secret nonces for a single signature. They must
+// never be reused, and FROSTSign erases them.
+type FROSTNonces struct {
+	hiding, binding *edwards25519.Scalar
+	commitment      FROSTCommitment
+}
+
+// A FROSTCommitment is a signer's public commitment to their nonces.
+type FROSTCommitment struct {
+	Identifier      *big.Int
+	Hiding, Binding []byte
+}
+
+// A FROSTSignatureShare is a signer's contribution to a signature.
+type FROSTSignatureShare struct {
+	Identifier *big.Int
+	Z          []byte
+}
+
+// FROSTCommit generates nonces for signing with share, which must have
+// resulted from SplitEd25519. If rand is nil, crypto/rand.Reader is used.
+func FROSTCommit(share Share, rand io.Reader) (*FROSTNonces, FROSTCommitment, error) {
+	s, err := frostShareScalar(share)
+	if err != nil {
+		return nil, FROSTCommitment{}, err
+	}
+
+	rand = defaultRand(rand)
+	n := new(FROSTNonces)
+	if n.hiding, err = frostNonce(s, rand); err != nil {
+		return nil, FROSTCommitment{}, err
+	}
+	if n.binding, err = frostNonce(s, rand); err != nil {
+		return nil, FROSTCommitment{}, err
+	}
+
+	n.commitment = FROSTCommitment{
+		Identifier: new(big.Int).Set(share.X),
+		Hiding:     new(edwards25519.Point).ScalarBaseMult(n.hiding).Bytes(),
+		Binding:    new(edwards25519.Point).ScalarBaseMult(n.binding).Bytes(),
+	}
+	return n, n.commitment, nil
+}
+
+// FROSTSign returns the signature share of message from the holder of share,
+// given the nonces from FROSTCommit and the commitments of all the signers,
+// including this one. pub is the Ed25519 public key that was split.
+func FROSTSign(share Share, nonces *FROSTNonces, commitments []FROSTCommitment, message []byte, pub ed25519.PublicKey) (*FROSTSignatureShare, error) {
+	if nonces.hiding == nil {
+		return nil, errors.New("nonces have already been used")
+	}
+	s, err := frostShareScalar(share)
+	if err != nil {
+		return nil, err
+	}
+
+	commitments = frostSortCommitments(commitments)
+	i := slices.IndexFunc(commitments, func(c FROSTCommitment) bool {
+		return c.Identifier.Cmp(share.X) == 0
+	})
+	if i < 0 || !slices.Equal(commitments[i].Hiding, nonces.commitment.Hiding) ||
+		!slices.Equal(commitments[i].Binding, nonces.commitment.Binding) {
+		return nil, errors.New("signer's commitment is missing")
+	}
+
+	rhos, R, err := frostGroupCommitment(commitments, message, pub)
+	if err != nil {
+		return nil, err
+	}
+	lambda, err := frostLagrange(commitments, share.X)
+	if err != nil {
+		return nil, err
+	}
+	c := ed25519Challenge(R.Bytes(), pub, message)
+
+	// z = hiding + binding*rho + lambda*s*c
+	z := edwards25519.NewScalar().Multiply(lambda, s)
+	z.MultiplyAdd(z, c, nonces.hiding)
+	z.MultiplyAdd(nonces.binding, rhos[i], z)
+
+	*nonces = FROSTNonces{}
+	return &FROSTSignatureShare{Identifier: new(big.Int).Set(share.X), Z: z.Bytes()}, nil
+}
+
+// FROSTAggregate combines the signature shares of message from each of the
+// signers who committed to commitments into an Ed25519 signature, which is
+// verified against pub.
+func FROSTAggregate(commitments []FROSTCommitment, shares []FROSTSignatureShare, message []byte, pub ed25519.PublicKey) ([]byte, error) {
+	if len(shares) != len(commitments) {
+		return nil, errors.New("lengths of shares and commitments must match")
+	}
+
+	commitments = frostSortCommitments(commitments)
+	_, R, err := frostGroupCommitment(commitments, message, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	z := edwards25519.NewScalar()
+	for _, c := range commitments {
+		i := slices.IndexFunc(shares, func(s FROSTSignatureShare) bool {
+			return s.Identifier != nil && s.Identifier.Cmp(c.Identifier) == 0
+		})
+		if i < 0 {
+			return nil, errors.New("missing signature share")
+		}
+		zi, err := edwards25519.NewScalar().SetCanonicalBytes(shares[i].Z)
+		if err != nil {
+			return nil, errors.New("invalid signature share")
+		}
+		z.Add(z, zi)
+	}
+
+	sig := append(R.Bytes(), z.Bytes()...)
+	if !ed25519.Verify(pub, message, sig) {
+		return nil, errors.New("signature doesn't verify: a signer misbehaved")
+	}
+	return sig, nil
+}
+
+// frostShareScalar returns the value of share as a scalar.
+func frostShareScalar(share Share) (*edwards25519.Scalar, error) {
+	if share.Modulus == nil || share.Modulus.Cmp(Edwards25519Order) != 0 || share.X == nil || share.Y == nil {
+		return nil, errors.New("share is not over the Ed25519 group order")
+	}
+	return ed25519IntToScalar(share.Y)
+}
+
+// frostNonce implements nonce_generate from RFC 9591, section 4.1.
+func frostNonce(secret *edwards25519.Scalar, rand io.Reader) (*edwards25519.Scalar, error) {
+	var b [32]byte
+	if _, err := io.ReadFull(rand, b[:]); err != nil {
+		return nil, err
+	}
+	h := sha512.New()
+	h.Write([]byte(frostContext + "nonce"))
+	h.Write(b[:])
+	h.Write(secret.Bytes())
+	return edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+}
+
+// frostSortCommitments returns a copy of commitments sorted by identifier.
+func frostSortCommitments(commitments []FROSTCommitment) []FROSTCommitment {
+	commitments = slices.Clone(commitments)
+	slices.SortFunc(commitments, func(a, b FROSTCommitment) int {
+		return a.Identifier.Cmp(b.Identifier)
+	})
+	return commitments
+}
+
+// frostGroupCommitment returns the binding factor of each of the sorted
+// commitments and the group commitment, R.
+func frostGroupCommitment(commitments []FROSTCommitment, message []byte, pub ed25519.PublicKey) ([]*edwards25519.Scalar, *edwards25519.Point, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, nil, errors.New("invalid Ed25519 public key")
+	}
+
+	var encoded []byte
+	ids := make([][]byte, len(commitments))
+	for i, c := range commitments {
+		if i > 0 && c.Identifier.Cmp(commitments[i-1].Identifier) == 0 {
+			return nil, nil, errors.New("duplicate commitment")
+		}
+		id, err := ed25519IntToScalar(c.Identifier)
+		if err != nil || c.Identifier.Sign() == 0 {
+			return nil, nil, errors.New("invalid identifier")
+		}
+		ids[i] = id.Bytes()
+		encoded = append(encoded, ids[i]...)
+		encoded = append(encoded, c.Hiding...)
+		encoded = append(encoded, c.Binding...)
+	}
+
+	prefix := slices.Clone([]byte(pub))
+	prefix = append(prefix, frostHash("msg", message)...)
+	prefix = append(prefix, frostHash("com", encoded)...)
+
+	rhos := make([]*edwards25519.Scalar, len(commitments))
+	R := edwards25519.NewIdentityPoint()
+	for i, c := range commitments {
+		rho, err := edwards25519.NewScalar().SetUniformBytes(frostHash("rho", append(prefix, ids[i]...)))
+		if err != nil {
+			return nil, nil, err
+		}
+		rhos[i] = rho
+
+		D, err := new(edwards25519.Point).SetBytes(c.Hiding)
+		if err != nil {
+			return nil, nil, errors.New("invalid commitment")
+		}
+		E, err := new(edwards25519.Point).SetBytes(c.Binding)
+		if err != nil {
+			return nil, nil, errors.New("invalid commitment")
+		}
+		R.Add(R, D)
+		R.Add(R, E.ScalarMult(rho, E))
+	}
+	return rhos, R, nil
+}
+
+// frostLagrange returns the Lagrange coefficient of the signer with identifier
+// x among the signers who committed to commitments.
+func frostLagrange(commitments []FROSTCommitment, x *big.Int) (*edwards25519.Scalar, error) {
+	xs := make([]*big.Int, len(commitments))
+	i := -1
+	for j, c := range commitments {
+		xs[j] = c.Identifier
+		if c.Identifier.Cmp(x) == 0 {
+			i = j
+		}
+	}
+	if i < 0 {
+		return nil, errors.New("signer's commitment is missing")
+	}
+	return ed25519IntToScalar(lagrangeAtZero(xs, i, Edwards25519Order))
+}
+
+func frostHash(tag string, m []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(frostContext + tag))
+	h.Write(m)
+	return h.Sum(nil)
+}
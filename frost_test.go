@@ -0,0 +1,66 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestFROST(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	shares, err := SplitEd25519(priv, 3, 5)
+	if err != nil {
+		t.Fatalf("failed to split key: %s", err)
+	}
+
+	message := []byte("hello")
+	signers := []Share{shares[4], shares[0], shares[2]}
+
+	nonces := make([]*FROSTNonces, len(signers))
+	commitments := make([]FROSTCommitment, len(signers))
+	for i, s := range signers {
+		if nonces[i], commitments[i], err = FROSTCommit(s, nil); err != nil {
+			t.Fatalf("failed to commit: %s", err)
+		}
+	}
+
+	sigShares := make([]FROSTSignatureShare, len(signers))
+	for i, s := range signers {
+		z, err := FROSTSign(s, nonces[i], commitments, message, pub)
+		if err != nil {
+			t.Fatalf("failed to sign: %s", err)
+		}
+		sigShares[i] = *z
+	}
+
+	if _, err := FROSTSign(signers[0], nonces[0], commitments, message, pub); err == nil {
+		t.Errorf("nonces were reused")
+	}
+
+	sig, err := FROSTAggregate(commitments, sigShares, message, pub)
+	if err != nil {
+		t.Fatalf("failed to aggregate: %s", err)
+	}
+	if !ed25519.Verify(pub, message, sig) {
+		t.Errorf("signature didn't verify")
+	}
+
+	sigShares[1].Z[0] ^= 1
+	if _, err := FROSTAggregate(commitments, sigShares, message, pub); err == nil {
+		t.Errorf("aggregated a corrupt signature share")
+	}
+
+	// Too few signers can't produce a valid signature.
+	n0, c0, _ := FROSTCommit(signers[0], nil)
+	n1, c1, _ := FROSTCommit(signers[1], nil)
+	z0, _ := FROSTSign(signers[0], n0, []FROSTCommitment{c0, c1}, message, pub)
+	z1, _ := FROSTSign(signers[1], n1, []FROSTCommitment{c0, c1}, message, pub)
+	if _, err := FROSTAggregate([]FROSTCommitment{c0, c1}, []FROSTSignatureShare{*z0, *z1}, message, pub); err == nil {
+		t.Errorf("two of three signers produced a signature")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import "encoding/binary"
+
+// This file implements arithmetic in GF(2^8) with the reducing polynomial
+// x^8 + x^4 + x^3 + x + 1, as used by AES. Addition is XOR. The functions
+// avoid secret dependent branches and tables indexed by secrets.
+
+// gf256Mul returns a*b.
+func gf256Mul(a, b byte) byte {
+	var r byte
+	for i := 0; i < 8; i++ {
+		// mask is 0xff if the low bit of b is set and zero otherwise.
+		mask := -(b & 1)
+		r ^= a & mask
+		// Multiply a by x, reducing if the top bit was set.
+		carry := -(a >> 7)
+		a = a<<1 ^ 0x1b&carry
+		b >>= 1
+	}
+	return r
+}
+
+// gf256Inv returns the multiplicative inverse of a, or zero if a is zero.
+func gf256Inv(a byte) byte {
+	// a^254 = a^-1 since the multiplicative group has order 255.
+	r := a
+	for i := 0; i < 6; i++ {
+		r = gf256Mul(r, r)
+		r = gf256Mul(r, a)
+	}
+	return gf256Mul(r, r)
+}
+
+// gf256MulXorSlice sets out[i] ^= c*in[i] for each i. out must be at least
+// as long as in. Where possible, whole blocks are handled with vector
+// instructions, and the rest with gf256MulXorGeneric. c may index tables, so
+// it mustn't be secret, but in may be.
+func gf256MulXorSlice(c byte, in, out []byte) {
+	out = out[:len(in)]
+	if gf256HaveAsm && len(in) >= 16 {
+		// tables holds c times each low nibble followed by c times
+		// each high nibble. Vector shuffles look up the nibbles of in
+		// in constant time.
+		var tables [32]byte
+		for i := range 16 {
+			tables[i] = gf256Mul(c, byte(i))
+			tables[16+i] = gf256Mul(c, byte(i)<<4)
+		}
+		n := len(in) &^ 15
+		gf256MulXorBlocks(&tables, in[:n], out[:n])
+		in, out = in[n:], out[n:]
+	}
+	gf256MulXorGeneric(c, in, out)
+}
+
+// gf256MulXorGeneric is the portable implementation of gf256MulXorSlice. It
+// multiplies eight bytes at a time in a uint64.
+func gf256MulXorGeneric(c byte, in, out []byte) {
+	for len(in) >= 8 {
+		v := binary.LittleEndian.Uint64(in)
+		var r uint64
+		for i := 0; i < 8; i++ {
+			r ^= v & -uint64(c>>i&1)
+			// Multiply each byte of v by x.
+			carries := v & 0x8080808080808080
+			v = (v&0x7f7f7f7f7f7f7f7f)<<1 ^ (carries>>7)*0x1b
+		}
+		binary.LittleEndian.PutUint64(out, binary.LittleEndian.Uint64(out)^r)
+		in, out = in[8:], out[8:]
+	}
+	for i := range in {
+		out[i] ^= gf256Mul(c, in[i])
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego && !tinygo
+
+package shamirsplit
+
+// gf256HaveAsm is true if the CPU supports SSSE3, which provides PSHUFB.
+var gf256HaveAsm = gf256HasSSSE3()
+
+func gf256HasSSSE3() bool {
+	_, _, ecx, _ := gf256CPUID(1, 0)
+	return ecx&(1<<9) != 0
+}
+
+//go:noescape
+func gf256CPUID(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// gf256MulXorBlocks sets out[i] ^= c*in[i], where tables is as described in
+// gf256MulXorSlice, for len(in) a multiple of 16.
+//
+//go:noescape
+func gf256MulXorBlocks(tables *[32]byte, in, out []byte)
@@ -0,0 +1,60 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego && !tinygo
+
+#include "textflag.h"
+
+// func gf256CPUID(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·gf256CPUID(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func gf256MulXorBlocks(tables *[32]byte, in, out []byte)
+TEXT ·gf256MulXorBlocks(SB), NOSPLIT, $0-56
+	MOVQ tables+0(FP), AX
+	MOVQ in_base+8(FP), SI
+	MOVQ in_len+16(FP), CX
+	MOVQ out_base+32(FP), DI
+
+	MOVOU (AX), X6
+	MOVOU 16(AX), X7
+	MOVQ  $0x0f0f0f0f0f0f0f0f, DX
+	MOVQ  DX, X5
+	PUNPCKLQDQ X5, X5
+
+	SHRQ $4, CX
+	JZ   done
+
+loop:
+	// X0 and X1 are the low and high nibbles of 16 bytes of in.
+	MOVOU (SI), X0
+	MOVOU X0, X1
+	PSRLQ $4, X1
+	PAND  X5, X0
+	PAND  X5, X1
+
+	MOVOU  X6, X2
+	PSHUFB X0, X2
+	MOVOU  X7, X3
+	PSHUFB X1, X3
+	PXOR   X2, X3
+
+	MOVOU (DI), X4
+	PXOR  X3, X4
+	MOVOU X4, (DI)
+
+	ADDQ $16, SI
+	ADDQ $16, DI
+	DECQ CX
+	JNZ  loop
+
+done:
+	RET
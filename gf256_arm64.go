@@ -0,0 +1,16 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego && !tinygo
+
+package shamirsplit
+
+// NEON, which provides TBL, is always available on arm64.
+const gf256HaveAsm = true
+
+// gf256MulXorBlocks sets out[i] ^= c*in[i], where tables is as described in
+// gf256MulXorSlice, for len(in) a multiple of 16.
+//
+//go:noescape
+func gf256MulXorBlocks(tables *[32]byte, in, out []byte)
@@ -0,0 +1,40 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !purego && !tinygo
+
+#include "textflag.h"
+
+// func gf256MulXorBlocks(tables *[32]byte, in, out []byte)
+TEXT ·gf256MulXorBlocks(SB), NOSPLIT, $0-56
+	MOVD tables+0(FP), R0
+	MOVD in_base+8(FP), R1
+	MOVD in_len+16(FP), R2
+	MOVD out_base+32(FP), R3
+
+	VLD1 (R0), [V6.B16, V7.B16]
+	VMOVI $15, V5.B16
+
+	LSR $4, R2, R2
+	CBZ R2, done
+
+loop:
+	// V0 and V1 are the low and high nibbles of 16 bytes of in.
+	VLD1.P 16(R1), [V0.B16]
+	VUSHR  $4, V0.B16, V1.B16
+	VAND   V5.B16, V0.B16, V0.B16
+
+	VTBL V0.B16, [V6.B16], V2.B16
+	VTBL V1.B16, [V7.B16], V3.B16
+	VEOR V2.B16, V3.B16, V3.B16
+
+	VLD1   (R3), [V4.B16]
+	VEOR   V3.B16, V4.B16, V4.B16
+	VST1.P [V4.B16], 16(R3)
+
+	SUB  $1, R2, R2
+	CBNZ R2, loop
+
+done:
+	RET
@@ -0,0 +1,13 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (!amd64 && !arm64) || purego || tinygo
+
+package shamirsplit
+
+const gf256HaveAsm = false
+
+func gf256MulXorBlocks(tables *[32]byte, in, out []byte) {
+	panic("unreachable")
+}
@@ -0,0 +1,170 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// This file implements secret sharing over GF(2^16), with the reducing
+// polynomial x^16 + x^12 + x^3 + x + 1, which allows up to 65535 shares.
+
+// gf65536Mul returns a*b.
+func gf65536Mul(a, b uint16) uint16 {
+	var r uint16
+	for i := 0; i < 16; i++ {
+		mask := -(b & 1)
+		r ^= a & mask
+		carry := -(a >> 15)
+		a = a<<1 ^ 0x100b&carry
+		b >>= 1
+	}
+	return r
+}
+
+// gf65536Inv returns the multiplicative inverse of a, or zero if a is zero.
+func gf65536Inv(a uint16) uint16 {
+	// a^(2^16 - 2) = a^-1.
+	r := a
+	for i := 0; i < 14; i++ {
+		r = gf65536Mul(r, r)
+		r = gf65536Mul(r, a)
+	}
+	return gf65536Mul(r, r)
+}
+
+// gf65536Basis returns, for each i, the Lagrange basis polynomial for xs[i]
+// evaluated at zero. The xs must be distinct and non-zero.
+func gf65536Basis(xs []uint16) []uint16 {
+	basis := make([]uint16, len(xs))
+	for i := range xs {
+		num, den := uint16(1), uint16(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			num = gf65536Mul(num, xs[j])
+			den = gf65536Mul(den, xs[j]^xs[i])
+		}
+		basis[i] = gf65536Mul(num, gf65536Inv(den))
+	}
+	return basis
+}
+
+// SplitGF65536 splits secret into n shares, any k of which can be combined by
+// JoinGF65536 to recover it. It works like SplitVaultCompatible, but each
+// two-byte word of the secret is shared over GF(2^16), allowing up to 65535
+// shares. Each share is its x coordinate, as two big-endian bytes, followed
+// by the big-endian y value for each word. If the secret has an odd length, it
+// is padded with a zero byte and each share has an extra zero byte appended to
+// record that. If rand is nil, crypto/rand.Reader is used.
+func SplitGF65536(secret []byte, k, n int, rand io.Reader) (shares [][]byte, err error) {
+	if k < 1 || n < k || n > 65535 {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+
+	rand = defaultRand(rand)
+	words := (len(secret) + 1) / 2
+	shareLen := 2 + 2*words + len(secret)%2
+
+	shares = make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, shareLen)
+		binary.BigEndian.PutUint16(shares[i], uint16(i+1))
+	}
+
+	a := make([]uint16, k)
+	buf := make([]byte, 2*(k-1))
+	for w := 0; w < words; w++ {
+		a[0] = uint16(secret[2*w]) << 8
+		if 2*w+1 < len(secret) {
+			a[0] |= uint16(secret[2*w+1])
+		}
+		if _, err = io.ReadFull(rand, buf); err != nil {
+			return nil, err
+		}
+		for j := 1; j < k; j++ {
+			a[j] = binary.BigEndian.Uint16(buf[2*(j-1):])
+		}
+
+		for i := range shares {
+			x := uint16(i + 1)
+			var y uint16
+			for j := k - 1; j >= 0; j-- {
+				y = gf65536Mul(y, x) ^ a[j]
+			}
+			binary.BigEndian.PutUint16(shares[i][2+2*w:], y)
+		}
+	}
+
+	for i := range a {
+		a[i] = 0
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	return
+}
+
+// JoinGF65536 recovers a secret from at least k shares that resulted from
+// SplitGF65536.
+func JoinGF65536(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	if len(shares) > DefaultLimits.MaxShares {
+		return nil, ErrLimitExceeded
+	}
+
+	l := len(shares[0])
+	if l < 4 {
+		return nil, errors.New("shares must be at least four bytes long")
+	}
+
+	xs := make([]uint16, len(shares))
+	seen := make(map[uint16]bool)
+	for i, share := range shares {
+		if len(share) != l {
+			return nil, errors.New("shares must all be the same length")
+		}
+		x := binary.BigEndian.Uint16(share)
+		if x == 0 {
+			return nil, errors.New("found share with zero x coordinate")
+		}
+		if seen[x] {
+			return nil, errors.New("found duplicate share")
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	basis := gf65536Basis(xs)
+	words := (l - 2) / 2
+	secret := make([]byte, 2*words)
+	for w := 0; w < words; w++ {
+		var v uint16
+		for i, share := range shares {
+			v ^= gf65536Mul(basis[i], binary.BigEndian.Uint16(share[2+2*w:]))
+		}
+		binary.BigEndian.PutUint16(secret[2*w:], v)
+	}
+
+	if (l-2)%2 == 1 {
+		// The secret had an odd length and was padded.
+		secret = secret[:len(secret)-1]
+	}
+	return secret, nil
+}
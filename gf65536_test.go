@@ -0,0 +1,48 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGF65536(t *testing.T) {
+	for _, a := range []uint16{1, 2, 3, 0x100b, 0x8000, 0xffff} {
+		if got := gf65536Mul(a, gf65536Inv(a)); got != 1 {
+			t.Errorf("%#x * %#x^-1 = %#x", a, a, got)
+		}
+	}
+}
+
+func TestSplitGF65536(t *testing.T) {
+	for _, secret := range []string{"a", "ab", "\x00odd length secret", "even length!"} {
+		shares, err := SplitGF65536([]byte(secret), 4, 1000, nil)
+		if err != nil {
+			t.Fatalf("error while splitting: %s", err)
+		}
+
+		result, err := JoinGF65536([][]byte{shares[999], shares[300], shares[0], shares[256]})
+		if err != nil {
+			t.Fatalf("failed to join shares: %s", err)
+		}
+		if !bytes.Equal(result, []byte(secret)) {
+			t.Errorf("JoinGF65536 returned %q, want %q", result, secret)
+		}
+	}
+
+	// The default limits allow every share that the field can hold.
+	shares, err := SplitGF65536([]byte("x"), 2, 65535, nil)
+	if err != nil {
+		t.Fatalf("error while splitting into 65535 shares: %s", err)
+	}
+	if result, err := JoinGF65536(shares[65533:]); err != nil || string(result) != "x" {
+		t.Errorf("JoinGF65536 returned %q, %v", result, err)
+	}
+
+	if _, err := SplitGF65536([]byte("x"), 2, 65536, nil); err == nil {
+		t.Errorf("too many shares were accepted")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import "encoding/gob"
+
+// Shares are gob encoded using their binary encoding, so that nothing, such as
+// a MAC or signature, is lost and decoding checks them in the same way. The
+// types are registered so that they can also be sent as interface values.
+
+func init() {
+	gob.Register(Share{})
+	gob.Register(ChunkedShare{})
+	gob.Register(ShareSet{})
+}
+
+// GobEncode implements gob.GobEncoder. It has a value receiver so that shares
+// held in interface values, which aren't addressable, can be encoded.
+func (s Share) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (s *Share) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// GobEncode implements gob.GobEncoder, with a value receiver like that of
+// Share.
+func (s ChunkedShare) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (s *ChunkedShare) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
@@ -0,0 +1,136 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/agl/shamirsplit/internal/edwards25519"
+)
+
+// A Group is a cyclic group of prime order with elements of type E, written
+// additively. If secret shares are over the group order, multiplying the
+// generator by each share gives shares of the public value secret*G, which
+// JoinPoints combines without learning the secret. Arguments to the methods
+// must be elements of the group.
+type Group[E any] interface {
+	// Order is the prime order of the group.
+	Order() *big.Int
+	Identity() E
+	Generator() E
+	Add(a, b E) E
+	// ScalarMult returns k*a, for k in [0, Order()).
+	ScalarMult(k *big.Int, a E) E
+}
+
+// JoinPoints combines group elements, points[i] = y_i*G where y_i is a share
+// at xs[i] of a secret s over the group order, to give s*G. This is Lagrange
+// interpolation in the exponent, as needed to assemble a public key from
+// public shares or to check partial results in threshold protocols. As with
+// JoinAt, too few or wrong points give a wrong result, not an error.
+func JoinPoints[E any](g Group[E], xs []*big.Int, points []E) (result E, err error) {
+	if len(xs) != len(points) {
+		return result, errors.New("lengths of xs and points must match")
+	}
+	if len(points) == 0 {
+		return result, errors.New("no points given")
+	}
+	order := g.Order()
+	if err := checkXs(xs, order); err != nil {
+		return result, err
+	}
+
+	result = g.Identity()
+	for i, p := range points {
+		result = g.Add(result, g.ScalarMult(lagrangeAtZero(xs, i, order), p))
+	}
+	return result, nil
+}
+
+// SchnorrGroup is the subgroup of order Q of the multiplicative group modulo
+// P, generated by G, as used by SplitVerifiable. Its elements are integers
+// mod P and the group operation, written as addition, is multiplication.
+type SchnorrGroup struct {
+	P, Q, G *big.Int
+}
+
+func (g *SchnorrGroup) Order() *big.Int     { return g.Q }
+func (g *SchnorrGroup) Identity() *big.Int  { return big.NewInt(1) }
+func (g *SchnorrGroup) Generator() *big.Int { return g.G }
+func (g *SchnorrGroup) Add(a, b *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Mul(a, b), g.P)
+}
+
+func (g *SchnorrGroup) ScalarMult(k, a *big.Int) *big.Int {
+	return new(big.Int).Exp(a, k, g.P)
+}
+
+// An Ed25519Point is an element of the prime order group of edwards25519.
+type Ed25519Point struct {
+	p edwards25519.Point
+}
+
+// NewEd25519Point decodes a point from its 32-byte encoding, as used for
+// Ed25519 public keys.
+func NewEd25519Point(b []byte) (*Ed25519Point, error) {
+	p := new(Ed25519Point)
+	if _, err := p.p.SetBytes(b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Bytes returns the 32-byte encoding of p.
+func (p *Ed25519Point) Bytes() []byte {
+	return p.p.Bytes()
+}
+
+// Equal returns true iff p and q are the same point.
+func (p *Ed25519Point) Equal(q *Ed25519Point) bool {
+	return p.p.Equal(&q.p) == 1
+}
+
+// Ed25519Group is the group of edwards25519, of order Edwards25519Order, in
+// which Ed25519 public keys are elements. Shares from SplitEd25519 are over
+// its order.
+type Ed25519Group struct{}
+
+func (Ed25519Group) Order() *big.Int { return Edwards25519Order }
+
+func (Ed25519Group) Identity() *Ed25519Point {
+	p := new(Ed25519Point)
+	p.p.Set(edwards25519.NewIdentityPoint())
+	return p
+}
+
+func (Ed25519Group) Generator() *Ed25519Point {
+	p := new(Ed25519Point)
+	p.p.Set(edwards25519.NewGeneratorPoint())
+	return p
+}
+
+func (Ed25519Group) Add(a, b *Ed25519Point) *Ed25519Point {
+	p := new(Ed25519Point)
+	p.p.Add(&a.p, &b.p)
+	return p
+}
+
+func (Ed25519Group) ScalarMult(k *big.Int, a *Ed25519Point) *Ed25519Point {
+	s, err := ed25519IntToScalar(k)
+	if err != nil {
+		panic("shamirsplit: scalar out of range")
+	}
+	p := new(Ed25519Point)
+	p.p.ScalarMult(s, &a.p)
+	return p
+}
+
+var (
+	_ Group[*big.Int]      = (*SchnorrGroup)(nil)
+	_ Group[*Ed25519Point] = Ed25519Group{}
+)
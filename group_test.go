@@ -0,0 +1,75 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"math/big"
+	"testing"
+)
+
+// testJoinPoints checks that the points of k of the shares of secret
+// interpolate to secret*G.
+func testJoinPoints[E any](t *testing.T, g Group[E], secret *big.Int, equal func(a, b E) bool) {
+	shares, err := SplitShares(secret, g.Order(), 3, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var xs []*big.Int
+	var points []E
+	for _, i := range []int{4, 0, 2} {
+		xs = append(xs, shares[i].X)
+		points = append(points, g.ScalarMult(shares[i].Y, g.Generator()))
+	}
+	got, err := JoinPoints(g, xs, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal(got, g.ScalarMult(secret, g.Generator())) {
+		t.Errorf("interpolated point isn't secret*G")
+	}
+
+	if got, _ := JoinPoints(g, xs[:2], points[:2]); equal(got, g.ScalarMult(secret, g.Generator())) {
+		t.Errorf("two points were enough")
+	}
+}
+
+func TestJoinPoints(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	schnorr := &SchnorrGroup{P: p, Q: new(big.Int).Rsh(p, 1), G: big.NewInt(4)}
+	testJoinPoints(t, schnorr, big.NewInt(42), func(a, b *big.Int) bool { return a.Cmp(b) == 0 })
+
+	secret, _ := new(big.Int).SetString("1234567890123456789012345678901234567890", 10)
+	testJoinPoints(t, Ed25519Group{}, secret, (*Ed25519Point).Equal)
+}
+
+func TestJoinPointsEd25519PublicKey(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	shares, err := SplitEd25519(priv, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each shareholder can publish their public share, from which the
+	// public key can be assembled.
+	var g Ed25519Group
+	xs := []*big.Int{shares[0].X, shares[2].X}
+	points := []*Ed25519Point{g.ScalarMult(shares[0].Y, g.Generator()), g.ScalarMult(shares[2].Y, g.Generator())}
+	got, err := JoinPoints(g, xs, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), pub) {
+		t.Errorf("assembled public key doesn't match")
+	}
+
+	if _, err := NewEd25519Point(pub); err != nil {
+		t.Errorf("failed to decode public key: %s", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// A GroupSpec describes one group in a two level split: the number of members
+// and how many of them must take part to recover the group's share.
+type GroupSpec struct {
+	Threshold, Members int
+}
+
+// A GroupShare is a member's share from SplitGroups. As in SLIP-39, each
+// share records both thresholds so that JoinGroups needs nothing else.
+type GroupShare struct {
+	// Member is a share of the group's share of the secret, and records
+	// the member's x coordinate. It isn't embedded, since its encodings
+	// would drop the group and thresholds, and the result would look like
+	// a share of the secret itself.
+	Member          Share
+	Group           int
+	GroupThreshold  int
+	MemberThreshold int
+}
+
+// SplitGroups splits secret groupThreshold-of-len(groups) across the groups,
+// and then splits each group's share among its members according to its
+// GroupSpec. The secret can be recovered by JoinGroups from the shares of
+// enough members of enough groups. The shares are returned by group. If rand
+// is nil, crypto/rand.Reader is used.
+func SplitGroups(secret, modulus *big.Int, groupThreshold int, groups []GroupSpec, rand io.Reader) ([][]GroupShare, error) {
+	rand = defaultRand(rand)
+	groupShares, err := Split(secret, modulus, groupThreshold, len(groups), rand)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([][]GroupShare, len(groups))
+	for i, g := range groups {
+		members, err := SplitShares(groupShares[i], modulus, g.Threshold, g.Members, rand)
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %s", i, err)
+		}
+		for _, m := range members {
+			shares[i] = append(shares[i], GroupShare{
+				Member:          m,
+				Group:           i,
+				GroupThreshold:  groupThreshold,
+				MemberThreshold: g.Threshold,
+			})
+		}
+	}
+	return shares, nil
+}
+
+// JoinGroups recovers the secret from any mix of member shares from
+// SplitGroups. Groups with too few members present are ignored, and an error
+// is returned if too few groups are complete.
+func JoinGroups(shares []GroupShare) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	groupThreshold := shares[0].GroupThreshold
+	modulus := shares[0].Member.Modulus
+	byGroup := make(map[int][]Share)
+	memberThresholds := make(map[int]int)
+	for _, s := range shares {
+		if s.GroupThreshold != groupThreshold || s.Member.Modulus == nil || modulus == nil || s.Member.Modulus.Cmp(modulus) != 0 {
+			return nil, errors.New("shares are from different splits")
+		}
+		if t, ok := memberThresholds[s.Group]; ok && t != s.MemberThreshold || s.Group < 0 {
+			return nil, fmt.Errorf("inconsistent shares for group %d", s.Group)
+		}
+		memberThresholds[s.Group] = s.MemberThreshold
+		byGroup[s.Group] = append(byGroup[s.Group], s.Member)
+	}
+
+	groups := make([]int, 0, len(byGroup))
+	for g := range byGroup {
+		groups = append(groups, g)
+	}
+	sort.Ints(groups)
+
+	var xs, ys []*big.Int
+	for _, g := range groups {
+		members := byGroup[g]
+		if len(members) < memberThresholds[g] {
+			continue
+		}
+		y, err := JoinShares(members[:memberThresholds[g]])
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %s", g, err)
+		}
+		xs = append(xs, big.NewInt(int64(g+1)))
+		ys = append(ys, y)
+		if len(xs) == groupThreshold {
+			return interpolate(xs, ys, modulus)
+		}
+	}
+	return nil, fmt.Errorf("only %d of the %d required groups are complete", len(xs), groupThreshold)
+}
@@ -0,0 +1,42 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding"
+	"math/big"
+	"testing"
+)
+
+func TestGroups(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, err := SplitGroups(secret, MODP2048, 2, []GroupSpec{{1, 1}, {2, 3}, {3, 5}}, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	for _, test := range []struct {
+		name  string
+		group []GroupShare
+		ok    bool
+	}{
+		{"owner and two friends", []GroupShare{shares[0][0], shares[1][2], shares[1][0]}, true},
+		{"two friends and three family", []GroupShare{shares[2][4], shares[1][1], shares[2][0], shares[1][2], shares[2][1]}, true},
+		{"owner, a friend and two family", []GroupShare{shares[0][0], shares[1][1], shares[2][0], shares[2][3]}, false},
+		{"all the family", shares[2], false},
+	} {
+		result, err := JoinGroups(test.group)
+		if ok := err == nil && result.Cmp(secret) == 0; ok != test.ok {
+			t.Errorf("%s: got %t, want %t (%v)", test.name, ok, test.ok, err)
+		}
+	}
+
+	// Encoding a GroupShare as a Share would lose its group.
+	if _, ok := any(&shares[1][0]).(encoding.BinaryMarshaler); ok {
+		t.Errorf("GroupShare has the encodings of a Share")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrEntropyFailure is returned by a reader from NewHealthCheckedReader once
+// its source has failed a health check.
+var ErrEntropyFailure = errors.New("entropy source failed health check")
+
+// The health tests are the repetition count and adaptive proportion tests of
+// NIST SP 800-90B, section 4.4, treating each byte as a sample with eight
+// bits of entropy and with a false positive rate of 2^-40 per sample.
+const (
+	// healthRepetitionCutoff is the number of identical bytes in a row
+	// that fails the repetition count test.
+	healthRepetitionCutoff = 6
+	// healthWindow is the number of bytes in each window of the adaptive
+	// proportion test, and healthProportionCutoff is the number of
+	// occurrences of its first byte that fails it.
+	healthWindow           = 512
+	healthProportionCutoff = 13
+	// healthStartup is the number of bytes read, tested and discarded
+	// before any are returned.
+	healthStartup = 1024
+	// healthBlock is the length of the prefix of each read that must
+	// differ from that of the previous read.
+	healthBlock = 16
+)
+
+type healthReader struct {
+	mu     sync.Mutex
+	rand   io.Reader
+	failed bool
+
+	started bool
+	last    byte
+	run     int
+
+	first  byte
+	count  int
+	window int
+
+	prev    [healthBlock]byte
+	hasPrev bool
+}
+
+// NewHealthCheckedReader returns an io.Reader that reads from rand, checking
+// that it isn't obviously broken: that reads succeed in full, that output
+// isn't stuck or repeated and that no byte value is overrepresented. These
+// checks only catch gross failures, since no test can show that output is
+// unpredictable. A broken source makes shares guessable, so the reader fails
+// closed: once a check fails, every read returns ErrEntropyFailure. The
+// reader is safe for concurrent use. If rand is nil, crypto/rand.Reader is
+// used.
+func NewHealthCheckedReader(rand io.Reader) io.Reader {
+	return &healthReader{rand: defaultRand(rand)}
+}
+
+func (r *healthReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.started {
+		r.started = true
+		var startup [healthStartup]byte
+		if err := r.fill(startup[:]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := r.fill(p); err != nil {
+		return 0, err
+	}
+
+	if len(p) >= healthBlock {
+		if r.hasPrev && subtle.ConstantTimeCompare(p[:healthBlock], r.prev[:]) == 1 {
+			return 0, r.fail()
+		}
+		copy(r.prev[:], p)
+		r.hasPrev = true
+	}
+	return len(p), nil
+}
+
+// fill reads len(p) bytes into p and runs the health tests on them.
+func (r *healthReader) fill(p []byte) error {
+	if r.failed {
+		return ErrEntropyFailure
+	}
+	if _, err := io.ReadFull(r.rand, p); err != nil {
+		return r.fail()
+	}
+
+	// The tests branch on the output, so could leak a little about it
+	// through timing, but only in the rare case of a repeated byte.
+	for _, b := range p {
+		if r.run > 0 && b == r.last {
+			r.run++
+		} else {
+			r.last, r.run = b, 1
+		}
+		if r.run >= healthRepetitionCutoff {
+			return r.fail()
+		}
+
+		if r.window == 0 {
+			r.first, r.count = b, 0
+		}
+		if b == r.first {
+			r.count++
+		}
+		if r.count >= healthProportionCutoff {
+			return r.fail()
+		}
+		r.window = (r.window + 1) % healthWindow
+	}
+	return nil
+}
+
+func (r *healthReader) fail() error {
+	r.failed = true
+	return ErrEntropyFailure
+}
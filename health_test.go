@@ -0,0 +1,67 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// repeatReader returns the same bytes for every read.
+type repeatReader []byte
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r[i%len(r)]
+	}
+	return len(p), nil
+}
+
+func TestHealthCheckedReader(t *testing.T) {
+	r := NewHealthCheckedReader(nil)
+	buf := make([]byte, 1<<16)
+	for i := 0; i < 16; i++ {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			t.Fatalf("read from crypto/rand failed: %s", err)
+		}
+	}
+
+	var pattern [256]byte
+	for i := range pattern {
+		pattern[i] = byte(i)
+	}
+
+	tests := []struct {
+		name string
+		rand io.Reader
+	}{
+		{"zeros", bytes.NewReader(make([]byte, 1<<20))},
+		{"stuck", repeatReader{0x5a}},
+		{"short", io.LimitReader(rand.Reader, 100)},
+		{"failing", io.MultiReader(io.LimitReader(rand.Reader, 1100), iotest.ErrReader(errors.New("device failure")))},
+		// Each read returns the same bytes, although they have no
+		// runs and are evenly distributed.
+		{"repeated", repeatReader(pattern[:])},
+	}
+	for _, test := range tests {
+		r := NewHealthCheckedReader(test.rand)
+		var err error
+		for i := 0; i < 4 && err == nil; i++ {
+			_, err = r.Read(make([]byte, 32))
+		}
+		if err != ErrEntropyFailure {
+			t.Errorf("%s: got %v, want ErrEntropyFailure", test.name, err)
+			continue
+		}
+		// Failures are permanent.
+		if _, err := r.Read(make([]byte, 1)); err != ErrEntropyFailure {
+			t.Errorf("%s: read after failure gave %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// A HierarchicalShare is a share from SplitHierarchical: the value at X of
+// the Derivative'th derivative of a polynomial of degree Threshold-1.
+type HierarchicalShare struct {
+	// Point holds X, Y and the modulus. It isn't embedded, since its
+	// encodings would drop Derivative and Threshold, and the result would
+	// look like an ordinary share that JoinShares would misinterpret: all
+	// three fields must be kept.
+	Point      Share
+	Derivative int
+	Threshold  int
+}
+
+// SplitHierarchical implements Tassa's conjunctive hierarchical threshold
+// scheme. There are len(thresholds) levels, the first being the most senior,
+// with counts[i] participants at level i. The secret can be recovered by any
+// group that, for each i, includes at least thresholds[i] participants from
+// levels 0 to i, so thresholds must be increasing. For example, thresholds of
+// {2, 3} require at least two participants from the first level and at least
+// three in total.
+//
+// Participants at level i receive the value of the thresholds[i-1]'th
+// derivative of the polynomial, and reconstruction is by Birkhoff
+// interpolation. The modulus should be large, since the scheme is only
+// guaranteed to work when it's much larger than the number of participants.
+// If rand is nil, crypto/rand.Reader is used.
+func SplitHierarchical(secret, modulus *big.Int, thresholds, counts []int, rand io.Reader) ([][]HierarchicalShare, error) {
+	if len(thresholds) == 0 || len(thresholds) != len(counts) {
+		return nil, errors.New("lengths of thresholds and counts must match")
+	}
+	total := 0
+	for i, k := range thresholds {
+		if k < 1 || i > 0 && k <= thresholds[i-1] || counts[i] < 0 {
+			return nil, errors.New("thresholds must be positive and increasing")
+		}
+		total += counts[i]
+	}
+	k := thresholds[len(thresholds)-1]
+	if total < k {
+		return nil, errors.New("too few participants to meet the threshold")
+	}
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+
+	a, err := randomPolynomial(secret, modulus, k, defaultRand(rand))
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([][]HierarchicalShare, len(counts))
+	x := int64(1)
+	for level, n := range counts {
+		derivative := 0
+		if level > 0 {
+			derivative = thresholds[level-1]
+		}
+		for j := 0; j < n; j++ {
+			row := birkhoffRow(big.NewInt(x), derivative, k, modulus)
+			y := new(big.Int)
+			for i := range row {
+				y.Add(y, row[i].Mul(row[i], a[i]))
+			}
+			shares[level] = append(shares[level], HierarchicalShare{
+				Point:      Share{X: big.NewInt(x), Y: y.Mod(y, modulus), Modulus: modulus},
+				Derivative: derivative,
+				Threshold:  k,
+			})
+			x++
+		}
+	}
+	return shares, nil
+}
+
+// JoinHierarchical recovers the secret from shares that resulted from
+// SplitHierarchical and satisfy its thresholds. If they don't, an error is
+// returned.
+func JoinHierarchical(shares []HierarchicalShare) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+	modulus, k := shares[0].Point.Modulus, shares[0].Threshold
+	if modulus == nil || k < 1 {
+		return nil, errors.New("shares don't record their modulus and threshold")
+	}
+
+	// Build the system of equations for the coefficients.
+	rows := make([][]*big.Int, len(shares))
+	for i, s := range shares {
+		p := &s.Point
+		if p.Modulus == nil || p.Modulus.Cmp(modulus) != 0 || s.Threshold != k {
+			return nil, errors.New("shares are from different splits")
+		}
+		if p.X == nil || p.Y == nil || s.Derivative < 0 || s.Derivative >= k {
+			return nil, errors.New("invalid share")
+		}
+		rows[i] = append(birkhoffRow(p.X, s.Derivative, k, modulus), new(big.Int).Set(p.Y))
+	}
+
+	a, err := solveLinear(rows, k, modulus)
+	if err != nil {
+		return nil, err
+	}
+	return a[0], nil
+}
+
+// birkhoffRow returns the coefficients of a_0, ..., a_{k-1} in the value at x
+// of the d'th derivative of the polynomial with those coefficients.
+func birkhoffRow(x *big.Int, d, k int, modulus *big.Int) []*big.Int {
+	row := make([]*big.Int, k)
+	for j := range row {
+		row[j] = new(big.Int)
+		if j < d {
+			continue
+		}
+		// j!/(j-d)! * x^(j-d)
+		row[j].Exp(x, big.NewInt(int64(j-d)), modulus)
+		for i := j - d + 1; i <= j; i++ {
+			row[j].Mul(row[j], big.NewInt(int64(i)))
+		}
+		row[j].Mod(row[j], modulus)
+	}
+	return row
+}
+
+// solveLinear solves the system of equations given by rows, each of which
+// holds the coefficients of n unknowns followed by the constant, modulo a
+// prime, by Gaussian elimination. It fails if the solution isn't unique or if
+// the equations are inconsistent.
+func solveLinear(rows [][]*big.Int, n int, modulus *big.Int) ([]*big.Int, error) {
+	r := 0
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for i := r; i < len(rows); i++ {
+			if rows[i][col].Sign() != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, errors.New("shares don't satisfy the access structure")
+		}
+		rows[r], rows[pivot] = rows[pivot], rows[r]
+
+		inv := new(big.Int).ModInverse(rows[r][col], modulus)
+		if inv == nil {
+			return nil, errors.New("modulus is not prime")
+		}
+		for j := col; j <= n; j++ {
+			rows[r][j].Mul(rows[r][j], inv)
+			rows[r][j].Mod(rows[r][j], modulus)
+		}
+
+		for i := range rows {
+			if i == r || rows[i][col].Sign() == 0 {
+				continue
+			}
+			f := new(big.Int).Set(rows[i][col])
+			for j := col; j <= n; j++ {
+				t := new(big.Int).Mul(f, rows[r][j])
+				rows[i][j].Sub(rows[i][j], t)
+				rows[i][j].Mod(rows[i][j], modulus)
+			}
+		}
+		r++
+	}
+
+	for i := r; i < len(rows); i++ {
+		if rows[i][n].Sign() != 0 {
+			return nil, errors.New("shares are inconsistent: at least one is corrupt")
+		}
+	}
+
+	solution := make([]*big.Int, n)
+	for i := range solution {
+		solution[i] = rows[i][n]
+	}
+	return solution, nil
+}
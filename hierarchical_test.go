@@ -0,0 +1,51 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding"
+	"math/big"
+	"testing"
+)
+
+func TestHierarchical(t *testing.T) {
+	secret := big.NewInt(42)
+	// At least two executives and at least three participants in total.
+	shares, err := SplitHierarchical(secret, MODP2048, []int{2, 3}, []int{3, 4}, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	execs, staff := shares[0], shares[1]
+
+	for _, test := range []struct {
+		name  string
+		group []HierarchicalShare
+		ok    bool
+	}{
+		{"two executives and one staff", []HierarchicalShare{execs[0], execs[2], staff[1]}, true},
+		{"three executives", []HierarchicalShare{execs[0], execs[1], execs[2]}, true},
+		{"one executive and three staff", []HierarchicalShare{execs[1], staff[0], staff[1], staff[2]}, false},
+		{"two executives", []HierarchicalShare{execs[0], execs[1]}, false},
+		{"all", append(append([]HierarchicalShare(nil), execs...), staff...), true},
+	} {
+		result, err := JoinHierarchical(test.group)
+		if ok := err == nil && result.Cmp(secret) == 0; ok != test.ok {
+			t.Errorf("%s: got %t, want %t (%v)", test.name, ok, test.ok, err)
+		}
+	}
+
+	corrupt := append(append([]HierarchicalShare(nil), execs...), staff[0])
+	corrupt[3].Point.Y = new(big.Int).Add(corrupt[3].Point.Y, big.NewInt(1))
+	if _, err := JoinHierarchical(corrupt); err == nil {
+		t.Errorf("inconsistent shares weren't detected")
+	}
+
+	// Encoding a HierarchicalShare as a Share would lose its derivative.
+	if _, ok := any(&staff[0]).(encoding.BinaryMarshaler); ok {
+		t.Errorf("HierarchicalShare has the encodings of a Share")
+	}
+}
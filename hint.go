@@ -0,0 +1,53 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// MaxHintLen is the maximum length in bytes of a share's hint.
+const MaxHintLen = 256
+
+// HintShares sets the Hint of shares[i] to hints[i], which may be empty for
+// no hint. Hints must be valid UTF-8 and no longer than MaxHintLen.
+//
+// Unlike participant labels, hints are outside of the MAC and signature, so
+// they can be set, or changed by a shareholder, after the shares have been
+// authenticated or signed. They're carried by every encoding of a share
+// other than the fixed-size compact one and, as they aren't treated as
+// secret, they shouldn't say anything about the secret.
+func HintShares(shares []Share, hints []string) error {
+	if len(hints) != len(shares) {
+		return errors.New("need one hint per share")
+	}
+	for _, hint := range hints {
+		if err := checkHint(hint); err != nil {
+			return err
+		}
+	}
+	for i := range shares {
+		shares[i].Hint = hints[i]
+	}
+	return nil
+}
+
+// marshalWithoutHint returns the binary encoding of s without its hint, for
+// digests that mustn't change when the hint does.
+func marshalWithoutHint(s *Share) ([]byte, error) {
+	t := *s
+	t.Hint = ""
+	return t.MarshalBinary()
+}
+
+func checkHint(hint string) error {
+	if len(hint) > MaxHintLen || !utf8.ValidString(hint) {
+		return errors.New("invalid hint")
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestHints(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	hints := []string{"stored with lawyer", "", "give to sibling"}
+	set, err := Deal(big.NewInt(7), 3, WithThreshold(2), WithField(modulus), WithHints(hints), WithMAC(), WithRand(rand.Reader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	if err := SignShares(set.Shares, key); err != nil {
+		t.Fatal(err)
+	}
+	root, proofs, err := MerkleCommit(set.Shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transcript, err := NewTranscript(set, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hints can be changed after the shares are authenticated.
+	s := set.Shares[2]
+	s.Hint = "safe-deposit box 2"
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Share
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("share with a changed hint: %s", err)
+	}
+	if got.Hint != "safe-deposit box 2" {
+		t.Errorf("got hint %q", got.Hint)
+	}
+	if err := got.VerifyDealer(key.Public().(ed25519.PublicKey)); err != nil {
+		t.Errorf("signature of share with a changed hint: %s", err)
+	}
+	if err := VerifyMerkleProof(root, &got, &proofs[2]); err != nil {
+		t.Errorf("Merkle proof of share with a changed hint: %s", err)
+	}
+	if !transcript.Contains(&got) {
+		t.Error("transcript doesn't contain the share with a changed hint")
+	}
+	if d, err := Describe(data); err != nil || !strings.Contains(d.String(), `hint="safe-deposit box 2"`) {
+		t.Errorf("description %v (%v) lacks the hint", d, err)
+	}
+
+	armored, err := Armor(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := Dearmor(armored); err != nil || s.Hint != got.Hint {
+		t.Errorf("armor lost the hint: %q, %v", s.Hint, err)
+	}
+	uri, err := EncodeShareURI(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := ParseShareURI(uri); err != nil || s.Hint != got.Hint {
+		t.Errorf("URI lost the hint: %q, %v", s.Hint, err)
+	}
+	// The protobuf encoding can't carry the MAC or signature.
+	plain := got
+	plain.MACKey, plain.DealerKey, plain.Signature = nil, nil, nil
+	pb, err := plain.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromProto Share
+	if err := fromProto.UnmarshalProto(pb); err != nil || fromProto.Hint != got.Hint {
+		t.Errorf("protobuf lost the hint: %q, %v", fromProto.Hint, err)
+	}
+
+	secret, err := JoinShares([]Share{set.Shares[0], got})
+	if err != nil || secret.Int64() != 7 {
+		t.Errorf("JoinShares gave %v, %v", secret, err)
+	}
+
+	if err := HintShares(set.Shares, hints[:2]); err == nil {
+		t.Error("too few hints were accepted")
+	}
+	if err := HintShares(set.Shares, []string{"", strings.Repeat("x", MaxHintLen+1), ""}); err == nil {
+		t.Error("overlong hint was accepted")
+	}
+	s.Hint = "\xff"
+	if _, err := s.MarshalBinary(); err == nil {
+		t.Error("invalid UTF-8 hint was marshaled")
+	}
+}
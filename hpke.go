@@ -0,0 +1,67 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ecdh"
+	"crypto/hpke"
+	"errors"
+)
+
+// Shares are encrypted with HPKE using DHKEM(X25519, HKDF-SHA256),
+// HKDF-SHA256 and ChaCha20-Poly1305.
+const hpkeInfo = "shamirsplit share"
+
+// EncryptShares encrypts the binary encoding of shares[i] to recipients[i],
+// which must be X25519 public keys, so that the resulting ciphertexts can be
+// sent over untrusted channels. Each recipient recovers their share with
+// DecryptShare.
+func EncryptShares(shares []Share, recipients []*ecdh.PublicKey) ([][]byte, error) {
+	if len(shares) != len(recipients) {
+		return nil, errors.New("lengths of shares and recipients must match")
+	}
+
+	ciphertexts := make([][]byte, len(shares))
+	for i := range shares {
+		if recipients[i].Curve() != ecdh.X25519() {
+			return nil, errors.New("recipient key is not an X25519 key")
+		}
+		pub, err := hpke.NewDHKEMPublicKey(recipients[i])
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := shares[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ciphertexts[i], err = hpke.Seal(pub, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), []byte(hpkeInfo), data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ciphertexts, nil
+}
+
+// DecryptShare decrypts a ciphertext from EncryptShares with the recipient's
+// X25519 private key and parses the share within.
+func DecryptShare(ciphertext []byte, key *ecdh.PrivateKey) (s Share, err error) {
+	if key.Curve() != ecdh.X25519() {
+		return s, errors.New("key is not an X25519 key")
+	}
+	priv, err := hpke.NewDHKEMPrivateKey(key)
+	if err != nil {
+		return
+	}
+
+	data, err := hpke.Open(priv, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), []byte(hpkeInfo), ciphertext)
+	if err != nil {
+		return s, errors.New("failed to decrypt share")
+	}
+	err = s.UnmarshalBinary(data)
+	return
+}
@@ -0,0 +1,53 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestEncryptShares(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, _ := SplitShares(secret, MODP2048, 2, 3, nil)
+
+	keys := make([]*ecdh.PrivateKey, len(shares))
+	pubs := make([]*ecdh.PublicKey, len(shares))
+	for i := range keys {
+		keys[i], _ = ecdh.X25519().GenerateKey(rand.Reader)
+		pubs[i] = keys[i].PublicKey()
+	}
+
+	ciphertexts, err := EncryptShares(shares, pubs)
+	if err != nil {
+		t.Fatalf("failed to encrypt shares: %s", err)
+	}
+
+	var decrypted []Share
+	for i, c := range ciphertexts {
+		if _, err := DecryptShare(c, keys[(i+1)%len(keys)]); err == nil {
+			t.Errorf("share %d decrypted with the wrong key", i)
+		}
+		s, err := DecryptShare(c, keys[i])
+		if err != nil {
+			t.Fatalf("failed to decrypt share %d: %s", i, err)
+		}
+		decrypted = append(decrypted, s)
+	}
+
+	result, err := JoinShares(decrypted[1:])
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to join decrypted shares: %v", err)
+	}
+
+	p256Key, _ := ecdh.P256().GenerateKey(rand.Reader)
+	if _, err := EncryptShares(shares[:1], []*ecdh.PublicKey{p256Key.PublicKey()}); err == nil {
+		t.Errorf("encrypted to a P-256 key")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// This file lets shares be dealt directly into hardware, such as HSM or
+// smartcard slots. This package doesn't speak PKCS#11 itself: the caller
+// provides adapters, typically wrapping C_GenerateRandom and C_CreateObject
+// on a session with each token.
+
+// A RandomGenerator is a source of randomness, such as an HSM's
+// C_GenerateRandom.
+type RandomGenerator interface {
+	GenerateRandom(length int) ([]byte, error)
+}
+
+// generatorReader adapts a RandomGenerator to an io.Reader.
+type generatorReader struct {
+	g RandomGenerator
+}
+
+// NewGeneratorReader returns an io.Reader that reads from g, for use as the
+// rand argument of the functions in this package.
+func NewGeneratorReader(g RandomGenerator) io.Reader {
+	return generatorReader{g}
+}
+
+func (r generatorReader) Read(p []byte) (int, error) {
+	b, err := r.g.GenerateRandom(len(p))
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != len(p) {
+		return 0, errors.New("random generator returned the wrong length")
+	}
+	copy(p, b)
+	clear(b)
+	return len(p), nil
+}
+
+// A ShareSlot holds a single share, for example as a secret object in an HSM
+// or smartcard slot.
+type ShareSlot interface {
+	// ImportShare stores the binary encoding of a share. encoded is
+	// zeroed once ImportShare returns, so it must be copied, ideally
+	// straight into the device, rather than retained.
+	ImportShare(encoded []byte) error
+}
+
+// DealToSlots splits secret, like Deal, into a share for each of slots, any
+// k of which can be combined to recover it. Each share is computed, encoded
+// and imported in turn, and its encoding is zeroed immediately afterwards,
+// so that only one share is held in host memory at a time. The shares carry
+// a random set ID and the threshold.
+//
+// If any import fails, the remaining slots are still tried and a
+// *DistributionError is returned; as with Distribute, the dealing must then
+// be treated as undelivered. If rand is nil, crypto/rand.Reader is used; use
+// NewGeneratorReader to take the randomness from a device.
+func DealToSlots(secret, modulus *big.Int, k int, slots []ShareSlot, rand io.Reader) error {
+	n := len(slots)
+	if k < 1 || n < k {
+		return errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return err
+	}
+	if modulus.Cmp(big.NewInt(int64(n))) <= 0 {
+		return errors.New("modulus is too small for the number of shares")
+	}
+	rand = defaultRand(rand)
+	d, err := NewDealer(secret, modulus, k, rand)
+	if err != nil {
+		return err
+	}
+	m, err := newSetMetadata(k, rand)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, a := range d.coefficients[1:] {
+			clear(a.Bits())
+		}
+	}()
+
+	errs := make([]error, n)
+	failed := false
+	for i, slot := range slots {
+		s, err := d.Share(big.NewInt(int64(i + 1)))
+		if err != nil {
+			return err
+		}
+		s.Metadata = m
+		encoded, err := s.MarshalBinary()
+		clear(s.Y.Bits())
+		if err != nil {
+			return err
+		}
+		err = slot.ImportShare(encoded)
+		clear(encoded)
+		if err != nil {
+			errs[i] = err
+			failed = true
+		}
+	}
+	if failed {
+		return &DistributionError{Errs: errs}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// testToken is a RandomGenerator and ShareSlot.
+type testToken struct {
+	stored []byte
+	full   bool
+}
+
+func (t *testToken) GenerateRandom(length int) ([]byte, error) {
+	b := make([]byte, length)
+	_, err := rand.Read(b)
+	return b, err
+}
+
+func (t *testToken) ImportShare(encoded []byte) error {
+	if t.full {
+		return errors.New("slot is full")
+	}
+	t.stored = bytes.Clone(encoded)
+	return nil
+}
+
+func TestDealToSlots(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(1234)
+	tokens := make([]*testToken, 4)
+	slots := make([]ShareSlot, len(tokens))
+	for i := range tokens {
+		tokens[i] = new(testToken)
+		slots[i] = tokens[i]
+	}
+
+	if err := DealToSlots(secret, modulus, 3, slots, NewGeneratorReader(tokens[0])); err != nil {
+		t.Fatal(err)
+	}
+
+	shares := make([]Share, 3)
+	for i := range shares {
+		if err := shares[i].UnmarshalBinary(tokens[i+1].stored); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := JoinShares(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("got %s, want %s", result, secret)
+	}
+
+	tokens[2].full = true
+	err = DealToSlots(secret, modulus, 3, slots, nil)
+	var derr *DistributionError
+	if !errors.As(err, &derr) || derr.Errs[2] == nil || derr.Errs[3] != nil {
+		t.Errorf("dealing to a full slot gave %v", err)
+	}
+}
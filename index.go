@@ -0,0 +1,66 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+)
+
+// A ShareIndex is the zero based number of a share from Split, as used by
+// Join: the share with index i is the value at i+1. Valid indices are made
+// by NewShareIndices, so that mistakes such as one-based numbering are caught
+// before they give a wrong secret.
+type ShareIndex struct {
+	i int
+}
+
+// NewShareIndices checks that numbers are distinct valid share numbers for a
+// split into n shares over modulus, that is, in [0, n) with each number plus
+// one less than modulus, and returns them as ShareIndex values.
+func NewShareIndices(numbers []int, n int, modulus *big.Int) ([]ShareIndex, error) {
+	if modulus.Cmp(big.NewInt(int64(n))) <= 0 {
+		return nil, errors.New("modulus is too small for the number of shares")
+	}
+
+	indices := make([]ShareIndex, len(numbers))
+	seen := make(map[int]bool)
+	for i, number := range numbers {
+		if number < 0 || number >= n {
+			return nil, errors.New("share number out of range")
+		}
+		if seen[number] {
+			return nil, errors.New("found duplicate share number")
+		}
+		seen[number] = true
+		indices[i] = ShareIndex{number}
+	}
+	return indices, nil
+}
+
+// Int returns the zero based share number.
+func (i ShareIndex) Int() int {
+	return i.i
+}
+
+// X returns the x coordinate of the share.
+func (i ShareIndex) X() *big.Int {
+	return big.NewInt(int64(i.i) + 1)
+}
+
+// JoinIndexed is like Join, but takes the share numbers as ShareIndex values
+// from NewShareIndices.
+func JoinIndexed(shares []*big.Int, indices []ShareIndex, modulus *big.Int) (*big.Int, error) {
+	if len(shares) != len(indices) {
+		return nil, errors.New("lengths of shares and indices must match")
+	}
+	xs := make([]*big.Int, len(indices))
+	for i, index := range indices {
+		xs[i] = index.X()
+	}
+	return JoinAt(shares, xs, modulus)
+}
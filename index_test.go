@@ -0,0 +1,49 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestShareIndices(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, err := Split(secret, P256Order, 3, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	indices, err := NewShareIndices([]int{4, 0, 2}, 5, P256Order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indices[0].Int() != 4 || indices[0].X().Int64() != 5 {
+		t.Errorf("index 4 has number %d and x %s", indices[0].Int(), indices[0].X())
+	}
+	result, err := JoinIndexed([]*big.Int{shares[4], shares[0], shares[2]}, indices, P256Order)
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("JoinIndexed gave %v, %v", result, err)
+	}
+
+	bad := []struct {
+		numbers []int
+		n       int
+		modulus *big.Int
+	}{
+		// One-based numbering.
+		{[]int{1, 5}, 5, P256Order},
+		{[]int{-1}, 5, P256Order},
+		{[]int{2, 2}, 5, P256Order},
+		{[]int{0}, 7, big.NewInt(7)},
+	}
+	for _, test := range bad {
+		if _, err := NewShareIndices(test.numbers, test.n, test.modulus); err == nil {
+			t.Errorf("NewShareIndices(%v, %d, %s) succeeded", test.numbers, test.n, test.modulus)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package argon2 implements the Argon2id password hash (RFC 9106), which the
+// standard library lacks.
+package argon2
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	version    = 0x13
+	argon2id   = 2
+	syncPoints = 4
+)
+
+type block [128]uint64
+
+// IDKey derives a key of keyLen bytes from password and salt using Argon2id
+// with the given number of passes over memory KiB of memory, computed with
+// threads lanes.
+func IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return deriveKey(password, salt, nil, nil, time, memory, threads, keyLen)
+}
+
+func deriveKey(password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	if time < 1 || threads < 1 || keyLen < 4 {
+		panic("argon2: invalid parameters")
+	}
+	lanes := uint32(threads)
+
+	h0 := initHash(password, salt, secret, data, time, memory, lanes, keyLen)
+	memory = memory / (syncPoints * lanes) * (syncPoints * lanes)
+	if memory < 2*syncPoints*lanes {
+		memory = 2 * syncPoints * lanes
+	}
+
+	B := initBlocks(h0, memory, lanes)
+	processBlocks(B, time, memory, lanes)
+
+	// The final block is the XOR of the last block of each lane.
+	laneLen := memory / lanes
+	var c block
+	for lane := uint32(0); lane < lanes; lane++ {
+		for i, v := range B[lane*laneLen+laneLen-1] {
+			c[i] ^= v
+		}
+	}
+	var cb [1024]byte
+	for i, v := range c {
+		binary.LittleEndian.PutUint64(cb[8*i:], v)
+	}
+	return hashLong(keyLen, cb[:])
+}
+
+func initHash(password, salt, secret, data []byte, time, memory, lanes, keyLen uint32) []byte {
+	h := newBlake2b(64)
+	var params [24]byte
+	for i, v := range []uint32{lanes, keyLen, memory, time, version, argon2id} {
+		binary.LittleEndian.PutUint32(params[4*i:], v)
+	}
+	h.Write(params[:])
+	for _, b := range [][]byte{password, salt, secret, data} {
+		var l [4]byte
+		binary.LittleEndian.PutUint32(l[:], uint32(len(b)))
+		h.Write(l[:])
+		h.Write(b)
+	}
+	return h.sum(nil)
+}
+
+// hashLong is the variable length hash function H' of RFC 9106.
+func hashLong(outLen uint32, in ...[]byte) []byte {
+	var l [4]byte
+	binary.LittleEndian.PutUint32(l[:], outLen)
+
+	if outLen <= 64 {
+		h := newBlake2b(int(outLen))
+		h.Write(l[:])
+		for _, b := range in {
+			h.Write(b)
+		}
+		return h.sum(nil)
+	}
+
+	h := newBlake2b(64)
+	h.Write(l[:])
+	for _, b := range in {
+		h.Write(b)
+	}
+	v := h.sum(nil)
+
+	out := make([]byte, 0, outLen)
+	for uint32(len(out))+64 < outLen {
+		out = append(out, v[:32]...)
+		h = newBlake2b(64)
+		h.Write(v)
+		v = h.sum(nil)
+	}
+	if rest := outLen - uint32(len(out)); rest < 64 {
+		h = newBlake2b(int(rest))
+		h.Write(v)
+		v = h.sum(nil)
+	}
+	return append(out, v...)
+}
+
+func initBlocks(h0 []byte, memory, lanes uint32) []block {
+	B := make([]block, memory)
+	laneLen := memory / lanes
+	for lane := uint32(0); lane < lanes; lane++ {
+		for i := uint32(0); i < 2; i++ {
+			var suffix [8]byte
+			binary.LittleEndian.PutUint32(suffix[:], i)
+			binary.LittleEndian.PutUint32(suffix[4:], lane)
+			b := hashLong(1024, h0, suffix[:])
+			for j := range B[lane*laneLen+i] {
+				B[lane*laneLen+i][j] = binary.LittleEndian.Uint64(b[8*j:])
+			}
+		}
+	}
+	return B
+}
+
+func processBlocks(B []block, time, memory, lanes uint32) {
+	laneLen := memory / lanes
+	segLen := laneLen / syncPoints
+
+	for pass := uint32(0); pass < time; pass++ {
+		for slice := uint32(0); slice < syncPoints; slice++ {
+			for lane := uint32(0); lane < lanes; lane++ {
+				processSegment(B, pass, slice, lane, time, memory, lanes, laneLen, segLen)
+			}
+		}
+	}
+}
+
+func processSegment(B []block, pass, slice, lane, time, memory, lanes, laneLen, segLen uint32) {
+	// Argon2id uses data-independent addressing for the first half of
+	// the first pass.
+	independent := pass == 0 && slice < syncPoints/2
+
+	var addresses, in, zero block
+	in[0], in[1], in[2] = uint64(pass), uint64(lane), uint64(slice)
+	in[3], in[4], in[5] = uint64(memory), uint64(time), argon2id
+
+	index := uint32(0)
+	if pass == 0 && slice == 0 {
+		// The first two blocks of each lane were set by initBlocks.
+		index = 2
+		if independent {
+			nextAddresses(&addresses, &in, &zero)
+		}
+	}
+
+	offset := lane*laneLen + slice*segLen + index
+	for ; index < segLen; index, offset = index+1, offset+1 {
+		prev := offset - 1
+		if index == 0 && slice == 0 {
+			prev += laneLen
+		}
+
+		var rand uint64
+		if independent {
+			if index%128 == 0 {
+				nextAddresses(&addresses, &in, &zero)
+			}
+			rand = addresses[index%128]
+		} else {
+			rand = B[prev][0]
+		}
+
+		ref := refIndex(rand, pass, slice, lane, index, lanes, laneLen, segLen)
+		compress(&B[offset], &B[prev], &B[ref])
+	}
+}
+
+func nextAddresses(addresses, in, zero *block) {
+	in[6]++
+	*addresses = block{}
+	compress(addresses, zero, in)
+	t := *addresses
+	*addresses = block{}
+	compress(addresses, zero, &t)
+}
+
+// refIndex returns the index of the reference block for the block at index in
+// the given segment.
+func refIndex(rand uint64, pass, slice, lane, index, lanes, laneLen, segLen uint32) uint32 {
+	refLane := uint32(rand>>32) % lanes
+	if pass == 0 && slice == 0 {
+		refLane = lane
+	}
+
+	// m is the number of blocks that can be referenced, which end at
+	// position s.
+	m, s := 3*segLen, ((slice+1)%syncPoints)*segLen
+	if lane == refLane {
+		m += index
+	}
+	if pass == 0 {
+		m, s = slice*segLen, 0
+		if slice == 0 || lane == refLane {
+			m += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		m--
+	}
+
+	p := rand & 0xffffffff
+	p = (p * p) >> 32
+	p = (p * uint64(m)) >> 32
+	return refLane*laneLen + uint32((uint64(s)+uint64(m)-(p+1))%uint64(laneLen))
+}
+
+// compress sets out ^= G(x, y).
+func compress(out, x, y *block) {
+	var r block
+	for i := range r {
+		r[i] = x[i] ^ y[i]
+	}
+	z := r
+	for i := 0; i < 8; i++ {
+		permute(&z, 16*i, 16*i+1, 16*i+2, 16*i+3, 16*i+4, 16*i+5, 16*i+6, 16*i+7,
+			16*i+8, 16*i+9, 16*i+10, 16*i+11, 16*i+12, 16*i+13, 16*i+14, 16*i+15)
+	}
+	for i := 0; i < 8; i++ {
+		permute(&z, 2*i, 2*i+1, 2*i+16, 2*i+17, 2*i+32, 2*i+33, 2*i+48, 2*i+49,
+			2*i+64, 2*i+65, 2*i+80, 2*i+81, 2*i+96, 2*i+97, 2*i+112, 2*i+113)
+	}
+	for i := range out {
+		out[i] ^= z[i] ^ r[i]
+	}
+}
+
+// permute applies the Argon2 permutation P to the given words of b.
+func permute(b *block, i0, i1, i2, i3, i4, i5, i6, i7, i8, i9, i10, i11, i12, i13, i14, i15 int) {
+	gb := func(a, b, c, d *uint64) {
+		*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+		*d = bits.RotateLeft64(*d^*a, -32)
+		*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+		*b = bits.RotateLeft64(*b^*c, -24)
+		*a += *b + 2*uint64(uint32(*a))*uint64(uint32(*b))
+		*d = bits.RotateLeft64(*d^*a, -16)
+		*c += *d + 2*uint64(uint32(*c))*uint64(uint32(*d))
+		*b = bits.RotateLeft64(*b^*c, -63)
+	}
+	gb(&b[i0], &b[i4], &b[i8], &b[i12])
+	gb(&b[i1], &b[i5], &b[i9], &b[i13])
+	gb(&b[i2], &b[i6], &b[i10], &b[i14])
+	gb(&b[i3], &b[i7], &b[i11], &b[i15])
+	gb(&b[i0], &b[i5], &b[i10], &b[i15])
+	gb(&b[i1], &b[i6], &b[i11], &b[i12])
+	gb(&b[i2], &b[i7], &b[i8], &b[i13])
+	gb(&b[i3], &b[i4], &b[i9], &b[i14])
+}
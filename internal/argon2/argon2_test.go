@@ -0,0 +1,35 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package argon2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestBlake2b(t *testing.T) {
+	h := newBlake2b(64)
+	h.Write([]byte("abc"))
+	got := hex.EncodeToString(h.sum(nil))
+	want := "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"
+	if got != want {
+		t.Errorf("BLAKE2b-512(abc) = %s, want %s", got, want)
+	}
+}
+
+// TestRFC9106 checks the Argon2id test vector from RFC 9106, section 5.3.
+func TestRFC9106(t *testing.T) {
+	password := bytes.Repeat([]byte{1}, 32)
+	salt := bytes.Repeat([]byte{2}, 16)
+	secret := bytes.Repeat([]byte{3}, 8)
+	data := bytes.Repeat([]byte{4}, 12)
+
+	got := hex.EncodeToString(deriveKey(password, salt, secret, data, 3, 32, 4, 32))
+	want := "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bcryptpbkdf implements bcrypt_pbkdf, the password-based key
+// derivation function that OpenSSH uses to encrypt private keys, which the
+// standard library lacks.
+package bcryptpbkdf
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+)
+
+// hashSize is the size of the output of bcryptHash.
+const hashSize = 32
+
+// Key derives a key of keyLen bytes from password and salt with the given
+// number of rounds, as bcrypt_pbkdf in OpenBSD does.
+func Key(password, salt []byte, rounds, keyLen int) ([]byte, error) {
+	if rounds < 1 {
+		return nil, errors.New("bcryptpbkdf: number of rounds is too small")
+	}
+	if len(password) == 0 {
+		return nil, errors.New("bcryptpbkdf: empty password")
+	}
+	if len(salt) == 0 || len(salt) > 1<<20 {
+		return nil, errors.New("bcryptpbkdf: bad salt length")
+	}
+	if keyLen < 1 || keyLen > hashSize*hashSize {
+		return nil, errors.New("bcryptpbkdf: bad key length")
+	}
+
+	// Each block of output is spread across the key with a stride, so
+	// that an attacker must compute every block to learn any part of it.
+	stride := (keyLen + hashSize - 1) / hashSize
+	key := make([]byte, keyLen)
+
+	sha2Pass := sha512.Sum512(password)
+	countSalt := make([]byte, len(salt)+4)
+	copy(countSalt, salt)
+	var out, tmp [hashSize]byte
+	for count := 1; count <= stride; count++ {
+		binary.BigEndian.PutUint32(countSalt[len(salt):], uint32(count))
+		sha2Salt := sha512.Sum512(countSalt)
+		bcryptHash(&tmp, &sha2Pass, &sha2Salt)
+		out = tmp
+		for i := 1; i < rounds; i++ {
+			sha2Salt = sha512.Sum512(tmp[:])
+			bcryptHash(&tmp, &sha2Pass, &sha2Salt)
+			for j := range out {
+				out[j] ^= tmp[j]
+			}
+		}
+		for i := range out {
+			dest := i*stride + count - 1
+			if dest >= keyLen {
+				break
+			}
+			key[dest] = out[i]
+		}
+	}
+	return key, nil
+}
+
+// bcryptHash is bcrypt_pbkdf's variant of bcrypt, which encrypts a fixed
+// string with Blowfish keyed by the hashed password and salt.
+func bcryptHash(out *[hashSize]byte, sha2Pass, sha2Salt *[sha512.Size]byte) {
+	c := newBlowfish()
+	c.expand(sha2Pass[:], sha2Salt[:])
+	for i := 0; i < 64; i++ {
+		c.expand(sha2Salt[:], nil)
+		c.expand(sha2Pass[:], nil)
+	}
+
+	var data [hashSize / 4]uint32
+	magic := []byte("OxychromaticBlowfishSwatDynamite")
+	for i := range data {
+		data[i] = binary.BigEndian.Uint32(magic[4*i:])
+	}
+	for i := 0; i < 64; i++ {
+		for j := 0; j < len(data); j += 2 {
+			data[j], data[j+1] = c.encrypt(data[j], data[j+1])
+		}
+	}
+	for i, w := range data {
+		binary.LittleEndian.PutUint32(out[4*i:], w)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bcryptpbkdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestBlowfish checks test vectors for Blowfish from Eric Young's set.
+func TestBlowfish(t *testing.T) {
+	vectors := []struct{ key, plaintext, ciphertext string }{
+		{"0000000000000000", "0000000000000000", "4ef997456198dd78"},
+		{"ffffffffffffffff", "ffffffffffffffff", "51866fd5b85ecb8a"},
+		{"3000000000000000", "1000000000000001", "7d856f9a613063f2"},
+	}
+	for _, v := range vectors {
+		key, _ := hex.DecodeString(v.key)
+		plaintext, _ := hex.DecodeString(v.plaintext)
+		c := newBlowfish()
+		c.expand(key, nil)
+		j := 0
+		l, r := c.encrypt(streamWord(plaintext, &j), streamWord(plaintext, &j))
+		got := hex.EncodeToString([]byte{byte(l >> 24), byte(l >> 16), byte(l >> 8), byte(l), byte(r >> 24), byte(r >> 16), byte(r >> 8), byte(r)})
+		if got != v.ciphertext {
+			t.Errorf("Blowfish(%s, %s) = %s, want %s", v.key, v.plaintext, got, v.ciphertext)
+		}
+	}
+}
+
+// TestKey checks the key and IV that decrypt an aes128-ctr key file written
+// by ssh-keygen from OpenSSH 9.2 with four rounds.
+func TestKey(t *testing.T) {
+	salt, _ := hex.DecodeString("315a7895d694f013130957aa5f0caf90")
+	key, err := Key([]byte("correct horse"), salt, 4, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := hex.EncodeToString(key)
+	want := "54348345b1ed8590d0da46ba4e32bd222562eb2a8b6cb3e336c230e932970595"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// Each byte of a shorter key is the same as in a longer one with the
+	// same stride.
+	short, err := Key([]byte("correct horse"), salt, 4, 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(short, key[:17]) {
+		t.Errorf("17-byte key isn't a prefix of the 32-byte key")
+	}
+
+	if _, err := Key(nil, salt, 4, 32); err == nil {
+		t.Error("empty password was accepted")
+	}
+	if _, err := Key([]byte("correct horse"), salt, 0, 32); err == nil {
+		t.Error("zero rounds were accepted")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bcryptpbkdf
+
+// blowfish is the state of the Blowfish cipher, with the expensive key
+// schedule of bcrypt.
+type blowfish struct {
+	p              [18]uint32
+	s0, s1, s2, s3 [256]uint32
+}
+
+func newBlowfish() *blowfish {
+	return &blowfish{p: initialP, s0: initialS0, s1: initialS1, s2: initialS2, s3: initialS3}
+}
+
+func (c *blowfish) f(x uint32) uint32 {
+	return ((c.s0[x>>24] + c.s1[x>>16&0xff]) ^ c.s2[x>>8&0xff]) + c.s3[x&0xff]
+}
+
+// encrypt enciphers the block l, r.
+func (c *blowfish) encrypt(l, r uint32) (uint32, uint32) {
+	l ^= c.p[0]
+	for i := 1; i < 17; i += 2 {
+		r ^= c.f(l) ^ c.p[i]
+		l ^= c.f(r) ^ c.p[i+1]
+	}
+	return r ^ c.p[17], l
+}
+
+// streamWord returns the next big-endian word of b, starting at *j and
+// wrapping around to the start of b as needed.
+func streamWord(b []byte, j *int) uint32 {
+	var w uint32
+	for i := 0; i < 4; i++ {
+		if *j >= len(b) {
+			*j = 0
+		}
+		w = w<<8 | uint32(b[*j])
+		*j++
+	}
+	return w
+}
+
+// expand mixes key into the P-array and then replaces the P-array and
+// S-boxes with successive encryptions, each of which first XORs in the next
+// words of data, if any. This is Blowfish's key schedule when data is
+// empty, and bcrypt's salted key schedule otherwise.
+func (c *blowfish) expand(key, data []byte) {
+	j := 0
+	for i := range c.p {
+		c.p[i] ^= streamWord(key, &j)
+	}
+
+	j = 0
+	var l, r uint32
+	next := func(dst []uint32) {
+		for i := 0; i < len(dst); i += 2 {
+			if len(data) > 0 {
+				l ^= streamWord(data, &j)
+				r ^= streamWord(data, &j)
+			}
+			l, r = c.encrypt(l, r)
+			dst[i], dst[i+1] = l, r
+		}
+	}
+	next(c.p[:])
+	next(c.s0[:])
+	next(c.s1[:])
+	next(c.s2[:])
+	next(c.s3[:])
+}
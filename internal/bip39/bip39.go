@@ -0,0 +1,25 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bip39 holds the wordlists of BIP-39, as published in the
+// bitcoin/bips repository, one word per line.
+package bip39
+
+import (
+	"embed"
+	"strings"
+)
+
+//go:embed *.txt
+var lists embed.FS
+
+// Words returns the words of the list for language, such as "english" or
+// "chinese_simplified", in order, or nil if there's no such list.
+func Words(language string) []string {
+	data, err := lists.ReadFile(language + ".txt")
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(data))
+}
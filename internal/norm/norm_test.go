@@ -0,0 +1,22 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package norm
+
+import "testing"
+
+func TestNFKD(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"café", "café"},
+		{"café", "café"},
+		{"ﬁ", "fi"},
+		{"①", "1"},
+		{"Ångström", "Ångström"},
+	}
+	for _, test := range tests {
+		if got := NFKD.String(test.in); got != test.want {
+			t.Errorf("NFKD(%+q) = %+q, want %+q", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/base64"
+	"errors"
+)
+
+// A ShareStore keeps shares under names, for example a custodian's share in
+// local storage.
+type ShareStore interface {
+	Save(name string, s *Share) error
+	// Load returns ErrShareNotFound if there's no share with the name.
+	Load(name string) (Share, error)
+	Delete(name string) error
+}
+
+// ErrShareNotFound is returned by a ShareStore for a name it doesn't hold.
+var ErrShareNotFound = errors.New("share not found")
+
+// A KeyringStore is a ShareStore that keeps shares in the operating system's
+// credential store: the Keychain on macOS, via the security command; the
+// Secret Service, such as GNOME Keyring, on other Unix systems, via
+// libsecret's secret-tool command; and the Credential Manager on Windows.
+// Each share is stored, in its binary encoding, as the password of a generic
+// item for the service and with the name as the account.
+type KeyringStore struct {
+	// Service identifies the application, for example "com.example.vault".
+	Service string
+}
+
+var _ ShareStore = (*KeyringStore)(nil)
+
+// Save implements ShareStore, replacing any share with the same name.
+func (k *KeyringStore) Save(name string, s *Share) error {
+	if err := k.check(name); err != nil {
+		return err
+	}
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	secret := base64.StdEncoding.EncodeToString(data)
+	clear(data)
+	return keyringSet(k.Service, name, secret)
+}
+
+// Load implements ShareStore.
+func (k *KeyringStore) Load(name string) (s Share, err error) {
+	if err = k.check(name); err != nil {
+		return
+	}
+	secret, err := keyringGet(k.Service, name)
+	if err != nil {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return s, errors.New("keyring item isn't a share")
+	}
+	err = s.UnmarshalBinary(data)
+	clear(data)
+	return
+}
+
+// Delete implements ShareStore.
+func (k *KeyringStore) Delete(name string) error {
+	if err := k.check(name); err != nil {
+		return err
+	}
+	return keyringDelete(k.Service, name)
+}
+
+// check returns an error unless the service and name can be used as keyring
+// attributes.
+func (k *KeyringStore) check(name string) error {
+	for _, s := range []string{k.Service, name} {
+		if len(s) == 0 {
+			return errors.New("service and name must not be empty")
+		}
+		for _, c := range s {
+			if c < ' ' || c == '"' || c == '\\' || c == 0x7f {
+				return errors.New("service and name must not contain control characters, quotes or backslashes")
+			}
+		}
+	}
+	return nil
+}
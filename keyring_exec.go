@@ -0,0 +1,82 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// runKeyringCommand runs a command with the given standard input and
+// returns its standard output. It's a variable so that tests can replace it.
+var runKeyringCommand = func(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", &keyringError{name, strings.TrimSpace(stderr.String())}
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// A keyringError is a failure reported by a keyring command.
+type keyringError struct {
+	command, stderr string
+}
+
+func (e *keyringError) Error() string {
+	return e.command + " failed: " + e.stderr
+}
+
+// The secret is never passed as an argument, where other users could see
+// it. On macOS, the security command reads the command to add the item from
+// its standard input.
+
+func keyringSet(service, account, secret string) error {
+	if runtime.GOOS == "darwin" {
+		_, err := runKeyringCommand("add-generic-password -U -s \""+service+"\" -a \""+account+"\" -w \""+secret+"\"\n",
+			"security", "-i")
+		return err
+	}
+	_, err := runKeyringCommand(secret, "secret-tool", "store", "--label="+service+" "+account,
+		"service", service, "account", account)
+	return err
+}
+
+func keyringGet(service, account string) (string, error) {
+	var out string
+	var err error
+	if runtime.GOOS == "darwin" {
+		out, err = runKeyringCommand("", "security", "find-generic-password", "-s", service, "-a", account, "-w")
+	} else {
+		out, err = runKeyringCommand("", "secret-tool", "lookup", "service", service, "account", account)
+	}
+	var kerr *keyringError
+	if errors.As(err, &kerr) || (err == nil && len(out) == 0) {
+		// Both commands fail, without a distinctive message, if
+		// there's no such item.
+		return "", ErrShareNotFound
+	}
+	return strings.TrimSpace(out), err
+}
+
+func keyringDelete(service, account string) error {
+	var err error
+	if runtime.GOOS == "darwin" {
+		_, err = runKeyringCommand("", "security", "delete-generic-password", "-s", service, "-a", account)
+	} else {
+		_, err = runKeyringCommand("", "secret-tool", "clear", "service", service, "account", account)
+	}
+	return err
+}
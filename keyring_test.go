@@ -0,0 +1,74 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows && !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestKeyringStore(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("fake keyring only models secret-tool")
+	}
+
+	// A fake secret-tool, keyed by its attributes.
+	items := make(map[string]string)
+	saved := runKeyringCommand
+	defer func() { runKeyringCommand = saved }()
+	runKeyringCommand = func(stdin string, name string, args ...string) (string, error) {
+		if name != "secret-tool" {
+			t.Fatalf("unexpected command %s", name)
+		}
+		switch args[0] {
+		case "store":
+			items[strings.Join(args[2:], " ")] = stdin
+			return "", nil
+		case "lookup":
+			item, ok := items[strings.Join(args[1:], " ")]
+			if !ok {
+				return "", &keyringError{name, ""}
+			}
+			return item, nil
+		case "clear":
+			delete(items, strings.Join(args[1:], " "))
+			return "", nil
+		}
+		t.Fatalf("unexpected arguments %q", args)
+		return "", nil
+	}
+
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitShares(big.NewInt(3), modulus, 2, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store ShareStore = &KeyringStore{Service: "test"}
+	if err := store.Save("alice", &shares[0]); err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.Load("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.X.Cmp(shares[0].X) != 0 || s.Y.Cmp(shares[0].Y) != 0 {
+		t.Error("loaded share differs")
+	}
+
+	if err := store.Delete("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("alice"); err != ErrShareNotFound {
+		t.Errorf("loading a deleted share gave %v", err)
+	}
+	if err := store.Save("bad\"name", &shares[0]); err == nil {
+		t.Error("name with a quote was accepted")
+	}
+}
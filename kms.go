@@ -0,0 +1,213 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// A KMS blob, sealed by a KMSWrapper, uses the encoding of binary shares (see
+// wire.go) with its own magic and tags. The share is encrypted with
+// AES-256-GCM under a random data key, with the wrapper's name as
+// additional data, and the data key is encrypted by the KMS.
+const kmsMagic = "SHMK"
+
+const (
+	tagKMSWrappedKey = 1
+	tagKMSCiphertext = 2
+)
+
+// A ShareWrapper protects shares with an external key, for SealShare, and
+// recovers them, for UnsealShare and JoinSealedShares.
+type ShareWrapper interface {
+	Sealer
+	Unsealer
+}
+
+// A KMSWrapper is a ShareWrapper that envelope-encrypts shares under a key
+// held by a key management service. Giving each share a key in a different
+// cloud account, with WrapShares, means that each is protected by different
+// access controls.
+type KMSWrapper struct {
+	name               string
+	wrapKey, unwrapKey func(key []byte) ([]byte, error)
+}
+
+// NewKMSWrapper returns a KMSWrapper with the given name, which is recorded
+// in sealed envelopes and must identify the key. The data key for each
+// share is encrypted by wrapKey and decrypted by unwrapKey, which will
+// typically call a KMS through its SDK.
+func NewKMSWrapper(name string, wrapKey, unwrapKey func(key []byte) ([]byte, error)) *KMSWrapper {
+	return &KMSWrapper{name: name, wrapKey: wrapKey, unwrapKey: unwrapKey}
+}
+
+// Name implements Sealer and Unsealer.
+func (w *KMSWrapper) Name() string {
+	return w.name
+}
+
+// Seal implements Sealer.
+func (w *KMSWrapper) Seal(data []byte) ([]byte, error) {
+	key := make([]byte, storageKeyLen)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	wrapped, err := w.wrapKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) == 0 {
+		return nil, errors.New("KMS returned an empty wrapped key")
+	}
+	var r wireRecords
+	r.add(tagKMSWrappedKey, wrapped)
+	r.add(tagKMSCiphertext, storageAEAD(key).Seal(nil, make([]byte, 12), data, []byte(w.name)))
+	return r.marshalAs(kmsMagic), nil
+}
+
+// Unseal implements Unsealer.
+func (w *KMSWrapper) Unseal(blob []byte) ([]byte, error) {
+	var wrapped, ciphertext []byte
+	err := parseWireAs(kmsMagic, blob, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagKMSWrappedKey:
+			wrapped = value
+		case tagKMSCiphertext:
+			ciphertext = value
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) == 0 || ciphertext == nil {
+		return nil, errors.New("truncated KMS blob")
+	}
+
+	key, err := w.unwrapKey(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	if len(key) != storageKeyLen {
+		return nil, errors.New("KMS returned a data key of the wrong length")
+	}
+	data, err := storageAEAD(key).Open(nil, make([]byte, 12), ciphertext, []byte(w.name))
+	if err != nil {
+		return nil, errors.New("KMS blob is corrupt")
+	}
+	return data, nil
+}
+
+// WrapShares seals each of shares with the corresponding wrapper, which must
+// all have different names, and returns the envelopes.
+func WrapShares(shares []Share, wrappers []ShareWrapper) ([][]byte, error) {
+	if len(wrappers) != len(shares) {
+		return nil, errors.New("need one wrapper per share")
+	}
+	names := make(map[string]bool)
+	for _, w := range wrappers {
+		if names[w.Name()] {
+			return nil, errors.New("found duplicate wrapper " + w.Name())
+		}
+		names[w.Name()] = true
+	}
+
+	envelopes := make([][]byte, len(shares))
+	for i := range shares {
+		var err error
+		if envelopes[i], err = SealShare(&shares[i], wrappers[i]); err != nil {
+			return nil, err
+		}
+	}
+	return envelopes, nil
+}
+
+// The adapters for cloud KMSes run the providers' command-line tools, which
+// must be installed and logged in, so that no SDKs are needed. Keys are
+// passed on standard input, never as arguments, where other users could see
+// them.
+
+// runKMSCommand runs a command with the given standard input and returns its
+// standard output. It's a variable so that tests can replace it.
+var runKMSCommand = func(stdin []byte, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil, errors.New(name + " failed: " + strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// runKMSCommandBase64 is like runKMSCommand, but decodes its output from
+// base64.
+func runKMSCommandBase64(stdin []byte, name string, args ...string) ([]byte, error) {
+	out, err := runKMSCommand(stdin, name, args...)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+}
+
+// NewAWSKMSWrapper returns a KMSWrapper for the AWS KMS key with the given ID
+// or ARN, using the aws command.
+func NewAWSKMSWrapper(keyID string) *KMSWrapper {
+	return NewKMSWrapper("aws-kms:"+keyID,
+		func(key []byte) ([]byte, error) {
+			return runKMSCommandBase64(key, "aws", "kms", "encrypt", "--key-id", keyID,
+				"--plaintext", "fileb:///dev/stdin", "--output", "text", "--query", "CiphertextBlob")
+		},
+		func(wrapped []byte) ([]byte, error) {
+			return runKMSCommandBase64(wrapped, "aws", "kms", "decrypt", "--key-id", keyID,
+				"--ciphertext-blob", "fileb:///dev/stdin", "--output", "text", "--query", "Plaintext")
+		})
+}
+
+// NewGCPKMSWrapper returns a KMSWrapper for the Cloud KMS key with the given
+// resource name, such as
+// "projects/p/locations/global/keyRings/r/cryptoKeys/k", using the gcloud
+// command.
+func NewGCPKMSWrapper(keyName string) *KMSWrapper {
+	return NewKMSWrapper("gcp-kms:"+keyName,
+		func(key []byte) ([]byte, error) {
+			return runKMSCommand(key, "gcloud", "kms", "encrypt", "--key", keyName,
+				"--plaintext-file", "-", "--ciphertext-file", "-")
+		},
+		func(wrapped []byte) ([]byte, error) {
+			return runKMSCommand(wrapped, "gcloud", "kms", "decrypt", "--key", keyName,
+				"--ciphertext-file", "-", "--plaintext-file", "-")
+		})
+}
+
+// NewAzureKeyVaultWrapper returns a KMSWrapper for the Azure Key Vault RSA key
+// with the given ID, such as "https://v.vault.azure.net/keys/k", using the
+// az command and RSA-OAEP-256.
+func NewAzureKeyVaultWrapper(keyID string) *KMSWrapper {
+	run := func(op string, value []byte) ([]byte, error) {
+		return runKMSCommandBase64([]byte(base64.StdEncoding.EncodeToString(value)), "az", "keyvault", "key", op,
+			"--id", keyID, "--algorithm", "RSA-OAEP-256", "--data-type", "base64",
+			"--value", "@/dev/stdin", "--query", "result", "--output", "tsv")
+	}
+	return NewKMSWrapper("azure-keyvault:"+keyID,
+		func(key []byte) ([]byte, error) { return run("encrypt", key) },
+		func(wrapped []byte) ([]byte, error) { return run("decrypt", wrapped) })
+}
@@ -0,0 +1,97 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func xorBytes(b []byte, key byte) []byte {
+	out := bytes.Clone(b)
+	for i := range out {
+		out[i] ^= key
+	}
+	return out
+}
+
+func TestKMSWrapper(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, _ := SplitShares(secret, P256Order, 2, 3, nil)
+
+	wrappers := make([]ShareWrapper, len(shares))
+	for i := range wrappers {
+		key := byte(i + 1)
+		xor := func(b []byte) ([]byte, error) { return xorBytes(b, key), nil }
+		wrappers[i] = NewKMSWrapper("test-kms:"+string(rune('a'+i)), xor, xor)
+	}
+	envelopes, err := WrapShares(shares, wrappers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsealers := []Unsealer{wrappers[0], wrappers[1], wrappers[2]}
+	if got, err := JoinSealedShares([][]byte{envelopes[2], envelopes[0]}, unsealers...); err != nil || got.Cmp(secret) != 0 {
+		t.Errorf("got %v, %v, want %v", got, err, secret)
+	}
+
+	envelopes[1][len(envelopes[1])-1] ^= 1
+	if _, err := UnsealShare(envelopes[1], unsealers...); err == nil {
+		t.Error("corrupt envelope was unsealed")
+	}
+	if _, err := WrapShares(shares, []ShareWrapper{wrappers[0], wrappers[1], wrappers[0]}); err == nil {
+		t.Error("duplicate wrappers were accepted")
+	}
+}
+
+func TestCloudKMSWrappers(t *testing.T) {
+	saved := runKMSCommand
+	defer func() { runKMSCommand = saved }()
+	// Each command XORs its input with 0x5c, and so decrypts what it
+	// encrypts.
+	var commands []string
+	runKMSCommand = func(stdin []byte, name string, args ...string) ([]byte, error) {
+		commands = append(commands, name)
+		switch name {
+		case "aws":
+			return []byte(base64.StdEncoding.EncodeToString(xorBytes(stdin, 0x5c)) + "\n"), nil
+		case "gcloud":
+			return xorBytes(stdin, 0x5c), nil
+		case "az":
+			value, err := base64.StdEncoding.DecodeString(string(stdin))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return []byte(base64.StdEncoding.EncodeToString(xorBytes(value, 0x5c)) + "\n"), nil
+		}
+		t.Fatalf("unexpected command %s", name)
+		return nil, nil
+	}
+
+	shares, _ := SplitShares(big.NewInt(42), P256Order, 2, 3, nil)
+	wrappers := []ShareWrapper{
+		NewAWSKMSWrapper("arn:aws:kms:us-east-1:111122223333:key/k"),
+		NewGCPKMSWrapper("projects/p/locations/global/keyRings/r/cryptoKeys/k"),
+		NewAzureKeyVaultWrapper("https://v.vault.azure.net/keys/k"),
+	}
+	envelopes, err := WrapShares(shares, wrappers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, envelope := range envelopes {
+		s, err := UnsealShare(envelope, wrappers[0], wrappers[1], wrappers[2])
+		if err != nil || s.Y.Cmp(shares[i].Y) != 0 {
+			t.Errorf("share %d: got %v, %v", i, s.Y, err)
+		}
+	}
+	want := "aws gcloud az aws gcloud az"
+	if got := strings.Join(commands, " "); got != want {
+		t.Errorf("ran %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KubernetesSecretKey is the key, in the data of a Secret written by
+// KubernetesSecrets, that holds the share.
+const KubernetesSecretKey = "share"
+
+// KubernetesOptions controls the manifests written by KubernetesSecrets.
+type KubernetesOptions struct {
+	// Name is the prefix of the names of the Secrets: the share with x
+	// coordinate 3 is in the Secret Name-3. It must be a DNS subdomain
+	// name, as Kubernetes requires.
+	Name string
+	// Namespace, if not empty, is the namespace of the Secrets.
+	Namespace string
+	// Labels are added to each Secret, in addition to labels recording
+	// the set ID, where the shares have one, and the x coordinate.
+	Labels map[string]string
+	// SealingKey, if not nil, is the public key of a Bitnami Sealed
+	// Secrets controller. SealedSecret manifests, with the share
+	// encrypted to that key with strict scope, are written instead of
+	// Secrets, so that they can be committed to a repository. Namespace
+	// must then be set.
+	SealingKey *rsa.PublicKey
+	// Rand is used for sealing. If nil, crypto/rand.Reader is used.
+	Rand io.Reader
+}
+
+// KubernetesSecrets returns a YAML manifest for each share: a Secret of type
+// Opaque with the binary encoding of the share under KubernetesSecretKey, or
+// a SealedSecret if opts.SealingKey is set. The manifests can be joined with
+// "---" lines into a single file.
+func KubernetesSecrets(shares []Share, opts *KubernetesOptions) ([][]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+	if !isDNSSubdomain(opts.Name) {
+		return nil, errors.New("invalid Secret name")
+	}
+	if opts.Namespace != "" && !isDNSSubdomain(opts.Namespace) {
+		return nil, errors.New("invalid namespace")
+	}
+	if opts.SealingKey != nil && opts.Namespace == "" {
+		return nil, errors.New("sealed secrets require a namespace")
+	}
+	for key := range opts.Labels {
+		if strings.HasPrefix(key, "shamirsplit/") {
+			return nil, errors.New("label " + strconv.Quote(key) + " is reserved")
+		}
+	}
+
+	manifests := make([][]byte, len(shares))
+	for i := range shares {
+		s := &shares[i]
+		data, err := s.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		x := s.X.String()
+		name := opts.Name + "-" + x
+		if len(name) > 253 {
+			return nil, errors.New("Secret name is too long")
+		}
+
+		labels := make(map[string]string, len(opts.Labels)+2)
+		for key, value := range opts.Labels {
+			labels[key] = value
+		}
+		if id, ok := s.SetID(); ok {
+			labels["shamirsplit/set-id"] = hex.EncodeToString(id[:])
+		}
+		if len(x) <= 63 {
+			labels["shamirsplit/x"] = x
+		}
+
+		var b strings.Builder
+		value := data
+		if opts.SealingKey != nil {
+			value, err = sealForKubernetes(data, opts.SealingKey, opts.Namespace+"/"+name, defaultRand(opts.Rand))
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString("apiVersion: bitnami.com/v1alpha1\nkind: SealedSecret\n")
+			writeKubernetesMetadata(&b, "", name, opts.Namespace, nil)
+			b.WriteString("spec:\n  encryptedData:\n    " + KubernetesSecretKey + ": " +
+				base64.StdEncoding.EncodeToString(value) + "\n  template:\n")
+			writeKubernetesMetadata(&b, "    ", name, opts.Namespace, labels)
+			b.WriteString("    type: Opaque\n")
+		} else {
+			b.WriteString("apiVersion: v1\nkind: Secret\n")
+			writeKubernetesMetadata(&b, "", name, opts.Namespace, labels)
+			b.WriteString("type: Opaque\ndata:\n  " + KubernetesSecretKey + ": " +
+				base64.StdEncoding.EncodeToString(value) + "\n")
+		}
+		clear(data)
+		manifests[i] = []byte(b.String())
+	}
+	return manifests, nil
+}
+
+// writeKubernetesMetadata writes an object's metadata, indented by indent.
+// Label values are written as double-quoted strings, which YAML parses
+// like Go's quoted strings.
+func writeKubernetesMetadata(b *strings.Builder, indent, name, namespace string, labels map[string]string) {
+	b.WriteString(indent + "metadata:\n" + indent + "  name: " + name + "\n")
+	if namespace != "" {
+		b.WriteString(indent + "  namespace: " + namespace + "\n")
+	}
+	if len(labels) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	b.WriteString(indent + "  labels:\n")
+	for _, key := range keys {
+		b.WriteString(indent + "    " + strconv.Quote(key) + ": " + strconv.Quote(labels[key]) + "\n")
+	}
+}
+
+// sealForKubernetes encrypts data as the Sealed Secrets controller expects:
+// a random AES-256 key is encrypted with RSA-OAEP, using SHA-256 and the
+// scope as the label, and prefixed, with its length as two big-endian bytes,
+// to the data sealed under that key with AES-GCM and a zero nonce.
+func sealForKubernetes(data []byte, pub *rsa.PublicKey, scope string, rand io.Reader) ([]byte, error) {
+	key := make([]byte, 32)
+	defer clear(key)
+	if _, err := io.ReadFull(rand, key); err != nil {
+		return nil, err
+	}
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand, pub, key, []byte(scope))
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(wrapped)))
+	out = append(out, wrapped...)
+	return aead.Seal(out, make([]byte, aead.NonceSize()), data, nil), nil
+}
+
+// isDNSSubdomain reports whether s is a DNS subdomain name as defined by
+// RFC 1123 and used for the names of Kubernetes objects.
+func isDNSSubdomain(s string) bool {
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// kubernetesData returns the base64 value of the share key in a manifest.
+func kubernetesData(t *testing.T, manifest []byte) []byte {
+	for _, line := range strings.Split(string(manifest), "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), KubernetesSecretKey+": "); ok {
+			data, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return data
+		}
+	}
+	t.Fatalf("no share in manifest:\n%s", manifest)
+	return nil
+}
+
+func TestKubernetesSecrets(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(1234)
+	shares, err := SplitShares(secret, modulus, 2, 3, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &KubernetesOptions{Name: "vault-unseal", Namespace: "vault", Labels: map[string]string{"team": "platform"}}
+	manifests, err := KubernetesSecrets(shares, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 3 {
+		t.Fatalf("got %d manifests", len(manifests))
+	}
+	m := string(manifests[1])
+	for _, want := range []string{"kind: Secret\n", "  name: vault-unseal-2\n", "  namespace: vault\n", "    \"team\": \"platform\"\n", "    \"shamirsplit/x\": \"2\"\n"} {
+		if !strings.Contains(m, want) {
+			t.Errorf("manifest lacks %q:\n%s", want, m)
+		}
+	}
+
+	var got []Share
+	for _, manifest := range manifests[:2] {
+		var s Share
+		if err := s.UnmarshalBinary(kubernetesData(t, manifest)); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	if v, err := JoinShares(got); err != nil || v.Cmp(secret) != 0 {
+		t.Errorf("got %v, %v from Secrets", v, err)
+	}
+
+	for _, bad := range []*KubernetesOptions{
+		{Name: "Vault"},
+		{Name: "vault", Namespace: "-x"},
+		{Name: "vault", Labels: map[string]string{"shamirsplit/x": "1"}},
+	} {
+		if _, err := KubernetesSecrets(shares, bad); err == nil {
+			t.Errorf("%+v was accepted", bad)
+		}
+	}
+}
+
+func TestKubernetesSealedSecrets(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitShares(big.NewInt(1234), modulus, 2, 2, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &KubernetesOptions{Name: "vault-unseal", SealingKey: &priv.PublicKey}
+	if _, err := KubernetesSecrets(shares, opts); err == nil {
+		t.Error("sealing without a namespace was accepted")
+	}
+	opts.Namespace = "vault"
+	manifests, err := KubernetesSecrets(shares, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(manifests[0]), "kind: SealedSecret\n") {
+		t.Errorf("not a SealedSecret:\n%s", manifests[0])
+	}
+
+	// Unseal as the controller does.
+	ct := kubernetesData(t, manifests[0])
+	n := int(binary.BigEndian.Uint16(ct))
+	key, err := rsa.DecryptOAEP(sha256.New(), nil, priv, ct[2:2+n], []byte("vault/vault-unseal-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	data, err := aead.Open(nil, make([]byte, aead.NonceSize()), ct[2+n:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Share
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if s.Y.Cmp(shares[0].Y) != 0 {
+		t.Error("unsealed share differs")
+	}
+}
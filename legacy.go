@@ -0,0 +1,70 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// ImportLegacyShares wraps shares from Split, with the share numbers that
+// would be given to Join, as self-describing Shares in a ShareSet, so that
+// deployments that store raw values can move to the binary encoding without
+// dealing afresh. k is the threshold, or zero if it's unknown.
+//
+// The shares are given metadata with a random set ID, so all the shares of a
+// dealing that are to be combined later must be imported together. If rand
+// is nil, crypto/rand.Reader is used.
+func ImportLegacyShares(shares []*big.Int, shareNumbers []int, modulus *big.Int, k int, rand io.Reader) (*ShareSet, error) {
+	if len(shares) != len(shareNumbers) {
+		return nil, errors.New("lengths of shares and shareNumbers must match")
+	}
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+	if k < 0 {
+		return nil, errors.New("invalid threshold")
+	}
+
+	xs := make([]*big.Int, len(shares))
+	for i, number := range shareNumbers {
+		if number < 0 {
+			return nil, errors.New("found negative share number")
+		}
+		xs[i] = big.NewInt(int64(number + 1))
+	}
+	if err := checkXs(xs, modulus); err != nil {
+		return nil, err
+	}
+
+	m, err := newSetMetadata(k, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ShareSet{Modulus: modulus, Threshold: k, Shares: make([]Share, len(shares))}
+	for i, y := range shares {
+		if y == nil || y.Sign() < 0 || y.Cmp(modulus) >= 0 {
+			return nil, errors.New("share is out of range")
+		}
+		set.Shares[i] = Share{X: xs[i], Y: new(big.Int).Set(y), Modulus: modulus, Metadata: m}
+	}
+	return set, nil
+}
+
+// MarshalShares returns the binary encoding of each share in s.
+func (s *ShareSet) MarshalShares() ([][]byte, error) {
+	encoded := make([][]byte, len(s.Shares))
+	for i := range s.Shares {
+		var err error
+		if encoded[i], err = s.Shares[i].MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	return encoded, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestImportLegacyShares(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(123)
+	ys, err := Split(secret, modulus, 3, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := ImportLegacyShares([]*big.Int{ys[4], ys[1], ys[2]}, []int{4, 1, 2}, modulus, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := set.MarshalShares()
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := make([]Share, len(encoded))
+	for i := range encoded {
+		if err := shares[i].UnmarshalBinary(encoded[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := JoinShares(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("got %s, want %s", result, secret)
+	}
+	if _, err := JoinShares(shares[:2]); err == nil {
+		t.Error("imported threshold wasn't enforced")
+	}
+
+	if _, err := ImportLegacyShares(ys[:2], []int{0, 0}, modulus, 0, nil); err == nil {
+		t.Error("duplicate share numbers were accepted")
+	}
+	if _, err := ImportLegacyShares([]*big.Int{modulus}, []int{0}, modulus, 0, nil); err == nil {
+		t.Error("out of range share was accepted")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import "errors"
+
+// Limits bound the threshold and number of shares that are accepted, since
+// the work, and memory, needed to split and join grow with their product.
+// Services that split or join on behalf of others should keep them low.
+type Limits struct {
+	// MaxThreshold is the largest k accepted when splitting.
+	MaxThreshold int
+	// MaxShares is the largest number of shares that will be produced
+	// by a split or accepted by a join.
+	MaxShares int
+}
+
+// DefaultLimits are the limits applied to splitting and joining. They allow
+// the 65535 shares that SplitGF65536 can make, and can be raised, before use,
+// by programs that need larger splits.
+var DefaultLimits = Limits{MaxThreshold: 1024, MaxShares: 65535}
+
+// ErrLimitExceeded results from a split or join that is larger than the
+// limits allow.
+var ErrLimitExceeded = errors.New("threshold or number of shares exceeds limits")
+
+// check returns ErrLimitExceeded if a split with threshold k and n shares is
+// larger than l allows.
+func (l *Limits) check(k, n int) error {
+	if k > l.MaxThreshold || n > l.MaxShares {
+		return ErrLimitExceeded
+	}
+	return nil
+}
+
+// checkLimits is like check, but uses DefaultLimits.
+func checkLimits(k, n int) error {
+	return DefaultLimits.check(k, n)
+}
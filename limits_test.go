@@ -0,0 +1,31 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import "testing"
+
+func TestLimits(t *testing.T) {
+	if _, err := SplitUint64(1, 3, 1<<30, nil); err != ErrLimitExceeded {
+		t.Errorf("huge split gave %v, want ErrLimitExceeded", err)
+	}
+	if _, err := SplitUint64(1, 1<<20, 1<<20, nil); err != ErrLimitExceeded {
+		t.Errorf("huge threshold gave %v, want ErrLimitExceeded", err)
+	}
+
+	saved := DefaultLimits
+	defer func() { DefaultLimits = saved }()
+	DefaultLimits.MaxShares = 4
+
+	shares, err := SplitUint64(1, 2, 4, nil)
+	if err != nil {
+		t.Fatalf("split within limits failed: %s", err)
+	}
+	if _, err := SplitUint64(1, 2, 5, nil); err != ErrLimitExceeded {
+		t.Errorf("split over lowered limit gave %v, want ErrLimitExceeded", err)
+	}
+	if _, err := JoinUint64(append(shares, shares[0]), []int{0, 1, 2, 3, 4}); err != ErrLimitExceeded {
+		t.Errorf("join over lowered limit gave %v, want ErrLimitExceeded", err)
+	}
+}
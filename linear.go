@@ -0,0 +1,73 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+)
+
+// AddShares returns shares of the sum, modulo the field, of the secrets
+// shared by a and b. The shares must record their modulus and a[i] and b[i]
+// must be shares for the same x coordinate. Each share holder can compute
+// their own share of the sum, so secrets can be aggregated without ever being
+// reconstructed. The result carries no metadata, MAC, fingerprint or
+// signature, since none of those from a or b apply to it.
+func AddShares(a, b []Share) ([]Share, error) {
+	if len(a) != len(b) {
+		return nil, errors.New("share sets have different lengths")
+	}
+
+	sum := make([]Share, len(a))
+	for i := range a {
+		if a[i].Modulus == nil || b[i].Modulus == nil || a[i].Modulus.Cmp(b[i].Modulus) != 0 {
+			return nil, errors.New("shares are from different fields")
+		}
+		if a[i].X == nil || a[i].Y == nil || b[i].X == nil || b[i].Y == nil {
+			return nil, errors.New("share is missing coordinates")
+		}
+		if a[i].X.Cmp(b[i].X) != 0 {
+			return nil, errors.New("shares have different x coordinates")
+		}
+		if a[i].Additive != b[i].Additive {
+			return nil, errors.New("cannot add additive and Shamir shares")
+		}
+
+		y := new(big.Int).Add(a[i].Y, b[i].Y)
+		sum[i] = Share{
+			X:        a[i].X,
+			Y:        y.Mod(y, a[i].Modulus),
+			Modulus:  a[i].Modulus,
+			Additive: a[i].Additive,
+		}
+	}
+	return sum, nil
+}
+
+// ScaleShares returns shares of c times the secret shared by shares, modulo
+// the field. Like AddShares, it can be computed by each share holder alone,
+// and the result carries no metadata, MAC, fingerprint or signature.
+func ScaleShares(shares []Share, c *big.Int) ([]Share, error) {
+	scaled := make([]Share, len(shares))
+	for i, s := range shares {
+		if s.Modulus == nil {
+			return nil, errors.New("shares don't record their modulus")
+		}
+		if s.X == nil || s.Y == nil {
+			return nil, errors.New("share is missing coordinates")
+		}
+
+		y := new(big.Int).Mul(s.Y, c)
+		scaled[i] = Share{
+			X:        s.X,
+			Y:        y.Mod(y, s.Modulus),
+			Modulus:  s.Modulus,
+			Additive: s.Additive,
+		}
+	}
+	return scaled, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAddShares(t *testing.T) {
+	a, _ := SplitShares(big.NewInt(1000), P256Order, 3, 5, nil)
+	b, _ := SplitShares(big.NewInt(234), P256Order, 3, 5, nil)
+
+	sum, err := AddShares(a, b)
+	if err != nil {
+		t.Fatalf("error while adding: %s", err)
+	}
+	if result, err := JoinShares(sum[1:4]); err != nil || result.Cmp(big.NewInt(1234)) != 0 {
+		t.Errorf("failed to join sum: got %v, %v", result, err)
+	}
+
+	if _, err := AddShares(a[:4], b[1:]); err == nil {
+		t.Errorf("added shares with different x coordinates")
+	}
+	c, _ := SplitShares(big.NewInt(1), MODP2048, 3, 5, nil)
+	if _, err := AddShares(a, c); err == nil {
+		t.Errorf("added shares from different fields")
+	}
+}
+
+func TestScaleShares(t *testing.T) {
+	a, _ := SplitShares(big.NewInt(1000), P256Order, 3, 5, nil)
+	b, _ := SplitShares(big.NewInt(234), P256Order, 3, 5, nil)
+
+	// 3a - b, with the subtraction done by scaling by -1.
+	a3, err := ScaleShares(a, big.NewInt(3))
+	if err != nil {
+		t.Fatalf("error while scaling: %s", err)
+	}
+	negB, _ := ScaleShares(b, big.NewInt(-1))
+	diff, _ := AddShares(a3, negB)
+	if result, err := JoinShares(diff[:3]); err != nil || result.Cmp(big.NewInt(2766)) != 0 {
+		t.Errorf("failed to join scaled shares: got %v, %v", result, err)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// A ShareFile is a file examined by LoadShares.
+type ShareFile struct {
+	Path string
+	// Share is the share in the file, if Err is nil.
+	Share Share
+	// Err is why the file's share can't be used, if it can't.
+	Err error
+}
+
+// A ShareGroup is the shares of one dealing and epoch found by LoadShares.
+type ShareGroup struct {
+	// SetID and Epoch identify the dealing. The set ID is all zeros for
+	// shares that don't record one, which are grouped together.
+	SetID [16]byte
+	Epoch uint64
+	// Threshold is the number of shares needed, or zero if the shares
+	// don't record it.
+	Threshold int
+	// Files are the files with distinct shares, in order of x coordinate.
+	Files []ShareFile
+}
+
+// Shares returns the shares of g.
+func (g *ShareGroup) Shares() []Share {
+	shares := make([]Share, len(g.Files))
+	for i, f := range g.Files {
+		shares[i] = f.Share
+	}
+	return shares
+}
+
+// Needed returns the number of further shares needed to recover the secret
+// of g, or -1 if the threshold isn't known.
+func (g *ShareGroup) Needed() int {
+	if g.Threshold == 0 {
+		return -1
+	}
+	return max(g.Threshold-len(g.Files), 0)
+}
+
+// Usable returns whether g has enough shares to recover its secret.
+func (g *ShareGroup) Usable() bool {
+	return g.Needed() == 0
+}
+
+// LoadResult is the result of LoadShares.
+type LoadResult struct {
+	// Groups are the dealings found, in order of set ID and then epoch.
+	Groups []ShareGroup
+	// Unusable are the files that matched but couldn't be used: those that
+	// couldn't be read or parsed and duplicates of shares already found.
+	Unusable []ShareFile
+}
+
+// LoadShares reads every file in fsys that matches pattern, as for
+// fs.Glob, or every file at the top level if pattern is empty. It parses
+// the shares in them, in binary, armored, URI or compact form, and groups
+// them by dealing, reporting what can be recovered. This is for when all
+// that's left is a directory of share files of unknown provenance. Files
+// named ManifestName are ignored. MACs and signatures are checked, as by
+// UnmarshalBinary, but the shares aren't otherwise checked against each
+// other. Only an invalid pattern results in an error.
+func LoadShares(fsys fs.FS, pattern string) (*LoadResult, error) {
+	if len(pattern) == 0 {
+		pattern = "*"
+	}
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	type groupKey struct {
+		setID [16]byte
+		epoch uint64
+	}
+	groups := make(map[groupKey]*ShareGroup)
+	result := new(LoadResult)
+	for _, name := range names {
+		if path.Base(name) == ManifestName {
+			continue
+		}
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			result.Unusable = append(result.Unusable, ShareFile{Path: name, Err: err})
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		f := ShareFile{Path: name}
+		data, err := fs.ReadFile(fsys, name)
+		if err == nil {
+			f.Share, err = parseAnyShare(data)
+		}
+		if err != nil {
+			f.Err = err
+			result.Unusable = append(result.Unusable, f)
+			continue
+		}
+
+		id, _ := f.Share.SetID()
+		key := groupKey{id, shareEpoch(&f.Share)}
+		g := groups[key]
+		if g == nil {
+			g = &ShareGroup{SetID: id, Epoch: key.epoch}
+			groups[key] = g
+		}
+		if err := g.add(f); err != nil {
+			f.Err = err
+			result.Unusable = append(result.Unusable, f)
+		}
+	}
+
+	for _, g := range groups {
+		sort.Slice(g.Files, func(i, j int) bool {
+			return g.Files[i].Share.X.Cmp(g.Files[j].Share.X) < 0
+		})
+		result.Groups = append(result.Groups, *g)
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		a, b := &result.Groups[i], &result.Groups[j]
+		if c := bytes.Compare(a.SetID[:], b.SetID[:]); c != 0 {
+			return c < 0
+		}
+		return a.Epoch < b.Epoch
+	})
+	return result, nil
+}
+
+// add adds f to g unless it repeats a share already in g or is from a
+// different dealing.
+func (g *ShareGroup) add(f ShareFile) error {
+	if len(g.Files) > 0 {
+		if err := checkSameDealing(len(g.Files), &g.Files[0].Share, &f.Share); err != nil {
+			return err
+		}
+	}
+	for _, other := range g.Files {
+		if other.Share.X.Cmp(f.Share.X) == 0 {
+			return errDuplicateShare
+		}
+	}
+	if m := f.Share.Metadata; m != nil && g.Threshold == 0 {
+		g.Threshold = m.Threshold
+	}
+	g.Files = append(g.Files, f)
+	return nil
+}
+
+// parseAnyShare parses a share in any of the single-share encodings.
+func parseAnyShare(data []byte) (Share, error) {
+	text := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(text, []byte(uriScheme+"://")):
+		return ParseShareURI(string(text))
+	case bytes.Contains(text, []byte(armorBegin)):
+		return Dearmor(text)
+	case isBinaryShare(data):
+		return ParseShare(data, nil)
+	}
+	if s, err := ParseCompact(data); err == nil {
+		return s, nil
+	}
+	return Share{}, errors.New("unrecognized format")
+}
@@ -0,0 +1,82 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadShares(t *testing.T) {
+	secret := big.NewInt(31337)
+	a, err := Deal(secret, 5, WithThreshold(3), WithField(NamedModulus("p256")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Deal(secret, 5, WithThreshold(3), WithField(NamedModulus("p256")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary, _ := a.Shares[0].MarshalBinary()
+	armored, _ := Armor(&a.Shares[1])
+	uri, _ := EncodeShareURI(&a.Shares[2])
+	compact, _ := MarshalCompact(&a.Shares[3], 0)
+	other, _ := b.Shares[0].MarshalBinary()
+	fsys := fstest.MapFS{
+		"share-1":      {Data: binary},
+		"share-1.copy": {Data: binary},
+		"share-2.asc":  {Data: armored},
+		"share-3.txt":  {Data: []byte(uri + "\n")},
+		"share-4.nfc":  {Data: compact},
+		"other":        {Data: other},
+		"notes.txt":    {Data: []byte("remember to water the plants\n")},
+		ManifestName:   {Data: []byte("not a share\n")},
+		"sub/share-5":  {Data: binary},
+	}
+
+	result, err := LoadShares(fsys, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Groups) != 2 {
+		t.Fatalf("found %d groups, want 2", len(result.Groups))
+	}
+	var g *ShareGroup
+	for i := range result.Groups {
+		if result.Groups[i].SetID == a.Shares[0].Metadata.SetID {
+			g = &result.Groups[i]
+		} else if result.Groups[i].Usable() || result.Groups[i].Needed() != 2 {
+			t.Errorf("other group: usable %t, needs %d", result.Groups[i].Usable(), result.Groups[i].Needed())
+		}
+	}
+	if g == nil {
+		t.Fatal("dealing wasn't found")
+	}
+	if len(g.Files) != 4 || !g.Usable() || g.Threshold != 3 {
+		t.Errorf("group has %d files, usable %t, threshold %d", len(g.Files), g.Usable(), g.Threshold)
+	}
+	if got, err := JoinShares(g.Shares()); err != nil || got.Cmp(secret) != 0 {
+		t.Errorf("got %v, %v, want %s", got, err, secret)
+	}
+
+	unusable := make(map[string]bool)
+	for _, f := range result.Unusable {
+		unusable[f.Path] = f.Err != nil
+	}
+	if len(unusable) != 2 || !unusable["share-1.copy"] || !unusable["notes.txt"] {
+		t.Errorf("unusable files: %v", unusable)
+	}
+
+	if result, err := LoadShares(fsys, "sub/*"); err != nil || len(result.Groups) != 1 || len(result.Groups[0].Files) != 1 {
+		t.Errorf("loading a subdirectory gave %+v, %v", result, err)
+	}
+	if _, err := LoadShares(fsys, "["); err == nil {
+		t.Error("invalid pattern was accepted")
+	}
+}
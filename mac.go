@@ -0,0 +1,76 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"io"
+)
+
+// ErrCorruptShare is returned when a share fails an integrity check.
+var ErrCorruptShare = errors.New("share is corrupt")
+
+// macKeyLen is the length of a dealing's MAC key.
+const macKeyLen = 32
+
+// AuthenticateShares generates a random MAC key for a dealing and sets it as
+// the MACKey of each share, so that the binary encoding of each share carries
+// a MAC. UnmarshalBinary then rejects shares whose MAC doesn't verify, and
+// JoinShares rejects sets of shares with different keys, detecting corrupted
+// shares and shares from different dealings.
+//
+// Since the key is stored in every share, the MAC doesn't stop a shareholder
+// from deliberately altering their own share: dealer signatures are needed
+// for that. If rand is nil, crypto/rand.Reader is used.
+func AuthenticateShares(shares []Share, rand io.Reader) error {
+	key := make([]byte, macKeyLen)
+	if _, err := io.ReadFull(defaultRand(rand), key); err != nil {
+		return err
+	}
+
+	for i := range shares {
+		shares[i].MACKey = key
+	}
+	return nil
+}
+
+// shareMAC returns the MAC of the binary encoding of all records of a share
+// other than the MAC itself and the hint.
+func shareMAC(key []byte, r wireRecords) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(r.marshal())
+	return h.Sum(nil)
+}
+
+// addMAC adds a MAC record to r, which must already contain the key, if key
+// isn't empty.
+func addMAC(r *wireRecords, key []byte) error {
+	if len(key) == 0 {
+		return nil
+	}
+	if len(key) != macKeyLen {
+		return errors.New("MAC key has the wrong length")
+	}
+
+	r.add(tagMAC, shareMAC(key, *r))
+	return nil
+}
+
+// checkMAC verifies the MAC in r, if any. A share with a MAC key but no MAC,
+// or a MAC but no key, is corrupt.
+func checkMAC(r wireRecords) error {
+	key, mac := r.get(tagMACKey), r.get(tagMAC)
+	if key == nil && mac == nil {
+		return nil
+	}
+	if len(key) != macKeyLen || !hmac.Equal(mac, shareMAC(key, r.without(tagMAC, tagHint))) {
+		return ErrCorruptShare
+	}
+	return nil
+}
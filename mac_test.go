@@ -0,0 +1,56 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestShareMAC(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, _ := SplitShares(secret, MODP2048, 2, 3, nil)
+	if err := AuthenticateShares(shares, nil); err != nil {
+		t.Fatalf("failed to authenticate shares: %s", err)
+	}
+	shares[0].Metadata = &Metadata{Label: "label"}
+
+	var parsed []Share
+	for i := range shares {
+		data, err := shares[i].MarshalBinary()
+		if err != nil {
+			t.Fatalf("error while marshaling: %s", err)
+		}
+
+		// Flipping any bit after the header must be detected.
+		for j := len(wireMagic) + 1; j < len(data); j++ {
+			data[j] ^= 0x10
+			var s Share
+			if err := s.UnmarshalBinary(data); err == nil {
+				t.Errorf("share %d: corruption at byte %d wasn't detected", i, j)
+			}
+			data[j] ^= 0x10
+		}
+
+		var s Share
+		if err := s.UnmarshalBinary(data); err != nil {
+			t.Fatalf("error while unmarshaling: %s", err)
+		}
+		parsed = append(parsed, s)
+	}
+
+	result, err := JoinShares(parsed[1:])
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to join authenticated shares: %v", err)
+	}
+
+	other, _ := SplitShares(secret, MODP2048, 2, 3, nil)
+	AuthenticateShares(other, nil)
+	if _, err := JoinShares([]Share{parsed[0], other[1]}); err == nil {
+		t.Errorf("shares with different MAC keys were joined")
+	}
+}
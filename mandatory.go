@@ -0,0 +1,53 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrMissingMandatory is returned by JoinShares when the shares are from a
+// dealing with a mandatory share, from WithMandatory, that isn't among them.
+var ErrMissingMandatory = errors.New("mandatory share is missing")
+
+// joinMandatory recovers a secret that was split with a mandatory share at
+// x: the sum of that share's y and the secret of the others.
+func joinMandatory(xs, ys []*big.Int, x, modulus *big.Int) (*big.Int, error) {
+	i := 0
+	for i < len(xs) && xs[i].Cmp(x) != 0 {
+		i++
+	}
+	if i == len(xs) {
+		return nil, ErrMissingMandatory
+	}
+	if len(xs) == 1 {
+		return nil, errors.New("mandatory share can't recover the secret alone")
+	}
+
+	otherXs := append(append([]*big.Int(nil), xs[:i]...), xs[i+1:]...)
+	otherYs := append(append([]*big.Int(nil), ys[:i]...), ys[i+1:]...)
+	secret, err := interpolate(otherXs, otherYs, modulus)
+	if err != nil {
+		return nil, err
+	}
+	sum := new(big.Int).Add(secret, ys[i])
+	clear(secret.Bits())
+	secret.Mod(sum, modulus)
+	clear(sum.Bits())
+	return secret, nil
+}
+
+// indexOfParticipant returns the index of name in names, or -1.
+func indexOfParticipant(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
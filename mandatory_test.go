@@ -0,0 +1,90 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestMandatory(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(42)
+	names := []string{"alice", "hsm", "bob", "carol", "dave"}
+	set, err := Deal(secret, 5, WithThreshold(3), WithField(p), WithParticipants(names),
+		WithMandatory("hsm"), WithSelfTest(10), WithMAC())
+	if err != nil {
+		t.Fatalf("error while dealing: %s", err)
+	}
+	for i, s := range set.Shares {
+		if s.Mandatory == nil || s.Mandatory.Cmp(set.Shares[1].X) != 0 {
+			t.Errorf("share %d doesn't record the mandatory share", i)
+		}
+	}
+
+	shares := make([]Share, len(set.Shares))
+	for i := range set.Shares {
+		data, err := set.Shares[i].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shares[i], err = ParseShare(data, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, subset := range [][]int{{1, 0, 2}, {3, 1, 4}, {4, 1, 0, 2, 3}} {
+		var given []Share
+		for _, i := range subset {
+			given = append(given, shares[i])
+		}
+		if result, err := JoinShares(given); err != nil || result.Cmp(secret) != 0 {
+			t.Errorf("%v: got %v, %v, want %s", subset, result, err, secret)
+		}
+	}
+
+	if _, err := JoinShares([]Share{shares[0], shares[2], shares[3], shares[4]}); err != ErrMissingMandatory {
+		t.Errorf("join without the mandatory share gave %v, want ErrMissingMandatory", err)
+	}
+	if _, err := JoinShares([]Share{shares[1], shares[2]}); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("join of too few shares gave %v, want ErrNotEnoughShares", err)
+	}
+
+	other, err := Deal(secret, 5, WithThreshold(3), WithField(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := other.Shares[0]
+	plain.Metadata = shares[0].Metadata
+	plain.MACKey = shares[0].MACKey
+	var mismatch *MismatchError
+	if _, err := JoinShares([]Share{shares[1], shares[2], plain}); !errors.As(err, &mismatch) || mismatch.Field != "mandatory share" {
+		t.Errorf("join with an ordinary share gave %v", err)
+	}
+
+	// Refreshing would re-randomize the polynomial but not the 2-of-2
+	// split, and the other encodings can't record the mandatory share.
+	if _, err := NewRefreshPacket(&shares[0], 1, nil, nil); err == nil {
+		t.Errorf("share with a mandatory share was refreshed")
+	}
+	stripped := shares[0]
+	stripped.MACKey, stripped.Participant = nil, ""
+	if _, err := MarshalCompact(&stripped, 0); err == nil {
+		t.Errorf("compact encoding dropped the mandatory share")
+	}
+	if _, err := stripped.MarshalProto(); err == nil {
+		t.Errorf("protobuf encoding dropped the mandatory share")
+	}
+
+	if _, err := Deal(secret, 5, WithThreshold(3), WithField(p), WithMandatory("hsm")); err == nil {
+		t.Errorf("deal with an unnamed mandatory participant succeeded")
+	}
+	if _, err := Deal(secret, 5, WithThreshold(1), WithField(p), WithParticipants(names), WithMandatory("hsm")); err == nil {
+		t.Errorf("deal with a mandatory share and threshold one succeeded")
+	}
+}
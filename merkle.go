@@ -0,0 +1,125 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// A MerkleProof shows that a share is one of those committed to by a Merkle
+// root from MerkleCommit. Path is the audit path of RFC 9162, section 2.1.3,
+// from the leaf up.
+type MerkleProof struct {
+	Index, Size int
+	Path        [][sha256.Size]byte
+}
+
+// MerkleCommit returns the root of a Merkle tree, as specified in RFC 9162,
+// over the binary encodings of shares, less their hints, and an inclusion
+// proof for each share. A coordinator need only keep the root to later check,
+// with VerifyMerkleProof, that a returned share is one of those dealt.
+func MerkleCommit(shares []Share) (root [sha256.Size]byte, proofs []MerkleProof, err error) {
+	if len(shares) == 0 {
+		return root, nil, errors.New("no shares given")
+	}
+
+	leaves := make([][sha256.Size]byte, len(shares))
+	for i := range shares {
+		if leaves[i], err = shareLeaf(&shares[i]); err != nil {
+			return root, nil, err
+		}
+	}
+
+	proofs = make([]MerkleProof, len(shares))
+	for i := range proofs {
+		proofs[i] = MerkleProof{Index: i, Size: len(leaves), Path: merklePath(i, leaves)}
+	}
+	return merkleRoot(leaves), proofs, nil
+}
+
+// VerifyMerkleProof returns nil iff proof shows that share was committed to
+// by root.
+func VerifyMerkleProof(root [sha256.Size]byte, share *Share, proof *MerkleProof) error {
+	if proof.Index < 0 || proof.Index >= proof.Size {
+		return errors.New("invalid Merkle proof")
+	}
+	r, err := shareLeaf(share)
+	if err != nil {
+		return err
+	}
+
+	fn, sn := proof.Index, proof.Size-1
+	for _, p := range proof.Path {
+		if sn == 0 {
+			return errors.New("invalid Merkle proof")
+		}
+		if fn&1 == 1 || fn == sn {
+			r = merkleNode(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkleNode(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || r != root {
+		return errors.New("share is not committed to by the Merkle root")
+	}
+	return nil
+}
+
+// shareLeaf returns the leaf for s, which doesn't depend on its hint, so
+// that hints can be changed after committing to the shares.
+func shareLeaf(s *Share) ([sha256.Size]byte, error) {
+	data, err := marshalWithoutHint(s)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return merkleLeaf(data), nil
+}
+
+func merkleLeaf(data []byte) [sha256.Size]byte {
+	return sha256.Sum256(append([]byte{0}, data...))
+}
+
+func merkleNode(left, right [sha256.Size]byte) [sha256.Size]byte {
+	b := append([]byte{1}, left[:]...)
+	return sha256.Sum256(append(b, right[:]...))
+}
+
+// merkleSplit returns the largest power of two less than n, which must be
+// at least two.
+func merkleSplit(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+func merkleRoot(leaves [][sha256.Size]byte) [sha256.Size]byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := merkleSplit(len(leaves))
+	return merkleNode(merkleRoot(leaves[:k]), merkleRoot(leaves[k:]))
+}
+
+func merklePath(m int, leaves [][sha256.Size]byte) [][sha256.Size]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := merkleSplit(len(leaves))
+	if m < k {
+		return append(merklePath(m, leaves[:k]), merkleRoot(leaves[k:]))
+	}
+	return append(merklePath(m-k, leaves[k:]), merkleRoot(leaves[:k]))
+}
@@ -0,0 +1,38 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMerkleCommit(t *testing.T) {
+	for _, n := range []int{1, 2, 5, 8, 13} {
+		shares, _ := SplitShares(big.NewInt(42), P256Order, 1, n, nil)
+		root, proofs, err := MerkleCommit(shares)
+		if err != nil {
+			t.Fatalf("error while committing: %s", err)
+		}
+
+		for i := range shares {
+			if err := VerifyMerkleProof(root, &shares[i], &proofs[i]); err != nil {
+				t.Errorf("n=%d: proof %d failed: %s", n, i, err)
+			}
+			if n > 1 {
+				if err := VerifyMerkleProof(root, &shares[(i+1)%n], &proofs[i]); err == nil {
+					t.Errorf("n=%d: proof %d verified the wrong share", n, i)
+				}
+			}
+		}
+
+		forged := Share{X: big.NewInt(1), Y: big.NewInt(43), Modulus: P256Order}
+		if err := VerifyMerkleProof(root, &forged, &proofs[0]); err == nil {
+			t.Errorf("n=%d: forged share verified", n)
+		}
+	}
+}
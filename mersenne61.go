@@ -0,0 +1,120 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+)
+
+// This file implements secret sharing over the integers modulo the Mersenne
+// prime 2^61 - 1 with native arithmetic, which is much faster than math/big
+// for secrets that fit, such as PINs and short tokens.
+
+// Mersenne61Prime is 2^61 - 1.
+const Mersenne61Prime = 1<<61 - 1
+
+// Mersenne61 is the field of integers modulo Mersenne61Prime. It implements
+// Field[uint64].
+type Mersenne61 struct{}
+
+// NewMersenne61 returns the field of integers modulo 2^61 - 1.
+func NewMersenne61() *Mersenne61 {
+	return new(Mersenne61)
+}
+
+// m61Reduce returns a mod 2^61 - 1 for a < 2^62.
+func m61Reduce(a uint64) uint64 {
+	a = a&Mersenne61Prime + a>>61
+	if a >= Mersenne61Prime {
+		a -= Mersenne61Prime
+	}
+	return a
+}
+
+func m61Mul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	// Since 2^61 = 1, the bits above 2^61 are added to those below.
+	return m61Reduce(lo&Mersenne61Prime + (hi<<3 | lo>>61))
+}
+
+// m61Inv returns a^-1, or zero if a is zero.
+func m61Inv(a uint64) uint64 {
+	// a^(p-2) = a^-1.
+	r := uint64(1)
+	for e := uint64(Mersenne61Prime - 2); e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = m61Mul(r, a)
+		}
+		a = m61Mul(a, a)
+	}
+	return r
+}
+
+func (*Mersenne61) Zero() uint64           { return 0 }
+func (*Mersenne61) One() uint64            { return 1 }
+func (*Mersenne61) Add(a, b uint64) uint64 { return m61Reduce(a + b) }
+func (*Mersenne61) Sub(a, b uint64) uint64 { return m61Reduce(a + Mersenne61Prime - b) }
+func (*Mersenne61) Mul(a, b uint64) uint64 { return m61Mul(a, b) }
+func (*Mersenne61) Equal(a, b uint64) bool { return a == b }
+func (*Mersenne61) Encode(a uint64) []byte { return binary.BigEndian.AppendUint64(nil, a) }
+func (*Mersenne61) Inv(a uint64) (uint64, error) {
+	if a == 0 {
+		return 0, errors.New("element has no inverse")
+	}
+	return m61Inv(a), nil
+}
+
+func (*Mersenne61) Element(i uint64) (uint64, error) {
+	if i >= Mersenne61Prime {
+		return 0, errors.New("field is too small")
+	}
+	return i, nil
+}
+
+func (*Mersenne61) Random(rand io.Reader) (uint64, error) {
+	var b [8]byte
+	for {
+		if _, err := io.ReadFull(rand, b[:]); err != nil {
+			return 0, err
+		}
+		if v := binary.BigEndian.Uint64(b[:]) & Mersenne61Prime; v < Mersenne61Prime {
+			return v, nil
+		}
+	}
+}
+
+func (*Mersenne61) Decode(b []byte) (uint64, error) {
+	if len(b) != 8 {
+		return 0, errors.New("encoded element has the wrong length")
+	}
+	a := binary.BigEndian.Uint64(b)
+	if a >= Mersenne61Prime {
+		return 0, errors.New("encoded element is out of range")
+	}
+	return a, nil
+}
+
+// SplitUint64 is like Split for secrets less than 2^61 - 1, using native
+// arithmetic over Mersenne61. The shares can be recombined with JoinUint64.
+// If rand is nil, crypto/rand.Reader is used.
+func SplitUint64(secret uint64, k, n int, rand io.Reader) ([]uint64, error) {
+	if secret >= Mersenne61Prime {
+		return nil, errors.New("secret must be less than 2^61 - 1")
+	}
+	return SplitField[uint64](NewMersenne61(), secret, k, n, rand)
+}
+
+// JoinUint64 recovers the secret from shares that resulted from SplitUint64.
+func JoinUint64(shares []uint64, shareNumbers []int) (uint64, error) {
+	for _, s := range shares {
+		if s >= Mersenne61Prime {
+			return 0, errors.New("share is out of range")
+		}
+	}
+	return JoinField[uint64](NewMersenne61(), shares, shareNumbers)
+}
@@ -0,0 +1,64 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"math/bits"
+	"testing"
+	"testing/quick"
+)
+
+func TestMersenne61Arithmetic(t *testing.T) {
+	f := NewMersenne61()
+
+	mul := func(a, b uint64) bool {
+		a %= Mersenne61Prime
+		b %= Mersenne61Prime
+		hi, lo := bits.Mul64(a, b)
+		return f.Mul(a, b) == bits.Rem64(hi, lo, Mersenne61Prime)
+	}
+	if err := quick.Check(mul, nil); err != nil {
+		t.Error(err)
+	}
+
+	inv := func(a uint64) bool {
+		a %= Mersenne61Prime
+		if a == 0 {
+			return true
+		}
+		i, err := f.Inv(a)
+		return err == nil && f.Mul(a, i) == 1
+	}
+	if err := quick.Check(inv, nil); err != nil {
+		t.Error(err)
+	}
+
+	if f.Sub(1, 2) != Mersenne61Prime-1 || f.Add(Mersenne61Prime-1, 2) != 1 {
+		t.Errorf("addition doesn't wrap correctly")
+	}
+}
+
+func TestSplitUint64(t *testing.T) {
+	testField[uint64](t, NewMersenne61(), 123456)
+
+	const secret = 1<<61 - 2
+	shares, err := SplitUint64(secret, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	if result, err := JoinUint64(shares[2:], []int{2, 3, 4}); err != nil || result != secret {
+		t.Errorf("got %d, %v, want %d", result, err, uint64(secret))
+	}
+	if _, err := SplitUint64(Mersenne61Prime, 3, 5, nil); err == nil {
+		t.Errorf("split an out of range secret")
+	}
+}
+
+func BenchmarkSplitUint64(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		SplitUint64(42, 10, 1000, nil)
+	}
+}
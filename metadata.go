@@ -0,0 +1,183 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Metadata records information about a dealing so that, long after the
+// fact, the holder of a share can tell which secret it belongs to and how
+// many other shares are needed to recover it. None of it is secret.
+type Metadata struct {
+	// Threshold is the number of shares needed to recover the secret,
+	// or zero if it isn't recorded.
+	Threshold int
+	// SetID identifies the dealing. It's the same for all shares from
+	// one dealing and random otherwise.
+	SetID [16]byte
+	// Created is the time of the dealing, to the second, or the zero
+	// time if it isn't recorded.
+	Created time.Time
+	// Label is a free-form description of the secret.
+	Label string
+	// NotAfter, if not the zero time, is when the shares expire and
+	// should no longer be used. See CheckExpiry.
+	NotAfter time.Time
+	// Epoch counts rotations of the secret: shares from different epochs
+	// can't be combined.
+	Epoch uint64
+}
+
+// NewMetadata returns Metadata for a new dealing with the given threshold
+// and label, a random set ID and the current time. If rand is nil,
+// crypto/rand.Reader is used.
+func NewMetadata(k int, label string, rand io.Reader) (*Metadata, error) {
+	m := &Metadata{
+		Threshold: k,
+		Created:   time.Now().Truncate(time.Second),
+		Label:     label,
+	}
+
+	if _, err := io.ReadFull(defaultRand(rand), m.SetID[:]); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// newSetMetadata returns Metadata for a new dealing, with the given threshold
+// and a random set ID but no time, so that the shares of a dealing depend only
+// on rand. If rand is nil, crypto/rand.Reader is used.
+func newSetMetadata(k int, rand io.Reader) (*Metadata, error) {
+	m := &Metadata{Threshold: k}
+	if _, err := io.ReadFull(defaultRand(rand), m.SetID[:]); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// addRecords adds the records that encode m.
+func (m *Metadata) addRecords(r *wireRecords) error {
+	if m.Threshold < 0 || uint64(m.Threshold) > math.MaxUint32 {
+		return errors.New("threshold out of range")
+	}
+	if !utf8.ValidString(m.Label) {
+		return errors.New("label is not valid UTF-8")
+	}
+
+	if m.Threshold > 0 {
+		r.addUint(tagThreshold, uint64(m.Threshold))
+	}
+	if m.SetID != [16]byte{} {
+		r.add(tagSetID, m.SetID[:])
+	}
+	if !m.Created.IsZero() {
+		r.add(tagCreated, binary.AppendVarint(nil, m.Created.Unix()))
+	}
+	if len(m.Label) > 0 {
+		r.add(tagLabel, []byte(m.Label))
+	}
+	if !m.NotAfter.IsZero() {
+		r.add(tagNotAfter, binary.AppendVarint(nil, m.NotAfter.Unix()))
+	}
+	if m.Epoch > 0 {
+		r.addUint(tagEpoch, m.Epoch)
+	}
+	return nil
+}
+
+// parseMetadataRecord parses a record that may be part of the metadata,
+// allocating *m if needed. Records with other tags are rejected.
+func parseMetadataRecord(m **Metadata, tag uint64, value []byte) error {
+	switch tag {
+	case tagThreshold, tagSetID, tagCreated, tagLabel, tagNotAfter, tagEpoch:
+	default:
+		return errUnknownRecord
+	}
+
+	if *m == nil {
+		*m = new(Metadata)
+	}
+
+	switch tag {
+	case tagThreshold:
+		k, err := parseWireUint(value)
+		if err != nil || k > math.MaxUint32 {
+			return errors.New("invalid threshold")
+		}
+		(*m).Threshold = int(k)
+	case tagSetID:
+		if len(value) != len((*m).SetID) {
+			return errors.New("invalid set ID")
+		}
+		copy((*m).SetID[:], value)
+	case tagCreated:
+		t, n := binary.Varint(value)
+		if n <= 0 || n != len(value) {
+			return errors.New("invalid creation time")
+		}
+		(*m).Created = time.Unix(t, 0)
+	case tagLabel:
+		if !utf8.Valid(value) {
+			return errors.New("label is not valid UTF-8")
+		}
+		(*m).Label = string(value)
+	case tagNotAfter:
+		t, n := binary.Varint(value)
+		if n <= 0 || n != len(value) {
+			return errors.New("invalid expiry time")
+		}
+		(*m).NotAfter = time.Unix(t, 0)
+	case tagEpoch:
+		e, err := parseWireUint(value)
+		if err != nil {
+			return errors.New("invalid epoch")
+		}
+		(*m).Epoch = e
+	}
+	return nil
+}
+
+// An ExpiredError is returned by CheckExpiry for a share that has expired.
+type ExpiredError struct {
+	// Index is the index of the share in the slice given to CheckExpiry.
+	Index    int
+	NotAfter time.Time
+}
+
+func (e *ExpiredError) Error() string {
+	return "share " + strconv.Itoa(e.Index) + " expired at " + e.NotAfter.UTC().Format(time.RFC3339)
+}
+
+// CheckExpiry returns an *ExpiredError if any of shares has expired at time
+// now, or an error if the shares are from different epochs. JoinShares
+// doesn't check expiry, so callers that enforce a rotation policy should call
+// this first.
+func CheckExpiry(shares []Share, now time.Time) error {
+	var epoch *uint64
+	for i := range shares {
+		m := shares[i].Metadata
+		if m == nil {
+			continue
+		}
+		if !m.NotAfter.IsZero() && now.After(m.NotAfter) {
+			return &ExpiredError{Index: i, NotAfter: m.NotAfter}
+		}
+		if epoch == nil {
+			epoch = &m.Epoch
+		} else if *epoch != m.Epoch {
+			return errors.New("shares are from different epochs")
+		}
+	}
+	return nil
+}
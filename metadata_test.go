@@ -0,0 +1,130 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+	m, err := NewMetadata(3, "backup key for db-1", nil)
+	if err != nil {
+		t.Fatalf("failed to create metadata: %s", err)
+	}
+
+	shares, _ := SplitShares(big.NewInt(42), MODP2048, 3, 5, nil)
+	shares[0].Metadata = m
+
+	data, err := shares[0].MarshalBinary()
+	if err != nil {
+		t.Fatalf("error while marshaling: %s", err)
+	}
+
+	var s Share
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatalf("error while unmarshaling: %s", err)
+	}
+
+	if s.Metadata == nil {
+		t.Fatalf("metadata was lost")
+	}
+	if got := *s.Metadata; got.Threshold != m.Threshold || got.SetID != m.SetID || !got.Created.Equal(m.Created) || got.Label != m.Label {
+		t.Errorf("got metadata %+v, want %+v", got, *m)
+	}
+
+	shares[1].Metadata = nil
+	data, _ = shares[1].MarshalBinary()
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatalf("error while unmarshaling: %s", err)
+	}
+	if s.Metadata != nil {
+		t.Errorf("share without metadata gained some")
+	}
+}
+
+func TestSetID(t *testing.T) {
+	set, err := Deal(big.NewInt(42), 3, WithThreshold(2), WithField(MODP2048))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, ok := set.SetID()
+	if !ok || id == [16]byte{} {
+		t.Fatalf("dealing has no set ID")
+	}
+	if set.Shares[0].Metadata.Threshold != 2 {
+		t.Errorf("threshold wasn't recorded")
+	}
+
+	shares, err := SplitShares(big.NewInt(42), MODP2048, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, ok := shares[2].SetID()
+	if !ok || other == id {
+		t.Errorf("dealings don't have distinct set IDs")
+	}
+
+	set.Shares[1] = shares[1]
+	if _, ok := set.SetID(); ok {
+		t.Errorf("mixed set has a set ID")
+	}
+	if _, ok := (&Share{}).SetID(); ok {
+		t.Errorf("share without metadata has a set ID")
+	}
+}
+
+func TestMetadataProto(t *testing.T) {
+	m, _ := NewMetadata(2, "label", nil)
+	s := Share{X: big.NewInt(1), Y: big.NewInt(2), Metadata: m}
+
+	data, err := s.MarshalProto()
+	if err != nil {
+		t.Fatalf("error while marshaling: %s", err)
+	}
+
+	var s2 Share
+	if err := s2.UnmarshalProto(data); err != nil {
+		t.Fatalf("error while unmarshaling: %s", err)
+	}
+	if s2.Metadata == nil || s2.Metadata.SetID != m.SetID || !s2.Metadata.Created.Equal(m.Created) {
+		t.Errorf("metadata didn't round trip")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Now()
+	m := &Metadata{NotAfter: now.Add(time.Hour).Truncate(time.Second), Epoch: 2}
+	shares, _ := SplitShares(big.NewInt(42), MODP2048, 2, 3, nil)
+	for i := range shares {
+		shares[i].Metadata = m
+	}
+
+	data, _ := shares[0].MarshalBinary()
+	var s Share
+	if err := s.UnmarshalBinary(data); err != nil || !s.Metadata.NotAfter.Equal(m.NotAfter) || s.Metadata.Epoch != 2 {
+		t.Errorf("expiry didn't survive marshaling: %v", err)
+	}
+	data, _ = shares[0].MarshalProto()
+	if err := s.UnmarshalProto(data); err != nil || !s.Metadata.NotAfter.Equal(m.NotAfter) || s.Metadata.Epoch != 2 {
+		t.Errorf("expiry didn't survive proto marshaling: %v", err)
+	}
+
+	if err := CheckExpiry(shares, now); err != nil {
+		t.Errorf("unexpired shares failed the check: %s", err)
+	}
+	err := CheckExpiry(shares, now.Add(2*time.Hour))
+	if e, ok := err.(*ExpiredError); !ok || e.Index != 0 {
+		t.Errorf("expired shares passed the check: %v", err)
+	}
+
+	shares[1].Metadata = &Metadata{Epoch: 3}
+	if err := CheckExpiry(shares, now); err == nil {
+		t.Errorf("shares from different epochs passed the check")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build (!unix || nommap) && !nobig
+
+package shamirsplit
+
+import (
+	"io"
+	"os"
+)
+
+// A mapping is a file read into memory, on systems where this package
+// doesn't use mmap, or with the nommap tag.
+type mapping struct {
+	data []byte
+	f    *os.File
+}
+
+// mapFile reads the first size bytes of f, which must be at least that long.
+// If writable is true, the mapping owns f: unmap writes the data back to f
+// and closes it.
+func mapFile(f *os.File, size int, writable bool) (*mapping, error) {
+	m := &mapping{data: make([]byte, size)}
+	if _, err := io.ReadFull(io.NewSectionReader(f, 0, int64(size)), m.data); err != nil {
+		return nil, err
+	}
+	if writable {
+		m.f = f
+	}
+	return m, nil
+}
+
+// unmap writes back the data and closes the file, if the mapping is
+// writable, after which its data must not be used.
+func (m *mapping) unmap() error {
+	var err error
+	if m.f != nil {
+		_, err = m.f.WriteAt(m.data, 0)
+		if closeErr := m.f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	m.data, m.f = nil, nil
+	return err
+}
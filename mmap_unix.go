@@ -0,0 +1,57 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build unix && !nommap && !nobig
+
+package shamirsplit
+
+import (
+	"os"
+	"syscall"
+)
+
+// A mapping is a file mapped into memory.
+type mapping struct {
+	data []byte
+	f    *os.File
+}
+
+// mapFile maps the first size bytes of f, which must be at least that long,
+// into memory. If writable is true, changes to the mapping are written to f
+// and the mapping owns f, which unmap closes.
+func mapFile(f *os.File, size int, writable bool) (*mapping, error) {
+	m := new(mapping)
+	if writable {
+		m.f = f
+	}
+	if size == 0 {
+		return m, nil
+	}
+	prot := syscall.PROT_READ
+	if writable {
+		prot |= syscall.PROT_WRITE
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, prot, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	m.data = data
+	return m, nil
+}
+
+// unmap removes the mapping, and closes the file if the mapping is
+// writable, after which its data must not be used.
+func (m *mapping) unmap() error {
+	var err error
+	if m.data != nil {
+		err = syscall.Munmap(m.data)
+	}
+	if m.f != nil {
+		if closeErr := m.f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	m.data, m.f = nil, nil
+	return err
+}
@@ -0,0 +1,222 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/agl/shamirsplit/internal/bip39"
+	"github.com/agl/shamirsplit/internal/norm"
+)
+
+// Mnemonic shares are the binary encoding of a share followed by the first
+// four bytes of its SHA-256 hash, written as a sequence of words from a list
+// of 2048, each encoding 11 bits, most significant first. The final word is
+// padded with zero bits. Shares can thus be written down, or read over the
+// phone, in the custodian's own language. The wordlists of BIP-39 are
+// included, and callers can supply their own.
+
+// MnemonicWords is the number of words in a Wordlist.
+const MnemonicWords = 2048
+
+const mnemonicChecksumLen = 4
+
+// A Wordlist is a list of MnemonicWords distinct words for encoding
+// mnemonic shares.
+type Wordlist struct {
+	// Language names the list, such as "english" or "japanese".
+	Language string
+	// Separator is written between words: a space for most languages,
+	// but an ideographic space (U+3000) for Japanese. Any white space
+	// separates words when decoding.
+	Separator string
+
+	words []string
+	index map[string]int
+}
+
+// NewWordlist returns a Wordlist of words, with words separated by a space.
+// Words are compared in normalization form NFKD, as in BIP-39, so that they
+// match however they were typed, and must be distinct in that form.
+func NewWordlist(language string, words []string) (*Wordlist, error) {
+	if len(words) != MnemonicWords {
+		return nil, errors.New("wordlist must have " + strconv.Itoa(MnemonicWords) + " words")
+	}
+	w := &Wordlist{
+		Language:  language,
+		Separator: " ",
+		words:     make([]string, len(words)),
+		index:     make(map[string]int, len(words)),
+	}
+	for i, word := range words {
+		if !utf8.ValidString(word) || len(strings.Fields(word)) != 1 {
+			return nil, errors.New("invalid word " + strconv.Quote(word) + " in wordlist")
+		}
+		word = norm.NFKD.String(word)
+		if _, ok := w.index[word]; ok {
+			return nil, errors.New("duplicate word " + strconv.Quote(word) + " in wordlist")
+		}
+		w.words[i] = word
+		w.index[word] = i
+	}
+	return w, nil
+}
+
+// bip39Languages are the languages of the BIP-39 wordlists, in the order of
+// bip-0039-wordlists.md.
+var bip39Languages = []string{
+	"english",
+	"japanese",
+	"korean",
+	"spanish",
+	"chinese_simplified",
+	"chinese_traditional",
+	"french",
+	"italian",
+	"czech",
+}
+
+var bip39Wordlists struct {
+	once  sync.Once
+	lists map[string]*Wordlist
+}
+
+// BIP39Languages returns the languages for which BIP39Wordlist has a list,
+// such as "english", "japanese" and "chinese_simplified".
+func BIP39Languages() []string {
+	return append([]string(nil), bip39Languages...)
+}
+
+// BIP39Wordlist returns the BIP-39 wordlist for language, one of
+// BIP39Languages, or nil if there's none. Japanese words are separated by
+// an ideographic space, as BIP-39 recommends.
+func BIP39Wordlist(language string) *Wordlist {
+	bip39Wordlists.once.Do(func() {
+		bip39Wordlists.lists = make(map[string]*Wordlist, len(bip39Languages))
+		for _, language := range bip39Languages {
+			w, err := NewWordlist(language, bip39.Words(language))
+			if err != nil {
+				panic("shamirsplit: bad BIP-39 wordlist: " + err.Error())
+			}
+			if language == "japanese" {
+				w.Separator = "\u3000"
+			}
+			bip39Wordlists.lists[language] = w
+		}
+	})
+	w, ok := bip39Wordlists.lists[language]
+	if !ok {
+		return nil
+	}
+	// The words are shared, but not the fields that callers can change.
+	c := *w
+	return &c
+}
+
+// EncodeMnemonic returns s as a mnemonic phrase of words from w.
+func EncodeMnemonic(s *Share, w *Wordlist) (string, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(data)
+	data = append(data, h[:mnemonicChecksumLen]...)
+
+	var words []string
+	var acc uint32
+	bits := 0
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 11 {
+			bits -= 11
+			words = append(words, w.words[acc>>bits&(MnemonicWords-1)])
+		}
+	}
+	if bits > 0 {
+		words = append(words, w.words[acc<<(11-bits)&(MnemonicWords-1)])
+	}
+	return strings.Join(words, w.Separator), nil
+}
+
+// DecodeMnemonic parses a mnemonic phrase from EncodeMnemonic, written with
+// any of wordlists, or, if none are given, any of the BIP-39 wordlists, and
+// returns the share and the wordlist that it's in. The wordlist is
+// detected: the first one that contains every word, and whose decoding has
+// a valid checksum, is used.
+func DecodeMnemonic(phrase string, wordlists ...*Wordlist) (Share, *Wordlist, error) {
+	words := strings.Fields(norm.NFKD.String(phrase))
+	if len(words) == 0 {
+		return Share{}, nil, errors.New("empty mnemonic")
+	}
+	if len(wordlists) == 0 {
+		for _, language := range bip39Languages {
+			wordlists = append(wordlists, BIP39Wordlist(language))
+		}
+	}
+
+	found := false
+	for _, w := range wordlists {
+		data, ok := w.decode(words)
+		if !ok {
+			continue
+		}
+		found = true
+		if s, err := parseMnemonicData(data, len(words)); err == nil {
+			return s, w, nil
+		} else if err != ErrCorruptShare {
+			return Share{}, nil, err
+		}
+	}
+	if !found {
+		return Share{}, nil, errors.New("mnemonic isn't from any of the wordlists")
+	}
+	return Share{}, nil, ErrCorruptShare
+}
+
+// decode returns the bits encoded by words, or false if any of them isn't
+// in w.
+func (w *Wordlist) decode(words []string) (data []byte, ok bool) {
+	var acc uint32
+	bits := 0
+	for _, word := range words {
+		i, ok := w.index[word]
+		if !ok {
+			return nil, false
+		}
+		acc = acc<<11 | uint32(i)
+		bits += 11
+		for bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	return data, true
+}
+
+// parseMnemonicData parses the share and checksum at the start of data,
+// which was decoded from n words. Since the last word is padded, data may
+// have a final byte of padding.
+func parseMnemonicData(data []byte, n int) (Share, error) {
+	for l := len(data); l >= 0 && 8*l > 11*(n-1); l-- {
+		if l < mnemonicChecksumLen {
+			break
+		}
+		body, checksum := data[:l-mnemonicChecksumLen], data[l-mnemonicChecksumLen:l]
+		h := sha256.Sum256(body)
+		if bytes.Equal(h[:mnemonicChecksumLen], checksum) {
+			return ParseShare(body, nil)
+		}
+	}
+	return Share{}, ErrCorruptShare
+}
@@ -0,0 +1,139 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/agl/shamirsplit/internal/norm"
+)
+
+// testWordlist returns a wordlist of words made from prefix and a number.
+func testWordlist(t *testing.T, language, prefix string) *Wordlist {
+	words := make([]string, MnemonicWords)
+	for i := range words {
+		words[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	w, err := NewWordlist(language, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestMnemonic(t *testing.T) {
+	english := testWordlist(t, "english", "word")
+	french := testWordlist(t, "french", "mot\u00e9")
+	japanese := testWordlist(t, "japanese", "ことば")
+	japanese.Separator = "　"
+
+	secret := big.NewInt(271828)
+	set, err := Deal(secret, 3, WithThreshold(2), WithField(NamedModulus("p256")), WithParticipants([]string{"a", "bb", "ccc"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, w := range []*Wordlist{english, french, japanese} {
+		phrase, err := EncodeMnemonic(&set.Shares[i], w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w == french {
+			// Encoded phrases are in NFKD, so this is as if they
+			// were typed with precomposed characters.
+			phrase = strings.ReplaceAll(phrase, "e\u0301", "\u00e9")
+		}
+		s, found, err := DecodeMnemonic(phrase, english, french, japanese)
+		if err != nil {
+			t.Fatalf("%s: %s", w.Language, err)
+		}
+		if found != w {
+			t.Errorf("%s: detected %s", w.Language, found.Language)
+		}
+		if s.X.Cmp(set.Shares[i].X) != 0 || s.Y.Cmp(set.Shares[i].Y) != 0 || s.Participant != set.Shares[i].Participant {
+			t.Errorf("%s: share didn't round trip", w.Language)
+		}
+	}
+
+	phrase, _ := EncodeMnemonic(&set.Shares[0], english)
+	words := strings.Fields(phrase)
+	words[3], words[4] = words[4], words[3]
+	if _, _, err := DecodeMnemonic(strings.Join(words, " "), english); err != ErrCorruptShare {
+		t.Errorf("swapped words gave %v, want ErrCorruptShare", err)
+	}
+	if _, _, err := DecodeMnemonic(phrase, french, japanese); err == nil {
+		t.Error("phrase was decoded with the wrong wordlists")
+	}
+
+	if _, err := NewWordlist("short", []string{"a", "b"}); err == nil {
+		t.Error("short wordlist was accepted")
+	}
+	words = make([]string, MnemonicWords)
+	for i := range words {
+		words[i] = fmt.Sprint(i % 2000)
+	}
+	if _, err := NewWordlist("duplicates", words); err == nil {
+		t.Error("wordlist with duplicates was accepted")
+	}
+}
+
+func TestBIP39Wordlists(t *testing.T) {
+	for _, language := range BIP39Languages() {
+		if BIP39Wordlist(language) == nil {
+			t.Errorf("no wordlist for %s", language)
+		}
+	}
+	if BIP39Wordlist("klingon") != nil {
+		t.Error("got a wordlist for a language without one")
+	}
+
+	// Test vectors from BIP-39 and from its Japanese test vectors, each
+	// of which encodes sixteen bytes of 0x7f and a checksum.
+	for language, phrase := range map[string]string{
+		"english":  "legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"japanese": "そつう　れきだい　ほんやく　わかす　りくつ　ばいか　ろせん　やちん　そつう　れきだい　ほんやく　わかめ",
+	} {
+		w := BIP39Wordlist(language)
+		data, ok := w.decode(strings.Fields(norm.NFKD.String(phrase)))
+		if !ok {
+			t.Errorf("%s: phrase isn't in the wordlist", language)
+			continue
+		}
+		if want := strings.Repeat("\x7f", 16); string(data[:16]) != want {
+			t.Errorf("%s: decoded %x, want %x", language, data[:16], want)
+		}
+	}
+
+	secret := big.NewInt(314159)
+	set, err := Deal(secret, 3, WithThreshold(2), WithField(NamedModulus("p256")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, language := range []string{"japanese", "spanish", "english"} {
+		w := BIP39Wordlist(language)
+		phrase, err := EncodeMnemonic(&set.Shares[0], w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if language == "japanese" && !strings.Contains(phrase, "　") {
+			t.Errorf("Japanese phrase isn't separated by ideographic spaces: %q", phrase)
+		}
+		s, found, err := DecodeMnemonic(phrase)
+		if err != nil {
+			t.Fatalf("%s: %s", language, err)
+		}
+		if found.Language != language {
+			t.Errorf("%s: detected %s", language, found.Language)
+		}
+		if s.X.Cmp(set.Shares[0].X) != 0 || s.Y.Cmp(set.Shares[0].Y) != 0 {
+			t.Errorf("%s: share didn't round trip", language)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestGenerateModulus(t *testing.T) {
+	modulus, err := GenerateModulus(256, nil)
+	if err != nil {
+		t.Fatalf("failed to generate modulus: %s", err)
+	}
+
+	if modulus.BitLen() != 256 || !modulus.ProbablyPrime(20) {
+		t.Errorf("bad modulus: %s", modulus)
+	}
+
+	secret := new(big.Int).Lsh(big.NewInt(1), 254)
+	shares, err := Split(secret, modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	result, err := Join(shares[1:], []int{1, 2}, modulus)
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to recover secret: %v", err)
+	}
+
+	if _, err := GenerateModulus(1, nil); err == nil {
+		t.Errorf("one bit modulus was accepted")
+	}
+}
+
+func TestNamedModuli(t *testing.T) {
+	if MODP2048.Text(16) != strings.ToLower(modulusStr) {
+		t.Errorf("MODP2048 doesn't match RFC 3526")
+	}
+
+	for name, m := range namedModuli {
+		if !m.ProbablyPrime(0) {
+			t.Errorf("%s isn't prime", name)
+		}
+		if strings.HasPrefix(name, "modp") && !new(big.Int).Rsh(m, 1).ProbablyPrime(0) {
+			t.Errorf("%s isn't a safe prime", name)
+		}
+	}
+
+	// P256Order must not alias crypto/elliptic's value, which callers
+	// could then change for the whole process.
+	if n := elliptic.P256().Params().N; P256Order == n || P256Order.Cmp(n) != 0 {
+		t.Errorf("P256Order is aliased or wrong")
+	}
+
+	m := NamedModulus("secp256k1")
+	m.SetInt64(1)
+	if Secp256k1Order.Cmp(m) == 0 {
+		t.Errorf("NamedModulus didn't return a copy")
+	}
+
+	if NamedModulus("unknown") != nil {
+		t.Errorf("unknown name returned a modulus")
+	}
+}
+
+func TestGenerateSafePrimeModulus(t *testing.T) {
+	calls := 0
+	sp, err := GenerateSafePrimeModulus(128, nil, func(candidates int) error {
+		calls++
+		if candidates != calls {
+			t.Errorf("progress got %d candidates on call %d", candidates, calls)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sp.P.BitLen() != 128 || !IsSafePrime(sp.P) || new(big.Int).Rsh(sp.P, 1).Cmp(sp.Q) != 0 {
+		t.Errorf("%s isn't a 128-bit safe prime with q = %s", sp.P, sp.Q)
+	}
+	if r := Analyze(DealingParams{Modulus: sp.P, Threshold: 2, Shares: 3}); !r.SafePrime {
+		t.Error("Analyze didn't report a safe prime")
+	}
+
+	if !IsSafePrime(MODP2048) || IsSafePrime(P256Order) || IsSafePrime(big.NewInt(13)) {
+		t.Error("IsSafePrime gave the wrong answer for a known value")
+	}
+
+	stop := errors.New("stop")
+	// The first candidate is occasionally a safe prime, in which case
+	// progress isn't called.
+	sp, err = GenerateSafePrimeModulus(1024, nil, func(int) error { return stop })
+	if err != stop && (err != nil || !IsSafePrime(sp.P)) {
+		t.Errorf("cancelled generation gave %v", err)
+	}
+}
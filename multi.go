@@ -0,0 +1,130 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// SplitMulti packs several secrets into a single polynomial of degree k-1, so
+// that each of the n shares protects all of them, and any k shares can be
+// combined by JoinMulti to recover them. The secrets are the values of the
+// polynomial at -1, -2, ..., -len(secrets), and the shares its values at
+// 1, 2, ..., n.
+//
+// Packing weakens the scheme: only k-len(secrets) shares are guaranteed to
+// reveal nothing, and between that and k shares reveal partial information
+// about the secrets. So len(secrets) must be less than k, and the modulus
+// larger than n+k. If rand is nil, crypto/rand.Reader is used.
+func SplitMulti(secrets []*big.Int, modulus *big.Int, k, n int, rand io.Reader) ([]Share, error) {
+	m := len(secrets)
+	if m < 1 || k < 1 || n < k {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+	if m >= k {
+		return nil, errors.New("at least one more share than secrets must be required to recover them")
+	}
+	if modulus.Cmp(big.NewInt(int64(n)+int64(k))) <= 0 {
+		return nil, errors.New("modulus is too small")
+	}
+	rand = defaultRand(rand)
+
+	// The polynomial is defined by the secrets and k-m random values at
+	// -m-1, ..., -k.
+	xs := make([]*big.Int, k)
+	ys := make([]*big.Int, k)
+	for j := range xs {
+		xs[j] = new(big.Int).Sub(modulus, big.NewInt(int64(j+1)))
+		if j < m {
+			if secrets[j].Sign() < 0 || secrets[j].Cmp(modulus) >= 0 {
+				return nil, errors.New("secret must be less than split modulus")
+			}
+			ys[j] = secrets[j]
+			continue
+		}
+		var err error
+		if ys[j], err = randomNumber(rand, modulus); err != nil {
+			return nil, err
+		}
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		shares[i] = Share{X: x, Y: interpolateAt(xs, ys, x, modulus), Modulus: modulus}
+	}
+	return shares, nil
+}
+
+// JoinMulti recovers the count secrets from at least k shares that resulted
+// from SplitMulti. The x coordinates of the shares must be less than the
+// modulus minus k.
+func JoinMulti(shares []Share, count int) ([]*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+	modulus := shares[0].Modulus
+	if modulus == nil {
+		return nil, errors.New("shares don't record their modulus")
+	}
+	if count < 1 || count >= len(shares) {
+		return nil, errors.New("too few shares for the number of secrets")
+	}
+	if err := checkLimits(len(shares), len(shares)); err != nil {
+		return nil, err
+	}
+
+	xs := make([]*big.Int, len(shares))
+	ys := make([]*big.Int, len(shares))
+	limit := new(big.Int).Sub(modulus, big.NewInt(int64(len(shares))))
+	for i, s := range shares {
+		if s.Modulus == nil || s.Modulus.Cmp(modulus) != 0 {
+			return nil, errors.New("shares are from different splits")
+		}
+		if s.X == nil || s.Y == nil || s.X.Cmp(limit) >= 0 {
+			return nil, errors.New("share has invalid coordinates")
+		}
+		xs[i], ys[i] = s.X, s.Y
+	}
+	if err := checkXs(xs, modulus); err != nil {
+		return nil, err
+	}
+
+	secrets := make([]*big.Int, count)
+	for j := range secrets {
+		secrets[j] = interpolateAt(xs, ys, new(big.Int).Sub(modulus, big.NewInt(int64(j+1))), modulus)
+	}
+	return secrets, nil
+}
+
+// interpolateAt returns the value at x of the polynomial of minimal degree
+// that passes through the points (xs[i], ys[i]) modulo modulus.
+func interpolateAt(xs, ys []*big.Int, x, modulus *big.Int) *big.Int {
+	result := new(big.Int)
+	for i := range xs {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			num.Mul(num, new(big.Int).Sub(x, xs[j]))
+			num.Mod(num, modulus)
+			den.Mul(den, new(big.Int).Sub(xs[i], xs[j]))
+			den.Mod(den, modulus)
+		}
+		den.ModInverse(den, modulus)
+		num.Mul(num, den)
+		num.Mul(num, ys[i])
+		result.Add(result, num)
+	}
+	return result.Mod(result, modulus)
+}
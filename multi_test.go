@@ -0,0 +1,42 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMulti(t *testing.T) {
+	secrets := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	shares, err := SplitMulti(secrets, MODP2048, 5, 7, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	result, err := JoinMulti(shares[2:], len(secrets))
+	if err != nil {
+		t.Fatalf("error while joining: %s", err)
+	}
+	for i := range secrets {
+		if result[i].Cmp(secrets[i]) != 0 {
+			t.Errorf("secret %d: got %s, want %s", i, result[i], secrets[i])
+		}
+	}
+
+	result, _ = JoinMulti(shares[3:], len(secrets))
+	if result[0].Cmp(secrets[0]) == 0 {
+		t.Errorf("recovered a secret from too few shares")
+	}
+
+	if _, err := SplitMulti(secrets, MODP2048, 3, 5, nil); err == nil {
+		t.Errorf("packed as many secrets as the threshold")
+	}
+	if _, err := SplitMulti(secrets, big.NewInt(11), 4, 7, nil); err == nil {
+		t.Errorf("split with a modulus that's too small")
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+
+	"github.com/agl/shamirsplit/internal/bcryptpbkdf"
+)
+
+// openSSHShareType is the PEM block type of the shares from SplitOpenSSHKey.
+// The contents of each block are a storage shard (see storage.go) of the key
+// file, byte for byte, with the key's public key blob as the info.
+const openSSHShareType = "SHAMIRSPLIT OPENSSH KEY SHARE"
+
+const openSSHKeyMagic = "openssh-key-v1\x00"
+
+// maxBcryptRounds is the largest number of bcrypt_pbkdf rounds that an
+// encrypted key may ask for, so that a corrupt key can't make decrypting it
+// take days. ssh-keygen uses 16 by default.
+const maxBcryptRounds = 1 << 12
+
+// openSSHCiphers are the ciphers of encrypted OpenSSH keys that can be
+// decrypted, with their key, IV and authentication tag sizes.
+var openSSHCiphers = map[string]struct{ keyLen, ivLen, tagLen int }{
+	"aes128-ctr":             {16, aes.BlockSize, 0},
+	"aes192-ctr":             {24, aes.BlockSize, 0},
+	"aes256-ctr":             {32, aes.BlockSize, 0},
+	"aes128-gcm@openssh.com": {16, 12, 16},
+	"aes256-gcm@openssh.com": {32, 12, 16},
+}
+
+// SplitOpenSSHKey splits the OpenSSH private key file in keyFile, which holds
+// an "OPENSSH PRIVATE KEY" PEM block, into n PEM-encoded shares, any k of
+// which can be combined by JoinOpenSSHKey to recover the file exactly. The
+// file is encrypted and split as by SplitStorage.
+//
+// The key is checked for consistency before it's split. If the key is
+// protected by a passphrase, passphrase is called to get it, so that the
+// private part can be decrypted and checked; the key is still split as it
+// is, encrypted, and recovering it needs the passphrase as well as the
+// shares. If passphrase is nil, encrypted keys are rejected.
+func SplitOpenSSHKey(keyFile []byte, k, n int, passphrase func() ([]byte, error)) ([][]byte, error) {
+	pub, err := parseOpenSSHKeyFile(keyFile, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	shards, err := splitStorage(keyFile, pub, k, n, nil)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([][]byte, n)
+	for i, shard := range shards {
+		shares[i] = pem.EncodeToMemory(&pem.Block{Type: openSSHShareType, Bytes: shard})
+	}
+	return shares, nil
+}
+
+// JoinOpenSSHKey recovers an OpenSSH private key file from at least k shares
+// that resulted from SplitOpenSSHKey. The result is identical to the file
+// that was split, and can be used directly by ssh and sshd. As with
+// SplitOpenSSHKey, passphrase is called to decrypt and check an encrypted
+// key.
+func JoinOpenSSHKey(shares [][]byte, passphrase func() ([]byte, error)) ([]byte, error) {
+	shards := make([][]byte, len(shares))
+	for i, share := range shares {
+		block, _ := pem.Decode(share)
+		if block == nil || block.Type != openSSHShareType {
+			return nil, errors.New("share isn't an OpenSSH key share")
+		}
+		shards[i] = block.Bytes
+	}
+
+	keyFile, pub, err := joinStorage(shards)
+	if err != nil {
+		return nil, err
+	}
+	recovered, err := parseOpenSSHKeyFile(keyFile, passphrase)
+	if err != nil {
+		clear(keyFile)
+		return nil, err
+	}
+	if !bytes.Equal(recovered, pub) {
+		clear(keyFile)
+		return nil, errors.New("recovered key doesn't match the recorded public key")
+	}
+	return keyFile, nil
+}
+
+// parseOpenSSHKeyFile parses an OpenSSH private key file, as described in
+// PROTOCOL.key in the OpenSSH sources, and returns the key's public key
+// blob. The private part of the key is checked, after decrypting it with
+// the result of passphrase if the key is encrypted.
+func parseOpenSSHKeyFile(keyFile []byte, passphrase func() ([]byte, error)) (pub []byte, err error) {
+	block, _ := pem.Decode(keyFile)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	if block.Type != "OPENSSH PRIVATE KEY" {
+		return nil, errors.New("unsupported PEM block type: " + block.Type)
+	}
+	defer clear(block.Bytes)
+
+	rest, ok := bytes.CutPrefix(block.Bytes, []byte(openSSHKeyMagic))
+	if !ok {
+		return nil, errors.New("invalid OpenSSH private key")
+	}
+	var cipherName, kdf, kdfOptions, private []byte
+	ok = true
+	cipherName, rest, ok = readSSHString(rest, ok)
+	kdf, rest, ok = readSSHString(rest, ok)
+	kdfOptions, rest, ok = readSSHString(rest, ok)
+	if !ok || len(rest) < 4 || binary.BigEndian.Uint32(rest) != 1 {
+		return nil, errors.New("OpenSSH private key file must hold exactly one key")
+	}
+	pub, rest, ok = readSSHString(rest[4:], ok)
+	private, rest, ok = readSSHString(rest, ok)
+	if !ok {
+		return nil, errors.New("invalid OpenSSH private key")
+	}
+
+	switch {
+	case string(cipherName) == "none" && string(kdf) == "none":
+		if len(rest) != 0 {
+			return nil, errors.New("invalid OpenSSH private key")
+		}
+	case string(cipherName) == "none" || string(kdf) == "none":
+		return nil, errors.New("invalid OpenSSH private key encryption")
+	default:
+		// An authenticated cipher's tag follows the private section.
+		// Decrypting in place is safe because block.Bytes is a copy.
+		private, err = decryptOpenSSHPrivate(string(cipherName), string(kdf), kdfOptions, private, rest, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := checkOpenSSHPrivate(private, pub); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(pub), nil
+}
+
+// decryptOpenSSHPrivate decrypts, in place, the private section of an
+// OpenSSH key file that's encrypted with cipherName under a key derived by
+// kdf from the result of passphrase. tag is the rest of the key file, which
+// is the authentication tag for an authenticated cipher and otherwise empty.
+func decryptOpenSSHPrivate(cipherName, kdf string, kdfOptions, private, tag []byte, passphrase func() ([]byte, error)) ([]byte, error) {
+	params, ok := openSSHCiphers[cipherName]
+	if !ok {
+		return nil, errors.New("unsupported OpenSSH private key cipher: " + cipherName)
+	}
+	if kdf != "bcrypt" {
+		return nil, errors.New("unsupported OpenSSH private key KDF: " + kdf)
+	}
+	salt, rest, ok := readSSHString(kdfOptions, true)
+	if !ok || len(rest) != 4 {
+		return nil, errors.New("invalid OpenSSH private key KDF options")
+	}
+	rounds := binary.BigEndian.Uint32(rest)
+	if rounds > maxBcryptRounds {
+		return nil, errors.New("OpenSSH private key KDF rounds are too large")
+	}
+	if len(tag) != params.tagLen || len(private)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid OpenSSH private key")
+	}
+	if passphrase == nil {
+		return nil, errors.New("OpenSSH private key is encrypted but no passphrase was given")
+	}
+
+	pass, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	defer clear(pass)
+	keyIV, err := bcryptpbkdf.Key(pass, salt, int(rounds), params.keyLen+params.ivLen)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(keyIV)
+	block, err := aes.NewCipher(keyIV[:params.keyLen])
+	if err != nil {
+		return nil, err
+	}
+	iv := keyIV[params.keyLen:]
+
+	if params.tagLen == 0 {
+		cipher.NewCTR(block, iv).XORKeyStream(private, private)
+	} else {
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		// The ciphertext and tag are contiguous in the key file.
+		if _, err := aead.Open(private[:0], iv, private[:len(private)+len(tag)], nil); err != nil {
+			return nil, errors.New("incorrect passphrase for OpenSSH private key")
+		}
+	}
+	if len(private) < 8 || subtle.ConstantTimeCompare(private[:4], private[4:8]) != 1 {
+		return nil, errors.New("incorrect passphrase for OpenSSH private key")
+	}
+	return private, nil
+}
+
+// checkOpenSSHPrivate checks the unencrypted private section of an OpenSSH
+// key file against the key's public key blob, pub.
+func checkOpenSSHPrivate(private, pub []byte) error {
+	if len(private) < 8 || subtle.ConstantTimeCompare(private[:4], private[4:8]) != 1 {
+		return errors.New("invalid OpenSSH private key")
+	}
+	keyType, _, ok := readSSHString(pub, true)
+	privateType, rest, ok := readSSHString(private[8:], ok)
+	if !ok || !bytes.Equal(keyType, privateType) {
+		return errors.New("OpenSSH private key doesn't match its public key")
+	}
+
+	if string(keyType) == "ssh-ed25519" {
+		var edPub, edPriv []byte
+		edPub, rest, ok = readSSHString(rest, ok)
+		edPriv, _, ok = readSSHString(rest, ok)
+		if !ok || len(edPub) != ed25519.PublicKeySize || len(edPriv) != ed25519.PrivateKeySize {
+			return errors.New("invalid OpenSSH Ed25519 key")
+		}
+		derived := ed25519.NewKeyFromSeed(edPriv[:ed25519.SeedSize])
+		defer clear(derived)
+		if subtle.ConstantTimeCompare(derived, edPriv) != 1 || !bytes.HasSuffix(pub, edPub) {
+			return errors.New("OpenSSH private key doesn't match its public key")
+		}
+	}
+	return nil
+}
+
+// readSSHString reads a uint32 length-prefixed string from b, as in the SSH
+// wire format. ok is false if b is too short or if it was already false, so
+// that a run of reads can be checked once.
+func readSSHString(b []byte, ok bool) (s, rest []byte, stillOK bool) {
+	if !ok || len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
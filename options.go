@@ -0,0 +1,335 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// SplitOptions configure Deal. They're set with SplitOption functions, such
+// as WithThreshold, rather than directly.
+type SplitOptions struct {
+	// Threshold is the number of shares needed to recover the secret.
+	Threshold int
+	// Modulus is the prime modulus of the field.
+	Modulus *big.Int
+	// Xs, if not nil, are the x coordinates of the shares, as for SplitAt.
+	// Otherwise they are 1, 2, ..., n.
+	Xs []*big.Int
+	// P and G, if not nil, are the group used for Feldman commitments, as
+	// for SplitVerifiable.
+	P, G *big.Int
+	// Metadata is recorded in every share. If nil, metadata with the
+	// threshold and a random set ID is used.
+	Metadata *Metadata
+	// MAC is true if the shares should be authenticated, as by
+	// AuthenticateShares.
+	MAC bool
+	// ReduceSecret is true if the secret should be reduced modulo the
+	// modulus, rather than rejected if it's out of range.
+	ReduceSecret bool
+	// Rand is the source of randomness, or nil for crypto/rand.Reader.
+	Rand io.Reader
+	// Limits, if not nil, replace DefaultLimits.
+	Limits *Limits
+	// Participants, if not nil, are the participant labels of the shares,
+	// as for LabelShares.
+	Participants []string
+	// Hints, if not nil, are the hints of the shares, as for HintShares.
+	Hints []string
+	// Mandatory, if not empty, is the participant whose share is needed,
+	// as well as any Threshold-1 others, to recover the secret.
+	Mandatory string
+	// SelfTests is the number of random subsets of Threshold shares that
+	// are combined, and checked to recover the secret, before the shares
+	// are returned.
+	SelfTests int
+	// Events, if not nil, is told of each share dealt.
+	Events EventSink
+}
+
+// A SplitOption sets one of the SplitOptions.
+type SplitOption func(*SplitOptions)
+
+// WithThreshold sets the number of shares needed to recover the secret. It's
+// required.
+func WithThreshold(k int) SplitOption {
+	return func(o *SplitOptions) { o.Threshold = k }
+}
+
+// WithField sets the prime modulus of the field that the secret is shared
+// in. It's required.
+func WithField(modulus *big.Int) SplitOption {
+	return func(o *SplitOptions) { o.Modulus = modulus }
+}
+
+// WithXs sets the x coordinates of the shares, which must be distinct and in
+// the range [1, modulus). There must be one for each share.
+func WithXs(xs []*big.Int) SplitOption {
+	return func(o *SplitOptions) { o.Xs = xs }
+}
+
+// WithCommitments causes Feldman commitments to be made in the group
+// generated by g mod p, whose order must be the modulus of the field.
+func WithCommitments(p, g *big.Int) SplitOption {
+	return func(o *SplitOptions) { o.P, o.G = p, g }
+}
+
+// WithMetadata records m in every share, in place of the default metadata.
+func WithMetadata(m *Metadata) SplitOption {
+	return func(o *SplitOptions) { o.Metadata = m }
+}
+
+// WithMAC causes the shares to carry a MAC under a fresh key, as set by
+// AuthenticateShares.
+func WithMAC() SplitOption {
+	return func(o *SplitOptions) { o.MAC = true }
+}
+
+// WithReducedSecret causes a secret that is negative, or not less than the
+// modulus, to be reduced into range rather than rejected. The secret that
+// JoinShares recovers is then the reduced value.
+func WithReducedSecret() SplitOption {
+	return func(o *SplitOptions) { o.ReduceSecret = true }
+}
+
+// WithRand sets the source of randomness. Without it, crypto/rand.Reader is
+// used. CombineEntropy returns a source that several operators contribute
+// to.
+func WithRand(rand io.Reader) SplitOption {
+	return func(o *SplitOptions) { o.Rand = rand }
+}
+
+// WithDRBG sets the source of randomness to d, which is reseeded from entropy,
+// or crypto/rand.Reader if nil, as needed. See DRBGReader.
+func WithDRBG(d DRBG, entropy io.Reader) SplitOption {
+	return func(o *SplitOptions) { o.Rand = &DRBGReader{DRBG: d, Entropy: entropy} }
+}
+
+// WithLimits replaces DefaultLimits for one split.
+func WithLimits(l Limits) SplitOption {
+	return func(o *SplitOptions) { o.Limits = &l }
+}
+
+// WithParticipants labels the shares with the names of the participants
+// that they're for, as LabelShares does.
+func WithParticipants(names []string) SplitOption {
+	return func(o *SplitOptions) { o.Participants = names }
+}
+
+// WithHints sets a hint for each share, such as where it's to be stored, as
+// HintShares does.
+func WithHints(hints []string) SplitOption {
+	return func(o *SplitOptions) { o.Hints = hints }
+}
+
+// WithMandatory makes the share of participant, who must be named by
+// WithParticipants, necessary to recover the secret: it takes part in every
+// recovery along with any k-1 other shares, such as when one share is held
+// by an HSM. The secret is split 2-of-2, with one half given to participant
+// and the other split (k-1)-of-(n-1) between the others. Commitments can't be
+// used with it.
+func WithMandatory(participant string) SplitOption {
+	return func(o *SplitOptions) { o.Mandatory = participant }
+}
+
+// WithSelfTest causes the secret to be recovered from rounds random subsets
+// of k shares, and compared with the original, before the shares are
+// returned. If any differ, Deal fails with ErrSelfTestFailed. This gives
+// assurance that the shares work before the secret is destroyed.
+func WithSelfTest(rounds int) SplitOption {
+	return func(o *SplitOptions) { o.SelfTests = rounds }
+}
+
+// WithEventSink causes Deal to report each share that it deals to sink.
+func WithEventSink(sink EventSink) SplitOption {
+	return func(o *SplitOptions) { o.Events = sink }
+}
+
+// ErrSelfTestFailed is returned by Deal if a self-test, requested with
+// WithSelfTest, didn't recover the secret.
+var ErrSelfTestFailed = errors.New("shares failed self-test")
+
+// Deal splits secret into n self-describing shares, configured by opts,
+// which must include at least WithThreshold and WithField. The shares record
+// their x coordinates and the modulus, and can be recombined with JoinShares.
+func Deal(secret *big.Int, n int, opts ...SplitOption) (set *ShareSet, err error) {
+	var o SplitOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	k, modulus := o.Threshold, o.Modulus
+	if modulus == nil {
+		return nil, errors.New("no modulus given")
+	}
+	if k < 1 || n < k {
+		return nil, errors.New("invalid split parameters")
+	}
+	limits := o.Limits
+	if limits == nil {
+		limits = &DefaultLimits
+	}
+	if err := limits.check(k, n); err != nil {
+		return nil, err
+	}
+
+	if o.ReduceSecret {
+		secret = new(big.Int).Mod(secret, modulus)
+	}
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+	original := secret
+
+	xs := o.Xs
+	if xs == nil {
+		xs = make([]*big.Int, n)
+		for i := range xs {
+			xs[i] = big.NewInt(int64(i + 1))
+		}
+	} else if len(xs) != n {
+		return nil, errors.New("wrong number of x coordinates")
+	}
+	if err := checkXs(xs, modulus); err != nil {
+		return nil, err
+	}
+
+	if (o.P == nil) != (o.G == nil) {
+		return nil, errors.New("commitments need both p and g")
+	}
+	if o.G != nil && new(big.Int).Exp(o.G, modulus, o.P).Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("modulus is not the order of the generator")
+	}
+
+	mandatory := -1
+	if len(o.Mandatory) > 0 {
+		if mandatory = indexOfParticipant(o.Participants, o.Mandatory); mandatory < 0 {
+			return nil, errors.New("mandatory participant isn't one of the participants")
+		}
+		if k < 2 {
+			return nil, errors.New("mandatory share needs a threshold of at least two")
+		}
+		if o.G != nil {
+			return nil, errors.New("commitments can't be used with a mandatory share")
+		}
+	}
+
+	rand := defaultRand(o.Rand)
+	var half *big.Int
+	if mandatory >= 0 {
+		// The other shares are a (k-1)-of-(n-1) split of secret - half.
+		if half, err = randomNumber(rand, modulus); err != nil {
+			return nil, err
+		}
+		secret = new(big.Int).Sub(secret, half)
+		secret.Mod(secret, modulus)
+		k--
+	}
+	a, err := randomPolynomial(secret, modulus, k, rand)
+	if err != nil {
+		return
+	}
+
+	m := o.Metadata
+	if m == nil {
+		if m, err = newSetMetadata(o.Threshold, rand); err != nil {
+			return nil, err
+		}
+	}
+
+	set = &ShareSet{Modulus: modulus, Threshold: o.Threshold, Shares: make([]Share, n)}
+	ys := make([]big.Int, n)
+	var e evaluator
+	for i, x := range xs {
+		set.Shares[i] = Share{
+			X:        x,
+			Y:        e.evaluate(&ys[i], a, x, modulus),
+			Modulus:  modulus,
+			Metadata: m,
+		}
+	}
+	if mandatory >= 0 {
+		for i := range set.Shares {
+			set.Shares[i].Mandatory = xs[mandatory]
+		}
+		set.Shares[mandatory].Y = half
+	}
+
+	if o.G != nil {
+		c := &Commitments{P: o.P, G: o.G, Values: make([]*big.Int, k), Epoch: m.Epoch}
+		for j := range a {
+			c.Values[j] = new(big.Int).Exp(o.G, a[j], o.P)
+		}
+		set.Commitments = c
+	}
+
+	if o.Participants != nil {
+		if err := LabelShares(set.Shares, o.Participants); err != nil {
+			return nil, err
+		}
+	}
+	if o.Hints != nil {
+		if err := HintShares(set.Shares, o.Hints); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.MAC {
+		if err := AuthenticateShares(set.Shares, rand); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := selfTest(set.Shares, o.Threshold, original, o.SelfTests, rand); err != nil {
+		return nil, err
+	}
+	if o.Events != nil {
+		for i := range set.Shares {
+			o.Events.ShareDealt(shareEvent(&set.Shares[i]))
+		}
+	}
+	return set, nil
+}
+
+// selfTest recovers the secret from rounds random subsets of k of the shares
+// and returns ErrSelfTestFailed unless they all match secret.
+func selfTest(shares []Share, k int, secret *big.Int, rounds int, rand io.Reader) error {
+	subset := make([]Share, k)
+	for r := 0; r < rounds; r++ {
+		p, err := randomPermutation(rand, len(shares))
+		if err != nil {
+			return err
+		}
+		for i := range subset {
+			subset[i] = shares[p[i]]
+		}
+		if x := shares[0].Mandatory; x != nil {
+			// Every recovery needs the mandatory share, so make
+			// sure that it's the last of the subset.
+			last := len(subset) - 1
+			for i, s := range subset {
+				if s.X.Cmp(x) == 0 {
+					subset[i], subset[last] = subset[last], subset[i]
+				}
+			}
+			for _, s := range shares {
+				if s.X.Cmp(x) == 0 {
+					subset[last] = s
+				}
+			}
+		}
+
+		result, err := JoinShares(subset)
+		if err != nil || result.Cmp(secret) != 0 {
+			return ErrSelfTestFailed
+		}
+	}
+	return nil
+}
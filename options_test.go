@@ -0,0 +1,83 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestDeal(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	m, err := NewMetadata(3, "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xs := []*big.Int{big.NewInt(10), big.NewInt(20), big.NewInt(30), big.NewInt(40)}
+
+	secret := big.NewInt(42)
+	set, err := Deal(secret, 4, WithThreshold(3), WithField(q), WithXs(xs),
+		WithCommitments(p, big.NewInt(4)), WithMetadata(m), WithMAC())
+	if err != nil {
+		t.Fatalf("error while dealing: %s", err)
+	}
+
+	for i, s := range set.Shares {
+		if s.X.Cmp(xs[i]) != 0 || s.Metadata != m || s.MACKey == nil {
+			t.Errorf("share %d wasn't dealt as configured", i)
+		}
+		if !set.Commitments.Verify(s) {
+			t.Errorf("share %d failed to verify", i)
+		}
+		if _, err := s.MarshalBinary(); err != nil {
+			t.Errorf("share %d failed to marshal: %s", i, err)
+		}
+	}
+
+	result, err := JoinShares(set.Shares[1:])
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("got %s, want %s", result, secret)
+	}
+
+	if _, err := Deal(secret, 4, WithField(q)); err == nil {
+		t.Errorf("deal without a threshold succeeded")
+	}
+	if _, err := Deal(secret, 4, WithThreshold(2)); err == nil {
+		t.Errorf("deal without a modulus succeeded")
+	}
+	if _, err := Deal(secret, 4, WithThreshold(2), WithField(q), WithLimits(Limits{MaxThreshold: 2, MaxShares: 3})); err != ErrLimitExceeded {
+		t.Errorf("deal over limits gave %v, want ErrLimitExceeded", err)
+	}
+	if _, err := Deal(secret, 5, WithThreshold(2), WithField(q), WithXs(xs)); err == nil {
+		t.Errorf("deal with too few x coordinates succeeded")
+	}
+}
+
+func TestDealSelfTest(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(42)
+	set, err := Deal(secret, 5, WithThreshold(3), WithField(p), WithSelfTest(10), WithMAC())
+	if err != nil {
+		t.Fatalf("error while dealing: %s", err)
+	}
+
+	if err := selfTest(set.Shares, 3, secret, 10, rand.Reader); err != nil {
+		t.Errorf("self-test of good shares failed: %s", err)
+	}
+
+	// A corrupt share is found once it's in a tested subset, which, since
+	// all the shares are used, is certain here.
+	set.Shares[2].Y = new(big.Int).Add(set.Shares[2].Y, big.NewInt(1))
+	if err := selfTest(set.Shares, 5, secret, 1, rand.Reader); err != ErrSelfTestFailed {
+		t.Errorf("self-test of corrupt shares gave %v, want ErrSelfTestFailed", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"strconv"
+	"unicode/utf8"
+)
+
+// MaxParticipantLen is the maximum length in bytes of a participant label.
+const MaxParticipantLen = 256
+
+// LabelShares sets the Participant of shares[i] to names[i], so that
+// collection ceremonies can refer to people, or places, instead of share
+// numbers. The names must be distinct, valid UTF-8 and no longer than
+// MaxParticipantLen. Labels are covered by MACs and signatures, so shares
+// must be labelled before AuthenticateShares or SignShares.
+func LabelShares(shares []Share, names []string) error {
+	if len(names) != len(shares) {
+		return errors.New("need one participant per share")
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if len(name) == 0 || len(name) > MaxParticipantLen || !utf8.ValidString(name) {
+			return errors.New("invalid participant " + strconv.Quote(name))
+		}
+		if seen[name] {
+			return errors.New("duplicate participant " + strconv.Quote(name))
+		}
+		seen[name] = true
+	}
+	for i := range shares {
+		shares[i].Participant = names[i]
+	}
+	return nil
+}
+
+// A ParticipantError is returned by Combiner.Add for a share that was
+// rejected, when the share names its participant.
+type ParticipantError struct {
+	Participant string
+	Err         error
+}
+
+func (e *ParticipantError) Error() string {
+	return "share of " + strconv.Quote(e.Participant) + ": " + e.Err.Error()
+}
+
+func (e *ParticipantError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,60 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParticipants(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	names := []string{"alice@ops", "bob@ops", "safe-deposit-box-2"}
+	set, err := Deal(big.NewInt(7), 3, WithThreshold(2), WithField(modulus), WithParticipants(names), WithMAC(), WithRand(rand.Reader))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := set.Shares[2].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Share
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if s.Participant != "safe-deposit-box-2" {
+		t.Errorf("got participant %q", s.Participant)
+	}
+	d, err := Describe(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d.String(), `participant="safe-deposit-box-2"`) {
+		t.Errorf("description %q lacks the participant", d)
+	}
+
+	c, _ := NewCombiner(0, nil)
+	if err := c.Add(set.Shares[0]); err != nil {
+		t.Fatal(err)
+	}
+	err = c.Add(set.Shares[0])
+	var perr *ParticipantError
+	if !errors.As(err, &perr) || perr.Participant != "alice@ops" || !strings.Contains(err.Error(), `"alice@ops"`) {
+		t.Errorf("duplicate share gave %v", err)
+	}
+
+	if err := LabelShares(set.Shares, []string{"a", "a", "b"}); err == nil {
+		t.Error("duplicate participants were accepted")
+	}
+	if err := LabelShares(set.Shares, names[:2]); err == nil {
+		t.Error("too few participants were accepted")
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/agl/shamirsplit/internal/argon2"
+)
+
+// A passphrase envelope uses the encoding of binary shares (see wire.go) with
+// its own magic and tags. The Argon2id parameters and salt are authenticated
+// as additional data.
+const passphraseMagic = "SHMP"
+
+const (
+	tagKDFTime            = 1
+	tagKDFMemory          = 2
+	tagKDFThreads         = 3
+	tagKDFSalt            = 4
+	tagEnvelopeCiphertext = 5
+)
+
+// Argon2idParams are the cost parameters for deriving a key from a
+// passphrase. Memory is in KiB.
+type Argon2idParams struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// DefaultArgon2idParams are the parameters recommended by RFC 9106 for
+// memory constrained environments.
+var DefaultArgon2idParams = Argon2idParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// maxArgon2idMemory and maxArgon2idTime limit the memory and the number of
+// passes that DecryptShareWithPassphrase will spend on an envelope, which
+// may be malicious: 4GiB and 16.
+const (
+	maxArgon2idMemory = 4 * 1024 * 1024
+	maxArgon2idTime   = 16
+)
+
+// minArgon2idSaltLen is the shortest salt that Argon2id allows.
+const minArgon2idSaltLen = 8
+
+// check returns an error if p is outside the limits above.
+func (p *Argon2idParams) check() error {
+	if p.Time < 1 || p.Time > maxArgon2idTime || p.Threads < 1 || p.Memory > maxArgon2idMemory {
+		return errors.New("invalid Argon2id parameters")
+	}
+	return nil
+}
+
+// EncryptShareWithPassphrase encrypts the binary encoding of s with AES-256-GCM
+// under a key derived from passphrase with Argon2id. If params is nil,
+// DefaultArgon2idParams are used. If rand is nil, crypto/rand.Reader is used.
+func EncryptShareWithPassphrase(s *Share, passphrase []byte, params *Argon2idParams, rand io.Reader) ([]byte, error) {
+	if params == nil {
+		params = &DefaultArgon2idParams
+	}
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(defaultRand(rand), salt); err != nil {
+		return nil, err
+	}
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var r wireRecords
+	r.addUint(tagKDFTime, uint64(params.Time))
+	r.addUint(tagKDFMemory, uint64(params.Memory))
+	r.addUint(tagKDFThreads, uint64(params.Threads))
+	r.add(tagKDFSalt, salt)
+
+	aead := passphraseAEAD(passphrase, salt, params)
+	ciphertext := aead.Seal(nil, make([]byte, aead.NonceSize()), data, r.marshalAs(passphraseMagic))
+	r.add(tagEnvelopeCiphertext, ciphertext)
+	return r.marshalAs(passphraseMagic), nil
+}
+
+// DecryptShareWithPassphrase decrypts an envelope from
+// EncryptShareWithPassphrase and parses the share within.
+func DecryptShareWithPassphrase(envelope, passphrase []byte) (s Share, err error) {
+	var params Argon2idParams
+	var salt, ciphertext []byte
+	var r wireRecords
+
+	err = parseWireAs(passphraseMagic, envelope, func(tag uint64, value []byte) error {
+		if tag != tagEnvelopeCiphertext {
+			r.add(tag, value)
+		}
+
+		switch tag {
+		case tagKDFTime, tagKDFMemory, tagKDFThreads:
+			v, err := parseWireUint(value)
+			if err != nil || v > math.MaxUint32 {
+				return errors.New("invalid Argon2id parameters")
+			}
+			switch tag {
+			case tagKDFTime:
+				params.Time = uint32(v)
+			case tagKDFMemory:
+				params.Memory = uint32(v)
+			case tagKDFThreads:
+				if v > math.MaxUint8 {
+					return errors.New("invalid Argon2id parameters")
+				}
+				params.Threads = uint8(v)
+			}
+		case tagKDFSalt:
+			salt = value
+		case tagEnvelopeCiphertext:
+			ciphertext = value
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	if err := params.check(); err != nil {
+		return s, err
+	}
+	if salt == nil || ciphertext == nil {
+		return s, errors.New("truncated envelope")
+	}
+	if len(salt) < minArgon2idSaltLen {
+		return s, errors.New("Argon2id salt is too short")
+	}
+
+	aead := passphraseAEAD(passphrase, salt, &params)
+	data, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext, r.marshalAs(passphraseMagic))
+	if err != nil {
+		return s, errors.New("incorrect passphrase or corrupt envelope")
+	}
+	err = s.UnmarshalBinary(data)
+	return
+}
+
+// passphraseAEAD returns the AEAD keyed from passphrase. Since the salt is
+// random, each key is only used once and so a zero nonce is safe.
+func passphraseAEAD(passphrase, salt []byte, params *Argon2idParams) cipher.AEAD {
+	key := argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
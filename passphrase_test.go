@@ -0,0 +1,62 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+// cheapArgon2idParams keeps the tests fast.
+var cheapArgon2idParams = &Argon2idParams{Time: 1, Memory: 64, Threads: 1}
+
+func TestPassphrase(t *testing.T) {
+	shares, _ := SplitShares(big.NewInt(42), MODP2048, 2, 3, nil)
+	shares[0].Metadata = &Metadata{Label: "alice"}
+
+	envelope, err := EncryptShareWithPassphrase(&shares[0], []byte("hunter2"), cheapArgon2idParams, nil)
+	if err != nil {
+		t.Fatalf("failed to encrypt share: %s", err)
+	}
+
+	if _, err := DecryptShareWithPassphrase(envelope, []byte("hunter3")); err == nil {
+		t.Errorf("decrypted with the wrong passphrase")
+	}
+
+	s, err := DecryptShareWithPassphrase(envelope, []byte("hunter2"))
+	if err != nil {
+		t.Fatalf("failed to decrypt share: %s", err)
+	}
+	if s.X.Cmp(shares[0].X) != 0 || s.Y.Cmp(shares[0].Y) != 0 || s.Metadata.Label != "alice" {
+		t.Errorf("decrypted share doesn't match")
+	}
+
+	for i := len(passphraseMagic) + 1; i < len(envelope); i++ {
+		envelope[i] ^= 1
+		if _, err := DecryptShareWithPassphrase(envelope, []byte("hunter2")); err == nil {
+			t.Errorf("corruption at byte %d wasn't detected", i)
+		}
+		envelope[i] ^= 1
+	}
+
+	slow := &Argon2idParams{Time: maxArgon2idTime + 1, Memory: 8, Threads: 1}
+	if _, err := EncryptShareWithPassphrase(&shares[0], []byte("hunter2"), slow, nil); err == nil {
+		t.Errorf("excessive Argon2id time was accepted")
+	}
+
+	// An envelope without a salt would let one precomputation attack
+	// every envelope.
+	var r wireRecords
+	r.addUint(tagKDFTime, 1)
+	r.addUint(tagKDFMemory, 8)
+	r.addUint(tagKDFThreads, 1)
+	r.add(tagKDFSalt, nil)
+	r.add(tagEnvelopeCiphertext, make([]byte, 32))
+	if _, err := DecryptShareWithPassphrase(r.marshalAs(passphraseMagic), []byte("hunter2")); err == nil {
+		t.Errorf("envelope with an empty salt was accepted")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+)
+
+// pemShareType is the PEM block type of the shares from SplitPEMKey. The
+// contents of each block are a storage shard (see storage.go) of the DER
+// encoding of the key, with the PKIX encoding of its public key as the info.
+const pemShareType = "SHAMIRSPLIT KEY SHARE"
+
+// SplitPEMKey splits the PKCS#8 ("PRIVATE KEY") or SEC 1 ("EC PRIVATE KEY")
+// private key in pemBytes into n PEM-encoded shares, any k of which can be
+// combined by JoinPEMKey to recover it. The key is encrypted and its DER
+// encoding is split as by SplitStorage. The public key is recorded in each
+// share, so that JoinPEMKey can check that the key was recovered correctly.
+func SplitPEMKey(pemBytes []byte, k, n int) ([][]byte, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	if block.Type != "PRIVATE KEY" && block.Type != "EC PRIVATE KEY" {
+		return nil, errors.New("unsupported PEM block type: " + block.Type)
+	}
+
+	key, err := parsePEMKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	shards, err := splitStorage(block.Bytes, pub, k, n, nil)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([][]byte, n)
+	for i, shard := range shards {
+		shares[i] = pem.EncodeToMemory(&pem.Block{Type: pemShareType, Bytes: shard})
+	}
+	return shares, nil
+}
+
+// JoinPEMKey recovers a private key from at least k shares that resulted from
+// SplitPEMKey. It returns an error if the public key of the recovered key
+// isn't the one recorded when the key was split.
+func JoinPEMKey(shares [][]byte) (crypto.Signer, error) {
+	shards := make([][]byte, len(shares))
+	for i, share := range shares {
+		block, _ := pem.Decode(share)
+		if block == nil || block.Type != pemShareType {
+			return nil, errors.New("share isn't a PEM key share")
+		}
+		shards[i] = block.Bytes
+	}
+
+	der, pub, err := joinStorage(shards)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(der)
+	if pub == nil {
+		return nil, errors.New("shares don't record a public key")
+	}
+
+	key, err := parsePEMKey(der)
+	if err != nil {
+		return nil, err
+	}
+	recovered, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(recovered, pub) {
+		return nil, errors.New("recovered key doesn't match the recorded public key")
+	}
+	return key, nil
+}
+
+// parsePEMKey parses a PKCS#8 or SEC 1 private key that can sign.
+func parsePEMKey(der []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		if ecKey, ecErr := x509.ParseECPrivateKey(der); ecErr == nil {
+			return ecKey, nil
+		}
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key can't sign")
+	}
+	return signer, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestPEMKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []struct {
+		block *pem.Block
+		pub   interface{ Equal(crypto.PublicKey) bool }
+	}{
+		{&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}, &ecKey.PublicKey},
+		{&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}, edKey.Public().(ed25519.PublicKey)},
+	}
+	for _, test := range keys {
+		shares, err := SplitPEMKey(pem.EncodeToMemory(test.block), 2, 3)
+		if err != nil {
+			t.Fatalf("%s: error while splitting: %s", test.block.Type, err)
+		}
+		key, err := JoinPEMKey(shares[1:])
+		if err != nil {
+			t.Fatalf("%s: error while joining: %s", test.block.Type, err)
+		}
+		if !test.pub.Equal(key.Public()) {
+			t.Errorf("%s: recovered the wrong key", test.block.Type)
+		}
+		if _, err := JoinPEMKey(shares[:1]); err == nil {
+			t.Errorf("%s: joined too few shares", test.block.Type)
+		}
+	}
+
+	// A share set recording a different public key is rejected.
+	otherPub, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards, err := splitStorage(pkcs8, otherPub, 2, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shares [][]byte
+	for _, shard := range shards {
+		shares = append(shares, pem.EncodeToMemory(&pem.Block{Type: pemShareType, Bytes: shard}))
+	}
+	if _, err := JoinPEMKey(shares); err == nil {
+		t.Error("key with the wrong public key was accepted")
+	}
+
+	if _, err := SplitPEMKey(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: sec1}), 2, 3); err == nil {
+		t.Error("certificate was accepted as a key")
+	}
+}
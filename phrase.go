@@ -0,0 +1,46 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"unicode/utf8"
+
+	"github.com/agl/shamirsplit/internal/norm"
+)
+
+// SplitPassphrase splits a passphrase into n shares, any k of which can be
+// combined by JoinPassphrase to recover it. The same text can be represented
+// by different sequences of code points, for example an accented letter may be
+// a single precomposed character or a letter followed by a combining accent,
+// depending on the platform it was typed on. So the passphrase is converted to
+// Unicode normalization form NFKD and encoded as UTF-8 before it is split
+// with SplitGF65536. If rand is nil, crypto/rand.Reader is used.
+func SplitPassphrase(passphrase string, k, n int, rand io.Reader) ([][]byte, error) {
+	if !utf8.ValidString(passphrase) {
+		return nil, errors.New("passphrase is not valid UTF-8")
+	}
+	return SplitGF65536([]byte(norm.NFKD.String(passphrase)), k, n, rand)
+}
+
+// JoinPassphrase recovers a passphrase from at least k shares that resulted
+// from SplitPassphrase. The result is in normalization form NFKD.
+func JoinPassphrase(shares [][]byte) (string, error) {
+	b, err := JoinGF65536(shares)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(b) || !norm.NFKD.IsNormal(b) {
+		return "", errors.New("shares don't encode a normalized passphrase")
+	}
+	return string(b), nil
+}
+
+// NormalizePassphrase returns passphrase in the form that SplitPassphrase
+// splits, so that it can be compared with the result of JoinPassphrase.
+func NormalizePassphrase(passphrase string) string {
+	return norm.NFKD.String(passphrase)
+}
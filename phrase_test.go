@@ -0,0 +1,48 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import "testing"
+
+func TestPassphraseNormalization(t *testing.T) {
+	composed := "caf\u00e9 cr\u00e8me"
+	decomposed := "cafe\u0301 cre\u0300me"
+
+	var results []string
+	for _, passphrase := range []string{composed, decomposed} {
+		shares, err := SplitPassphrase(passphrase, 2, 3, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := JoinPassphrase(shares[1:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, got)
+	}
+
+	if results[0] != results[1] {
+		t.Errorf("composed and decomposed passphrases recovered as %+q and %+q", results[0], results[1])
+	}
+	if results[0] != NormalizePassphrase(composed) {
+		t.Errorf("recovered %+q, want %+q", results[0], NormalizePassphrase(composed))
+	}
+
+	if _, err := SplitPassphrase("\xff", 2, 3, nil); err == nil {
+		t.Error("invalid UTF-8 was accepted")
+	}
+	if _, err := SplitPassphrase("", 2, 3, nil); err == nil {
+		t.Error("empty passphrase was accepted")
+	}
+
+	// Shares of a passphrase that isn't normalized are rejected.
+	shares, err := SplitGF65536([]byte(composed), 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := JoinPassphrase(shares); err == nil {
+		t.Error("unnormalized passphrase was accepted")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var _ Field[*big.Int] = (*PrimeField)(nil)
+
+// ErrCompositeModulus is returned when a modulus fails the primality check.
+// Over a composite modulus, some of the values that Join must invert have no
+// inverse and it returns wrong answers, while Split may leak information
+// about the secret.
+var ErrCompositeModulus = errors.New("modulus is not prime")
+
+// CheckModulus returns ErrCompositeModulus unless modulus is (with
+// overwhelming probability) a prime. Split and Join don't check their modulus
+// for efficiency and compatibility; PrimeField always does.
+func CheckModulus(modulus *big.Int) error {
+	if modulus == nil || !modulus.ProbablyPrime(20) {
+		return ErrCompositeModulus
+	}
+	return nil
+}
+
+// A PrimeField is the field of integers modulo a prime. It implements
+// Field[*big.Int].
+type PrimeField struct {
+	modulus *big.Int
+}
+
+// NewPrimeField returns the field of integers modulo modulus, or
+// ErrCompositeModulus if modulus isn't prime.
+func NewPrimeField(modulus *big.Int) (*PrimeField, error) {
+	if err := CheckModulus(modulus); err != nil {
+		return nil, err
+	}
+	return &PrimeField{new(big.Int).Set(modulus)}, nil
+}
+
+// NewFieldFromCurve returns the field of integers modulo the order of the
+// base point of curve, over which private keys for the curve are shared.
+func NewFieldFromCurve(curve elliptic.Curve) (*PrimeField, error) {
+	return NewPrimeField(curve.Params().N)
+}
+
+// NewSecp256k1Field returns the field of integers modulo Secp256k1Order, for
+// sharing secp256k1 private keys.
+func NewSecp256k1Field() *PrimeField {
+	return &PrimeField{new(big.Int).Set(Secp256k1Order)}
+}
+
+// NewEdwards25519Field returns the field of integers modulo
+// Edwards25519Order, for sharing Ed25519 and X25519 scalars.
+func NewEdwards25519Field() *PrimeField {
+	return &PrimeField{new(big.Int).Set(Edwards25519Order)}
+}
+
+// Modulus returns a copy of the field's modulus.
+func (f *PrimeField) Modulus() *big.Int {
+	return new(big.Int).Set(f.modulus)
+}
+
+// Split is equivalent to the package function Split over f.
+func (f *PrimeField) Split(secret *big.Int, k, n int, rand io.Reader) ([]*big.Int, error) {
+	return Split(secret, f.modulus, k, n, rand)
+}
+
+// Join is equivalent to the package function Join over f.
+func (f *PrimeField) Join(shares []*big.Int, shareNumbers []int) (*big.Int, error) {
+	return Join(shares, shareNumbers, f.modulus)
+}
+
+func (f *PrimeField) Zero() *big.Int { return new(big.Int) }
+func (f *PrimeField) One() *big.Int  { return big.NewInt(1) }
+
+func (f *PrimeField) Add(a, b *big.Int) *big.Int {
+	r := new(big.Int).Add(a, b)
+	return r.Mod(r, f.modulus)
+}
+
+func (f *PrimeField) Sub(a, b *big.Int) *big.Int {
+	r := new(big.Int).Sub(a, b)
+	return r.Mod(r, f.modulus)
+}
+
+func (f *PrimeField) Mul(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, f.modulus)
+}
+
+func (f *PrimeField) Inv(a *big.Int) (*big.Int, error) {
+	r := new(big.Int).ModInverse(a, f.modulus)
+	if r == nil {
+		return nil, errors.New("element has no inverse")
+	}
+	return r, nil
+}
+
+func (f *PrimeField) Equal(a, b *big.Int) bool { return a.Cmp(b) == 0 }
+
+func (f *PrimeField) Element(i uint64) (*big.Int, error) {
+	e := new(big.Int).SetUint64(i)
+	if e.Cmp(f.modulus) >= 0 {
+		return nil, errors.New("field is too small")
+	}
+	return e, nil
+}
+
+func (f *PrimeField) Random(rand io.Reader) (*big.Int, error) {
+	return randomNumber(rand, f.modulus)
+}
+
+// Encode returns a as a big-endian integer, padded to the length of the
+// modulus.
+func (f *PrimeField) Encode(a *big.Int) []byte {
+	return a.FillBytes(make([]byte, (f.modulus.BitLen()+7)/8))
+}
+
+func (f *PrimeField) Decode(b []byte) (*big.Int, error) {
+	if len(b) != (f.modulus.BitLen()+7)/8 {
+		return nil, errors.New("encoded element has the wrong length")
+	}
+	a := new(big.Int).SetBytes(b)
+	if a.Cmp(f.modulus) >= 0 {
+		return nil, errors.New("encoded element is out of range")
+	}
+	return a, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+)
+
+func TestNewPrimeField(t *testing.T) {
+	// 2^127 - 1 is prime, 2^128 + 1 isn't.
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	c := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+	if _, err := NewPrimeField(c); err != ErrCompositeModulus {
+		t.Errorf("composite modulus was accepted")
+	}
+
+	f, err := NewPrimeField(p)
+	if err != nil {
+		t.Fatalf("prime modulus was rejected: %s", err)
+	}
+
+	secret := big.NewInt(42)
+	shares, err := f.Split(secret, 2, 4, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	result, err := f.Join(shares[2:], []int{2, 3})
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to recover secret: %v", err)
+	}
+}
+
+func TestCurveFields(t *testing.T) {
+	p256, err := NewFieldFromCurve(elliptic.P256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		f     *PrimeField
+		order *big.Int
+	}{
+		{p256, P256Order},
+		{NewSecp256k1Field(), Secp256k1Order},
+		{NewEdwards25519Field(), Edwards25519Order},
+	}
+	for _, test := range tests {
+		if test.f.Modulus().Cmp(test.order) != 0 {
+			t.Errorf("field has modulus %x, want %x", test.f.Modulus(), test.order)
+		}
+		if err := CheckModulus(test.f.Modulus()); err != nil {
+			t.Errorf("order %x isn't prime", test.order)
+		}
+
+		secret := new(big.Int).Sub(test.order, big.NewInt(1))
+		shares, err := test.f.Split(secret, 2, 3, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := test.f.Join(shares[1:], []int{1, 2}); err != nil || got.Cmp(secret) != 0 {
+			t.Errorf("failed to join: %v, %v", got, err)
+		}
+	}
+}
+
+func TestPrimeFieldGeneric(t *testing.T) {
+	p, _ := NewPrimeField(MODP2048)
+	testField[*big.Int](t, p, big.NewInt(42))
+}
@@ -0,0 +1,372 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+	"time"
+)
+
+// This file implements the Protocol Buffers encoding of the messages in
+// shamirsplit.proto. It is written by hand against the wire format so that
+// this package doesn't depend on a protobuf runtime; the output is identical
+// to that of the generated code.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("truncated protobuf message")
+
+func appendProtoTag(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	b = appendProtoTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendProtoInt(b []byte, field int, v *big.Int) []byte {
+	if v == nil || v.Sign() == 0 {
+		return b
+	}
+	return appendProtoBytes(b, field, v.Bytes())
+}
+
+func appendProtoUint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// parseProto calls f for each field in data. For varint fields, v holds the
+// value; for length-delimited fields, b does. Fields of other wire types are
+// skipped, as are any that f doesn't recognise. Errors are returned as a
+// *ParseError with the offset of the field concerned.
+func parseProto(data []byte, f func(field, wireType int, v uint64, b []byte) error) error {
+	offset := 0
+	for offset < len(data) {
+		start := offset
+		tag, n := binary.Uvarint(data[offset:])
+		if n <= 0 {
+			return &ParseError{Offset: start, Err: errProtoTruncated}
+		}
+		offset += n
+
+		field := tag >> 3
+		wireType := int(tag & 7)
+		if field == 0 || field > math.MaxInt32 {
+			return &ParseError{Offset: start, Err: errors.New("invalid protobuf field number")}
+		}
+
+		var v uint64
+		var b []byte
+		switch wireType {
+		case wireVarint:
+			v, n = binary.Uvarint(data[offset:])
+			if n <= 0 {
+				return &ParseError{Offset: start, Err: errProtoTruncated}
+			}
+			offset += n
+		case wireFixed64:
+			if len(data)-offset < 8 {
+				return &ParseError{Offset: start, Err: errProtoTruncated}
+			}
+			offset += 8
+			continue
+		case wireBytes:
+			l, n := binary.Uvarint(data[offset:])
+			if n <= 0 || l > uint64(len(data)-offset-n) {
+				return &ParseError{Offset: start, Err: errProtoTruncated}
+			}
+			offset += n
+			b = data[offset : offset+int(l)]
+			offset += int(l)
+		case wireFixed32:
+			if len(data)-offset < 4 {
+				return &ParseError{Offset: start, Err: errProtoTruncated}
+			}
+			offset += 4
+			continue
+		default:
+			return &ParseError{Offset: start, Err: errors.New("unsupported protobuf wire type")}
+		}
+
+		if err := f(int(field), wireType, v, b); err != nil {
+			// Errors from an embedded message are relative to it.
+			if pe, ok := err.(*ParseError); ok && pe.Offset >= 0 && b != nil {
+				return &ParseError{Offset: offset - len(b) + pe.Offset, Err: pe.Err}
+			}
+			return parseError(start, err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalProto returns the Protocol Buffers encoding of s as a
+// shamirsplit.v1.Share message. The message has no fields for a MAC,
+// fingerprint, signature, hyperplane, mandatory share or participant, so
+// shares with them are rejected rather than silently losing them: use
+// MarshalBinary for those.
+func (s *Share) MarshalProto() ([]byte, error) {
+	if s.MACKey != nil || s.Fingerprint != nil || s.DealerKey != nil || s.Signature != nil {
+		return nil, errors.New("protobuf encoding can't carry the share's MAC, fingerprint or signature")
+	}
+	// Without its hyperplane, a Blakley share would be taken for a point
+	// on a polynomial.
+	if s.Hyperplane != nil {
+		return nil, errors.New("protobuf encoding can't carry Blakley shares")
+	}
+	if s.Mandatory != nil {
+		return nil, errors.New("protobuf encoding can't carry shares with a mandatory share")
+	}
+	if len(s.Participant) > 0 {
+		return nil, errors.New("protobuf encoding can't carry the share's participant")
+	}
+
+	var b []byte
+	b = appendProtoInt(b, 1, s.X)
+	b = appendProtoInt(b, 2, s.Y)
+	b = appendProtoInt(b, 3, s.Modulus)
+	if s.SecretLen < 0 || uint64(s.SecretLen) > math.MaxUint32 {
+		return nil, errors.New("secret length out of range")
+	}
+	b = appendProtoUint(b, 4, uint64(s.SecretLen))
+	if s.Metadata != nil {
+		m, err := s.Metadata.MarshalProto()
+		if err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, 5, m)
+	}
+	if s.Additive {
+		b = appendProtoUint(b, 6, 1)
+	}
+	if len(s.Hint) > 0 {
+		if err := checkHint(s.Hint); err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, 7, []byte(s.Hint))
+	}
+	return b, nil
+}
+
+// UnmarshalProto parses a shamirsplit.v1.Share message into s, subject to
+// DefaultParseLimits.
+func (s *Share) UnmarshalProto(data []byte) error {
+	limits := &DefaultParseLimits
+	if err := limits.checkSize(data); err != nil {
+		return err
+	}
+
+	s.X = new(big.Int)
+	s.Y = new(big.Int)
+	s.Modulus = nil
+	s.SecretLen = 0
+	s.Metadata = nil
+	s.Additive = false
+	s.Hint = ""
+
+	return parseProto(data, func(field, wireType int, v uint64, b []byte) error {
+		if field <= 3 && wireType == wireBytes {
+			if err := limits.checkInt(b); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case field == 1 && wireType == wireBytes:
+			s.X.SetBytes(b)
+		case field == 2 && wireType == wireBytes:
+			s.Y.SetBytes(b)
+		case field == 3 && wireType == wireBytes:
+			s.Modulus = new(big.Int).SetBytes(b)
+		case field == 4 && wireType == wireVarint:
+			if v > math.MaxUint32 {
+				return errors.New("secret length out of range")
+			}
+			s.SecretLen = int(v)
+		case field == 5 && wireType == wireBytes:
+			s.Metadata = new(Metadata)
+			return s.Metadata.UnmarshalProto(b)
+		case field == 6 && wireType == wireVarint:
+			s.Additive = v != 0
+		case field == 7 && wireType == wireBytes:
+			if err := checkHint(string(b)); err != nil {
+				return err
+			}
+			s.Hint = string(b)
+		}
+		return nil
+	})
+}
+
+// MarshalProto returns the Protocol Buffers encoding of m as a
+// shamirsplit.v1.Metadata message.
+func (m *Metadata) MarshalProto() ([]byte, error) {
+	if m.Threshold < 0 || uint64(m.Threshold) > math.MaxUint32 {
+		return nil, errors.New("threshold out of range")
+	}
+
+	var b []byte
+	b = appendProtoUint(b, 1, uint64(m.Threshold))
+	if m.SetID != [16]byte{} {
+		b = appendProtoBytes(b, 2, m.SetID[:])
+	}
+	if !m.Created.IsZero() {
+		b = appendProtoUint(b, 3, uint64(m.Created.Unix()))
+	}
+	if len(m.Label) > 0 {
+		b = appendProtoBytes(b, 4, []byte(m.Label))
+	}
+	if !m.NotAfter.IsZero() {
+		b = appendProtoUint(b, 5, uint64(m.NotAfter.Unix()))
+	}
+	b = appendProtoUint(b, 6, m.Epoch)
+	return b, nil
+}
+
+// UnmarshalProto parses a shamirsplit.v1.Metadata message into m.
+func (m *Metadata) UnmarshalProto(data []byte) error {
+	*m = Metadata{}
+
+	return parseProto(data, func(field, wireType int, v uint64, b []byte) error {
+		switch {
+		case field == 1 && wireType == wireVarint:
+			if v > math.MaxUint32 {
+				return errors.New("threshold out of range")
+			}
+			m.Threshold = int(v)
+		case field == 2 && wireType == wireBytes:
+			if len(b) != len(m.SetID) {
+				return errors.New("invalid set ID")
+			}
+			copy(m.SetID[:], b)
+		case field == 3 && wireType == wireVarint:
+			m.Created = time.Unix(int64(v), 0)
+		case field == 4 && wireType == wireBytes:
+			m.Label = string(b)
+		case field == 5 && wireType == wireVarint:
+			m.NotAfter = time.Unix(int64(v), 0)
+		case field == 6 && wireType == wireVarint:
+			m.Epoch = v
+		}
+		return nil
+	})
+}
+
+// MarshalProto returns the Protocol Buffers encoding of c as a
+// shamirsplit.v1.Commitments message.
+func (c *Commitments) MarshalProto() ([]byte, error) {
+	var b []byte
+	b = appendProtoInt(b, 1, c.P)
+	b = appendProtoInt(b, 2, c.G)
+	for _, v := range c.Values {
+		// Repeated fields must keep their position, so zero values
+		// are encoded explicitly.
+		var bytes []byte
+		if v != nil {
+			bytes = v.Bytes()
+		}
+		b = appendProtoBytes(b, 3, bytes)
+	}
+	if c.Epoch > 0 {
+		b = appendProtoUint(b, 4, c.Epoch)
+	}
+	return b, nil
+}
+
+// UnmarshalProto parses a shamirsplit.v1.Commitments message into c.
+func (c *Commitments) UnmarshalProto(data []byte) error {
+	c.P = new(big.Int)
+	c.G = new(big.Int)
+	c.Values = nil
+	c.Epoch = 0
+
+	return parseProto(data, func(field, wireType int, v uint64, b []byte) error {
+		switch {
+		case field == 1 && wireType == wireBytes:
+			c.P.SetBytes(b)
+		case field == 2 && wireType == wireBytes:
+			c.G.SetBytes(b)
+		case field == 3 && wireType == wireBytes:
+			c.Values = append(c.Values, new(big.Int).SetBytes(b))
+		case field == 4 && wireType == wireVarint:
+			c.Epoch = v
+		}
+		return nil
+	})
+}
+
+// MarshalProto returns the Protocol Buffers encoding of s as a
+// shamirsplit.v1.ShareSet message.
+func (s *ShareSet) MarshalProto() ([]byte, error) {
+	if s.Threshold < 0 || uint64(s.Threshold) > math.MaxUint32 {
+		return nil, errors.New("threshold out of range")
+	}
+
+	var b []byte
+	b = appendProtoInt(b, 1, s.Modulus)
+	b = appendProtoUint(b, 2, uint64(s.Threshold))
+	for i := range s.Shares {
+		share, err := s.Shares[i].MarshalProto()
+		if err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, 3, share)
+	}
+	if s.Commitments != nil {
+		c, err := s.Commitments.MarshalProto()
+		if err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, 4, c)
+	}
+	return b, nil
+}
+
+// UnmarshalProto parses a shamirsplit.v1.ShareSet message into s.
+func (s *ShareSet) UnmarshalProto(data []byte) error {
+	s.Modulus = new(big.Int)
+	s.Threshold = 0
+	s.Shares = nil
+	s.Commitments = nil
+
+	return parseProto(data, func(field, wireType int, v uint64, b []byte) error {
+		switch {
+		case field == 1 && wireType == wireBytes:
+			s.Modulus.SetBytes(b)
+		case field == 2 && wireType == wireVarint:
+			if v > math.MaxUint32 {
+				return errors.New("threshold out of range")
+			}
+			s.Threshold = int(v)
+		case field == 3 && wireType == wireBytes:
+			var share Share
+			if err := share.UnmarshalProto(b); err != nil {
+				return err
+			}
+			s.Shares = append(s.Shares, share)
+		case field == 4 && wireType == wireBytes:
+			// If the field is repeated, the last occurrence wins.
+			s.Commitments = new(Commitments)
+			return s.Commitments.UnmarshalProto(b)
+		}
+		return nil
+	})
+}
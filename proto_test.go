@@ -0,0 +1,110 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestShareSetProto(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+
+	set, err := SplitVerifiable(big.NewInt(42), q, p, big.NewInt(4), 3, 5, rand.Reader)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	data, err := set.MarshalProto()
+	if err != nil {
+		t.Fatalf("error while marshaling: %s", err)
+	}
+
+	var set2 ShareSet
+	if err := set2.UnmarshalProto(data); err != nil {
+		t.Fatalf("error while unmarshaling: %s", err)
+	}
+
+	if set2.Modulus.Cmp(q) != 0 || set2.Threshold != 3 || len(set2.Shares) != 5 {
+		t.Errorf("parameters didn't round trip")
+	}
+	for i, s := range set2.Shares {
+		if !set2.Commitments.Verify(s) {
+			t.Errorf("share %d failed to verify after round trip", i)
+		}
+	}
+
+	data2, _ := set2.MarshalProto()
+	if !bytes.Equal(data, data2) {
+		t.Errorf("re-encoding differs")
+	}
+}
+
+func TestCommitmentsProtoEpoch(t *testing.T) {
+	c := &Commitments{P: big.NewInt(23), G: big.NewInt(4), Values: []*big.Int{big.NewInt(2), big.NewInt(3)}, Epoch: 7}
+	data, err := c.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c2 Commitments
+	if err := c2.UnmarshalProto(data); err != nil {
+		t.Fatal(err)
+	}
+	if c2.Epoch != 7 || len(c2.Values) != 2 {
+		t.Errorf("commitments didn't round trip: %+v", c2)
+	}
+}
+
+func TestProtoKnownEncoding(t *testing.T) {
+	// Share{x: "\x01", y: "\x02\x03"} as encoded by the reference
+	// implementation.
+	want := []byte{0x0a, 0x01, 0x01, 0x12, 0x02, 0x02, 0x03}
+
+	s := Share{X: big.NewInt(1), Y: big.NewInt(0x203)}
+	got, _ := s.MarshalProto()
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+
+	// Unknown fields must be skipped.
+	withUnknown := append([]byte{0x18, 0x05}, want...)
+	var s2 Share
+	if err := s2.UnmarshalProto(withUnknown); err != nil {
+		t.Fatalf("failed to parse: %s", err)
+	}
+	if s2.X.Cmp(s.X) != 0 || s2.Y.Cmp(s.Y) != 0 {
+		t.Errorf("unknown field wasn't skipped")
+	}
+
+	if err := s2.UnmarshalProto(want[:4]); err == nil {
+		t.Errorf("truncated message was accepted")
+	}
+}
+
+func TestShareProtoUnsupported(t *testing.T) {
+	shares, err := SplitShares(big.NewInt(42), big.NewInt(251), 2, 3, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AuthenticateShares(shares, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shares[0].MarshalProto(); err == nil {
+		t.Errorf("share with a MAC key was encoded without it")
+	}
+
+	blakley, err := SplitBlakley(big.NewInt(42), big.NewInt(251), 2, 3, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blakley[0].MarshalProto(); err == nil {
+		t.Errorf("Blakley share was encoded without its hyperplane")
+	}
+}
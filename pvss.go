@@ -0,0 +1,343 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// A PublicDealing is a publicly verifiable dealing, following Schoenmakers,
+// "A Simple Publicly Verifiable Secret Sharing Scheme", CRYPTO '99. The
+// shares are encrypted to the shareholders' public keys and the dealing
+// includes a non-interactive zero-knowledge proof that the encrypted shares
+// lie on the polynomial of degree less than k that the Feldman commitments
+// commit to. Anyone can check the proof with Verify, without access to any
+// share.
+//
+// The commitments use the generator G, but the shares and the secret are in
+// terms of a second generator, H, from PVSSGenerator, whose discrete log to
+// the base G nobody knows. Shareholder i has private key x_i in [1, Modulus)
+// and public key h_i = H^x_i mod P. Encrypted[i] is h_i^p(i+1) and decrypts
+// to H^p(i+1), so the value recovered by Join is H^secret mod P, from which a
+// key can be derived. Since the commitment to the secret is G^secret, the
+// dealing itself doesn't reveal that value.
+type PublicDealing struct {
+	Modulus     *big.Int
+	Commitments *Commitments
+	Encrypted   []*big.Int
+	// Challenge and Responses are the proof.
+	Challenge *big.Int
+	Responses []*big.Int
+}
+
+// A PVSSShare is a share decrypted from a PublicDealing, with a proof that it
+// was decrypted correctly.
+type PVSSShare struct {
+	// X is the share's x coordinate and D is H^p(X) mod P.
+	X, D *big.Int
+	// Challenge and Response prove that log_H h = log_D E, where h is the
+	// shareholder's public key and E their encrypted share.
+	Challenge, Response *big.Int
+}
+
+// PVSSGenerator returns the generator H of the subgroup of order modulus mod
+// p that shareholders' public keys must use for a PublicDealing. It's derived
+// by hashing p and modulus into the subgroup, so that nobody knows its
+// discrete log to any other generator.
+func PVSSGenerator(modulus, p *big.Int) (*big.Int, error) {
+	if modulus.Sign() <= 0 || p.Cmp(big.NewInt(3)) < 0 {
+		return nil, errors.New("invalid group")
+	}
+	cofactor, r := new(big.Int).QuoRem(new(big.Int).Sub(p, big.NewInt(1)), modulus, new(big.Int))
+	if r.Sign() != 0 {
+		return nil, errors.New("modulus doesn't divide p-1")
+	}
+
+	size := (p.BitLen()+7)/8 + 16
+	for counter := 0; counter < 64; counter++ {
+		b, err := hkdf.Key(sha256.New, p.Bytes(), modulus.Bytes(), "shamirsplit PVSS generator "+strconv.Itoa(counter), size)
+		if err != nil {
+			return nil, err
+		}
+		h := new(big.Int).SetBytes(b)
+		h.Exp(h.Mod(h, p), cofactor, p)
+		if pvssValidElement(h, modulus, p) {
+			return h, nil
+		}
+	}
+	return nil, errors.New("failed to find a generator")
+}
+
+// DealPublicly splits secret as SplitVerifiable does, with the modulus being
+// the order of g mod p, and encrypts share i to publicKeys[i], which must be
+// powers of PVSSGenerator(modulus, p). If rand is nil, crypto/rand.Reader is
+// used.
+func DealPublicly(secret, modulus, p, g *big.Int, k int, publicKeys []*big.Int, rand io.Reader) (*PublicDealing, error) {
+	n := len(publicKeys)
+	if k < 1 || n < k {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+	if new(big.Int).Exp(g, modulus, p).Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("modulus is not the order of the generator")
+	}
+	if _, err := PVSSGenerator(modulus, p); err != nil {
+		return nil, err
+	}
+	for _, h := range publicKeys {
+		if !pvssValidElement(h, modulus, p) {
+			return nil, errors.New("invalid public key")
+		}
+	}
+	rand = defaultRand(rand)
+
+	a, err := randomPolynomial(secret, modulus, k, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &PublicDealing{
+		Modulus:     modulus,
+		Commitments: &Commitments{P: p, G: g, Values: make([]*big.Int, k)},
+		Encrypted:   make([]*big.Int, n),
+		Responses:   make([]*big.Int, n),
+	}
+	for j := range a {
+		d.Commitments.Values[j] = new(big.Int).Exp(g, a[j], p)
+	}
+
+	ys := make([]*big.Int, n)
+	ws := make([]*big.Int, n)
+	a1s := make([]*big.Int, n)
+	a2s := make([]*big.Int, n)
+	for i, h := range publicKeys {
+		ys[i] = evaluatePolynomial(a, big.NewInt(int64(i+1)), modulus)
+		d.Encrypted[i] = new(big.Int).Exp(h, ys[i], p)
+
+		if ws[i], err = randomNumber(rand, modulus); err != nil {
+			return nil, err
+		}
+		a1s[i] = new(big.Int).Exp(g, ws[i], p)
+		a2s[i] = new(big.Int).Exp(h, ws[i], p)
+	}
+
+	d.Challenge = d.challenge(publicKeys, a1s, a2s)
+	for i := range ys {
+		// r_i = w_i - y_i*c mod q
+		r := new(big.Int).Mul(ys[i], d.Challenge)
+		r.Sub(ws[i], r)
+		d.Responses[i] = r.Mod(r, modulus)
+	}
+	return d, nil
+}
+
+// Verify returns nil iff the proof shows that the shares encrypted to
+// publicKeys are consistent with the commitments.
+func (d *PublicDealing) Verify(publicKeys []*big.Int) error {
+	c := d.Commitments
+	n := len(publicKeys)
+	if c == nil || len(c.Values) == 0 || len(c.Values) > n ||
+		len(d.Encrypted) != n || len(d.Responses) != n || d.Challenge == nil {
+		return errors.New("public dealing is incomplete")
+	}
+	for _, v := range c.Values {
+		// The secret, and so the first commitment, may be zero.
+		if v == nil || v.Cmp(big.NewInt(1)) != 0 && !pvssValidElement(v, d.Modulus, c.P) {
+			return errors.New("invalid commitment")
+		}
+	}
+
+	a1s := make([]*big.Int, n)
+	a2s := make([]*big.Int, n)
+	for i, h := range publicKeys {
+		if !pvssValidElement(h, d.Modulus, c.P) || !pvssValidElement(d.Encrypted[i], d.Modulus, c.P) {
+			return errors.New("invalid public key or encrypted share")
+		}
+		r := d.Responses[i]
+		if r == nil || r.Sign() < 0 || r.Cmp(d.Modulus) >= 0 {
+			return errors.New("invalid proof")
+		}
+
+		// a1_i = g^r_i * X_i^c, where X_i = g^p(i+1) is computed from the
+		// commitments, and a2_i = h_i^r_i * E_i^c.
+		x := pvssCommittedValue(c, big.NewInt(int64(i+1)))
+		a1s[i] = new(big.Int).Exp(c.G, r, c.P)
+		a1s[i].Mul(a1s[i], x.Exp(x, d.Challenge, c.P))
+		a1s[i].Mod(a1s[i], c.P)
+		a2s[i] = new(big.Int).Exp(h, r, c.P)
+		a2s[i].Mul(a2s[i], new(big.Int).Exp(d.Encrypted[i], d.Challenge, c.P))
+		a2s[i].Mod(a2s[i], c.P)
+	}
+
+	if d.challenge(publicKeys, a1s, a2s).Cmp(d.Challenge) != 0 {
+		return errors.New("public dealing proof doesn't verify")
+	}
+	return nil
+}
+
+// Decrypt returns the decryption of the share of the holder of i'th public
+// key, whose private key is priv: H^p(i+1) mod P, with a proof that it's
+// correct, in the form that Join expects. If rand is nil, crypto/rand.Reader
+// is used.
+func (d *PublicDealing) Decrypt(i int, priv *big.Int, rand io.Reader) (*PVSSShare, error) {
+	if i < 0 || i >= len(d.Encrypted) {
+		return nil, errors.New("share index out of range")
+	}
+	inv := new(big.Int).ModInverse(priv, d.Modulus)
+	if inv == nil {
+		return nil, errors.New("invalid private key")
+	}
+	h, err := PVSSGenerator(d.Modulus, d.Commitments.P)
+	if err != nil {
+		return nil, err
+	}
+
+	p := d.Commitments.P
+	s := &PVSSShare{X: big.NewInt(int64(i + 1)), D: new(big.Int).Exp(d.Encrypted[i], inv, p)}
+	w, err := randomNumber(defaultRand(rand), d.Modulus)
+	if err != nil {
+		return nil, err
+	}
+	pub := new(big.Int).Exp(h, priv, p)
+	s.Challenge = pvssDecryptionChallenge(h, pub, s.D, d.Encrypted[i], new(big.Int).Exp(h, w, p), new(big.Int).Exp(s.D, w, p), d.Modulus)
+	// r = w - x*c mod q
+	r := new(big.Int).Mul(priv, s.Challenge)
+	r.Sub(w, r)
+	s.Response = r.Mod(r, d.Modulus)
+	return s, nil
+}
+
+// VerifyShare returns nil iff s is a correct decryption of the share of the
+// holder of the public key publicKeys[s.X-1].
+func (d *PublicDealing) VerifyShare(s *PVSSShare, publicKeys []*big.Int) error {
+	if s.X == nil || s.D == nil || s.Challenge == nil || s.Response == nil {
+		return errors.New("decrypted share is incomplete")
+	}
+	if !s.X.IsInt64() || s.X.Int64() < 1 || s.X.Int64() > int64(len(d.Encrypted)) || len(publicKeys) != len(d.Encrypted) {
+		return errors.New("decrypted share is out of range")
+	}
+	i := int(s.X.Int64()) - 1
+	p, q := d.Commitments.P, d.Modulus
+	if !pvssValidElement(s.D, q, p) || !pvssValidElement(publicKeys[i], q, p) ||
+		s.Response.Sign() < 0 || s.Response.Cmp(q) >= 0 {
+		return errors.New("invalid decrypted share")
+	}
+	h, err := PVSSGenerator(q, p)
+	if err != nil {
+		return err
+	}
+
+	// a1 = H^r * h_i^c and a2 = D^r * E_i^c.
+	a1 := new(big.Int).Exp(h, s.Response, p)
+	a1.Mul(a1, new(big.Int).Exp(publicKeys[i], s.Challenge, p))
+	a1.Mod(a1, p)
+	a2 := new(big.Int).Exp(s.D, s.Response, p)
+	a2.Mul(a2, new(big.Int).Exp(d.Encrypted[i], s.Challenge, p))
+	a2.Mod(a2, p)
+	if pvssDecryptionChallenge(h, publicKeys[i], s.D, d.Encrypted[i], a1, a2, q).Cmp(s.Challenge) != 0 {
+		return errors.New("decrypted share " + s.X.String() + " is incorrect")
+	}
+	return nil
+}
+
+// Join recovers H^secret mod P from at least k decrypted shares by Lagrange
+// interpolation in the exponent. Each share's proof is checked against
+// publicKeys, and any incorrect share results in an error.
+func (d *PublicDealing) Join(shares []PVSSShare, publicKeys []*big.Int) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	xs := make([]*big.Int, len(shares))
+	for i := range shares {
+		if err := d.VerifyShare(&shares[i], publicKeys); err != nil {
+			return nil, err
+		}
+		xs[i] = shares[i].X
+	}
+	if err := checkXs(xs, d.Modulus); err != nil {
+		return nil, err
+	}
+
+	p := d.Commitments.P
+	result := big.NewInt(1)
+	for i, s := range shares {
+		result.Mul(result, new(big.Int).Exp(s.D, lagrangeAtZero(xs, i, d.Modulus), p))
+		result.Mod(result, p)
+	}
+	return result, nil
+}
+
+// challenge returns the Fiat-Shamir challenge for the proof.
+func (d *PublicDealing) challenge(publicKeys, a1s, a2s []*big.Int) *big.Int {
+	h := sha256.New()
+	write := func(v *big.Int) {
+		b := v.Bytes()
+		h.Write(binary.AppendUvarint(nil, uint64(len(b))))
+		h.Write(b)
+	}
+
+	c := d.Commitments
+	write(c.P)
+	write(c.G)
+	write(d.Modulus)
+	for _, v := range c.Values {
+		write(v)
+	}
+	for i := range publicKeys {
+		write(publicKeys[i])
+		write(d.Encrypted[i])
+		write(a1s[i])
+		write(a2s[i])
+	}
+
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, d.Modulus)
+}
+
+// pvssDecryptionChallenge returns the Fiat-Shamir challenge for the proof
+// that log_h pub = log_s e, with commitments a1 and a2.
+func pvssDecryptionChallenge(h, pub, s, e, a1, a2, q *big.Int) *big.Int {
+	hash := sha256.New()
+	hash.Write([]byte("shamirsplit PVSS decryption"))
+	for _, v := range []*big.Int{h, pub, s, e, a1, a2} {
+		b := v.Bytes()
+		hash.Write(binary.AppendUvarint(nil, uint64(len(b))))
+		hash.Write(b)
+	}
+	c := new(big.Int).SetBytes(hash.Sum(nil))
+	return c.Mod(c, q)
+}
+
+// pvssCommittedValue returns G^p(x) mod P, computed from the commitments.
+func pvssCommittedValue(c *Commitments, x *big.Int) *big.Int {
+	v := big.NewInt(1)
+	for j := len(c.Values) - 1; j >= 0; j-- {
+		v.Exp(v, x, c.P)
+		v.Mul(v, c.Values[j])
+		v.Mod(v, c.P)
+	}
+	return v
+}
+
+// pvssValidElement returns true iff v is a non-identity element of the
+// subgroup of order q mod p.
+func pvssValidElement(v, q, p *big.Int) bool {
+	return v != nil && v.Cmp(big.NewInt(1)) > 0 && v.Cmp(p) < 0 &&
+		new(big.Int).Exp(v, q, p).Cmp(big.NewInt(1)) == 0
+}
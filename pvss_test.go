@@ -0,0 +1,71 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPublicDealing(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	g := big.NewInt(4)
+	h, err := PVSSGenerator(q, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privs := make([]*big.Int, 4)
+	pubs := make([]*big.Int, 4)
+	for i := range privs {
+		privs[i], _ = randomNumber(defaultRand(nil), q)
+		privs[i].Add(privs[i], big.NewInt(1))
+		pubs[i] = new(big.Int).Exp(h, privs[i], p)
+	}
+
+	secret := big.NewInt(42)
+	d, err := DealPublicly(secret, q, p, g, 3, pubs, nil)
+	if err != nil {
+		t.Fatalf("error while dealing: %s", err)
+	}
+	if err := d.Verify(pubs); err != nil {
+		t.Errorf("dealing failed to verify: %s", err)
+	}
+
+	var shares []PVSSShare
+	for _, i := range []int{3, 0, 2} {
+		s, err := d.Decrypt(i, privs[i], nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, *s)
+	}
+	result, err := d.Join(shares, pubs)
+	if want := new(big.Int).Exp(h, secret, p); err != nil || result.Cmp(want) != 0 {
+		t.Errorf("failed to join decrypted shares: %v", err)
+	}
+	// The recovered value mustn't be public.
+	if result.Cmp(d.Commitments.Values[0]) == 0 {
+		t.Errorf("recovered value is the commitment to the secret")
+	}
+
+	// A shareholder who submits a wrong decryption must be caught.
+	bad := shares[1]
+	bad.D = new(big.Int).Mul(bad.D, h)
+	bad.D.Mod(bad.D, p)
+	if _, err := d.Join([]PVSSShare{shares[0], bad, shares[2]}, pubs); err == nil {
+		t.Errorf("incorrectly decrypted share was accepted")
+	}
+
+	// Corrupting an encrypted share must break the proof.
+	d.Encrypted[1] = new(big.Int).Mul(d.Encrypted[1], g)
+	d.Encrypted[1].Mod(d.Encrypted[1], p)
+	if err := d.Verify(pubs); err == nil {
+		t.Errorf("corrupt dealing verified")
+	}
+}
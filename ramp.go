@@ -0,0 +1,69 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// SplitRamp implements a (t, k, n) ramp scheme: secret is split into n
+// shares, any k of which can be combined by JoinChunked to recover it, while
+// any t reveal nothing about it. Between t and k shares reveal partial
+// information. In return, each share is only about 1/(k-t) of the size of the
+// secret, which makes ramp schemes suitable for splitting large data.
+//
+// The secret is cut into limbs as with SplitChunked, and each k-t limbs are
+// packed into a single polynomial with SplitMulti. If rand is nil,
+// crypto/rand.Reader is used.
+func SplitRamp(secret []byte, modulus *big.Int, t, k, n int, rand io.Reader) ([]ChunkedShare, error) {
+	if t < 1 || k <= t || n < k {
+		return nil, errors.New("invalid ramp parameters")
+	}
+	l := limbLen(modulus)
+	if l < 1 {
+		return nil, errors.New("modulus is too small for chunking")
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+	rand = defaultRand(rand)
+
+	packing := k - t
+	shares := make([]ChunkedShare, n)
+	for i := range shares {
+		shares[i] = ChunkedShare{
+			X:         big.NewInt(int64(i + 1)),
+			Modulus:   modulus,
+			SecretLen: len(secret),
+			Packing:   packing,
+		}
+	}
+
+	for len(secret) > 0 {
+		limbs := make([]*big.Int, packing)
+		for j := range limbs {
+			limb := secret
+			if len(limb) > l {
+				limb = limb[:l]
+			}
+			secret = secret[len(limb):]
+			limbs[j] = new(big.Int).SetBytes(limb)
+		}
+
+		points, err := SplitMulti(limbs, modulus, k, n, rand)
+		if err != nil {
+			return nil, err
+		}
+		for i := range shares {
+			shares[i].Ys = append(shares[i].Ys, points[i].Y)
+		}
+	}
+
+	return shares, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestRamp(t *testing.T) {
+	secret := make([]byte, 4000)
+	rand.Read(secret)
+
+	shares, err := SplitRamp(secret, P256Order, 2, 6, 8, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	if chunked, _ := SplitChunked(secret, P256Order, 6, 8, nil); len(shares[0].Ys)*4 > len(chunked[0].Ys)+3 {
+		t.Errorf("ramp shares have %d limbs, chunked shares %d", len(shares[0].Ys), len(chunked[0].Ys))
+	}
+
+	var parsed []ChunkedShare
+	for _, s := range shares[2:] {
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("error while marshaling: %s", err)
+		}
+		var p ChunkedShare
+		if err := p.UnmarshalBinary(data); err != nil {
+			t.Fatalf("error while unmarshaling: %s", err)
+		}
+		parsed = append(parsed, p)
+	}
+
+	result, err := JoinChunked(parsed)
+	if err != nil || !bytes.Equal(result, secret) {
+		t.Errorf("failed to join ramp shares: %v", err)
+	}
+
+	if result, err := JoinChunked(parsed[1:]); err == nil && bytes.Equal(result, secret) {
+		t.Errorf("joined too few ramp shares")
+	}
+}
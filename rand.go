@@ -0,0 +1,68 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"errors"
+	"io"
+)
+
+// defaultRand returns rand, or crypto/rand.Reader if rand is nil. Using
+// anything else, such as math/rand, makes the shares guessable.
+func defaultRand(rand io.Reader) io.Reader {
+	if rand == nil {
+		return cryptorand.Reader
+	}
+	return rand
+}
+
+// contextReader is an io.Reader that fails with ctx.Err() once ctx is done.
+// A read that has already started isn't interrupted.
+type contextReader struct {
+	ctx  context.Context
+	rand io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.rand.Read(p)
+}
+
+// randomUint returns a uniform random value in [0, max), consuming random
+// bytes exactly as randomNumber does.
+func randomUint(rand io.Reader, max uint64) (uint64, error) {
+	if max == 0 {
+		return 0, errors.New("invalid range")
+	}
+	bitLen := 64
+	for max>>(bitLen-1) == 0 {
+		bitLen--
+	}
+	k := (bitLen + 7) / 8
+	r := uint(bitLen % 8)
+	if r == 0 {
+		r = 8
+	}
+
+	var bytes [8]byte
+	for {
+		if _, err := io.ReadFull(rand, bytes[:k]); err != nil {
+			return 0, err
+		}
+		bytes[0] &= uint8(int(1<<r) - 1)
+
+		var n uint64
+		for _, b := range bytes[:k] {
+			n = n<<8 | uint64(b)
+		}
+		if n < max {
+			return n, nil
+		}
+	}
+}
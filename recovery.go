@@ -0,0 +1,225 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+)
+
+// A Recovery tracks a secret that has been shared among guardians for social
+// recovery. It holds only public information, the Feldman commitments and the
+// state of each guardian, and so can be stored by the owner of the secret,
+// or a service acting for them, without revealing anything.
+//
+// The lifecycle is: NewRecovery deals the shares as invitations, one for each
+// guardian. From time to time, including once an invitation is delivered, the
+// owner issues a Challenge, which the guardian answers with Respond using
+// their share, and Verify records that the guardian still holds a valid
+// share. Overdue lists the guardians who should be reminded. Finally, to
+// recover the secret, NewCombiner collects the guardians' shares.
+type Recovery struct {
+	Modulus     *big.Int
+	Threshold   int
+	Commitments *Commitments
+	Guardians   []Guardian
+}
+
+// A Guardian is the owner's record of one guardian.
+type Guardian struct {
+	Name string
+	// X is the x coordinate of the guardian's share.
+	X *big.Int
+	// Delivered is when the guardian first answered a challenge, showing
+	// that they received their share, or the zero time.
+	Delivered time.Time
+	// Verified is when the guardian last answered a challenge, or the
+	// zero time.
+	Verified time.Time
+	// Challenge is the outstanding challenge, if any. A challenge can be
+	// answered only once.
+	Challenge []byte
+}
+
+// An Invitation carries a guardian's share to them.
+type Invitation struct {
+	Guardian    string
+	Share       Share
+	Commitments *Commitments
+}
+
+// Check returns an error unless the share in the invitation lies on the
+// committed polynomial. A guardian should check an invitation before
+// accepting it.
+func (inv *Invitation) Check() error {
+	if inv.Commitments == nil || !inv.Commitments.Verify(inv.Share) {
+		return ErrCorruptShare
+	}
+	return nil
+}
+
+// NewRecovery splits secret among the named guardians, any k of whom can
+// recover it. The modulus must be the order of g mod p, as for
+// SplitVerifiable. It returns the Recovery, to be kept by the owner, and an
+// invitation for each guardian, to be delivered to them and then destroyed.
+// If rand is nil, crypto/rand.Reader is used.
+func NewRecovery(secret, modulus, p, g *big.Int, k int, names []string, rand io.Reader) (*Recovery, []Invitation, error) {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if len(name) == 0 || seen[name] {
+			return nil, nil, errors.New("guardian names must be distinct and non-empty")
+		}
+		seen[name] = true
+	}
+
+	set, err := Deal(secret, len(names), WithThreshold(k), WithField(modulus), WithCommitments(p, g), WithRand(rand))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := &Recovery{Modulus: modulus, Threshold: k, Commitments: set.Commitments}
+	r.Guardians = make([]Guardian, len(names))
+	invitations := make([]Invitation, len(names))
+	for i, name := range names {
+		r.Guardians[i] = Guardian{Name: name, X: set.Shares[i].X}
+		invitations[i] = Invitation{Guardian: name, Share: set.Shares[i], Commitments: set.Commitments}
+	}
+	return r, invitations, nil
+}
+
+// guardian returns the record for the named guardian.
+func (r *Recovery) guardian(name string) (*Guardian, error) {
+	for i := range r.Guardians {
+		if r.Guardians[i].Name == name {
+			return &r.Guardians[i], nil
+		}
+	}
+	return nil, errors.New("unknown guardian: " + name)
+}
+
+// Challenge returns a fresh challenge for the named guardian, replacing any
+// outstanding one. If rand is nil, crypto/rand.Reader is used.
+func (r *Recovery) Challenge(name string, rand io.Reader) ([]byte, error) {
+	g, err := r.guardian(name)
+	if err != nil {
+		return nil, err
+	}
+	c := make([]byte, 16)
+	if _, err := io.ReadFull(defaultRand(rand), c); err != nil {
+		return nil, err
+	}
+	g.Challenge = c
+	return c, nil
+}
+
+// A ChallengeResponse is a guardian's proof that they hold their share: a
+// Schnorr proof of knowledge of the share's y value, whose commitment, G^y,
+// follows from the Feldman commitments.
+type ChallengeResponse struct {
+	E, Z *big.Int
+}
+
+// Respond answers challenge using the share in inv, without revealing
+// it. If rand is nil, crypto/rand.Reader is used.
+func (inv *Invitation) Respond(challenge []byte, rand io.Reader) (*ChallengeResponse, error) {
+	if err := inv.Check(); err != nil {
+		return nil, err
+	}
+	c, q := inv.Commitments, inv.Share.Modulus
+
+	w, err := randomNumber(defaultRand(rand), q)
+	if err != nil {
+		return nil, err
+	}
+	t := new(big.Int).Exp(c.G, w, c.P)
+	e := recoveryChallenge(c, inv.Share.X, challenge, t, q)
+	z := new(big.Int).Mul(e, inv.Share.Y)
+	z.Add(z, w)
+	z.Mod(z, q)
+	return &ChallengeResponse{E: e, Z: z}, nil
+}
+
+// Verify checks the named guardian's response to their outstanding
+// challenge and, if it's valid, records that they held a valid share at time
+// now. The challenge is consumed whether or not the response is valid.
+func (r *Recovery) Verify(name string, resp *ChallengeResponse, now time.Time) error {
+	g, err := r.guardian(name)
+	if err != nil {
+		return err
+	}
+	challenge := g.Challenge
+	g.Challenge = nil
+	if challenge == nil {
+		return errors.New("no outstanding challenge")
+	}
+
+	q, c := r.Modulus, r.Commitments
+	if resp == nil || resp.E == nil || resp.Z == nil ||
+		resp.E.Sign() < 0 || resp.E.Cmp(q) >= 0 || resp.Z.Sign() < 0 || resp.Z.Cmp(q) >= 0 {
+		return errors.New("malformed response")
+	}
+
+	// t = G^z / (G^y)^e
+	y := pvssCommittedValue(c, g.X)
+	t := new(big.Int).Exp(y, new(big.Int).Sub(q, resp.E), c.P)
+	t.Mul(t, new(big.Int).Exp(c.G, resp.Z, c.P))
+	t.Mod(t, c.P)
+	if recoveryChallenge(c, g.X, challenge, t, q).Cmp(resp.E) != 0 {
+		return errors.New("guardian's response is invalid")
+	}
+
+	if g.Delivered.IsZero() {
+		g.Delivered = now
+	}
+	g.Verified = now
+	return nil
+}
+
+// Overdue returns the names of the guardians who haven't shown that they
+// hold a valid share since now minus maxAge, and so should be reminded.
+func (r *Recovery) Overdue(now time.Time, maxAge time.Duration) []string {
+	var names []string
+	cutoff := now.Add(-maxAge)
+	for _, g := range r.Guardians {
+		if g.Verified.Before(cutoff) {
+			names = append(names, g.Name)
+		}
+	}
+	return names
+}
+
+// NewCombiner returns a Combiner that checks each guardian's share against
+// the commitments, to assemble a quorum and recover the secret.
+func (r *Recovery) NewCombiner() (*Combiner, error) {
+	return NewCombiner(r.Threshold, r.Commitments)
+}
+
+// recoveryChallenge returns the Fiat-Shamir challenge for a guardian's
+// response.
+func recoveryChallenge(c *Commitments, x *big.Int, challenge []byte, t, q *big.Int) *big.Int {
+	h := sha256.New()
+	write := func(b []byte) {
+		h.Write(binary.AppendUvarint(nil, uint64(len(b))))
+		h.Write(b)
+	}
+
+	write(c.P.Bytes())
+	write(c.G.Bytes())
+	for _, v := range c.Values {
+		write(v.Bytes())
+	}
+	write(x.Bytes())
+	write(challenge)
+	write(t.Bytes())
+
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, q)
+}
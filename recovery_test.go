@@ -0,0 +1,85 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecovery(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	g := big.NewInt(4)
+	secret := big.NewInt(31337)
+
+	r, invitations, err := NewRecovery(secret, q, p, g, 2, []string{"alice", "bob", "carol"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1000000, 0)
+	for _, inv := range invitations[:2] {
+		if err := inv.Check(); err != nil {
+			t.Fatalf("%s: %s", inv.Guardian, err)
+		}
+		c, err := r.Challenge(inv.Guardian, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := inv.Respond(c, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Verify(inv.Guardian, resp, now); err != nil {
+			t.Errorf("%s: %s", inv.Guardian, err)
+		}
+		// A response can't be replayed.
+		if err := r.Verify(inv.Guardian, resp, now); err == nil {
+			t.Errorf("%s: replayed response was accepted", inv.Guardian)
+		}
+	}
+	if !r.Guardians[0].Delivered.Equal(now) || !r.Guardians[2].Delivered.IsZero() {
+		t.Error("deliveries weren't recorded")
+	}
+
+	if overdue := r.Overdue(now.Add(time.Hour), 24*time.Hour); !slices.Equal(overdue, []string{"carol"}) {
+		t.Errorf("overdue guardians are %q, want [carol]", overdue)
+	}
+
+	// Bob answers Carol's challenge with his share.
+	c, err := r.Challenge("carol", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := invitations[1].Respond(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Verify("carol", resp, now); err == nil {
+		t.Error("response with another guardian's share was accepted")
+	}
+
+	combiner, err := r.NewCombiner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, inv := range invitations[1:] {
+		if err := combiner.Add(inv.Share); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := combiner.Combine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("got %s, want %s", result, secret)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The types in this file hold secret material, so they print only what
+// identifies them. They implement fmt.Formatter, as well as fmt.Stringer, so
+// that no verb, including %#v and %x, prints their fields.
+
+// String returns a description of s that omits its y value and keys.
+func (s Share) String() string {
+	var fields []string
+	if s.X != nil {
+		fields = append(fields, "x="+s.X.String())
+	}
+	if m := s.Metadata; m != nil {
+		if m.Threshold > 0 {
+			fields = append(fields, "k="+strconv.Itoa(m.Threshold))
+		}
+		fields = append(fields, "set="+hex.EncodeToString(m.SetID[:]))
+		if m.Epoch > 0 {
+			fields = append(fields, "epoch="+strconv.FormatUint(m.Epoch, 10))
+		}
+	}
+	if s.SecretLen > 0 {
+		fields = append(fields, "len="+strconv.Itoa(s.SecretLen))
+	}
+	if s.Additive {
+		fields = append(fields, "additive")
+	}
+	return "shamirsplit.Share{" + strings.Join(fields, " ") + "}"
+}
+
+// Format implements fmt.Formatter, printing s.String() for every verb.
+func (s Share) Format(f fmt.State, verb rune) {
+	io.WriteString(f, s.String())
+}
+
+// String returns a description of s that omits its limbs.
+func (s ChunkedShare) String() string {
+	var fields []string
+	if s.X != nil {
+		fields = append(fields, "x="+s.X.String())
+	}
+	fields = append(fields, "limbs="+strconv.Itoa(len(s.Ys)))
+	if s.SecretLen > 0 {
+		fields = append(fields, "len="+strconv.Itoa(s.SecretLen))
+	}
+	return "shamirsplit.ChunkedShare{" + strings.Join(fields, " ") + "}"
+}
+
+// Format implements fmt.Formatter, printing s.String() for every verb.
+func (s ChunkedShare) Format(f fmt.State, verb rune) {
+	io.WriteString(f, s.String())
+}
+
+// String returns a description of d that omits its polynomial.
+func (d Dealer) String() string {
+	return "shamirsplit.Dealer{k=" + strconv.Itoa(len(d.coefficients)) + "}"
+}
+
+// Format implements fmt.Formatter, printing d.String() for every verb.
+func (d Dealer) Format(f fmt.State, verb rune) {
+	io.WriteString(f, d.String())
+}
@@ -0,0 +1,45 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestRedactedFormatting(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(0x5ec7e7)
+	d, err := NewDealer(secret, modulus, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.Share(big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.MACKey = []byte("mac key")
+	chunked := ChunkedShare{X: s.X, Ys: []*big.Int{s.Y}, Modulus: modulus, SecretLen: 3}
+
+	secrets := []string{s.Y.String(), s.Y.Text(16), secret.String(), secret.Text(16), "mac key"}
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%x", "%d"} {
+		for _, v := range []any{s, &s, chunked, &chunked, d, *d} {
+			out := fmt.Sprintf(verb, v)
+			for _, secret := range secrets {
+				if strings.Contains(out, secret) {
+					t.Errorf("%s of %T printed %q, which contains secret %q", verb, v, out, secret)
+				}
+			}
+		}
+	}
+
+	if got, want := s.String(), "shamirsplit.Share{x=2}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
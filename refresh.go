@@ -0,0 +1,430 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// A refresh packet uses the encoding of binary shares (see wire.go) with its
+// own magic.
+const refreshMagic = "SHMZ"
+
+// A PairwiseSeed is a secret known only to a shareholder and one other,
+// agreed once, for example with X25519, and used to mask the refresh
+// packets that pass between them.
+type PairwiseSeed struct {
+	// X is the x coordinate of the other shareholder's share.
+	X    *big.Int
+	Seed []byte
+}
+
+// A RefreshPacket is one shareholder's contribution to refreshing the shares
+// of a dealing: the values, at each shareholder's x coordinate, of a random
+// polynomial of degree k-1 that is zero at zero. Adding them to the shares
+// changes every share but not the secret, so shares stolen before a refresh
+// can't be combined with shares from after it.
+//
+// Each value is masked with a pseudorandom function of the seed shared by
+// the sender and recipient, so packets can be published, for example to a
+// shared directory, and applied offline whenever each shareholder is ready,
+// without a live round of communication. The sender's own value is masked
+// with a key derived from its share.
+//
+// Packets from NewVerifiableRefreshPacket also carry Feldman commitments to
+// the polynomial, which prove that it's zero at zero and that each value is
+// on it. So long as all shareholders see the same packets, a malicious
+// shareholder can't then corrupt the others' shares, or change the secret,
+// during a refresh.
+type RefreshPacket struct {
+	// From is the x coordinate of the sender's share.
+	From    *big.Int
+	Epoch   uint64
+	Modulus *big.Int
+	// Values[i] is the masked value for the share with x coordinate To[i].
+	To, Values []*big.Int
+	// Commitments, if not nil, are Feldman commitments to the polynomial.
+	Commitments *Commitments
+}
+
+// NewRefreshPacket returns s's holder's contribution to refreshing the
+// dealing into the given epoch, which must be later than that of s. The
+// packet has a value for s itself and for each shareholder with a seed in
+// seeds. The threshold is taken from the metadata of s. If rand is nil,
+// crypto/rand.Reader is used.
+func NewRefreshPacket(s *Share, epoch uint64, seeds []PairwiseSeed, rand io.Reader) (*RefreshPacket, error) {
+	return newRefreshPacket(s, epoch, seeds, nil, rand)
+}
+
+// NewVerifiableRefreshPacket is like NewRefreshPacket, but the packet
+// carries Feldman commitments in the group of the dealing's commitments, c,
+// as from SplitVerifiable.
+func NewVerifiableRefreshPacket(s *Share, epoch uint64, seeds []PairwiseSeed, c *Commitments, rand io.Reader) (*RefreshPacket, error) {
+	if c == nil || c.P == nil || c.G == nil {
+		return nil, errors.New("no commitment group given")
+	}
+	if c.Epoch != shareEpoch(s) {
+		return nil, errors.New("commitments are from a different epoch")
+	}
+	return newRefreshPacket(s, epoch, seeds, c, rand)
+}
+
+// newRefreshPacket implements NewRefreshPacket and, if c is not nil,
+// NewVerifiableRefreshPacket.
+func newRefreshPacket(s *Share, epoch uint64, seeds []PairwiseSeed, c *Commitments, rand io.Reader) (*RefreshPacket, error) {
+	k, err := checkRefresh(s, epoch)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSeeds(s, seeds); err != nil {
+		return nil, err
+	}
+	modulus := s.Modulus
+
+	a := make([]*big.Int, k)
+	a[0] = new(big.Int)
+	for j := 1; j < k; j++ {
+		if a[j], err = randomNumber(defaultRand(rand), modulus); err != nil {
+			return nil, err
+		}
+	}
+	defer func() {
+		for _, v := range a {
+			clear(v.Bits())
+		}
+	}()
+
+	p := &RefreshPacket{From: s.X, Epoch: epoch, Modulus: modulus}
+	if c != nil {
+		p.Commitments = &Commitments{P: c.P, G: c.G, Values: make([]*big.Int, k)}
+		for j := range a {
+			p.Commitments.Values[j] = new(big.Int).Exp(c.G, a[j], c.P)
+		}
+	}
+	selfKey := refreshSelfKey(s)
+	defer clear(selfKey)
+	p.To = append(p.To, s.X)
+	p.Values = append(p.Values, maskRefresh(evaluatePolynomial(a, s.X, modulus), selfKey, p, s.X))
+	for _, seed := range seeds {
+		p.To = append(p.To, seed.X)
+		p.Values = append(p.Values, maskRefresh(evaluatePolynomial(a, seed.X, modulus), seed.Seed, p, seed.X))
+	}
+	return p, nil
+}
+
+// ApplyRefresh returns s refreshed into the given epoch by adding the values
+// for it from packets, which must include one from every shareholder that is
+// to keep a working share, including s's holder. Every shareholder must
+// apply the same packets, or their shares won't combine. The result has the
+// new epoch, and no dealer signature, which no longer verifies.
+//
+// If c, the dealing's commitments, is not nil, every packet must carry
+// commitments in the same group, showing that it shares zero, and the value
+// for s must be consistent with them, or ErrCorruptShare is returned. Use
+// RefreshCommitments to update c to match the refreshed shares.
+func ApplyRefresh(s *Share, epoch uint64, packets []*RefreshPacket, seeds []PairwiseSeed, c *Commitments) (Share, error) {
+	k, err := checkRefresh(s, epoch)
+	if err != nil {
+		return Share{}, err
+	}
+	if err := checkSeeds(s, seeds); err != nil {
+		return Share{}, err
+	}
+	if c != nil && c.Epoch != shareEpoch(s) {
+		return Share{}, errors.New("commitments are from a different epoch")
+	}
+	if len(packets) == 0 {
+		return Share{}, errors.New("no refresh packets given")
+	}
+
+	selfKey := refreshSelfKey(s)
+	defer clear(selfKey)
+	y := new(big.Int).Set(s.Y)
+	var haveOwn bool
+	for i, p := range packets {
+		if p.Epoch != epoch || p.Modulus == nil || p.Modulus.Cmp(s.Modulus) != 0 || p.From == nil || len(p.To) != len(p.Values) {
+			return Share{}, errors.New("refresh packet " + strconv.Itoa(i) + " is for a different dealing or epoch")
+		}
+		for _, q := range packets[:i] {
+			if q.From.Cmp(p.From) == 0 {
+				return Share{}, errors.New("found duplicate refresh packet")
+			}
+		}
+
+		var key []byte
+		if p.From.Cmp(s.X) == 0 {
+			key, haveOwn = selfKey, true
+		} else {
+			for _, seed := range seeds {
+				if seed.X.Cmp(p.From) == 0 {
+					key = seed.Seed
+				}
+			}
+			if key == nil {
+				return Share{}, errors.New("no seed shared with the sender of refresh packet " + strconv.Itoa(i))
+			}
+		}
+
+		var v *big.Int
+		for j, to := range p.To {
+			if to != nil && to.Cmp(s.X) == 0 {
+				v = p.Values[j]
+			}
+		}
+		if v == nil || v.Sign() < 0 || v.Cmp(s.Modulus) >= 0 {
+			return Share{}, errors.New("refresh packet " + strconv.Itoa(i) + " has no value for this share")
+		}
+		v = unmaskRefresh(v, key, p, s.X)
+		if c != nil {
+			if err := checkRefreshCommitments(p, c, k); err != nil {
+				return Share{}, err
+			}
+			if !p.Commitments.Verify(Share{X: s.X, Y: v}) {
+				return Share{}, ErrCorruptShare
+			}
+		}
+		y.Add(y, v)
+	}
+	if !haveOwn {
+		return Share{}, errors.New("refresh packets don't include this shareholder's own")
+	}
+
+	refreshed := *s
+	refreshed.Y = y.Mod(y, s.Modulus)
+	m := *s.Metadata
+	m.Epoch = epoch
+	refreshed.Metadata = &m
+	refreshed.DealerKey, refreshed.Signature = nil, nil
+	return refreshed, nil
+}
+
+// RefreshCommitments returns the commitments to the refreshed dealing: the
+// dealing's commitments, c, multiplied by those of each of the packets,
+// which must be those given to ApplyRefresh. The commitment to the secret
+// is unchanged and the epoch advances to that of the packets.
+func RefreshCommitments(c *Commitments, packets []*RefreshPacket) (*Commitments, error) {
+	if c == nil || len(c.Values) == 0 {
+		return nil, errors.New("no commitments given")
+	}
+	if len(packets) == 0 {
+		return nil, errors.New("no refresh packets given")
+	}
+	epoch := packets[0].Epoch
+	if epoch <= c.Epoch {
+		return nil, errors.New("refresh epoch must be later than the commitments'")
+	}
+	refreshed := &Commitments{P: c.P, G: c.G, Values: make([]*big.Int, len(c.Values)), Epoch: epoch}
+	for j, v := range c.Values {
+		refreshed.Values[j] = new(big.Int).Set(v)
+	}
+	for i, p := range packets {
+		if p.Epoch != epoch {
+			return nil, errors.New("refresh packet " + strconv.Itoa(i) + " is for a different epoch")
+		}
+		if err := checkRefreshCommitments(p, c, len(c.Values)); err != nil {
+			return nil, err
+		}
+		for j, v := range p.Commitments.Values {
+			refreshed.Values[j].Mul(refreshed.Values[j], v)
+			refreshed.Values[j].Mod(refreshed.Values[j], c.P)
+		}
+	}
+	return refreshed, nil
+}
+
+// checkRefreshCommitments returns an error unless p carries commitments, in
+// the group of c, to a polynomial of degree k-1 that is zero at zero.
+func checkRefreshCommitments(p *RefreshPacket, c *Commitments, k int) error {
+	d := p.Commitments
+	if d == nil {
+		return errors.New("refresh packet has no commitments")
+	}
+	if d.P == nil || d.G == nil || d.P.Cmp(c.P) != 0 || d.G.Cmp(c.G) != 0 || len(d.Values) != k {
+		return errors.New("refresh packet's commitments are for a different dealing")
+	}
+	for _, v := range d.Values {
+		if v == nil || v.Sign() <= 0 || v.Cmp(d.P) >= 0 {
+			return ErrCorruptShare
+		}
+	}
+	// g^0 = 1.
+	if d.Values[0].Cmp(big.NewInt(1)) != 0 {
+		return ErrCorruptShare
+	}
+	return nil
+}
+
+// checkRefresh returns the threshold of s, or an error if s can't be
+// refreshed into epoch.
+func checkRefresh(s *Share, epoch uint64) (int, error) {
+	if s.X == nil || s.Y == nil || s.Modulus == nil {
+		return 0, errors.New("share doesn't record its coordinates and modulus")
+	}
+	if s.Additive || s.Hyperplane != nil || s.Mandatory != nil {
+		return 0, errors.New("only plain Shamir shares can be refreshed")
+	}
+	if s.Metadata == nil || s.Metadata.Threshold == 0 {
+		return 0, errors.New("share doesn't record its threshold")
+	}
+	if epoch <= s.Metadata.Epoch {
+		return 0, errors.New("refresh epoch must be later than the share's")
+	}
+	return s.Metadata.Threshold, nil
+}
+
+// checkSeeds returns an error unless each seed is for a different share
+// other than s.
+func checkSeeds(s *Share, seeds []PairwiseSeed) error {
+	for i, seed := range seeds {
+		if seed.X == nil || seed.X.Cmp(s.X) == 0 || len(seed.Seed) < 16 {
+			return errors.New("invalid pairwise seed")
+		}
+		for _, other := range seeds[:i] {
+			if other.X.Cmp(seed.X) == 0 {
+				return errors.New("found duplicate pairwise seed")
+			}
+		}
+	}
+	return nil
+}
+
+// refreshSelfKey returns the key that masks a shareholder's value for its
+// own share, which only it knows.
+func refreshSelfKey(s *Share) []byte {
+	h := hmac.New(sha256.New, s.Y.FillBytes(make([]byte, (s.Modulus.BitLen()+7)/8)))
+	h.Write([]byte("shamirsplit refresh self key"))
+	return h.Sum(nil)
+}
+
+// refreshMask returns the pseudorandom mask, modulo p.Modulus, for the value
+// for the share at x in p: HMAC-SHA256 of the epoch and the two x
+// coordinates, in counter mode, with 128 bits more than the modulus so that
+// the reduction is unbiased to within 2^-128.
+func refreshMask(key []byte, p *RefreshPacket, x *big.Int) *big.Int {
+	n := (p.Modulus.BitLen()+7)/8 + 16
+	var stream []byte
+	for counter := uint32(0); len(stream) < n; counter++ {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte("shamirsplit refresh mask"))
+		h.Write(binary.BigEndian.AppendUint32(nil, counter))
+		h.Write(binary.BigEndian.AppendUint64(nil, p.Epoch))
+		for _, v := range []*big.Int{p.From, x} {
+			b := v.Bytes()
+			h.Write(binary.AppendUvarint(nil, uint64(len(b))))
+			h.Write(b)
+		}
+		stream = h.Sum(stream)
+	}
+	mask := new(big.Int).SetBytes(stream[:n])
+	clear(stream)
+	return mask.Mod(mask, p.Modulus)
+}
+
+func maskRefresh(v *big.Int, key []byte, p *RefreshPacket, x *big.Int) *big.Int {
+	v.Add(v, refreshMask(key, p, x))
+	return v.Mod(v, p.Modulus)
+}
+
+func unmaskRefresh(v *big.Int, key []byte, p *RefreshPacket, x *big.Int) *big.Int {
+	u := new(big.Int).Sub(v, refreshMask(key, p, x))
+	return u.Mod(u, p.Modulus)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *RefreshPacket) MarshalBinary() ([]byte, error) {
+	if p.From == nil || p.Modulus == nil || p.Modulus.Sign() <= 0 || len(p.To) != len(p.Values) {
+		return nil, errors.New("refresh packet is incomplete")
+	}
+	var r wireRecords
+	r.addInt(tagX, p.From)
+	if name := modulusName(p.Modulus); len(name) > 0 {
+		r.add(tagModulusName, []byte(name))
+	} else {
+		r.addInt(tagModulus, p.Modulus)
+	}
+	if p.Commitments != nil {
+		b, err := p.Commitments.marshal()
+		if err != nil {
+			return nil, err
+		}
+		r.add(tagCommitments, b)
+	}
+	r.addUint(tagEpoch, p.Epoch)
+	var b []byte
+	for i := range p.To {
+		for _, v := range []*big.Int{p.To[i], p.Values[i]} {
+			if v == nil || v.Sign() < 0 {
+				return nil, errors.New("invalid refresh value")
+			}
+			b = binary.AppendUvarint(b, uint64(len(v.Bytes())))
+			b = append(b, v.Bytes()...)
+		}
+	}
+	r.add(tagRefresh, b)
+	return r.marshalAs(refreshMagic), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (p *RefreshPacket) UnmarshalBinary(data []byte) error {
+	var q RefreshPacket
+	var haveEpoch bool
+	err := parseWireAs(refreshMagic, data, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagX:
+			q.From = new(big.Int).SetBytes(value)
+		case tagModulus:
+			q.Modulus = new(big.Int).SetBytes(value)
+		case tagModulusName:
+			if q.Modulus = NamedModulus(string(value)); q.Modulus == nil {
+				return errors.New("refresh packet uses an unknown modulus")
+			}
+		case tagCommitments:
+			c, err := parseCommitments(value)
+			q.Commitments = c
+			return err
+		case tagEpoch:
+			e, err := parseWireUint(value)
+			if err != nil {
+				return errors.New("invalid epoch")
+			}
+			q.Epoch, haveEpoch = e, true
+		case tagRefresh:
+			for len(value) > 0 {
+				var vs [2]*big.Int
+				for j := range vs {
+					l, n := binary.Uvarint(value)
+					if n <= 0 || l > uint64(len(value)-n) {
+						return errors.New("invalid refresh values")
+					}
+					vs[j] = new(big.Int).SetBytes(value[n : n+int(l)])
+					value = value[n+int(l):]
+				}
+				if len(q.To) >= DefaultLimits.MaxShares {
+					return ErrLimitExceeded
+				}
+				q.To, q.Values = append(q.To, vs[0]), append(q.Values, vs[1])
+			}
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if q.From == nil || q.Modulus == nil || q.Modulus.Sign() <= 0 || !haveEpoch || len(q.To) == 0 {
+		return errors.New("refresh packet is incomplete")
+	}
+	*p = q
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// pairwiseSeeds returns, for each share, random seeds shared with each of
+// the others.
+func pairwiseSeeds(t *testing.T, shares []Share) [][]PairwiseSeed {
+	seeds := make([][]PairwiseSeed, len(shares))
+	for i := range shares {
+		for j := 0; j < i; j++ {
+			seed := make([]byte, 32)
+			if _, err := rand.Read(seed); err != nil {
+				t.Fatal(err)
+			}
+			seeds[i] = append(seeds[i], PairwiseSeed{X: shares[j].X, Seed: seed})
+			seeds[j] = append(seeds[j], PairwiseSeed{X: shares[i].X, Seed: seed})
+		}
+	}
+	return seeds
+}
+
+func TestRefresh(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(1234)
+	shares, err := SplitShares(secret, modulus, 3, 5, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeds := pairwiseSeeds(t, shares)
+
+	// Each shareholder publishes a packet, which travels encoded.
+	var packets []*RefreshPacket
+	for i := range shares {
+		p, err := NewRefreshPacket(&shares[i], 1, seeds[i], rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var q RefreshPacket
+		if err := q.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, &q)
+	}
+
+	refreshed := make([]Share, len(shares))
+	for i := range shares {
+		if refreshed[i], err = ApplyRefresh(&shares[i], 1, packets, seeds[i], nil); err != nil {
+			t.Fatal(err)
+		}
+		if refreshed[i].Y.Cmp(shares[i].Y) == 0 {
+			t.Errorf("share %d didn't change", i)
+		}
+		if refreshed[i].Metadata.Epoch != 1 || shares[i].Metadata.Epoch != 0 {
+			t.Error("epochs weren't updated correctly")
+		}
+	}
+
+	for _, subset := range [][]Share{refreshed[:3], refreshed[2:], {refreshed[4], refreshed[0], refreshed[2]}} {
+		if got, err := JoinShares(subset); err != nil || got.Cmp(secret) != 0 {
+			t.Errorf("got %v, %v from refreshed shares", got, err)
+		}
+	}
+	if _, err := JoinShares([]Share{shares[0], shares[1], refreshed[2]}); err == nil {
+		t.Error("shares from different epochs were joined")
+	}
+
+	if _, err := ApplyRefresh(&shares[0], 1, packets[1:], seeds[0], nil); err == nil {
+		t.Error("refresh without the shareholder's own packet was accepted")
+	}
+	if _, err := ApplyRefresh(&shares[0], 1, packets, seeds[0][1:], nil); err == nil {
+		t.Error("refresh with a missing seed was accepted")
+	}
+	if _, err := ApplyRefresh(&refreshed[0], 1, packets, seeds[0], nil); err == nil {
+		t.Error("refresh into the same epoch was accepted")
+	}
+}
+
+func TestVerifiableRefresh(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	secret := big.NewInt(99)
+	set, err := Deal(secret, 4, WithThreshold(2), WithField(q), WithCommitments(p, big.NewInt(4)), WithRand(rand.Reader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, c := set.Shares, set.Commitments
+	seeds := pairwiseSeeds(t, shares)
+
+	var packets []*RefreshPacket
+	for i := range shares {
+		packet, err := NewVerifiableRefreshPacket(&shares[i], 1, seeds[i], c, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, packet)
+	}
+	c2, err := RefreshCommitments(c, packets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2.Values[0].Cmp(c.Values[0]) != 0 {
+		t.Error("commitment to the secret changed")
+	}
+	refreshed := make([]Share, len(shares))
+	for i := range shares {
+		if refreshed[i], err = ApplyRefresh(&shares[i], 1, packets, seeds[i], c); err != nil {
+			t.Fatal(err)
+		}
+		if !c2.Verify(refreshed[i]) {
+			t.Errorf("refreshed share %d doesn't match the refreshed commitments", i)
+		}
+	}
+	if got, err := JoinShares(refreshed[1:3]); err != nil || got.Cmp(secret) != 0 {
+		t.Errorf("got %v, %v from refreshed shares", got, err)
+	}
+
+	// Shares from before the refresh can't be replayed, either alongside
+	// new ones or against the new commitments.
+	if c2.Epoch != 1 || c2.Verify(shares[0]) || c.Verify(refreshed[0]) {
+		t.Error("commitments don't bind the epoch")
+	}
+	var mismatch *MismatchError
+	if _, err := JoinShares([]Share{refreshed[1], shares[2]}); !errors.As(err, &mismatch) || mismatch.Field != "epoch" {
+		t.Errorf("mixing epochs gave %v", err)
+	}
+	combiner, _ := NewCombiner(0, nil)
+	combiner.SetMinEpoch(1)
+	if err := combiner.Add(shares[0]); err != ErrStaleEpoch {
+		t.Errorf("stale share gave %v, want ErrStaleEpoch", err)
+	}
+	if err := combiner.Add(refreshed[0]); err != nil {
+		t.Errorf("refreshed share was rejected: %s", err)
+	}
+	if _, err := RefreshCommitments(c2, packets); err == nil {
+		t.Error("commitments were refreshed into the same epoch twice")
+	}
+
+	newSet := &ShareSet{Modulus: q, Threshold: 2, Shares: refreshed, Commitments: c2}
+	data, err := newSet.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodedSet ShareSet
+	if err := decodedSet.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decodedSet.Commitments.Epoch != 1 {
+		t.Errorf("share set's commitments have epoch %d after encoding", decodedSet.Commitments.Epoch)
+	}
+
+	// A shareholder that sends a bad value to another is caught by that
+	// shareholder.
+	bad := *packets[0]
+	bad.Values = append([]*big.Int(nil), bad.Values...)
+	bad.Values[1] = new(big.Int).Add(bad.Values[1], big.NewInt(1))
+	withBad := []*RefreshPacket{&bad, packets[1], packets[2], packets[3]}
+	if _, err := ApplyRefresh(&shares[1], 1, withBad, seeds[1], c); err != ErrCorruptShare {
+		t.Errorf("bad value gave %v", err)
+	}
+
+	// As is one that tries to change the secret.
+	shift := *packets[0]
+	shift.Commitments = &Commitments{P: p, G: c.G, Values: append([]*big.Int{big.NewInt(4)}, packets[0].Commitments.Values[1:]...)}
+	withShift := []*RefreshPacket{&shift, packets[1], packets[2], packets[3]}
+	if _, err := ApplyRefresh(&shares[1], 1, withShift, seeds[1], c); err != ErrCorruptShare {
+		t.Errorf("nonzero sharing gave %v", err)
+	}
+
+	// And packets without commitments aren't accepted.
+	plain, err := NewRefreshPacket(&shares[0], 1, seeds[0], rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyRefresh(&shares[1], 1, []*RefreshPacket{plain, packets[1], packets[2], packets[3]}, seeds[1], c); err == nil {
+		t.Error("packet without commitments was accepted")
+	}
+
+	data, err = packets[2].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded RefreshPacket
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Commitments == nil || len(decoded.Commitments.Values) != 2 {
+		t.Error("commitments were lost in encoding")
+	}
+}
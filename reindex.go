@@ -0,0 +1,103 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// ReplaceShare computes a share at the new x coordinate x from quorum, at
+// least threshold shares of a dealing that records its threshold, so that a
+// custodian, whose share had x coordinate retired, can be replaced without
+// dealing afresh. The quorum must all lie on one polynomial. The new share
+// has the metadata, MAC key and fingerprint of the dealing, but no
+// participant, hint or signature, which the caller can add.
+//
+// If t, the transcript of the dealing, isn't nil, the new share's digest is
+// added to it and retired is recorded as retired, so that t.Contains no
+// longer accepts the old share. Since the signature of t no longer covers
+// it, the signature is removed: the dealer should sign t again.
+//
+// Retiring a share is a matter of record: the old share still works with
+// any threshold-1 others, so this is only appropriate when policy allows it.
+// Otherwise, Rotate the secret or refresh the shares. x must not be that of
+// any other share of the dealing, which the caller must check as t records
+// only digests.
+func ReplaceShare(quorum []Share, retired, x *big.Int, t *Transcript) (Share, error) {
+	xs, ys, modulus, err := shareCoordinates(quorum)
+	if err != nil {
+		return Share{}, err
+	}
+	first := &quorum[0]
+	for i := range quorum {
+		if err := checkSameDealing(i, first, &quorum[i]); err != nil {
+			return Share{}, err
+		}
+	}
+	if first.Hyperplane != nil || first.Mandatory != nil {
+		return Share{}, errors.New("only plain Shamir shares can be replaced")
+	}
+	m := first.Metadata
+	if m == nil || m.Threshold == 0 {
+		return Share{}, errors.New("shares don't record the threshold")
+	}
+	k := m.Threshold
+	if len(quorum) < k {
+		return Share{}, &NotEnoughSharesError{Need: k, Have: len(quorum)}
+	}
+	if err := CheckShares(quorum, k); err != nil {
+		return Share{}, err
+	}
+
+	if retired == nil || retired.Sign() <= 0 || retired.Cmp(modulus) >= 0 ||
+		x == nil || x.Sign() <= 0 || x.Cmp(modulus) >= 0 {
+		return Share{}, errors.New("invalid x coordinate")
+	}
+	if x.Cmp(retired) == 0 {
+		return Share{}, errors.New("replacement share needs a new x coordinate")
+	}
+	for _, qx := range xs {
+		if qx.Cmp(x) == 0 {
+			return Share{}, errors.New("x coordinate is already in use")
+		}
+	}
+	if t != nil {
+		id, _ := first.SetID()
+		if t.Modulus == nil || t.Modulus.Cmp(modulus) != 0 || t.Metadata.SetID != id {
+			return Share{}, errors.New("transcript is of a different dealing")
+		}
+		if t.IsRetired(x) {
+			return Share{}, errors.New("x coordinate has been retired")
+		}
+		if t.IsRetired(retired) {
+			return Share{}, errors.New("share has already been retired")
+		}
+	}
+
+	mCopy := *m
+	s := Share{
+		X:           new(big.Int).Set(x),
+		Y:           interpolateAt(xs[:k], ys[:k], x, modulus),
+		Modulus:     modulus,
+		SecretLen:   first.SecretLen,
+		Metadata:    &mCopy,
+		MACKey:      first.MACKey,
+		Fingerprint: first.Fingerprint,
+	}
+	if t != nil {
+		data, err := marshalWithoutHint(&s)
+		if err != nil {
+			return Share{}, err
+		}
+		t.ShareDigests = append(t.ShareDigests, sha256.Sum256(data))
+		t.Retired = append(t.Retired, new(big.Int).Set(retired))
+		t.Signature = nil
+	}
+	return s, nil
+}
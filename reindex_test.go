@@ -0,0 +1,87 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestReplaceShare(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(1234)
+	set, err := Deal(secret, 3, WithThreshold(2), WithField(modulus), WithMAC(), WithRand(rand.Reader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transcript, err := NewTranscript(set, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	if err := transcript.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	old := set.Shares[2]
+	s, err := ReplaceShare(set.Shares[:2], old.X, big.NewInt(4), transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := JoinShares([]Share{s, set.Shares[1]}); err != nil || got.Cmp(secret) != 0 {
+		t.Errorf("joining the replacement share gave %v, %v", got, err)
+	}
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Share
+	if err := parsed.UnmarshalBinary(data); err != nil || parsed.MACKey == nil {
+		t.Errorf("replacement share didn't round trip with a MAC: %v", err)
+	}
+
+	if transcript.Signature != nil {
+		t.Error("transcript signature wasn't removed")
+	}
+	if !transcript.Contains(&s) || transcript.Contains(&old) || !transcript.Contains(&set.Shares[0]) {
+		t.Error("transcript doesn't reflect the replacement")
+	}
+	tdata, err := transcript.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tr Transcript
+	if err := tr.UnmarshalBinary(tdata); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Retired) != 1 || tr.Retired[0].Cmp(old.X) != 0 || tr.Contains(&old) {
+		t.Errorf("retired shares didn't round trip: %v", tr.Retired)
+	}
+
+	if _, err := ReplaceShare(set.Shares[:2], old.X, big.NewInt(5), transcript); err == nil {
+		t.Error("share was retired twice")
+	}
+	if _, err := ReplaceShare(set.Shares[:2], big.NewInt(1), old.X, transcript); err == nil {
+		t.Error("retired x coordinate was reused")
+	}
+	if _, err := ReplaceShare(set.Shares[:2], old.X, set.Shares[1].X, nil); err == nil {
+		t.Error("x coordinate of a quorum share was reused")
+	}
+	if _, err := ReplaceShare(set.Shares[:1], old.X, big.NewInt(5), nil); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("replacing from one share gave %v", err)
+	}
+	bad := append([]Share(nil), set.Shares...)
+	bad[2].Y = new(big.Int).Add(bad[2].Y, big.NewInt(1))
+	bad[2].MACKey = nil
+	bad[0].MACKey, bad[1].MACKey = nil, nil
+	if _, err := ReplaceShare(bad, big.NewInt(3), big.NewInt(5), nil); err == nil {
+		t.Error("inconsistent quorum was accepted")
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"math/bits"
+)
+
+// maxReplicatedSets limits the number of additive shares in a replicated
+// split, which grows as n choose k-1.
+const maxReplicatedSets = 1 << 16
+
+// A ReplicatedShare is a party's share of a replicated (CNF) split of a
+// secret with threshold K among N parties. The secret is the sum of one
+// additive value for each set of K-1 parties, and each party holds the values
+// for the sets that they aren't in, so any K parties hold all the values
+// between them, while K-1 parties lack the value for their own set.
+type ReplicatedShare struct {
+	Party   int
+	K, N    int
+	Modulus *big.Int
+	// Values maps each set of K-1 parties that doesn't include Party, as a
+	// bit mask where bit i-1 represents party i, to its additive value.
+	Values map[uint64]*big.Int
+}
+
+// SplitReplicated splits secret into n replicated shares, any k of which can
+// be combined by JoinReplicated to recover it. Since the shares grow as n
+// choose k-1, this is only suitable for small numbers of parties, such as
+// three party, honest majority protocols with k = 2. If rand is nil,
+// crypto/rand.Reader is used.
+func SplitReplicated(secret, modulus *big.Int, k, n int, rand io.Reader) ([]ReplicatedShare, error) {
+	sets, err := replicatedSets(k, n)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := SplitAdditive(secret, modulus, len(sets), rand)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]ReplicatedShare, n)
+	for i := range shares {
+		shares[i] = ReplicatedShare{Party: i + 1, K: k, N: n, Modulus: modulus, Values: make(map[uint64]*big.Int)}
+		for j, set := range sets {
+			if set&(1<<i) == 0 {
+				shares[i].Values[set] = values[j].Y
+			}
+		}
+	}
+	return shares, nil
+}
+
+// JoinReplicated recovers the secret from at least k shares that resulted
+// from SplitReplicated. Since values are held by several parties, some
+// corruption is detected.
+func JoinReplicated(shares []ReplicatedShare) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	first := shares[0]
+	if first.Modulus == nil {
+		return nil, errors.New("shares don't record their modulus")
+	}
+	sets, err := replicatedSets(first.K, first.N)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[uint64]*big.Int)
+	for _, s := range shares {
+		if s.K != first.K || s.N != first.N || s.Modulus == nil || s.Modulus.Cmp(first.Modulus) != 0 {
+			return nil, errors.New("shares are from different splits")
+		}
+		for set, v := range s.Values {
+			if prev, ok := values[set]; ok && prev.Cmp(v) != 0 {
+				return nil, errors.New("shares disagree: at least one is corrupt")
+			}
+			values[set] = v
+		}
+	}
+
+	sum := new(big.Int)
+	for _, set := range sets {
+		v, ok := values[set]
+		if !ok {
+			return nil, errors.New("too few shares")
+		}
+		sum.Add(sum, v)
+	}
+	return sum.Mod(sum, first.Modulus), nil
+}
+
+// ToShamir converts s into a Shamir share, with X = s.Party, of the same
+// secret and threshold, without any communication. Each additive value, for
+// the set T, is multiplied by the value at X of the polynomial of degree K-1
+// that is one at zero and zero at each member of T, so the shares of all the
+// parties lie on the sum of those polynomials.
+func (s *ReplicatedShare) ToShamir() (Share, error) {
+	if s.Modulus == nil || s.Party < 1 || s.Party > s.N {
+		return Share{}, errors.New("invalid replicated share")
+	}
+
+	x := big.NewInt(int64(s.Party))
+	y := new(big.Int)
+	for set, v := range s.Values {
+		if set&(1<<(s.Party-1)) != 0 {
+			return Share{}, errors.New("invalid replicated share")
+		}
+
+		// f(x) = product over j in T of (j - x)/j.
+		f := new(big.Int).Set(v)
+		for rest := set; rest != 0; rest &= rest - 1 {
+			j := big.NewInt(int64(bits.TrailingZeros64(rest) + 1))
+			f.Mul(f, new(big.Int).Sub(j, x))
+			f.Mul(f, new(big.Int).ModInverse(j, s.Modulus))
+			f.Mod(f, s.Modulus)
+		}
+		y.Add(y, f)
+	}
+	return Share{X: x, Y: y.Mod(y, s.Modulus), Modulus: s.Modulus}, nil
+}
+
+// replicatedSets returns each set of k-1 of n parties as a bit mask.
+func replicatedSets(k, n int) ([]uint64, error) {
+	if k < 1 || n < k || n > 64 {
+		return nil, errors.New("invalid split parameters")
+	}
+
+	var sets []uint64
+	var choose func(set uint64, next, size int) bool
+	choose = func(set uint64, next, size int) bool {
+		if size == k-1 {
+			sets = append(sets, set)
+			return len(sets) <= maxReplicatedSets
+		}
+		for i := next; i < n; i++ {
+			if !choose(set|1<<i, i+1, size+1) {
+				return false
+			}
+		}
+		return true
+	}
+	if !choose(0, 0, 0) {
+		return nil, errors.New("too many parties for replicated sharing")
+	}
+	return sets, nil
+}
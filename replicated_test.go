@@ -0,0 +1,53 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestReplicated(t *testing.T) {
+	secret := big.NewInt(42)
+	for _, test := range []struct{ k, n int }{{2, 3}, {3, 5}, {1, 2}, {4, 4}} {
+		shares, err := SplitReplicated(secret, MODP2048, test.k, test.n, nil)
+		if err != nil {
+			t.Fatalf("%d-of-%d: error while splitting: %s", test.k, test.n, err)
+		}
+
+		result, err := JoinReplicated(shares[test.n-test.k:])
+		if err != nil || result.Cmp(secret) != 0 {
+			t.Errorf("%d-of-%d: failed to join: %v", test.k, test.n, err)
+		}
+		if _, err := JoinReplicated(shares[test.n-test.k+1:]); test.k > 1 && err == nil {
+			t.Errorf("%d-of-%d: joined too few shares", test.k, test.n)
+		}
+
+		var shamir []Share
+		for _, s := range shares[:test.k] {
+			converted, err := s.ToShamir()
+			if err != nil {
+				t.Fatalf("%d-of-%d: failed to convert: %s", test.k, test.n, err)
+			}
+			shamir = append(shamir, converted)
+		}
+		if result, err := JoinShares(shamir); err != nil || result.Cmp(secret) != 0 {
+			t.Errorf("%d-of-%d: failed to join converted shares: %v", test.k, test.n, err)
+		}
+	}
+}
+
+func TestReplicatedCorruption(t *testing.T) {
+	shares, _ := SplitReplicated(big.NewInt(42), MODP2048, 2, 3, nil)
+	for set, v := range shares[0].Values {
+		shares[0].Values[set] = new(big.Int).Add(v, big.NewInt(1))
+		break
+	}
+	if _, err := JoinReplicated(shares); err == nil {
+		t.Errorf("corruption wasn't detected")
+	}
+}
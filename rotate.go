@@ -0,0 +1,66 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import "time"
+
+// A RotationRecord links a share set to the one it was rotated from. None of
+// it is secret, so it can be kept as an audit record.
+type RotationRecord struct {
+	// OldSetID and NewSetID are the set IDs of the old and new shares.
+	// OldSetID is all zeros if the old shares didn't record one.
+	OldSetID, NewSetID [16]byte
+	// OldEpoch and NewEpoch are the epochs of the old and new shares.
+	OldEpoch, NewEpoch uint64
+	// Time is when the rotation happened, to the second.
+	Time time.Time
+}
+
+// Rotate recovers the secret from old, as JoinShares does, and immediately
+// splits it into n new shares with Deal, configured by opts, which must
+// include at least WithThreshold and WithField. Unless opts include
+// WithMetadata, the new shares get a random set ID and the epoch after that
+// of the old shares, so that old and new shares can't be mixed. The
+// recovered secret is overwritten before Rotate returns.
+//
+// Go can't keep values out of swap or core dumps, so the secret may still be
+// written to disk while Rotate runs; where that matters, memory locking must
+// be applied to the whole process.
+func Rotate(old []Share, n int, opts ...SplitOption) (*ShareSet, *RotationRecord, error) {
+	secret, err := JoinShares(old)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer clear(secret.Bits())
+
+	var o SplitOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	r := &RotationRecord{Time: time.Now().Truncate(time.Second)}
+	if m := old[0].Metadata; m != nil {
+		r.OldSetID, r.OldEpoch = m.SetID, m.Epoch
+	}
+	if o.Metadata == nil {
+		m, err := newSetMetadata(o.Threshold, o.Rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.Epoch = r.OldEpoch + 1
+		opts = append(opts[:len(opts):len(opts)], WithMetadata(m))
+	}
+
+	set, err := Deal(secret, n, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if m := set.Shares[0].Metadata; m != nil {
+		r.NewSetID, r.NewEpoch = m.SetID, m.Epoch
+	}
+	return set, r, nil
+}
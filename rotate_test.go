@@ -0,0 +1,44 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRotate(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(77)
+	old, err := SplitShares(secret, modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set, r, err := Rotate(old[1:], 5, WithThreshold(3), WithField(modulus))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.OldSetID != old[0].Metadata.SetID || r.NewSetID == r.OldSetID || r.NewEpoch != r.OldEpoch+1 {
+		t.Errorf("unexpected rotation record %+v", r)
+	}
+
+	result, err := JoinShares(set.Shares[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("got %s, want %s", result, secret)
+	}
+	if _, err := JoinShares(append([]Share{old[0]}, set.Shares[:2]...)); err == nil {
+		t.Error("old and new shares were joined")
+	}
+
+	if _, _, err := Rotate(old[:1], 5, WithThreshold(3), WithField(modulus)); err == nil {
+		t.Error("rotated with too few old shares")
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+)
+
+// SplitRSAKey splits the PKCS #8 encoding of key into n shares, any k of which
+// can be combined by JoinRSAKey to recover it.
+func SplitRSAKey(key *rsa.PrivateKey, k, n int) ([]ChunkedShare, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(der)
+
+	return SplitChunked(der, MODP2048, k, n, nil)
+}
+
+// JoinRSAKey recovers an RSA private key from at least k shares that resulted
+// from SplitRSAKey. The key is validated, so too few or corrupt shares result
+// in an error rather than an unusable key.
+func JoinRSAKey(shares []ChunkedShare) (*rsa.PrivateKey, error) {
+	der, err := JoinChunked(shares)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(der)
+
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errors.New("recovered key is invalid: too few or corrupt shares")
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("recovered key is not an RSA key")
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+)
+
+func TestRSAKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := SplitRSAKey(key, 2, 3)
+	if err != nil {
+		t.Fatalf("failed to split key: %s", err)
+	}
+
+	result, err := JoinRSAKey(shares[1:])
+	if err != nil {
+		t.Fatalf("failed to join key: %s", err)
+	}
+	if !result.Equal(key) {
+		t.Errorf("recovered key doesn't match")
+	}
+
+	if _, err := JoinRSAKey(shares[:1]); err == nil {
+		t.Errorf("recovered a key from too few shares")
+	}
+
+	shares[0].Ys[0] = new(big.Int).Add(shares[0].Ys[0], big.NewInt(1))
+	if _, err := JoinRSAKey(shares[:2]); err == nil {
+		t.Errorf("recovered a key from a corrupt share")
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// A SafetyNumber is a short digest for people to compare by reading it out,
+// such as over the phone, in the manner of Signal's safety numbers. It's
+// 66 bits: enough that a mismatch is noticed, but not a cryptographic
+// commitment.
+type SafetyNumber [sha256.Size]byte
+
+// safetyNumberGroups is the number of five digit groups in a SafetyNumber,
+// each taken from five bytes of the digest.
+const safetyNumberGroups = 4
+
+// SafetyNumber returns a safety number for s, so that a custodian and the
+// dealer, say, can check that they have the same share. It covers all of
+// the share but its hint, signature and MAC.
+func (s *Share) SafetyNumber() (SafetyNumber, error) {
+	r, err := s.records()
+	if err != nil {
+		return SafetyNumber{}, err
+	}
+	return safetyNumber("shamirsplit share", r), nil
+}
+
+// SetSafetyNumber returns a safety number for the dealing that s is part of,
+// which is the same for every share of it. Custodians can compare them to
+// check that they hold shares of the same dealing before meeting to recover
+// the secret. It covers the parameters of the dealing, such as its set ID,
+// modulus, threshold and epoch, but nothing specific to s.
+func (s *Share) SetSafetyNumber() (SafetyNumber, error) {
+	r, err := s.records()
+	if err != nil {
+		return SafetyNumber{}, err
+	}
+	return safetyNumber("shamirsplit share set", r.without(tagX, tagY, tagParticipant, tagHyperplane)), nil
+}
+
+func safetyNumber(context string, r wireRecords) SafetyNumber {
+	h := sha256.New()
+	h.Write([]byte(context))
+	h.Write([]byte{0})
+	h.Write(r.marshal())
+	return SafetyNumber(h.Sum(nil))
+}
+
+// String returns n as groups of five decimal digits, such as
+// "05170 43718 99210 38561".
+func (n SafetyNumber) String() string {
+	groups := make([]string, safetyNumberGroups)
+	for i := range groups {
+		var b [8]byte
+		copy(b[3:], n[5*i:5*i+5])
+		g := strconv.FormatUint(binary.BigEndian.Uint64(b[:])%100000, 10)
+		groups[i] = strings.Repeat("0", 5-len(g)) + g
+	}
+	return strings.Join(groups, " ")
+}
+
+// Words returns n as three pairs of words from w, for those who find words
+// easier to compare than digits. Pairs are separated by " / ".
+func (n SafetyNumber) Words(w *Wordlist) (string, error) {
+	if w == nil || len(w.words) != MnemonicWords {
+		return "", errors.New("invalid wordlist")
+	}
+	// Six words of 11 bits are taken from the start of the digest.
+	word := func(i int) string {
+		bit := 11 * i
+		v := binary.BigEndian.Uint32(n[bit/8:]) >> (32 - 11 - bit%8)
+		return w.words[v&(MnemonicWords-1)]
+	}
+	pairs := make([]string, 3)
+	for i := range pairs {
+		pairs[i] = word(2*i) + w.Separator + word(2*i+1)
+	}
+	return strings.Join(pairs, " / "), nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSafetyNumber(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	set, err := Deal(big.NewInt(7), 3, WithThreshold(2), WithField(modulus), WithParticipants([]string{"a", "b", "c"}), WithMAC(), WithRand(rand.Reader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Deal(big.NewInt(7), 3, WithThreshold(2), WithField(modulus), WithRand(rand.Reader))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setNumber, err := set.Shares[0].SetSafetyNumber()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^\d{5} \d{5} \d{5} \d{5}$`).MatchString(setNumber.String()) {
+		t.Errorf("badly formatted safety number %q", setNumber)
+	}
+	for i := range set.Shares {
+		n, err := set.Shares[i].SetSafetyNumber()
+		if err != nil || n != setNumber {
+			t.Errorf("share %d has set safety number %s, want %s", i, n, setNumber)
+		}
+	}
+	if n, _ := other.Shares[0].SetSafetyNumber(); n == setNumber {
+		t.Error("different dealings have the same set safety number")
+	}
+
+	a, _ := set.Shares[0].SafetyNumber()
+	b, _ := set.Shares[1].SafetyNumber()
+	if a == b || a == setNumber {
+		t.Error("share safety numbers aren't distinct")
+	}
+	s := set.Shares[0]
+	s.Hint = "stored with lawyer"
+	if n, _ := s.SafetyNumber(); n != a {
+		t.Error("hint changed the share's safety number")
+	}
+
+	words, err := setNumber.Words(testWordlist(t, "test", "w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs := strings.Split(words, " / ")
+	if len(pairs) != 3 || len(strings.Fields(words)) != 8 {
+		t.Errorf("badly formatted words %q", words)
+	}
+	if _, err := setNumber.Words(nil); err == nil {
+		t.Error("nil wordlist was accepted")
+	}
+}
+
+func TestSafetyNumberWords(t *testing.T) {
+	var n SafetyNumber
+	// The first six 11-bit words are 1, 2, ..., 6.
+	bits := new(big.Int)
+	for i := 1; i <= 6; i++ {
+		bits.Lsh(bits, 11).Or(bits, big.NewInt(int64(i)))
+	}
+	bits.Lsh(bits, 256-66)
+	bits.FillBytes(n[:])
+	words, err := n.Words(testWordlist(t, "test", "w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "w1 w2 / w3 w4 / w5 w6"; words != want {
+		t.Errorf("got %q, want %q", words, want)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/agl/shamirsplit/internal/edwards25519"
+)
+
+// This file implements secret sharing over the scalars of edwards25519 (and
+// so of ristretto255) using the constant-time, fixed-width arithmetic of the
+// edwards25519 package rather than math/big, which is both faster and doesn't
+// leak the values of shares through timing.
+
+// Ed25519Scalars is the field of integers modulo Edwards25519Order. It
+// implements Field[[32]byte] where elements are canonical little-endian
+// encodings, as used by Ed25519 and ristretto255.
+type Ed25519Scalars struct{}
+
+// NewEd25519Scalars returns the field of edwards25519 scalars.
+func NewEd25519Scalars() *Ed25519Scalars {
+	return new(Ed25519Scalars)
+}
+
+// ed25519OrderMinus2 is l - 2 in little-endian order. Raising to this power
+// inverts a scalar.
+var ed25519OrderMinus2 = [32]byte{
+	0xeb, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+	0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0x10,
+}
+
+// scalar decodes a, which must be canonical.
+func scalar(a [32]byte) *edwards25519.Scalar {
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(a[:])
+	if err != nil {
+		panic("shamirsplit: non-canonical scalar")
+	}
+	return s
+}
+
+func scalarBytes(s *edwards25519.Scalar) (a [32]byte) {
+	copy(a[:], s.Bytes())
+	return
+}
+
+func (*Ed25519Scalars) Zero() (a [32]byte) { return }
+
+func (*Ed25519Scalars) One() (a [32]byte) {
+	a[0] = 1
+	return
+}
+
+func (*Ed25519Scalars) Add(a, b [32]byte) [32]byte {
+	return scalarBytes(edwards25519.NewScalar().Add(scalar(a), scalar(b)))
+}
+
+func (*Ed25519Scalars) Sub(a, b [32]byte) [32]byte {
+	return scalarBytes(edwards25519.NewScalar().Subtract(scalar(a), scalar(b)))
+}
+
+func (*Ed25519Scalars) Mul(a, b [32]byte) [32]byte {
+	return scalarBytes(edwards25519.NewScalar().Multiply(scalar(a), scalar(b)))
+}
+
+func (*Ed25519Scalars) Equal(a, b [32]byte) bool {
+	return scalar(a).Equal(scalar(b)) == 1
+}
+
+func (f *Ed25519Scalars) Inv(a [32]byte) ([32]byte, error) {
+	x := scalar(a)
+	if x.Equal(edwards25519.NewScalar()) == 1 {
+		return a, errors.New("element has no inverse")
+	}
+	// a^(l-2) = a^-1. The exponent is public, so the sequence of
+	// operations doesn't depend on a.
+	r := scalar(f.One())
+	for i := len(ed25519OrderMinus2) - 1; i >= 0; i-- {
+		for j := 7; j >= 0; j-- {
+			r.Multiply(r, r)
+			if ed25519OrderMinus2[i]>>j&1 == 1 {
+				r.Multiply(r, x)
+			}
+		}
+	}
+	return scalarBytes(r), nil
+}
+
+func (*Ed25519Scalars) Element(i uint64) (a [32]byte, err error) {
+	binary.LittleEndian.PutUint64(a[:], i)
+	return a, nil
+}
+
+func (*Ed25519Scalars) Random(rand io.Reader) (a [32]byte, err error) {
+	var b [64]byte
+	if _, err := io.ReadFull(rand, b[:]); err != nil {
+		return a, err
+	}
+	s, err := edwards25519.NewScalar().SetUniformBytes(b[:])
+	if err != nil {
+		return a, err
+	}
+	return scalarBytes(s), nil
+}
+
+func (*Ed25519Scalars) Encode(a [32]byte) []byte {
+	return append([]byte(nil), a[:]...)
+}
+
+func (*Ed25519Scalars) Decode(b []byte) (a [32]byte, err error) {
+	if len(b) != len(a) {
+		return a, errors.New("encoded element has the wrong length")
+	}
+	if _, err := edwards25519.NewScalar().SetCanonicalBytes(b); err != nil {
+		return a, errors.New("encoded element is out of range")
+	}
+	copy(a[:], b)
+	return a, nil
+}
+
+// SplitEd25519Scalar is like Split for a canonical edwards25519 scalar, using
+// constant-time arithmetic over Ed25519Scalars. The shares can be recombined
+// with JoinEd25519Scalar. If rand is nil, crypto/rand.Reader is used.
+func SplitEd25519Scalar(secret [32]byte, k, n int, rand io.Reader) ([][32]byte, error) {
+	f := NewEd25519Scalars()
+	if _, err := f.Decode(secret[:]); err != nil {
+		return nil, errors.New("secret is not a canonical scalar")
+	}
+	return SplitField[[32]byte](f, secret, k, n, rand)
+}
+
+// JoinEd25519Scalar recovers the secret from shares that resulted from
+// SplitEd25519Scalar.
+func JoinEd25519Scalar(shares [][32]byte, shareNumbers []int) ([32]byte, error) {
+	f := NewEd25519Scalars()
+	for _, s := range shares {
+		if _, err := f.Decode(s[:]); err != nil {
+			return [32]byte{}, errors.New("share is out of range")
+		}
+	}
+	return JoinField[[32]byte](f, shares, shareNumbers)
+}
@@ -0,0 +1,88 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"bytes"
+	"testing"
+	"testing/quick"
+)
+
+func TestEd25519ScalarsArithmetic(t *testing.T) {
+	f := NewEd25519Scalars()
+
+	reduce := func(b [64]byte) [32]byte {
+		s, err := f.Random(bytes.NewReader(b[:]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	inv := func(b [64]byte) bool {
+		a := reduce(b)
+		if f.Equal(a, f.Zero()) {
+			return true
+		}
+		i, err := f.Inv(a)
+		return err == nil && f.Equal(f.Mul(a, i), f.One())
+	}
+	if err := quick.Check(inv, nil); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := f.Inv(f.Zero()); err == nil {
+		t.Errorf("inverted zero")
+	}
+
+	minusOne := f.Sub(f.Zero(), f.One())
+	if !f.Equal(f.Add(minusOne, f.One()), f.Zero()) {
+		t.Errorf("addition doesn't wrap correctly")
+	}
+
+	l := ed25519OrderMinus2
+	l[0] += 2
+	if _, err := f.Decode(l[:]); err == nil {
+		t.Errorf("decoded the group order")
+	}
+	if a, err := f.Decode(minusOne[:]); err != nil || a != minusOne {
+		t.Errorf("failed to decode l - 1: %v", err)
+	}
+}
+
+func TestSplitEd25519Scalar(t *testing.T) {
+	f := NewEd25519Scalars()
+	secret, _ := f.Element(123456)
+	testField[[32]byte](t, f, secret)
+
+	shares, err := SplitEd25519Scalar(secret, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	if result, err := JoinEd25519Scalar(shares[2:], []int{2, 3, 4}); err != nil || result != secret {
+		t.Errorf("got %x, %v, want %x", result, err, secret)
+	}
+
+	var bad [32]byte
+	for i := range bad {
+		bad[i] = 0xff
+	}
+	if _, err := SplitEd25519Scalar(bad, 3, 5, nil); err == nil {
+		t.Errorf("split a non-canonical secret")
+	}
+	shares[2] = bad
+	if _, err := JoinEd25519Scalar(shares[2:], []int{2, 3, 4}); err == nil {
+		t.Errorf("joined a non-canonical share")
+	}
+}
+
+func BenchmarkSplitEd25519Scalar(b *testing.B) {
+	var secret [32]byte
+	secret[0] = 42
+	b.ReportAllocs()
+	for b.Loop() {
+		SplitEd25519Scalar(secret, 10, 1000, nil)
+	}
+}
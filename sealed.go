@@ -0,0 +1,113 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"math/big"
+	"unicode/utf8"
+)
+
+// A sealed share envelope uses the encoding of binary shares (see wire.go)
+// with its own magic and tags. It records the name of the sealer, so that
+// the envelope can be routed to the matching Unsealer, and the blob that the
+// sealer returned for the binary encoding of the share.
+const sealedMagic = "SHMS"
+
+const (
+	tagSealer     = 1
+	tagSealedBlob = 2
+)
+
+// A Sealer protects data such that only a corresponding Unsealer can recover
+// it, for example by sealing it to a TPM with a policy bound to PCR values.
+type Sealer interface {
+	// Name identifies the kind of sealing, for example "tpm2". It's
+	// recorded in the envelope.
+	Name() string
+	Seal(data []byte) (blob []byte, err error)
+}
+
+// An Unsealer recovers data protected by the Sealer with the same name.
+type Unsealer interface {
+	Name() string
+	Unseal(blob []byte) (data []byte, err error)
+}
+
+// SealShare returns an envelope containing s, sealed by sealer.
+func SealShare(s *Share, sealer Sealer) ([]byte, error) {
+	name := sealer.Name()
+	if len(name) == 0 || !utf8.ValidString(name) {
+		return nil, errors.New("invalid sealer name")
+	}
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	blob, err := sealer.Seal(data)
+	clear(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var r wireRecords
+	r.add(tagSealer, []byte(name))
+	r.add(tagSealedBlob, blob)
+	return r.marshalAs(sealedMagic), nil
+}
+
+// UnsealShare parses an envelope from SealShare and unseals the share within
+// using whichever of unsealers has the name recorded in the envelope.
+func UnsealShare(envelope []byte, unsealers ...Unsealer) (s Share, err error) {
+	var name string
+	var blob []byte
+	err = parseWireAs(sealedMagic, envelope, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagSealer:
+			name = string(value)
+		case tagSealedBlob:
+			blob = value
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	if len(name) == 0 || blob == nil {
+		return s, errors.New("truncated envelope")
+	}
+
+	for _, u := range unsealers {
+		if u.Name() != name {
+			continue
+		}
+		data, err := u.Unseal(blob)
+		if err != nil {
+			return s, err
+		}
+		err = s.UnmarshalBinary(data)
+		clear(data)
+		return s, err
+	}
+	return s, errors.New("no unsealer for " + name)
+}
+
+// JoinSealedShares unseals each of envelopes with UnsealShare and recovers the
+// secret from the shares with JoinShares.
+func JoinSealedShares(envelopes [][]byte, unsealers ...Unsealer) (*big.Int, error) {
+	shares := make([]Share, len(envelopes))
+	for i, envelope := range envelopes {
+		var err error
+		if shares[i], err = UnsealShare(envelope, unsealers...); err != nil {
+			return nil, err
+		}
+	}
+	return JoinShares(shares)
+}
@@ -0,0 +1,79 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// xorSealer is a Sealer and Unsealer that XORs data with a fixed byte and
+// prefixes it with a tag, standing in for a TPM.
+type xorSealer struct {
+	name string
+	key  byte
+}
+
+func (x *xorSealer) Name() string { return x.name }
+
+func (x *xorSealer) Seal(data []byte) ([]byte, error) {
+	blob := []byte{'S'}
+	for _, b := range data {
+		blob = append(blob, b^x.key)
+	}
+	return blob, nil
+}
+
+func (x *xorSealer) Unseal(blob []byte) ([]byte, error) {
+	if len(blob) == 0 || blob[0] != 'S' {
+		return nil, errors.New("not sealed")
+	}
+	data := bytes.Clone(blob[1:])
+	for i := range data {
+		data[i] ^= x.key
+	}
+	return data, nil
+}
+
+func TestSealedShares(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(99)
+	shares, err := SplitShares(secret, modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpm := &xorSealer{"tpm2", 0x5a}
+	card := &xorSealer{"card", 0xa5}
+	envelopes := make([][]byte, 2)
+	for i, sealer := range []Sealer{tpm, card} {
+		if envelopes[i], err = SealShare(&shares[i], sealer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := JoinSealedShares(envelopes, card, tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("got %s, want %s", result, secret)
+	}
+
+	if _, err := UnsealShare(envelopes[0], card); err == nil {
+		t.Error("envelope was unsealed without its unsealer")
+	}
+	if _, err := UnsealShare(envelopes[0][:len(envelopes[0])-1], tpm); err == nil {
+		t.Error("truncated envelope was unsealed")
+	}
+	extended := append(append([]byte{}, envelopes[0]...), 0x7f, 0x02, 0xaa, 0xbb)
+	if _, err := UnsealShare(extended, tpm); err == nil {
+		t.Error("envelope with unknown record was unsealed")
+	}
+}
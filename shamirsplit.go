@@ -2,56 +2,60 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package shamirsplit implements Shamir's cryptographic secret sharing
-// algorithm.
+//go:build !nobig
+
 package shamirsplit
 
 import (
+	"context"
 	"errors"
-	"math/big"
 	"io"
+	"math/big"
+	"strconv"
 )
 
 // Split takes a secret number and returns n shares where any k shares can be
 // combined to recover the original secret. However, possession of less than k
-// shares reveals nothing about the secret.
+// shares reveals nothing about the secret. The secret must be in
+// [0, modulus): zero is a valid secret, but negative or larger values aren't
+// reduced and result in an error. If rand is nil, crypto/rand.Reader is used.
+// A Splitter is faster for many splits with the same parameters.
 func Split(secret, modulus *big.Int, k, n int, rand io.Reader) (shares []*big.Int, err error) {
+	return SplitContext(context.Background(), secret, modulus, k, n, rand)
+}
+
+// SplitContext is like Split, but gives up, returning ctx.Err(), if ctx is
+// done before the shares have been computed. Reads from rand are not
+// interrupted, but no further reads are made once ctx is done.
+func SplitContext(ctx context.Context, secret, modulus *big.Int, k, n int, rand io.Reader) (shares []*big.Int, err error) {
 	if k < 1 || n < k {
 		return nil, errors.New("invalid split parameters")
 	}
-
-	if secret.Cmp(modulus) >= 0 {
-		return nil, errors.New("secret must be less than split modulus")
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
 	}
 
-	a := make([]*big.Int, k)
-	a[0] = secret
-	one := big.NewInt(1)
-	modulusMinus1 := new(big.Int)
-	modulusMinus1.Sub(modulus, one)
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
 
-	for i := 1; i < k; i++ {
-		a[i], err = randomNumber(rand, modulusMinus1)
-		if err != nil {
-			return
-		}
-		a[i].Add(a[i], one)
+	a, err := randomPolynomial(secret, modulus, k, contextReader{ctx, defaultRand(rand)})
+	if err != nil {
+		return nil, err
 	}
 
+	// The shares, and the scratch space for computing them, are allocated
+	// up front rather than for each share.
 	shares = make([]*big.Int, n)
-
-	for i := 1; i <= n; i++ {
-		bigI := big.NewInt(int64(i))
-		t := new(big.Int)
-
-		for j := 0; j < k; j++ {
-			e := new(big.Int).Exp(bigI, big.NewInt(int64(j)), nil)
-			e.Mul(e, a[j])
-			t.Add(t, e)
+	ys := make([]big.Int, n)
+	var e evaluator
+	x := new(big.Int)
+	for i := range shares {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-
-		t.Mod(t, modulus)
-		shares[i-1] = t
+		x.SetInt64(int64(i + 1))
+		shares[i] = e.evaluate(&ys[i], a, x, modulus)
 	}
 
 	return
@@ -61,47 +65,303 @@ func Split(secret, modulus *big.Int, k, n int, rand io.Reader) (shares []*big.In
 // secret. The shares can be presented in any order, however the (zero based)
 // index of each share must be known and provided in shareNumbers.
 func Join(shares []*big.Int, shareNumbers []int, modulus *big.Int) (*big.Int, error) {
+	return JoinContext(context.Background(), shares, shareNumbers, modulus)
+}
+
+// JoinContext is like Join, but gives up, returning ctx.Err(), if ctx is done
+// before the secret has been recovered.
+func JoinContext(ctx context.Context, shares []*big.Int, shareNumbers []int, modulus *big.Int) (*big.Int, error) {
 	if len(shares) != len(shareNumbers) {
 		return nil, errors.New("lengths of shares and shareNumbers must match")
 	}
 
-	secret := new(big.Int)
-	zero := new(big.Int)
-
-	for i := 0; i < len(shares); i++ {
-		if shareNumbers[i] < 0 {
+	xs := make([]*big.Int, len(shares))
+	for i, number := range shareNumbers {
+		if number < 0 {
 			return nil, errors.New("found negative share number")
 		}
+		xs[i] = big.NewInt(int64(number + 1))
+	}
 
-		c := big.NewInt(1)
-		for j := 0; j < len(shares); j++ {
-			if i == j {
-				continue
+	return interpolateContext(ctx, xs, shares, modulus)
+}
+
+// SplitAt is like Split, but rather than evaluating the polynomial at
+// 1, 2, ..., n, it returns its value at each of xs, which must be distinct
+// and in the range [1, modulus). The shares can be recombined with JoinAt.
+func SplitAt(secret, modulus *big.Int, k int, xs []*big.Int, rand io.Reader) (shares []*big.Int, err error) {
+	if k < 1 || len(xs) < k {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, len(xs)); err != nil {
+		return nil, err
+	}
+
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+
+	if err = checkXs(xs, modulus); err != nil {
+		return
+	}
+
+	a, err := randomPolynomial(secret, modulus, k, defaultRand(rand))
+	if err != nil {
+		return
+	}
+
+	shares = make([]*big.Int, len(xs))
+	ys := make([]big.Int, len(xs))
+	var e evaluator
+	for i, x := range xs {
+		shares[i] = e.evaluate(&ys[i], a, x, modulus)
+	}
+
+	return
+}
+
+// SplitRandomX is like SplitAt, but the x coordinates are chosen uniformly
+// at random from [1, modulus), so that a share reveals neither how many other
+// shares exist nor its position among them. The x coordinates are recorded in
+// the returned shares, along with the modulus, and the secret can be
+// recovered with JoinShares. As with SplitShares, the shares carry a random
+// set ID. The modulus should be large, since there must be at least n
+// possible x coordinates and collisions are retried.
+func SplitRandomX(secret, modulus *big.Int, k, n int, rand io.Reader) ([]Share, error) {
+	if n < 1 || modulus.Cmp(big.NewInt(int64(n))) <= 0 {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+
+	rand = defaultRand(rand)
+	modulusMinus1 := new(big.Int).Sub(modulus, big.NewInt(1))
+	xs := make([]*big.Int, 0, n)
+	seen := make(map[string]bool)
+	for len(xs) < n {
+		x, err := randomNumber(rand, modulusMinus1)
+		if err != nil {
+			return nil, err
+		}
+		x.Add(x, big.NewInt(1))
+
+		if seen[string(x.Bytes())] {
+			continue
+		}
+		seen[string(x.Bytes())] = true
+		xs = append(xs, x)
+	}
+
+	ys, err := SplitAt(secret, modulus, k, xs, rand)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newSetMetadata(k, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{X: xs[i], Y: ys[i], Modulus: modulus, Metadata: m}
+	}
+	return shares, nil
+}
+
+// JoinAt is like Join, but takes the x coordinate of each share, as passed to
+// SplitAt, rather than its share number.
+func JoinAt(shares, xs []*big.Int, modulus *big.Int) (*big.Int, error) {
+	if len(shares) != len(xs) {
+		return nil, errors.New("lengths of shares and xs must match")
+	}
+
+	if err := checkXs(xs, modulus); err != nil {
+		return nil, err
+	}
+
+	return interpolate(xs, shares, modulus)
+}
+
+// checkXs returns an error unless the xs are distinct and in [1, modulus).
+func checkXs(xs []*big.Int, modulus *big.Int) error {
+	if len(xs) > DefaultLimits.MaxShares {
+		return ErrLimitExceeded
+	}
+	for i, x := range xs {
+		if x.Sign() <= 0 || x.Cmp(modulus) >= 0 {
+			return errors.New("x coordinates must be in the range [1, modulus)")
+		}
+		for j := 0; j < i; j++ {
+			if xs[j].Cmp(x) == 0 {
+				return errors.New("x coordinates must be distinct")
 			}
-			bigJ := big.NewInt(int64(shareNumbers[j] + 1))
-			c.Mul(c, bigJ)
-			bigJ.Sub(bigJ, big.NewInt(int64(shareNumbers[i]+1)))
-			if bigJ.Cmp(zero) < 0 {
-				bigJ.Add(bigJ, modulus)
+		}
+	}
+	return nil
+}
+
+// interpolate returns the value at zero of the polynomial of minimal degree
+// that passes through the points (xs[i], ys[i]) modulo modulus. If the
+// difference of two of the xs isn't invertible, it returns a
+// *NonInvertibleError.
+func interpolate(xs, ys []*big.Int, modulus *big.Int) (*big.Int, error) {
+	return interpolateContext(context.Background(), xs, ys, modulus)
+}
+
+// A NonInvertibleError is returned when the difference between the x
+// coordinates of two shares has no inverse modulo the modulus, so the secret
+// can't be recovered. That happens if they're equal modulo the modulus or,
+// if the modulus isn't prime, modulo one of its factors.
+type NonInvertibleError struct {
+	// I and J are the indexes of the shares concerned.
+	I, J int
+}
+
+func (e *NonInvertibleError) Error() string {
+	return "difference of x coordinates of shares " + strconv.Itoa(e.I) + " and " + strconv.Itoa(e.J) + " isn't invertible"
+}
+
+// nonInvertiblePair returns a *NonInvertibleError for the first pair of xs
+// whose difference isn't invertible modulo modulus.
+func nonInvertiblePair(xs []*big.Int, modulus *big.Int) error {
+	d, g := new(big.Int), new(big.Int)
+	for j := range xs {
+		for i := 0; i < j; i++ {
+			d.Sub(xs[j], xs[i])
+			d.Mod(d, modulus)
+			if g.GCD(nil, nil, d, modulus).Cmp(big.NewInt(1)) != 0 {
+				return &NonInvertibleError{I: i, J: j}
 			}
+		}
+	}
+	return errors.New("Lagrange denominator isn't invertible")
+}
 
-			d := new(big.Int)
-			x := new(big.Int)
-			y := new(big.Int)
-			d.GCD(x, y, bigJ, modulus)
-			if x.Cmp(zero) < 0 {
-				x.Add(x, modulus)
+// interpolateContext is like interpolate, but returns ctx.Err() if ctx is
+// done before it finishes.
+func interpolateContext(ctx context.Context, xs, ys []*big.Int, modulus *big.Int) (*big.Int, error) {
+	// The sum of the Lagrange terms is accumulated as a fraction, num/den,
+	// so that only a single modular inversion is needed and the arithmetic
+	// can reuse a fixed set of values, whatever the number of shares. Mod
+	// and aliased Muls allocate, so products are formed in u and reduced
+	// with QuoRem into a reused quotient.
+	num, den := new(big.Int), big.NewInt(1)
+	termNum, termDen := new(big.Int), new(big.Int)
+	t, u, q := new(big.Int), new(big.Int), new(big.Int)
+	// The temporaries hold values derived from the secret.
+	defer func() {
+		for _, v := range []*big.Int{den, termNum, termDen, t, u, q} {
+			clear(v.Bits())
+		}
+	}()
+	reduce := func(z, x *big.Int) {
+		q.QuoRem(x, modulus, z)
+	}
+
+	for i := range xs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		termNum.SetInt64(1)
+		termDen.SetInt64(1)
+		negative := false
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			u.Mul(termNum, xs[j])
+			reduce(termNum, u)
+			// Keeping everything non-negative makes QuoRem's
+			// remainder the modular reduction, so the sign of
+			// the term is tracked separately.
+			if t.Sub(xs[j], xs[i]); t.Sign() < 0 {
+				t.Neg(t)
+				negative = !negative
 			}
-			c.Mul(c, x)
-			c.Mod(c, modulus)
+			u.Mul(termDen, t)
+			reduce(termDen, u)
+		}
+		// num/den += termNum*ys[i]/termDen
+		u.Mul(termNum, ys[i])
+		reduce(termNum, u)
+		if negative && termNum.Sign() != 0 {
+			termNum.Sub(modulus, termNum)
 		}
+		u.Mul(num, termDen)
+		t.Mul(termNum, den)
+		u.Add(u, t)
+		reduce(num, u)
+		u.Mul(den, termDen)
+		reduce(den, u)
+	}
+
+	if den.ModInverse(den, modulus) == nil {
+		return nil, nonInvertiblePair(xs, modulus)
+	}
+	u.Mul(num, den)
+	return num.Mod(u, modulus), nil
+}
 
-		c.Mul(c, shares[i])
-		secret.Add(secret, c)
+// checkSecret returns an error unless secret is in [0, modulus). A zero secret
+// is valid: the polynomial then has a zero constant term, but its other
+// coefficients are random as usual, so the shares reveal nothing.
+func checkSecret(secret, modulus *big.Int) error {
+	if secret.Sign() < 0 {
+		return errors.New("secret must not be negative")
 	}
+	if secret.Cmp(modulus) >= 0 {
+		return errors.New("secret must be less than split modulus")
+	}
+	return nil
+}
 
-	secret.Mod(secret, modulus)
-	return secret, nil
+// randomPolynomial returns the coefficients of a random polynomial of degree
+// k-1 with the given constant term. The remaining coefficients are non-zero.
+func randomPolynomial(secret, modulus *big.Int, k int, rand io.Reader) (a []*big.Int, err error) {
+	a = make([]*big.Int, k)
+	a[0] = secret
+	one := big.NewInt(1)
+	modulusMinus1 := new(big.Int)
+	modulusMinus1.Sub(modulus, one)
+
+	for i := 1; i < k; i++ {
+		a[i], err = randomNumber(rand, modulusMinus1)
+		if err != nil {
+			return
+		}
+		a[i].Add(a[i], one)
+	}
+
+	return
+}
+
+// evaluatePolynomial returns the value of the polynomial with coefficients a
+// at x.
+func evaluatePolynomial(a []*big.Int, x, modulus *big.Int) *big.Int {
+	var e evaluator
+	return e.evaluate(new(big.Int), a, x, modulus)
+}
+
+// An evaluator holds scratch space for evaluating polynomials, so that
+// evaluating at many points doesn't allocate for each one.
+type evaluator struct {
+	u, q big.Int
+}
+
+// evaluate sets z to the value of the polynomial with coefficients a at x,
+// using Horner's rule, and returns z.
+func (e *evaluator) evaluate(z *big.Int, a []*big.Int, x, modulus *big.Int) *big.Int {
+	z.SetInt64(0)
+	for j := len(a) - 1; j >= 0; j-- {
+		e.u.Mul(z, x)
+		e.u.Add(&e.u, a[j])
+		e.q.QuoRem(&e.u, modulus, z)
+	}
+	if z.Sign() < 0 {
+		z.Add(z, modulus)
+	}
+	return z
 }
 
 // randomNumber returns a uniform random value in [0, max).
@@ -2,10 +2,14 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build !nobig
+
 package shamirsplit
 
 import (
+	"context"
 	"crypto/rand"
+	"errors"
 	"math/big"
 	"testing"
 )
@@ -46,3 +50,192 @@ func TestSplitting(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitDefaultRand(t *testing.T) {
+	// A nil rand means crypto/rand.Reader.
+	secret := big.NewInt(42)
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := Split(secret, modulus, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	result, err := Join(shares[2:], []int{2, 3, 4}, modulus)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("Join returned %s, want %s", result, secret)
+	}
+}
+
+func TestSplitAt(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(42)
+
+	xs := []*big.Int{
+		big.NewInt(1000),
+		new(big.Int).Sub(modulus, big.NewInt(1)),
+		big.NewInt(7),
+		new(big.Int).Rsh(modulus, 1),
+	}
+
+	shares, err := SplitAt(secret, modulus, 3, xs, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	result, err := JoinAt(shares[1:], xs[1:], modulus)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("JoinAt returned %s, want %s", result, secret)
+	}
+
+	for _, bad := range [][]*big.Int{
+		{big.NewInt(1), big.NewInt(0), big.NewInt(2)},
+		{big.NewInt(1), big.NewInt(2), big.NewInt(1)},
+		{big.NewInt(1), big.NewInt(2), modulus},
+	} {
+		if _, err := SplitAt(secret, modulus, 2, bad, nil); err == nil {
+			t.Errorf("invalid x coordinates %v were accepted", bad)
+		}
+	}
+}
+
+func TestSplitRandomX(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(42)
+
+	shares, err := SplitRandomX(secret, modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	if shares[0].X.BitLen() < 64 {
+		t.Errorf("x coordinate %s doesn't look random", shares[0].X)
+	}
+
+	result, err := JoinAt([]*big.Int{shares[2].Y, shares[0].Y}, []*big.Int{shares[2].X, shares[0].X}, modulus)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("JoinAt returned %s, want %s", result, secret)
+	}
+
+	// All possible x coordinates of a tiny field must be found.
+	if _, err := SplitRandomX(big.NewInt(1), big.NewInt(5), 2, 4, nil); err != nil {
+		t.Errorf("failed to split over a small field: %s", err)
+	}
+	if _, err := SplitRandomX(big.NewInt(1), big.NewInt(5), 2, 5, nil); err == nil {
+		t.Errorf("more shares than x coordinates were accepted")
+	}
+}
+
+func TestSecretRange(t *testing.T) {
+	// A zero secret is shared like any other.
+	zero := new(big.Int)
+	shares, err := Split(zero, P256Order, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("failed to split zero: %s", err)
+	}
+	for i, s := range shares {
+		if s.Sign() == 0 {
+			t.Errorf("share %d of zero is zero", i)
+		}
+	}
+	if result, err := Join(shares[2:], []int{2, 3, 4}, P256Order); err != nil || result.Sign() != 0 {
+		t.Errorf("joining shares of zero gave %v, %v", result, err)
+	}
+
+	for _, secret := range []*big.Int{big.NewInt(-1), P256Order} {
+		if _, err := Split(secret, P256Order, 3, 5, nil); err == nil {
+			t.Errorf("split of %s succeeded", secret)
+		}
+	}
+
+	set, err := Deal(big.NewInt(-1), 3, WithThreshold(2), WithField(P256Order), WithReducedSecret())
+	if err != nil {
+		t.Fatalf("failed to deal a reduced secret: %s", err)
+	}
+	want := new(big.Int).Sub(P256Order, big.NewInt(1))
+	if result, err := JoinShares(set.Shares[:2]); err != nil || result.Cmp(want) != 0 {
+		t.Errorf("joining reduced secret gave %v, %v; want %s", result, err, want)
+	}
+}
+
+func TestContext(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(42)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	shares, err := SplitContext(ctx, secret, modulus, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	result, err := JoinContext(ctx, shares[:3], []int{0, 1, 2}, modulus)
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Fatalf("JoinContext gave %v, %v; want %s", result, err, secret)
+	}
+
+	cancel()
+	if _, err := SplitContext(ctx, secret, modulus, 3, 5, nil); err != context.Canceled {
+		t.Errorf("SplitContext with a cancelled context gave %v", err)
+	}
+	// With k = 1 nothing is read from rand, but evaluation is cancelled.
+	if _, err := SplitContext(ctx, secret, modulus, 1, 5, nil); err != context.Canceled {
+		t.Errorf("SplitContext with k = 1 and a cancelled context gave %v", err)
+	}
+	if _, err := JoinContext(ctx, shares[:3], []int{0, 1, 2}, modulus); err != context.Canceled {
+		t.Errorf("JoinContext with a cancelled context gave %v", err)
+	}
+}
+
+func BenchmarkJoin(b *testing.B) {
+	const k = 64
+
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, _ := Split(big.NewInt(42), modulus, k, k, nil)
+	shareNumbers := make([]int, k)
+	for i := range shareNumbers {
+		shareNumbers[i] = i
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		Join(shares, shareNumbers, modulus)
+	}
+}
+
+func BenchmarkSplit(b *testing.B) {
+	const k, n = 10, 1000
+
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(42)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		Split(secret, modulus, k, n, nil)
+	}
+}
+
+func TestNonInvertible(t *testing.T) {
+	// With a composite modulus, 15, x coordinates 1 and 4 differ by 3,
+	// which has no inverse.
+	modulus := big.NewInt(15)
+	shares := []*big.Int{big.NewInt(2), big.NewInt(7), big.NewInt(5)}
+	_, err := Join(shares, []int{1, 0, 3}, modulus)
+	var nerr *NonInvertibleError
+	if !errors.As(err, &nerr) || nerr.I != 1 || nerr.J != 2 {
+		t.Errorf("got %v, want a *NonInvertibleError for shares 1 and 2", err)
+	}
+
+	// Repeated share numbers are caught the same way.
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	_, err = Join(shares[:2], []int{4, 4}, p)
+	if !errors.As(err, &nerr) || nerr.I != 0 || nerr.J != 1 {
+		t.Errorf("got %v, want a *NonInvertibleError for shares 0 and 1", err)
+	}
+}
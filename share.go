@@ -0,0 +1,452 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+// A Share is a single point on the polynomial chosen when a secret is split.
+// The share with (zero based) share number i, as used by Join, has X = i+1.
+type Share struct {
+	X, Y *big.Int
+	// Modulus, if not nil, is the modulus of the field that the share was
+	// computed in.
+	Modulus *big.Int
+	// SecretLen, if not zero, is the length in bytes of the secret, which
+	// was shared as a big-endian integer.
+	SecretLen int
+	// Additive is true for additive shares, from SplitAdditive, whose Ys
+	// sum to the secret. X then just identifies the party.
+	Additive bool
+	// Hyperplane, if not nil, makes this a Blakley share, from
+	// SplitBlakley: the coefficients of the hyperplane that consists of
+	// the points p with Hyperplane·p = Y. X then just identifies the
+	// share.
+	Hyperplane []*big.Int
+	// Participant, if not empty, names who the share was dealt to, such
+	// as "alice@ops" or "safe-deposit-box-2". See LabelShares.
+	Participant string
+	// Mandatory, if not nil, is the x coordinate of the share that must
+	// take part in every recovery of the secret, dealt by WithMandatory.
+	// That share's Y is one half of a 2-of-2 split of the secret and the
+	// other shares are a split of the other half.
+	Mandatory *big.Int
+	// Metadata is optional information about the dealing.
+	Metadata *Metadata
+	// MACKey, if not nil, is the dealing's integrity key, set by
+	// AuthenticateShares, and the binary encoding of the share includes
+	// a MAC under it.
+	MACKey []byte
+	// Fingerprint, if not nil, is checked against the secret recovered by
+	// JoinShares.
+	Fingerprint *Fingerprint
+	// DealerKey and Signature, if not nil, are the public key of the dealer
+	// and their signature of the share, set by SignShares.
+	DealerKey ed25519.PublicKey
+	Signature []byte
+	// Hint, if not empty, is a note for whoever holds the share, such as
+	// "stored with lawyer". It isn't covered by the MAC or the signature,
+	// so it can be changed at any time, and it mustn't be trusted. See
+	// HintShares.
+	Hint string
+}
+
+// A ShareSet is a complete dealing: the parameters of the split and the
+// shares that resulted from it.
+type ShareSet struct {
+	Modulus   *big.Int
+	Threshold int
+	Shares    []Share
+	// Commitments is nil unless the set was dealt by SplitVerifiable.
+	Commitments *Commitments
+}
+
+// SplitShares is like Split, but returns self-describing shares that record
+// their x coordinate and the modulus, so that they can be recombined by
+// JoinShares without any other information. The shares also carry Metadata
+// with the threshold and a random set ID that identifies the dealing.
+func SplitShares(secret, modulus *big.Int, k, n int, rand io.Reader) ([]Share, error) {
+	ys, err := Split(secret, modulus, k, n, rand)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newSetMetadata(k, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, n)
+	for i, y := range ys {
+		shares[i] = Share{X: big.NewInt(int64(i + 1)), Y: y, Modulus: modulus, Metadata: m}
+	}
+	return shares, nil
+}
+
+// SetID returns the set ID of the dealing that s came from, or false if s
+// doesn't record one.
+func (s *Share) SetID() (id [16]byte, ok bool) {
+	if s.Metadata == nil || s.Metadata.SetID == [16]byte{} {
+		return id, false
+	}
+	return s.Metadata.SetID, true
+}
+
+// SetID returns the set ID shared by all the shares in s, or false if they
+// don't all record the same one.
+func (s *ShareSet) SetID() (id [16]byte, ok bool) {
+	for i := range s.Shares {
+		shareID, shareOK := s.Shares[i].SetID()
+		if !shareOK || i > 0 && shareID != id {
+			return [16]byte{}, false
+		}
+		id = shareID
+	}
+	return id, len(s.Shares) > 0
+}
+
+// JoinShares recovers the secret from at least k shares that record their
+// modulus, such as those from SplitShares or SplitRandomX, or from
+// SplitBlakley, for which it calls JoinBlakley. If the dealing has a
+// mandatory share (see WithMandatory), it must be among them. The shares can
+// be presented in any order. If the shares record the threshold, fewer than
+// that results in a *NotEnoughSharesError. If they carry a Fingerprint, the
+// recovered secret is checked against it.
+func JoinShares(shares []Share) (*big.Int, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	modulus := shares[0].Modulus
+	if modulus == nil {
+		return nil, errors.New("shares don't record their modulus")
+	}
+	if shares[0].Hyperplane != nil {
+		return JoinBlakley(shares)
+	}
+
+	xs := make([]*big.Int, len(shares))
+	ys := make([]*big.Int, len(shares))
+	for i, s := range shares {
+		if err := checkSameDealing(i, &shares[0], &s); err != nil {
+			return nil, err
+		}
+		if s.X == nil || s.Y == nil {
+			return nil, errors.New("share is missing coordinates")
+		}
+		if s.Additive {
+			return nil, errors.New("additive shares must be joined with JoinAdditive")
+		}
+		xs[i], ys[i] = s.X, s.Y
+	}
+
+	if err := checkXs(xs, modulus); err != nil {
+		return nil, err
+	}
+	if m := shares[0].Metadata; m != nil && len(shares) < m.Threshold {
+		return nil, &NotEnoughSharesError{Need: m.Threshold, Have: len(shares)}
+	}
+
+	var secret *big.Int
+	var err error
+	if x := shares[0].Mandatory; x != nil {
+		secret, err = joinMandatory(xs, ys, x, modulus)
+	} else {
+		secret, err = interpolate(xs, ys, modulus)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f := shares[0].Fingerprint; f != nil {
+		if err := f.Check(secret, modulus); err != nil {
+			return nil, err
+		}
+	}
+	return secret, nil
+}
+
+// ErrNotEnoughShares matches, with errors.Is, any *NotEnoughSharesError.
+var ErrNotEnoughShares = errors.New("not enough shares")
+
+// A NotEnoughSharesError is returned when fewer shares are given than the
+// threshold that they record, since interpolating them would give a wrong
+// secret.
+type NotEnoughSharesError struct {
+	Need, Have int
+}
+
+func (e *NotEnoughSharesError) Error() string {
+	return "not enough shares: need " + strconv.Itoa(e.Need) + " but have " + strconv.Itoa(e.Have)
+}
+
+func (e *NotEnoughSharesError) Is(target error) bool {
+	return target == ErrNotEnoughShares
+}
+
+// A MismatchError is returned when shares that are to be combined are from
+// different dealings, or disagree about its parameters.
+type MismatchError struct {
+	// Index is the index of the share that differs from the first.
+	Index int
+	// Field names what differs, such as "modulus" or "set ID".
+	Field string
+}
+
+func (e *MismatchError) Error() string {
+	return "share " + strconv.Itoa(e.Index) + " is from a different dealing: its " + e.Field + " differs"
+}
+
+// checkSameDealing returns a *MismatchError for index i unless s records the
+// same modulus and dealing as first.
+func checkSameDealing(i int, first, s *Share) error {
+	var field string
+	firstID, firstOK := first.SetID()
+	id, ok := s.SetID()
+	switch {
+	case s.Modulus == nil || first.Modulus == nil || s.Modulus.Cmp(first.Modulus) != 0:
+		field = "modulus"
+	case id != firstID || ok != firstOK:
+		field = "set ID"
+	case s.Metadata != nil && first.Metadata != nil && s.Metadata.Threshold != 0 &&
+		first.Metadata.Threshold != 0 && s.Metadata.Threshold != first.Metadata.Threshold:
+		field = "threshold"
+	case s.Metadata != nil && first.Metadata != nil && s.Metadata.Epoch != first.Metadata.Epoch:
+		field = "epoch"
+	case len(s.Hyperplane) != len(first.Hyperplane):
+		field = "scheme"
+	case (s.Mandatory == nil) != (first.Mandatory == nil) ||
+		s.Mandatory != nil && s.Mandatory.Cmp(first.Mandatory) != 0:
+		field = "mandatory share"
+	case !hmac.Equal(s.MACKey, first.MACKey):
+		field = "MAC key"
+	case (s.Fingerprint == nil) != (first.Fingerprint == nil) ||
+		s.Fingerprint != nil && *s.Fingerprint != *first.Fingerprint:
+		field = "fingerprint"
+	default:
+		return nil
+	}
+	return &MismatchError{Index: i, Field: field}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the binary share
+// format described in wire.go.
+func (s *Share) MarshalBinary() ([]byte, error) {
+	r, err := s.records()
+	if err != nil {
+		return nil, err
+	}
+	if s.Signature != nil {
+		r.add(tagSignature, s.Signature)
+	}
+	if err := addMAC(&r, s.MACKey); err != nil {
+		return nil, err
+	}
+	if len(s.Hint) > 0 {
+		if err := checkHint(s.Hint); err != nil {
+			return nil, err
+		}
+		r.add(tagHint, []byte(s.Hint))
+	}
+	return r.marshal(), nil
+}
+
+// records returns the records of the binary encoding of s, other than the
+// signature and the MAC, which cover them.
+func (s *Share) records() (r wireRecords, err error) {
+	if s.X == nil || s.Y == nil || s.X.Sign() < 0 || s.Y.Sign() < 0 {
+		return nil, errors.New("share has missing or negative coordinates")
+	}
+
+	r.addInt(tagX, s.X)
+	r.addInt(tagY, s.Y)
+	if s.Modulus != nil {
+		// Standard moduli are recorded by name to save space.
+		if name := modulusName(s.Modulus); len(name) > 0 {
+			r.add(tagModulusName, []byte(name))
+		} else {
+			r.addInt(tagModulus, s.Modulus)
+		}
+	}
+	if s.SecretLen < 0 {
+		return nil, errors.New("negative secret length")
+	}
+	if s.SecretLen > 0 {
+		r.addUint(tagSecretLen, uint64(s.SecretLen))
+	}
+	if s.Additive {
+		r.addUint(tagAdditive, 1)
+	}
+	if s.Hyperplane != nil {
+		if s.Modulus == nil {
+			return nil, errors.New("Blakley share doesn't record its modulus")
+		}
+		width := (s.Modulus.BitLen() + 7) / 8
+		coeffs := make([]byte, 0, width*len(s.Hyperplane))
+		for _, a := range s.Hyperplane {
+			if a == nil || a.Sign() < 0 || a.Cmp(s.Modulus) >= 0 {
+				return nil, errors.New("hyperplane coefficient out of range")
+			}
+			coeffs = append(coeffs, a.FillBytes(make([]byte, width))...)
+		}
+		r.add(tagHyperplane, coeffs)
+	}
+	if len(s.Participant) > 0 {
+		if !utf8.ValidString(s.Participant) {
+			return nil, errors.New("participant is not valid UTF-8")
+		}
+		r.add(tagParticipant, []byte(s.Participant))
+	}
+	if s.Mandatory != nil {
+		if s.Mandatory.Sign() <= 0 {
+			return nil, errors.New("invalid mandatory share")
+		}
+		r.addInt(tagMandatory, s.Mandatory)
+	}
+	if s.Metadata != nil {
+		if err := s.Metadata.addRecords(&r); err != nil {
+			return nil, err
+		}
+	}
+	if s.Fingerprint != nil {
+		r.add(tagFingerprint, s.Fingerprint.marshal())
+	}
+	if s.DealerKey != nil {
+		if len(s.DealerKey) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid dealer key")
+		}
+		r.add(tagDealerKey, s.DealerKey)
+	}
+	if len(s.MACKey) > 0 {
+		// The MAC key is covered by the signature, although the MAC
+		// itself can't be.
+		r.add(tagMACKey, s.MACKey)
+	}
+	return
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, using
+// DefaultParseLimits. If the share carries a MAC or signature that doesn't
+// verify, it returns ErrCorruptShare. A valid signature only shows that the
+// share is intact: use VerifyDealer to check who signed it.
+func (s *Share) UnmarshalBinary(data []byte) error {
+	share, err := ParseShare(data, nil)
+	if err != nil {
+		return err
+	}
+	*s = share
+	return nil
+}
+
+// ParseShare is like UnmarshalBinary, but with the given limits, or
+// DefaultParseLimits if nil. Invalid encodings result in a *ParseError.
+func ParseShare(data []byte, limits *ParseLimits) (share Share, err error) {
+	limits = limitsOrDefault(limits)
+	if err := limits.checkSize(data); err != nil {
+		return share, err
+	}
+
+	var records wireRecords
+	var hyperplane []byte
+	err = parseWire(data, func(tag uint64, value []byte) error {
+		records.add(tag, value)
+
+		switch tag {
+		case tagX, tagY, tagModulus, tagMandatory:
+			if err := limits.checkInt(value); err != nil {
+				return err
+			}
+		}
+
+		switch tag {
+		case tagX:
+			share.X = new(big.Int).SetBytes(value)
+		case tagY:
+			share.Y = new(big.Int).SetBytes(value)
+		case tagModulus:
+			share.Modulus = new(big.Int).SetBytes(value)
+		case tagModulusName:
+			if share.Modulus = NamedModulus(string(value)); share.Modulus == nil {
+				return errors.New("share uses an unknown modulus")
+			}
+		case tagSecretLen:
+			l, err := parseWireUint(value)
+			if err != nil || l > math.MaxInt32 {
+				return errors.New("invalid secret length")
+			}
+			share.SecretLen = int(l)
+		case tagAdditive:
+			if v, err := parseWireUint(value); err != nil || v != 1 {
+				return errors.New("invalid additive flag")
+			}
+			share.Additive = true
+		case tagHyperplane:
+			hyperplane = value
+		case tagParticipant:
+			if !utf8.Valid(value) {
+				return errors.New("participant is not valid UTF-8")
+			}
+			share.Participant = string(value)
+		case tagMandatory:
+			share.Mandatory = new(big.Int).SetBytes(value)
+		case tagMACKey:
+			share.MACKey = append([]byte(nil), value...)
+		case tagMAC:
+			// Checked once all records have been parsed.
+		case tagFingerprint:
+			f, err := parseFingerprint(value)
+			if err != nil {
+				return err
+			}
+			share.Fingerprint = f
+		case tagDealerKey:
+			if len(value) != ed25519.PublicKeySize {
+				return errors.New("invalid dealer key")
+			}
+			share.DealerKey = append(ed25519.PublicKey(nil), value...)
+		case tagSignature:
+			share.Signature = append([]byte(nil), value...)
+		case tagHint:
+			if err := checkHint(string(value)); err != nil {
+				return err
+			}
+			share.Hint = string(value)
+		default:
+			return parseMetadataRecord(&share.Metadata, tag, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return Share{}, err
+	}
+
+	if err := checkMAC(records); err != nil {
+		return Share{}, err
+	}
+	if err := checkSignature(records); err != nil {
+		return Share{}, err
+	}
+
+	if share.X == nil || share.Y == nil {
+		return Share{}, &ParseError{Offset: -1, Err: errors.New("share is missing coordinates")}
+	}
+	if m := share.Modulus; m != nil && (share.X.Cmp(m) >= 0 || share.Y.Cmp(m) >= 0) {
+		return Share{}, &ParseError{Offset: -1, Err: errors.New("share is out of range")}
+	}
+	if hyperplane != nil {
+		if share.Hyperplane, err = parseHyperplane(hyperplane, share.Modulus, limits); err != nil {
+			return Share{}, &ParseError{Offset: -1, Err: err}
+		}
+	}
+	return share, nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestShareBinary(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	s := Share{X: big.NewInt(7), Y: new(big.Int).Rsh(modulus, 3)}
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("error while marshaling: %s", err)
+	}
+
+	var s2 Share
+	if err := s2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("error while unmarshaling: %s", err)
+	}
+	if s2.X.Cmp(s.X) != 0 || s2.Y.Cmp(s.Y) != 0 {
+		t.Errorf("share didn't round trip")
+	}
+
+	for i := 0; i < len(data); i++ {
+		if err := s2.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("truncated share of length %d was accepted", i)
+		}
+	}
+	if err := s2.UnmarshalBinary(append(data, 0)); err == nil {
+		t.Errorf("share with trailing data was accepted")
+	}
+}
+
+func TestParseShareLimits(t *testing.T) {
+	// Not a standard modulus, so that it's encoded in full.
+	modulus := new(big.Int).Lsh(big.NewInt(1), 300)
+	s := Share{X: big.NewInt(7), Y: big.NewInt(9), Modulus: modulus}
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("error while marshaling: %s", err)
+	}
+
+	if _, err := ParseShare(data, nil); err != nil {
+		t.Errorf("share rejected with default limits: %s", err)
+	}
+
+	limits := DefaultParseLimits
+	limits.MaxModulusBits = modulus.BitLen() - 1
+	_, err = ParseShare(data, &limits)
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("share with a large modulus gave %v, want a *ParseError", err)
+	}
+	if data[pe.Offset] != tagModulus {
+		t.Errorf("error offset %d doesn't point at the modulus", pe.Offset)
+	}
+
+	limits = DefaultParseLimits
+	limits.MaxSize = len(data) - 1
+	if _, err := ParseShare(data, &limits); !errors.As(err, &pe) {
+		t.Errorf("oversized share gave %v, want a *ParseError", err)
+	}
+
+	// A record claiming to be far longer than the input.
+	huge := []byte("SHMR\x01\x01\xff\xff\xff\xff\x0f")
+	if _, err := ParseShare(huge, nil); !errors.As(err, &pe) || pe.Offset != 5 {
+		t.Errorf("truncated record gave %v, want a *ParseError at offset 5", err)
+	}
+}
+
+func TestShareBinaryKnownEncoding(t *testing.T) {
+	data := []byte("SHMR\x01\x01\x01\x05\x02\x02\x01\x00")
+
+	var s Share
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatalf("error while unmarshaling: %s", err)
+	}
+	if s.X.Int64() != 5 || s.Y.Int64() != 256 {
+		t.Errorf("got (%s, %s), want (5, 256)", s.X, s.Y)
+	}
+
+	out, _ := s.MarshalBinary()
+	if !bytes.Equal(out, data) {
+		t.Errorf("got %x, want %x", out, data)
+	}
+
+	// Records that this version doesn't understand must be rejected.
+	extended := append(append([]byte{}, data...), 0x7f, 0x02, 0xaa, 0xbb)
+	if err := s.UnmarshalBinary(extended); err == nil {
+		t.Errorf("share with unknown record was accepted")
+	}
+
+	bad := append([]byte{}, data...)
+	bad[4] = 2
+	if err := s.UnmarshalBinary(bad); err == nil {
+		t.Errorf("share with unknown version was accepted")
+	}
+
+	swapped := []byte("SHMR\x01\x02\x02\x01\x00\x01\x01\x05")
+	if err := s.UnmarshalBinary(swapped); err == nil {
+		t.Errorf("share with out of order records was accepted")
+	}
+}
+
+func TestShareGob(t *testing.T) {
+	s := Share{X: big.NewInt(3), Y: big.NewInt(12345)}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&s); err != nil {
+		t.Fatalf("error while encoding: %s", err)
+	}
+
+	var s2 Share
+	if err := gob.NewDecoder(&buf).Decode(&s2); err != nil {
+		t.Fatalf("error while decoding: %s", err)
+	}
+	if s2.X.Cmp(s.X) != 0 || s2.Y.Cmp(s.Y) != 0 {
+		t.Errorf("share didn't round trip")
+	}
+}
+
+func TestJoinSharesMismatch(t *testing.T) {
+	a, err := SplitShares(big.NewInt(42), MODP2048, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := SplitShares(big.NewInt(43), MODP2048, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = JoinShares([]Share{a[0], a[1], b[2]})
+	var me *MismatchError
+	if !errors.As(err, &me) || me.Index != 2 || me.Field != "set ID" {
+		t.Errorf("got %v, want a set ID mismatch for share 2", err)
+	}
+
+	c := a[1]
+	c.Modulus = P256Order
+	if _, err := JoinShares([]Share{a[0], c}); !errors.As(err, &me) || me.Index != 1 || me.Field != "modulus" {
+		t.Errorf("got %v, want a modulus mismatch for share 1", err)
+	}
+
+	m := *a[1].Metadata
+	m.Epoch = 1
+	c = a[1]
+	c.Metadata = &m
+	if _, err := JoinShares([]Share{a[0], c}); !errors.As(err, &me) || me.Field != "epoch" {
+		t.Errorf("got %v, want an epoch mismatch", err)
+	}
+}
+
+func TestJoinSharesNotEnough(t *testing.T) {
+	shares, err := SplitShares(big.NewInt(42), MODP2048, 3, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = JoinShares(shares[3:])
+	var ne *NotEnoughSharesError
+	if !errors.As(err, &ne) || ne.Need != 3 || ne.Have != 2 || !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("got %v, want need 3, have 2", err)
+	}
+}
+
+func TestShareGobInterface(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitShares(big.NewInt(42), modulus, 2, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares[0].Metadata.Label = "gob"
+	if err := AuthenticateShares(shares, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	type record struct {
+		Values []any
+		Set    ShareSet
+	}
+	in := record{
+		Values: []any{shares[0], ChunkedShare{X: big.NewInt(1), Ys: []*big.Int{big.NewInt(2)}, Modulus: modulus, SecretLen: 1}},
+		Set:    ShareSet{Modulus: modulus, Threshold: 2, Shares: shares},
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&in); err != nil {
+		t.Fatalf("error while encoding: %s", err)
+	}
+	var out record
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatalf("error while decoding: %s", err)
+	}
+
+	s, ok := out.Values[0].(Share)
+	if !ok || s.Y.Cmp(shares[0].Y) != 0 || s.Metadata == nil || s.Metadata.Label != "gob" || s.MACKey == nil {
+		t.Errorf("share in an interface didn't round trip")
+	}
+	if c, ok := out.Values[1].(ChunkedShare); !ok || len(c.Ys) != 1 {
+		t.Errorf("chunked share in an interface didn't round trip")
+	}
+	secret, err := JoinShares(out.Set.Shares)
+	if err != nil || secret.Int64() != 42 {
+		t.Errorf("share set didn't round trip: %v, %v", secret, err)
+	}
+}
+
+func TestJoinShares(t *testing.T) {
+	secret := big.NewInt(42)
+	shares, err := SplitShares(secret, MODP2048, 3, 5, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	var parsed []Share
+	for _, i := range []int{4, 0, 2} {
+		data, _ := shares[i].MarshalBinary()
+		var s Share
+		if err := s.UnmarshalBinary(data); err != nil {
+			t.Fatalf("error while unmarshaling: %s", err)
+		}
+		parsed = append(parsed, s)
+	}
+
+	result, err := JoinShares(parsed)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("JoinShares returned %s, want %s", result, secret)
+	}
+
+	if _, err := JoinShares([]Share{shares[0], shares[0]}); err == nil {
+		t.Errorf("duplicate shares were accepted")
+	}
+
+	random, _ := SplitRandomX(secret, MODP2048, 2, 2, nil)
+	result, err = JoinShares(random)
+	if err != nil || result.Cmp(secret) != 0 {
+		t.Errorf("failed to join shares with random x coordinates: %v", err)
+	}
+}
@@ -0,0 +1,492 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package sharecard
+
+import "errors"
+
+// This file implements a QR code encoder, following ISO/IEC 18004, for the
+// one case that cards need: text in byte mode at error correction level M,
+// in the smallest version that holds it.
+
+// qrBlocks gives, for each version at level M, the number of error
+// correction codewords per block and the number and data length of the
+// blocks in each of the two groups.
+var qrBlocks = [41]struct{ ec, blocks1, data1, blocks2, data2 int }{
+	1:  {10, 1, 16, 0, 0},
+	2:  {16, 1, 28, 0, 0},
+	3:  {26, 1, 44, 0, 0},
+	4:  {18, 2, 32, 0, 0},
+	5:  {24, 2, 43, 0, 0},
+	6:  {16, 4, 27, 0, 0},
+	7:  {18, 4, 31, 0, 0},
+	8:  {22, 2, 38, 2, 39},
+	9:  {22, 3, 36, 2, 37},
+	10: {26, 4, 43, 1, 44},
+	11: {30, 1, 50, 4, 51},
+	12: {22, 6, 36, 2, 37},
+	13: {22, 8, 37, 1, 38},
+	14: {24, 4, 40, 5, 41},
+	15: {24, 5, 41, 5, 42},
+	16: {28, 7, 45, 3, 46},
+	17: {28, 10, 46, 1, 47},
+	18: {26, 9, 43, 4, 44},
+	19: {26, 3, 44, 11, 45},
+	20: {26, 3, 41, 13, 42},
+	21: {26, 17, 42, 0, 0},
+	22: {28, 17, 46, 0, 0},
+	23: {28, 4, 47, 14, 48},
+	24: {28, 6, 45, 14, 46},
+	25: {28, 8, 47, 13, 48},
+	26: {28, 19, 46, 4, 47},
+	27: {28, 22, 45, 3, 46},
+	28: {28, 3, 45, 23, 46},
+	29: {28, 21, 45, 7, 46},
+	30: {28, 19, 47, 10, 48},
+	31: {28, 2, 46, 29, 47},
+	32: {28, 10, 46, 23, 47},
+	33: {28, 14, 46, 21, 47},
+	34: {28, 14, 46, 23, 47},
+	35: {28, 12, 47, 26, 48},
+	36: {28, 6, 47, 34, 48},
+	37: {28, 29, 46, 14, 47},
+	38: {28, 13, 46, 32, 47},
+	39: {28, 40, 47, 7, 48},
+	40: {28, 18, 47, 31, 48},
+}
+
+// qrDataCodewords returns the number of data codewords in version v.
+func qrDataCodewords(v int) int {
+	b := qrBlocks[v]
+	return b.blocks1*b.data1 + b.blocks2*b.data2
+}
+
+// qrRawModules returns the number of modules of version v that hold
+// codewords, after the function patterns, including any remainder bits.
+func qrRawModules(v int) int {
+	n := (16*v+128)*v + 64
+	if v >= 2 {
+		align := v/7 + 2
+		n -= (25*align-10)*align - 55
+		if v >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+// qrAlignment returns the row and column coordinates of the alignment
+// patterns of version v.
+func qrAlignment(v int) []int {
+	if v == 1 {
+		return nil
+	}
+	n := v/7 + 2
+	step := (v*4 + n*2 + 1) / (n*2 - 2) * 2
+	if v == 32 {
+		step = 26
+	}
+	pos := make([]int, n)
+	pos[0] = 6
+	for i, p := n-1, 4*v+10; i > 0; i, p = i-1, p-step {
+		pos[i] = p
+	}
+	return pos
+}
+
+// qrMul multiplies in GF(2^8) with the reducing polynomial 0x11d.
+func qrMul(a, b byte) byte {
+	var p byte
+	for ; b != 0; b >>= 1 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1d
+		}
+	}
+	return p
+}
+
+// qrECC returns the n Reed–Solomon error correction codewords of data.
+func qrECC(data []byte, n int) []byte {
+	// The generator is the product of (x - 2^i) for i < n, with its
+	// leading coefficient of one implicit.
+	gen := make([]byte, n)
+	gen[n-1] = 1
+	root := byte(1)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			gen[j] = qrMul(gen[j], root)
+			if j+1 < n {
+				gen[j] ^= gen[j+1]
+			}
+		}
+		root = qrMul(root, 2)
+	}
+
+	ecc := make([]byte, n)
+	for _, b := range data {
+		factor := b ^ ecc[0]
+		copy(ecc, ecc[1:])
+		ecc[n-1] = 0
+		for j := range ecc {
+			ecc[j] ^= qrMul(gen[j], factor)
+		}
+	}
+	return ecc
+}
+
+// qrCodewords returns the data and error correction codewords of text in
+// version v, interleaved.
+func qrCodewords(text []byte, v int) []byte {
+	countBits := 8
+	if v >= 10 {
+		countBits = 16
+	}
+	capacity := qrDataCodewords(v)
+
+	var data []byte
+	var acc uint64
+	bits := 0
+	put := func(value uint64, n int) {
+		acc = acc<<n | value
+		bits += n
+		for bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	put(4, 4)
+	put(uint64(len(text)), countBits)
+	for _, b := range text {
+		put(uint64(b), 8)
+	}
+	// The terminator is up to four zero bits, then the data is padded to
+	// a whole codeword and filled with alternating pad codewords.
+	put(0, min(4, 8*capacity-8*len(data)-bits))
+	if bits > 0 {
+		put(0, 8-bits)
+	}
+	for pad := byte(0xec); len(data) < capacity; pad ^= 0xec ^ 0x11 {
+		data = append(data, pad)
+	}
+
+	b := qrBlocks[v]
+	var blocks, eccs [][]byte
+	for i := 0; i < b.blocks1+b.blocks2; i++ {
+		n := b.data1
+		if i >= b.blocks1 {
+			n = b.data2
+		}
+		blocks = append(blocks, data[:n])
+		eccs = append(eccs, qrECC(data[:n], b.ec))
+		data = data[n:]
+	}
+
+	var out []byte
+	for i := 0; i < b.data2 || i < b.data1; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < b.ec; i++ {
+		for _, ecc := range eccs {
+			out = append(out, ecc[i])
+		}
+	}
+	return out
+}
+
+// A qrMatrix is a QR code under construction.
+type qrMatrix struct {
+	size     int
+	dark     [][]bool
+	function [][]bool
+}
+
+func (m *qrMatrix) set(row, col int, dark bool) {
+	m.dark[row][col] = dark
+	m.function[row][col] = true
+}
+
+// encodeQR returns the modules of a QR code for text, as rows of dark
+// (true) and light modules, without the quiet zone.
+func encodeQR(text string) ([][]bool, error) {
+	v := 1
+	for ; v <= 40; v++ {
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if len(text) < 1<<countBits && 4+countBits+8*len(text) <= 8*qrDataCodewords(v) {
+			break
+		}
+	}
+	if v > 40 {
+		return nil, errors.New("text is too long for a QR code")
+	}
+
+	m := &qrMatrix{size: 4*v + 17}
+	m.dark = make([][]bool, m.size)
+	m.function = make([][]bool, m.size)
+	for i := range m.dark {
+		m.dark[i] = make([]bool, m.size)
+		m.function[i] = make([]bool, m.size)
+	}
+	m.drawFunctionPatterns(v)
+	m.drawCodewords(qrCodewords([]byte(text), v))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		m.applyMask(mask)
+		m.drawFormat(mask)
+		if p := m.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		m.applyMask(mask)
+	}
+	m.applyMask(best)
+	m.drawFormat(best)
+	return m.dark, nil
+}
+
+func (m *qrMatrix) drawFunctionPatterns(v int) {
+	for i := 0; i < m.size; i++ {
+		m.set(6, i, i%2 == 0)
+		m.set(i, 6, i%2 == 0)
+	}
+
+	// The finder patterns, with their separators.
+	for _, corner := range [][2]int{{3, 3}, {3, m.size - 4}, {m.size - 4, 3}} {
+		for dr := -4; dr <= 4; dr++ {
+			for dc := -4; dc <= 4; dc++ {
+				r, c := corner[0]+dr, corner[1]+dc
+				if r < 0 || r >= m.size || c < 0 || c >= m.size {
+					continue
+				}
+				d := max(abs(dr), abs(dc))
+				m.set(r, c, d != 2 && d != 4)
+			}
+		}
+	}
+
+	pos := qrAlignment(v)
+	for i, r := range pos {
+		for j, c := range pos {
+			// Alignment patterns don't overlap the finder patterns.
+			if (i == 0 && j == 0) || (i == 0 && j == len(pos)-1) || (i == len(pos)-1 && j == 0) {
+				continue
+			}
+			for dr := -2; dr <= 2; dr++ {
+				for dc := -2; dc <= 2; dc++ {
+					m.set(r+dr, c+dc, max(abs(dr), abs(dc)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format information, which depends on the mask, and
+	// draw the version information.
+	m.drawFormat(0)
+	if v >= 7 {
+		bits := v << 12
+		rem := v
+		for i := 0; i < 12; i++ {
+			rem = rem<<1 ^ (rem>>11)*0x1f25
+		}
+		bits |= rem
+		for i := 0; i < 18; i++ {
+			dark := bits>>i&1 != 0
+			a, b := m.size-11+i%3, i/3
+			m.set(b, a, dark)
+			m.set(a, b, dark)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information for level M and
+// the given mask, and the dark module.
+func (m *qrMatrix) drawFormat(mask int) {
+	// Level M is encoded as zero.
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		m.set(i, 8, bit(i))
+	}
+	m.set(7, 8, bit(6))
+	m.set(8, 8, bit(7))
+	m.set(8, 7, bit(8))
+	for i := 9; i < 15; i++ {
+		m.set(8, 14-i, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.set(8, m.size-1-i, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.set(m.size-15+i, 8, bit(i))
+	}
+	m.set(m.size-8, 8, true)
+}
+
+// drawCodewords fills the modules that aren't part of a function pattern
+// with data, in pairs of columns from the right, alternately upwards and
+// downwards. The remainder bits are left light.
+func (m *qrMatrix) drawCodewords(data []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < m.size; vert++ {
+			row := vert
+			if upward {
+				row = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				col := right - j
+				if m.function[row][col] || i >= 8*len(data) {
+					continue
+				}
+				m.dark[row][col] = data[i>>3]>>(7-i&7)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by mask. Applying it twice
+// undoes it.
+func (m *qrMatrix) applyMask(mask int) {
+	for r := 0; r < m.size; r++ {
+		for c := 0; c < m.size; c++ {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (r+c)%2 == 0
+			case 1:
+				flip = r%2 == 0
+			case 2:
+				flip = c%3 == 0
+			case 3:
+				flip = (r+c)%3 == 0
+			case 4:
+				flip = (r/2+c/3)%2 == 0
+			case 5:
+				flip = r*c%2+r*c%3 == 0
+			case 6:
+				flip = (r*c%2+r*c%3)%2 == 0
+			case 7:
+				flip = ((r+c)%2+r*c%3)%2 == 0
+			}
+			if flip && !m.function[r][c] {
+				m.dark[r][c] = !m.dark[r][c]
+			}
+		}
+	}
+}
+
+// penalty scores the matrix by the rules of the standard, so that the
+// mask that makes it easiest to read can be chosen.
+func (m *qrMatrix) penalty() int {
+	at := func(r, c int, transpose bool) bool {
+		if transpose {
+			return m.dark[c][r]
+		}
+		return m.dark[r][c]
+	}
+
+	p := 0
+	dark := 0
+	for _, transpose := range []bool{false, true} {
+		for r := 0; r < m.size; r++ {
+			run := 0
+			for c := 0; c < m.size; c++ {
+				if c > 0 && at(r, c, transpose) == at(r, c-1, transpose) {
+					run++
+				} else {
+					run = 1
+				}
+				if run == 5 {
+					p += 3
+				} else if run > 5 {
+					p++
+				}
+
+				// A pattern like a finder's, dark-light-dark-dark-
+				// dark-light-dark, with four light modules beside it.
+				if c+7 <= m.size {
+					finder := true
+					for k, want := range [7]bool{true, false, true, true, true, false, true} {
+						if at(r, c+k, transpose) != want {
+							finder = false
+							break
+						}
+					}
+					if finder && (m.lightRun(r, c-4, c, transpose) || m.lightRun(r, c+7, c+11, transpose)) {
+						p += 40
+					}
+				}
+			}
+		}
+	}
+
+	for r := 0; r < m.size; r++ {
+		for c := 0; c < m.size; c++ {
+			if m.dark[r][c] {
+				dark++
+			}
+			if r+1 < m.size && c+1 < m.size {
+				d := m.dark[r][c]
+				if m.dark[r][c+1] == d && m.dark[r+1][c] == d && m.dark[r+1][c+1] == d {
+					p += 3
+				}
+			}
+		}
+	}
+
+	total := m.size * m.size
+	deviation := abs(dark*20-total*10) / total
+	return p + deviation*10
+}
+
+// lightRun returns whether the modules from start to end, exclusive, of the
+// given row (or, if transpose is true, column) are light. Modules beyond the
+// edge count as light.
+func (m *qrMatrix) lightRun(r, start, end int, transpose bool) bool {
+	for c := start; c < end; c++ {
+		if c < 0 || c >= m.size {
+			continue
+		}
+		d := m.dark[r][c]
+		if transpose {
+			d = m.dark[c][r]
+		}
+		if d {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
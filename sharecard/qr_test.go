@@ -0,0 +1,68 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package sharecard
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestQRBlocks checks that the codewords of each version exactly fill the
+// modules that hold them, up to the remainder bits.
+func TestQRBlocks(t *testing.T) {
+	for v := 1; v <= 40; v++ {
+		b := qrBlocks[v]
+		if b.blocks2 > 0 && b.data2 != b.data1+1 {
+			t.Errorf("version %d: blocks differ by %d codewords", v, b.data2-b.data1)
+		}
+		total := qrDataCodewords(v) + (b.blocks1+b.blocks2)*b.ec
+		if total != qrRawModules(v)/8 {
+			t.Errorf("version %d: %d codewords, want %d", v, total, qrRawModules(v)/8)
+		}
+	}
+}
+
+// TestQRECC checks the error correction of the "01234567" example in ISO/IEC
+// 18004, version 1-M.
+func TestQRECC(t *testing.T) {
+	data := []byte{0x10, 0x20, 0x0c, 0x56, 0x61, 0x80, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11}
+	want := []byte{0xa5, 0x24, 0xd4, 0xc1, 0xed, 0x36, 0xc7, 0x87, 0x2c, 0x55}
+	if got := qrECC(data, 10); string(got) != string(want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestEncodeQR(t *testing.T) {
+	tests := []struct{ length, size int }{
+		{0, 21},
+		{14, 21},
+		{15, 25},
+		{213, 57},
+		{214, 61},
+		{2331, 177},
+	}
+	for _, test := range tests {
+		m, err := encodeQR(strings.Repeat("a", test.length))
+		if err != nil {
+			t.Errorf("length %d: %s", test.length, err)
+			continue
+		}
+		if len(m) != test.size {
+			t.Errorf("length %d: got size %d, want %d", test.length, len(m), test.size)
+		}
+		// The finder patterns have a dark center and a light ring.
+		for _, corner := range [][2]int{{3, 3}, {3, test.size - 4}, {test.size - 4, 3}} {
+			r, c := corner[0], corner[1]
+			if !m[r][c] || m[r][c+2] || !m[r][c+3] {
+				t.Errorf("length %d: bad finder pattern at %d, %d", test.length, r, c)
+			}
+		}
+	}
+	if _, err := encodeQR(strings.Repeat("a", 2332)); err == nil {
+		t.Error("too long text was accepted")
+	}
+}
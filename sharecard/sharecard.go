@@ -0,0 +1,265 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+// Package sharecard renders shares as printable PDF cards, so that each
+// custodian can be handed a complete physical artifact: the share itself, as
+// a URI, a QR code and a mnemonic, what it belongs to, how to use it and a
+// checksum for checking that it has been transcribed correctly.
+//
+// The PDFs are deliberately minimal: they use only the standard Helvetica and
+// Courier fonts, without embedding, and draw QR codes as filled squares, so
+// that the output can be inspected by hand before it's printed. The standard
+// fonts cover Latin-1, so text in other scripts can't be printed.
+package sharecard
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agl/shamirsplit"
+	"github.com/agl/shamirsplit/internal/norm"
+)
+
+// Options configure Render. The zero value is usable.
+type Options struct {
+	// Title is printed at the top of the card. If empty, "Secret share" is
+	// used.
+	Title string
+	// Total is the number of shares dealt, or zero if it isn't to be
+	// printed.
+	Total int
+	// QR, if not nil, returns the modules of a QR code encoding text, as
+	// rows of dark (true) and light modules. It replaces the built-in
+	// encoder, which omits the QR code of a share URI that is too long for
+	// one.
+	QR func(text string) ([][]bool, error)
+	// Wordlist is used to print the share as a mnemonic. If nil, the
+	// English BIP-39 list is used. Its words must be in Latin-1.
+	Wordlist *shamirsplit.Wordlist
+}
+
+// Checksum returns a short checksum of the binary encoding of s, which is
+// printed on its card. Recomputing it after the share has been typed in
+// shows whether it was transcribed correctly.
+func Checksum(s *shamirsplit.Share) (string, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(data)
+	c := hex.EncodeToString(h[:4])
+	return c[:4] + "-" + c[4:], nil
+}
+
+const (
+	pageWidth  = 420
+	margin     = 24
+	lineHeight = 11
+	uriColumns = 64
+	qrSize     = 160
+	// wordsPerLine is the number of mnemonic words on each line.
+	wordsPerLine = 5
+)
+
+// Render returns a single page PDF card for s.
+func Render(s *shamirsplit.Share, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	uri, err := shamirsplit.EncodeShareURI(s)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := Checksum(s)
+	if err != nil {
+		return nil, err
+	}
+
+	title := opts.Title
+	if len(title) == 0 {
+		title = "Secret share"
+	}
+
+	var lines []string
+	lines = append(lines, "Share number: "+s.X.String())
+	if m := s.Metadata; m != nil {
+		if len(m.Label) > 0 {
+			lines = append(lines, "Label: "+m.Label)
+		}
+		if m.SetID != [16]byte{} {
+			lines = append(lines, "Set ID: "+hex.EncodeToString(m.SetID[:]))
+		}
+		if !m.Created.IsZero() {
+			lines = append(lines, "Created: "+m.Created.UTC().Format(time.RFC3339))
+		}
+		if !m.NotAfter.IsZero() {
+			lines = append(lines, "Expires: "+m.NotAfter.UTC().Format(time.RFC3339))
+		}
+		if m.Threshold > 0 {
+			of := ""
+			if opts.Total > 0 {
+				of = " of the " + strconv.Itoa(opts.Total)
+			}
+			lines = append(lines, "Any "+strconv.Itoa(m.Threshold)+of+" shares with this set ID recover the secret.")
+		}
+	}
+	lines = append(lines,
+		"Fewer shares reveal nothing. Keep this card safe and private.",
+		"Checksum: "+checksum)
+
+	var uriLines []string
+	for len(uri) > uriColumns {
+		uriLines = append(uriLines, uri[:uriColumns])
+		uri = uri[uriColumns:]
+	}
+	uriLines = append(uriLines, uri)
+
+	wordlist := opts.Wordlist
+	if wordlist == nil {
+		wordlist = shamirsplit.BIP39Wordlist("english")
+	}
+	phrase, err := shamirsplit.EncodeMnemonic(s, wordlist)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(phrase)
+	var wordLines []string
+	for i := 0; i < len(words); i += wordsPerLine {
+		var line strings.Builder
+		for j := i; j < len(words) && j < i+wordsPerLine; j++ {
+			word := norm.NFC.String(words[j])
+			if _, ok := latin1(word); !ok {
+				return nil, errors.New("mnemonic words can't be printed with the standard fonts")
+			}
+			fmt.Fprintf(&line, "%2d. %-10s", j+1, word)
+		}
+		wordLines = append(wordLines, strings.TrimRight(line.String(), " "))
+	}
+
+	var qr [][]bool
+	if opts.QR != nil {
+		if qr, err = opts.QR(strings.Join(uriLines, "")); err != nil {
+			return nil, err
+		}
+		if len(qr) == 0 {
+			return nil, errors.New("empty QR code")
+		}
+	} else if qr, err = encodeQR(strings.Join(uriLines, "")); err != nil {
+		// The URI is too long for a QR code, but the card still has it as
+		// text and as a mnemonic.
+		qr = nil
+	}
+
+	height := 2*margin + 2*lineHeight + (len(lines)+len(uriLines)+len(wordLines)+2)*lineHeight
+	if qr != nil {
+		height += qrSize + lineHeight
+	}
+
+	var c bytes.Buffer
+	y := height - margin - 14
+	fmt.Fprintf(&c, "BT /F1 14 Tf %d %d Td (%s) Tj ET\n", margin, y, escape(title))
+	y -= 2 * lineHeight
+	for _, line := range lines {
+		fmt.Fprintf(&c, "BT /F1 9 Tf %d %d Td (%s) Tj ET\n", margin, y, escape(line))
+		y -= lineHeight
+	}
+	y -= lineHeight
+	for _, line := range uriLines {
+		fmt.Fprintf(&c, "BT /F2 8 Tf %d %d Td (%s) Tj ET\n", margin, y, escape(line))
+		y -= lineHeight
+	}
+	y -= lineHeight
+	for _, line := range wordLines {
+		fmt.Fprintf(&c, "BT /F2 8 Tf %d %d Td (%s) Tj ET\n", margin, y, escape(line))
+		y -= lineHeight
+	}
+	if qr != nil {
+		module := float64(qrSize) / float64(len(qr))
+		top := float64(y)
+		for row, modules := range qr {
+			for col, dark := range modules {
+				if dark {
+					fmt.Fprintf(&c, "%.2f %.2f %.2f %.2f re\n", float64(margin)+float64(col)*module, top-float64(row+1)*module, module, module)
+				}
+			}
+		}
+		c.WriteString("f\n")
+	}
+
+	return writePDF(height, c.Bytes()), nil
+}
+
+// latin1 returns s, in NFC, encoded as Latin-1, and whether every character
+// could be encoded. Those that can't are replaced with '?'.
+func latin1(s string) ([]byte, bool) {
+	var b []byte
+	ok := true
+	for _, r := range norm.NFC.String(s) {
+		if r > 0xff {
+			r, ok = '?', false
+		}
+		b = append(b, byte(r))
+	}
+	return b, ok
+}
+
+// escape returns s as the contents of a PDF string literal in
+// WinAnsiEncoding, which agrees with Latin-1 apart from the C1 controls.
+// Characters that the standard fonts don't cover are replaced with '?'.
+func escape(s string) string {
+	b, _ := latin1(s)
+	var out strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case c < ' ' || (c >= 0x7f && c < 0xa0):
+			out.WriteByte('?')
+		case c > 0x7f:
+			fmt.Fprintf(&out, "\\%03o", c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// writePDF returns a PDF file with a single page of the given height,
+// drawn by the content stream, which may use Helvetica as /F1 and Courier as
+// /F2.
+func writePDF(height int, content []byte) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, height),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
@@ -0,0 +1,114 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package sharecard
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/agl/shamirsplit"
+)
+
+func TestRender(t *testing.T) {
+	modulus := new(big.Int).Lsh(big.NewInt(1), 127)
+	modulus.Sub(modulus, big.NewInt(1))
+	shares, err := shamirsplit.SplitShares(big.NewInt(42), modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares[0].Metadata.Label = "Vault (prod) \\ café"
+
+	qr := func(text string) ([][]bool, error) {
+		return [][]bool{{true, false}, {false, true}}, nil
+	}
+	pdf, err := Render(&shares[0], &Options{Total: 3, QR: qr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Error("output isn't framed as a PDF")
+	}
+	checksum, err := Checksum(&shares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Checksum: " + checksum, "Any 2 of the 3 shares", `Vault \(prod\) \\ caf\351`, " re\n"} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("card doesn't contain %q", want)
+		}
+	}
+
+	checkXref(t, pdf)
+}
+
+// TestRenderDefault checks that, by default, a card carries the share as a
+// mnemonic and a QR code.
+func TestRenderDefault(t *testing.T) {
+	modulus := new(big.Int).Lsh(big.NewInt(1), 127)
+	modulus.Sub(modulus, big.NewInt(1))
+	shares, err := shamirsplit.SplitShares(big.NewInt(42), modulus, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pdf, err := Render(&shares[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phrase, err := shamirsplit.EncodeMnemonic(&shares[0], shamirsplit.BIP39Wordlist("english"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := strings.Fields(phrase)
+	for i, word := range words {
+		if !regexp.MustCompile(`\b` + strconv.Itoa(i+1) + `\. ` + word + `\b`).Match(pdf) {
+			t.Errorf("card doesn't contain word %d, %q", i+1, word)
+		}
+	}
+	if bytes.Count(pdf, []byte(" re\n")) < 100 {
+		t.Error("card doesn't contain a QR code")
+	}
+	checkXref(t, pdf)
+
+	// French words are printable in Latin-1, but Japanese ones aren't.
+	pdf, err = Render(&shares[0], &Options{Wordlist: shamirsplit.BIP39Wordlist("french")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkXref(t, pdf)
+	if _, err := Render(&shares[0], &Options{Wordlist: shamirsplit.BIP39Wordlist("japanese")}); err == nil {
+		t.Error("Japanese mnemonic was accepted")
+	}
+}
+
+// checkXref checks that each entry in the cross-reference table of pdf points
+// at its object.
+func checkXref(t *testing.T, pdf []byte) {
+	t.Helper()
+	// Each entry in the cross-reference table must point at its object.
+	m := regexp.MustCompile(`xref\n0 (\d+)\n`).FindSubmatchIndex(pdf)
+	if m == nil {
+		t.Fatal("no cross-reference table")
+	}
+	n, _ := strconv.Atoi(string(pdf[m[2]:m[3]]))
+	entries := pdf[m[1]:]
+	for i := 1; i < n; i++ {
+		off, err := strconv.Atoi(string(entries[20*i : 20*i+10]))
+		if err != nil {
+			t.Fatalf("bad cross-reference entry %d", i)
+		}
+		if !bytes.HasPrefix(pdf[off:], []byte(fmt.Sprintf("%d 0 obj\n", i))) {
+			t.Errorf("cross-reference entry %d points at the wrong offset", i)
+		}
+	}
+}
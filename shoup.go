@@ -0,0 +1,388 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// This file implements Shoup's threshold RSA signatures ("Practical
+// Threshold Signatures", EUROCRYPT 2000), so that any k holders of shares
+// of an RSA private exponent can sign without the key ever being
+// reassembled. Each holder computes a signature share with ShoupSign and
+// anyone can combine k of them with ShoupCombine into an ordinary PKCS #1
+// v1.5 signature, which verifies with rsa.VerifyPKCS1v15.
+//
+// Signature shares carry a proof that they were computed correctly, checked
+// against the verification keys in the ShoupPublicKey, so that a
+// misbehaving signer is identified rather than just spoiling the signature.
+// Shoup's security proof needs a modulus that's the product of safe primes,
+// such as from GenerateShoupKey; other keys work but without that proof.
+
+// A ShoupPublicKey is the public part of a key split by SplitRSAShoup.
+type ShoupPublicKey struct {
+	rsa.PublicKey
+	// Threshold is the number of signature shares needed to sign, and
+	// Players the number of key shares dealt.
+	Threshold, Players int
+	// V is a random square modulo N, and VerificationKeys[i] is V raised
+	// to the key share with x coordinate i+1.
+	V                *big.Int
+	VerificationKeys []*big.Int
+}
+
+// A ShoupSignatureShare is a signer's contribution to a signature, with a
+// proof of its correctness.
+type ShoupSignatureShare struct {
+	X   int
+	Sig *big.Int
+	// C and Z are the non-interactive proof that Sig was computed with
+	// the key share that the verification key for X commits to.
+	C, Z *big.Int
+}
+
+// shoupHashes are the hashes whose signatures ShoupSign can compute, with
+// the prefixes of their PKCS #1 v1.5 DigestInfo encodings.
+var shoupHashes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// minShoupKeyBits is the smallest key size that GenerateShoupKey will
+// generate.
+const minShoupKeyBits = 1024
+
+// GenerateShoupKey generates an RSA key of the given size, which must be at
+// least 1024 bits, whose modulus is the product of two safe primes, as
+// Shoup's scheme assumes, with public exponent 65537. Safe primes are rare,
+// so this can take minutes for 2048-bit keys. If random is nil,
+// crypto/rand.Reader is used.
+func GenerateShoupKey(bits int, random io.Reader) (*rsa.PrivateKey, error) {
+	if bits < minShoupKeyBits {
+		return nil, errors.New("key size must be at least 1024 bits")
+	}
+	if bits%2 != 0 {
+		return nil, errors.New("invalid key size")
+	}
+	random = defaultRand(random)
+
+	primes := make([]*big.Int, 2)
+	for i := range primes {
+		for {
+			// rand.Prime sets the top two bits, so the product of
+			// two safe primes has exactly the requested size.
+			p, err := rand.Prime(random, bits/2-1)
+			if err != nil {
+				return nil, err
+			}
+			p.Lsh(p, 1).Add(p, big.NewInt(1))
+			if p.ProbablyPrime(20) && (i == 0 || p.Cmp(primes[0]) != 0) {
+				primes[i] = p
+				break
+			}
+		}
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: new(big.Int).Mul(primes[0], primes[1]), E: 65537},
+		Primes:    primes,
+	}
+	key.D = new(big.Int).ModInverse(big.NewInt(int64(key.E)), shoupLambda(primes[0], primes[1]))
+	if key.D == nil {
+		return nil, errors.New("public exponent isn't invertible")
+	}
+	key.Precompute()
+	return key, key.Validate()
+}
+
+// SplitRSAShoup splits the private exponent of key, which must have two
+// primes, into n shares, any k of which can sign with ShoupSign. The shares
+// don't record a modulus, since that would reveal the factorization of N,
+// and so can't be joined to recover the key. The public exponent must be
+// coprime to n!, which it is if it's a prime greater than n, such as 65537.
+// If rand is nil, crypto/rand.Reader is used.
+func SplitRSAShoup(key *rsa.PrivateKey, k, n int, rand io.Reader) (*ShoupPublicKey, []Share, error) {
+	if k < 1 || n < k {
+		return nil, nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, nil, err
+	}
+	if len(key.Primes) != 2 {
+		return nil, nil, errors.New("only two-prime keys can be split")
+	}
+	e := big.NewInt(int64(key.E))
+	if new(big.Int).GCD(nil, nil, e, shoupDelta(n)).Cmp(big.NewInt(1)) != 0 {
+		return nil, nil, errors.New("public exponent must be coprime to n!")
+	}
+	rand = defaultRand(rand)
+
+	// The exponent is shared modulo λ(N), so that signing works for any
+	// two-prime key. With safe primes, λ(N) is 2p'q', and d is odd, so
+	// this reveals no more than Shoup's sharing modulo p'q'.
+	lambda := shoupLambda(key.Primes[0], key.Primes[1])
+	d := new(big.Int).ModInverse(e, lambda)
+	if d == nil {
+		return nil, nil, errors.New("public exponent isn't invertible")
+	}
+	defer clear(d.Bits())
+	defer clear(lambda.Bits())
+	a, err := randomPolynomial(d, lambda, k, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		for _, c := range a[1:] {
+			clear(c.Bits())
+		}
+	}()
+
+	N := key.N
+	var v *big.Int
+	for v == nil || v.Sign() == 0 || new(big.Int).GCD(nil, nil, v, N).Cmp(big.NewInt(1)) != 0 {
+		if v, err = randomNumber(rand, N); err != nil {
+			return nil, nil, err
+		}
+	}
+	v.Mul(v, v).Mod(v, N)
+
+	m, err := newSetMetadata(k, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	pub := &ShoupPublicKey{
+		PublicKey:        key.PublicKey,
+		Threshold:        k,
+		Players:          n,
+		V:                v,
+		VerificationKeys: make([]*big.Int, n),
+	}
+	shares := make([]Share, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		y := evaluatePolynomial(a, x, lambda)
+		shares[i] = Share{X: x, Y: y, Metadata: m}
+		pub.VerificationKeys[i] = new(big.Int).Exp(v, y, N)
+	}
+	return pub, shares, nil
+}
+
+// ShoupSign returns the signature share, for the PKCS #1 v1.5 signature of
+// digest, the result of hashing the message with hash, from the holder of
+// share. If rand is nil, crypto/rand.Reader is used.
+func ShoupSign(pub *ShoupPublicKey, share Share, hash crypto.Hash, digest []byte, rand io.Reader) (*ShoupSignatureShare, error) {
+	if share.X == nil || share.Y == nil || share.Modulus != nil ||
+		!share.X.IsInt64() || share.X.Int64() < 1 || share.X.Int64() > int64(len(pub.VerificationKeys)) {
+		return nil, errors.New("share isn't a Shoup key share")
+	}
+	x, err := shoupMessage(&pub.PublicKey, hash, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	N := pub.N
+	delta := shoupDelta(pub.Players)
+	// xi = x^(2Δs)
+	e := new(big.Int).Lsh(delta, 1)
+	e.Mul(e, share.Y)
+	xi := new(big.Int).Exp(x, e, N)
+	clear(e.Bits())
+
+	// The proof is that log_V(vi) = log_x~(xi^2), where x~ = x^(4Δ),
+	// made non-interactive with SHA-256.
+	r, err := randomNumber(defaultRand(rand), new(big.Int).Lsh(big.NewInt(1), uint(N.BitLen()+2*8*sha256.Size)))
+	if err != nil {
+		return nil, err
+	}
+	defer clear(r.Bits())
+	xt := shoupXTilde(x, delta, N)
+	vi := pub.VerificationKeys[share.X.Int64()-1]
+	c := shoupChallenge(pub, xt, vi, new(big.Int).Exp(xi, big.NewInt(2), N),
+		new(big.Int).Exp(pub.V, r, N), new(big.Int).Exp(xt, r, N))
+	z := new(big.Int).Mul(share.Y, c)
+	z.Add(z, r)
+
+	return &ShoupSignatureShare{X: int(share.X.Int64()), Sig: xi, C: c, Z: z}, nil
+}
+
+// ShoupVerifyShare returns nil iff s is a correct signature share of
+// digest, the result of hashing the message with hash.
+func ShoupVerifyShare(pub *ShoupPublicKey, hash crypto.Hash, digest []byte, s *ShoupSignatureShare) error {
+	x, err := shoupMessage(&pub.PublicKey, hash, digest)
+	if err != nil {
+		return err
+	}
+	return pub.verifyShare(x, s)
+}
+
+func (pub *ShoupPublicKey) verifyShare(x *big.Int, s *ShoupSignatureShare) error {
+	N := pub.N
+	invalid := errors.New("invalid signature share from share " + strconv.Itoa(s.X))
+	if s.X < 1 || s.X > len(pub.VerificationKeys) || s.Sig == nil || s.C == nil || s.Z == nil ||
+		s.Sig.Sign() <= 0 || s.Sig.Cmp(N) >= 0 || s.Z.Sign() < 0 {
+		return invalid
+	}
+	vi := pub.VerificationKeys[s.X-1]
+	xi2 := new(big.Int).Exp(s.Sig, big.NewInt(2), N)
+	xt := shoupXTilde(x, shoupDelta(pub.Players), N)
+
+	// v' = V^z vi^-c and x' = x~^z (xi^2)^-c
+	viInv := new(big.Int).ModInverse(vi, N)
+	xi2Inv := new(big.Int).ModInverse(xi2, N)
+	if viInv == nil || xi2Inv == nil {
+		return invalid
+	}
+	v1 := new(big.Int).Exp(pub.V, s.Z, N)
+	v1.Mul(v1, new(big.Int).Exp(viInv, s.C, N)).Mod(v1, N)
+	x1 := new(big.Int).Exp(xt, s.Z, N)
+	x1.Mul(x1, new(big.Int).Exp(xi2Inv, s.C, N)).Mod(x1, N)
+	if shoupChallenge(pub, xt, vi, xi2, v1, x1).Cmp(s.C) != 0 {
+		return invalid
+	}
+	return nil
+}
+
+// ShoupCombine checks the signature shares of digest, the result of hashing
+// the message with hash, and combines Threshold of them into a PKCS #1 v1.5
+// signature, which is verified before it's returned. An invalid share
+// results in an error naming its signer.
+func ShoupCombine(pub *ShoupPublicKey, hash crypto.Hash, digest []byte, shares []ShoupSignatureShare) ([]byte, error) {
+	x, err := shoupMessage(&pub.PublicKey, hash, digest)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool)
+	var used []ShoupSignatureShare
+	for i := range shares {
+		if err := pub.verifyShare(x, &shares[i]); err != nil {
+			return nil, err
+		}
+		if !seen[shares[i].X] {
+			seen[shares[i].X] = true
+			used = append(used, shares[i])
+		}
+	}
+	if len(used) < pub.Threshold {
+		return nil, &NotEnoughSharesError{Need: pub.Threshold, Have: len(used)}
+	}
+	used = used[:pub.Threshold]
+
+	// w = Π xi^(2λi), where λi = Δ Π_{j≠i} j/(j-i) is an integer, and
+	// w^e = x^e' with e' = 4Δ².
+	N := pub.N
+	delta := shoupDelta(pub.Players)
+	w := big.NewInt(1)
+	for i, s := range used {
+		num, den := new(big.Int).Set(delta), big.NewInt(1)
+		for j, t := range used {
+			if i != j {
+				num.Mul(num, big.NewInt(int64(t.X)))
+				den.Mul(den, big.NewInt(int64(t.X-s.X)))
+			}
+		}
+		lambda := num.Quo(num, den)
+		lambda.Lsh(lambda, 1)
+		base := s.Sig
+		if lambda.Sign() < 0 {
+			if base = new(big.Int).ModInverse(base, N); base == nil {
+				return nil, errors.New("invalid signature share from share " + strconv.Itoa(s.X))
+			}
+			lambda.Neg(lambda)
+		}
+		w.Mul(w, new(big.Int).Exp(base, lambda, N)).Mod(w, N)
+	}
+
+	// With a e' + b e = 1, y = w^a x^b satisfies y^e = x.
+	e := big.NewInt(int64(pub.E))
+	ePrime := new(big.Int).Mul(delta, delta)
+	ePrime.Lsh(ePrime, 2)
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, ePrime, e).Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("public exponent must be coprime to n!")
+	}
+	y := new(big.Int).Mul(shoupExp(w, a, N), shoupExp(x, b, N))
+	y.Mod(y, N)
+
+	sig := y.FillBytes(make([]byte, pub.Size()))
+	if err := rsa.VerifyPKCS1v15(&pub.PublicKey, hash, digest, sig); err != nil {
+		return nil, errors.New("signature doesn't verify: a signer misbehaved")
+	}
+	return sig, nil
+}
+
+// shoupMessage returns the PKCS #1 v1.5 encoding of digest as an integer.
+func shoupMessage(pub *rsa.PublicKey, hash crypto.Hash, digest []byte) (*big.Int, error) {
+	prefix, ok := shoupHashes[hash]
+	if !ok {
+		return nil, errors.New("unsupported hash function")
+	}
+	if len(digest) != hash.Size() {
+		return nil, errors.New("digest has the wrong length")
+	}
+	k := pub.Size()
+	tLen := len(prefix) + len(digest)
+	if k < tLen+11 {
+		return nil, errors.New("key is too small for the hash")
+	}
+	em := make([]byte, k)
+	em[1] = 1
+	for i := 2; i < k-tLen-1; i++ {
+		em[i] = 0xff
+	}
+	copy(em[k-tLen:], prefix)
+	copy(em[k-len(digest):], digest)
+	return new(big.Int).SetBytes(em), nil
+}
+
+// shoupChallenge hashes the values of a proof of correctness to the
+// challenge.
+func shoupChallenge(pub *ShoupPublicKey, xt, vi, xi2, v1, x1 *big.Int) *big.Int {
+	h := sha256.New()
+	h.Write([]byte("shamirsplit shoup proof\x00"))
+	for _, v := range []*big.Int{pub.V, xt, vi, xi2, v1, x1} {
+		h.Write(v.FillBytes(make([]byte, pub.Size())))
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// shoupXTilde returns x^(4Δ) mod N.
+func shoupXTilde(x, delta, N *big.Int) *big.Int {
+	return new(big.Int).Exp(x, new(big.Int).Lsh(delta, 2), N)
+}
+
+// shoupExp returns x^e mod N, for e of either sign.
+func shoupExp(x, e, N *big.Int) *big.Int {
+	if e.Sign() < 0 {
+		inv := new(big.Int).ModInverse(x, N)
+		if inv == nil {
+			return new(big.Int)
+		}
+		return inv.Exp(inv, new(big.Int).Neg(e), N)
+	}
+	return new(big.Int).Exp(x, e, N)
+}
+
+// shoupDelta returns n!.
+func shoupDelta(n int) *big.Int {
+	return new(big.Int).MulRange(1, int64(n))
+}
+
+// shoupLambda returns lcm(p-1, q-1).
+func shoupLambda(p, q *big.Int) *big.Int {
+	p1 := new(big.Int).Sub(p, big.NewInt(1))
+	q1 := new(big.Int).Sub(q, big.NewInt(1))
+	g := new(big.Int).GCD(nil, nil, p1, q1)
+	return p1.Mul(p1, q1).Quo(p1, g)
+}
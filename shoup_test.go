@@ -0,0 +1,89 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestShoup(t *testing.T) {
+	if _, err := GenerateShoupKey(512, nil); err == nil {
+		t.Error("512-bit key was generated")
+	}
+
+	key, err := GenerateShoupKey(1024, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testShoup(t, key)
+}
+
+func TestShoupOrdinaryKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testShoup(t, key)
+}
+
+func testShoup(t *testing.T, key *rsa.PrivateKey) {
+	pub, shares, err := SplitRSAShoup(key, 3, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("release v1.2.3"))
+
+	var sigShares []ShoupSignatureShare
+	for _, i := range []int{4, 0, 2} {
+		s, err := ShoupSign(pub, shares[i], crypto.SHA256, digest[:], nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ShoupVerifyShare(pub, crypto.SHA256, digest[:], s); err != nil {
+			t.Errorf("signature share %d: %s", i, err)
+		}
+		sigShares = append(sigShares, *s)
+	}
+
+	sig, err := ShoupCombine(pub, crypto.SHA256, digest[:], sigShares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("combined signature doesn't verify: %s", err)
+	}
+	want, _ := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest[:])
+	if string(sig) != string(want) {
+		t.Error("combined signature differs from the key's own")
+	}
+
+	if _, err := ShoupCombine(pub, crypto.SHA256, digest[:], sigShares[:2]); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("combining two signature shares gave %v", err)
+	}
+	bad := append([]ShoupSignatureShare(nil), sigShares...)
+	bad[1].Sig = new(big.Int).Add(bad[1].Sig, big.NewInt(1))
+	if err := ShoupVerifyShare(pub, crypto.SHA256, digest[:], &bad[1]); err == nil {
+		t.Error("corrupt signature share verified")
+	}
+	if _, err := ShoupCombine(pub, crypto.SHA256, digest[:], bad); err == nil {
+		t.Error("corrupt signature share was combined")
+	}
+	other := sha256.Sum256([]byte("release v6.6.6"))
+	if err := ShoupVerifyShare(pub, crypto.SHA256, other[:], &sigShares[0]); err == nil {
+		t.Error("signature share verified for another message")
+	}
+
+	if _, err := JoinShares(shares[:3]); err == nil {
+		t.Error("key shares were joined")
+	}
+}
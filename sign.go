@@ -0,0 +1,70 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"errors"
+)
+
+// shareSignatureOptions separates share signatures from any other use of the
+// dealer's key.
+var shareSignatureOptions = &ed25519.Options{Context: "shamirsplit share"}
+
+// SignShares signs each share with the dealer's key, recording the public key
+// and the signature in the share. The signature covers the binary encoding of
+// the share, but for its hint, so it must be the last change made to it. Custodians can later
+// check that a share is authentic with VerifyDealer.
+func SignShares(shares []Share, key ed25519.PrivateKey) error {
+	pub := key.Public().(ed25519.PublicKey)
+
+	for i := range shares {
+		shares[i].DealerKey = pub
+		r, err := shares[i].records()
+		if err != nil {
+			return err
+		}
+
+		sig, err := key.Sign(nil, r.marshal(), shareSignatureOptions)
+		if err != nil {
+			return err
+		}
+		shares[i].Signature = sig
+	}
+	return nil
+}
+
+// VerifyDealer returns nil iff s was signed by the holder of the private key
+// for pub.
+func (s *Share) VerifyDealer(pub ed25519.PublicKey) error {
+	if s.Signature == nil {
+		return errors.New("share isn't signed")
+	}
+	if !pub.Equal(s.DealerKey) {
+		return errors.New("share was signed by a different dealer")
+	}
+
+	r, err := s.records()
+	if err != nil {
+		return err
+	}
+	return ed25519.VerifyWithOptions(pub, r.marshal(), s.Signature, shareSignatureOptions)
+}
+
+// checkSignature verifies the signature in r, if any, against the dealer key
+// that it contains.
+func checkSignature(r wireRecords) error {
+	pub, sig := r.get(tagDealerKey), r.get(tagSignature)
+	if sig == nil {
+		return nil
+	}
+	if len(pub) != ed25519.PublicKeySize ||
+		ed25519.VerifyWithOptions(pub, r.without(tagSignature, tagMAC, tagHint).marshal(), sig, shareSignatureOptions) != nil {
+		return ErrCorruptShare
+	}
+	return nil
+}
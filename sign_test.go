@@ -0,0 +1,52 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"math/big"
+	"testing"
+)
+
+func TestSignShares(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+
+	shares, _ := SplitShares(big.NewInt(42), MODP2048, 2, 3, nil)
+	AuthenticateShares(shares, nil)
+	if err := SignShares(shares, priv); err != nil {
+		t.Fatalf("failed to sign shares: %s", err)
+	}
+
+	for i := range shares {
+		data, err := shares[i].MarshalBinary()
+		if err != nil {
+			t.Fatalf("error while marshaling: %s", err)
+		}
+		var s Share
+		if err := s.UnmarshalBinary(data); err != nil {
+			t.Fatalf("error while unmarshaling: %s", err)
+		}
+		if err := s.VerifyDealer(pub); err != nil {
+			t.Errorf("share %d: signature didn't verify: %s", i, err)
+		}
+		if err := s.VerifyDealer(otherPub); err == nil {
+			t.Errorf("share %d: verified with the wrong key", i)
+		}
+
+		// A share that has been substituted, and whose MAC has been
+		// recomputed, must fail.
+		s.Y.Add(s.Y, big.NewInt(1))
+		if err := s.VerifyDealer(pub); err == nil {
+			t.Errorf("share %d: substituted share verified", i)
+		}
+		data, _ = s.MarshalBinary()
+		if err := s.UnmarshalBinary(data); err != ErrCorruptShare {
+			t.Errorf("share %d: substituted share parsed with %v", i, err)
+		}
+	}
+}
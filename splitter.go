@@ -0,0 +1,83 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// A Splitter splits any number of secrets with the same modulus, threshold
+// and number of shares, as Split does. It precomputes the powers of each
+// share number that the polynomials are evaluated at, so that each share is
+// a sum of products that's reduced just once, rather than computed by
+// Horner's rule with a reduction for each coefficient. Given the same
+// randomness, it returns the same shares as Split.
+//
+// A Splitter holds no secrets and is safe for concurrent use.
+type Splitter struct {
+	modulus *big.Int
+	k, n    int
+	// powers[i][j-1] is (i+1)^j modulo the modulus, for j in [1, k).
+	powers [][]big.Int
+}
+
+// NewSplitter returns a Splitter for k-of-n splits modulo modulus.
+func NewSplitter(modulus *big.Int, k, n int) (*Splitter, error) {
+	if k < 1 || n < k {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+	if modulus.Cmp(big.NewInt(int64(n))) <= 0 {
+		return nil, errors.New("modulus is too small for the number of shares")
+	}
+
+	s := &Splitter{modulus: new(big.Int).Set(modulus), k: k, n: n, powers: make([][]big.Int, n)}
+	for i := range s.powers {
+		x := big.NewInt(int64(i + 1))
+		row := make([]big.Int, k-1)
+		for j := range row {
+			if j == 0 {
+				row[j].Set(x)
+			} else {
+				row[j].Mul(&row[j-1], x)
+				row[j].Mod(&row[j], modulus)
+			}
+		}
+		s.powers[i] = row
+	}
+	return s, nil
+}
+
+// Split is like the package's Split function, with the modulus, threshold
+// and number of shares given to NewSplitter.
+func (s *Splitter) Split(secret *big.Int, rand io.Reader) (shares []*big.Int, err error) {
+	if err := checkSecret(secret, s.modulus); err != nil {
+		return nil, err
+	}
+	a, err := randomPolynomial(secret, s.modulus, s.k, defaultRand(rand))
+	if err != nil {
+		return nil, err
+	}
+
+	shares = make([]*big.Int, s.n)
+	ys := make([]big.Int, s.n)
+	var sum, product, q big.Int
+	for i, row := range s.powers {
+		sum.Set(secret)
+		for j := range row {
+			product.Mul(a[j+1], &row[j])
+			sum.Add(&sum, &product)
+		}
+		q.QuoRem(&sum, s.modulus, &ys[i])
+		shares[i] = &ys[i]
+	}
+	return shares, nil
+}
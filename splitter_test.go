@@ -0,0 +1,68 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestSplitter(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	const k, n = 3, 5
+	splitter, err := NewSplitter(modulus, k, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	random := make([]byte, 4096)
+	rand.Read(random)
+	for _, secret := range []*big.Int{big.NewInt(0), big.NewInt(42), new(big.Int).Sub(modulus, big.NewInt(1))} {
+		got, err := splitter.Split(secret, bytes.NewReader(random))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := Split(secret, modulus, k, n, bytes.NewReader(random))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range want {
+			if got[i].Cmp(want[i]) != 0 {
+				t.Errorf("secret %s: share %d differs from Split's", secret, i)
+			}
+		}
+		joined, err := Join(got[2:], []int{2, 3, 4}, modulus)
+		if err != nil || joined.Cmp(secret) != 0 {
+			t.Errorf("secret %s: joined to %v, %v", secret, joined, err)
+		}
+	}
+
+	if _, err := splitter.Split(modulus, nil); err == nil {
+		t.Error("secret out of range was split")
+	}
+	if _, err := NewSplitter(modulus, 3, 2); err == nil {
+		t.Error("n < k was accepted")
+	}
+	if _, err := NewSplitter(big.NewInt(5), 2, 5); err == nil {
+		t.Error("modulus no larger than n was accepted")
+	}
+}
+
+func BenchmarkSplitter(b *testing.B) {
+	const k, n = 10, 1000
+
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(42)
+	splitter, _ := NewSplitter(modulus, k, n)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		splitter.Split(secret, nil)
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"database/sql/driver"
+	"errors"
+)
+
+// Value implements driver.Valuer, storing s in its binary encoding, as a
+// BLOB. It has a value receiver so that shares can be passed to queries
+// directly.
+func (s Share) Value() (driver.Value, error) {
+	return s.MarshalBinary()
+}
+
+// Scan implements sql.Scanner for columns holding the binary encoding of a
+// share. NULL is an error: use sql.Null[Share] for nullable columns.
+func (s *Share) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		return s.UnmarshalBinary(src)
+	case string:
+		return s.UnmarshalBinary([]byte(src))
+	case nil:
+		return errors.New("cannot scan NULL into a Share")
+	}
+	return errors.New("cannot scan a non-binary value into a Share")
+}
@@ -0,0 +1,59 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"testing"
+)
+
+func TestShareSQL(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	shares, err := SplitShares(big.NewInt(42), modulus, 2, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AuthenticateShares(shares, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// database/sql converts arguments with the default converter, which
+	// must find the Valuer.
+	v, err := driver.DefaultParameterConverter.ConvertValue(shares[0])
+	if err != nil {
+		t.Fatalf("error converting share: %s", err)
+	}
+	blob, ok := v.([]byte)
+	if !ok {
+		t.Fatalf("share was converted to %T, want []byte", v)
+	}
+
+	var s Share
+	if err := s.Scan(blob); err != nil {
+		t.Fatalf("error scanning share: %s", err)
+	}
+	if s.Y.Cmp(shares[0].Y) != 0 || s.MACKey == nil {
+		t.Errorf("share didn't round trip")
+	}
+	if err := s.Scan(string(blob)); err != nil {
+		t.Errorf("error scanning share from a string: %s", err)
+	}
+
+	if err := s.Scan(nil); err == nil {
+		t.Errorf("NULL was scanned into a share")
+	}
+	if err := s.Scan(int64(1)); err == nil {
+		t.Errorf("an integer was scanned into a share")
+	}
+
+	var n sql.Null[Share]
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("NULL wasn't handled by sql.Null: %v", err)
+	}
+}
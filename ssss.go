@@ -0,0 +1,273 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// This file implements the share format of B. Poettering's ssss tool. ssss
+// works in GF(2^d), where d is a multiple of 8 no greater than 1024, and the
+// secret is the constant term of the monic polynomial
+//
+//	x^k + c_{k-1} x^{k-1} + ... + c_1 x + c_0
+//
+// Shares are printed as [token-]index-hex where the hex value has d/4 digits.
+// Unless disabled, a keyless XTEA based diffusion layer is applied to the
+// secret when d >= 64.
+
+// ssssIrreducible contains, for each d = 8, 16, ..., 1024, the exponents
+// a, b, c such that x^d + x^a + x^b + x^c + 1 is the reducing polynomial used
+// by ssss.
+var ssssIrreducible = [...]uint8{
+	4, 3, 1, 5, 3, 1, 4, 3, 1, 7, 3, 2, 5, 4, 3, 5, 3, 2, 7, 4, 2, 4, 3, 1, 10, 9, 3, 9, 4, 2, 7, 6, 2, 10, 9,
+	6, 4, 3, 1, 5, 4, 3, 4, 3, 1, 7, 2, 1, 5, 3, 2, 7, 4, 2, 6, 3, 2, 5, 3, 2, 15, 3, 2, 11, 3, 2, 9, 8, 7, 7,
+	2, 1, 5, 3, 2, 9, 3, 1, 7, 3, 1, 9, 8, 3, 9, 4, 2, 8, 5, 3, 15, 14, 10, 10, 5, 2, 9, 6, 2, 9, 3, 2, 9, 5,
+	2, 11, 10, 1, 7, 3, 2, 11, 2, 1, 9, 7, 4, 4, 3, 1, 8, 3, 1, 7, 4, 1, 7, 2, 1, 13, 11, 6, 5, 3, 2, 7, 3, 2,
+	8, 7, 5, 12, 3, 2, 13, 10, 6, 5, 3, 2, 5, 3, 2, 9, 5, 2, 9, 7, 2, 13, 4, 3, 4, 3, 1, 11, 6, 4, 18, 9, 6,
+	19, 18, 13, 11, 3, 2, 15, 9, 6, 4, 3, 1, 16, 5, 2, 15, 14, 6, 8, 5, 2, 15, 11, 2, 11, 6, 2, 7, 5, 3, 8,
+	3, 1, 19, 16, 9, 11, 9, 6, 15, 7, 6, 13, 4, 3, 14, 13, 3, 13, 6, 3, 9, 5, 2, 19, 13, 6, 19, 10, 3, 11,
+	6, 5, 9, 2, 1, 14, 3, 2, 13, 3, 1, 7, 5, 4, 11, 9, 8, 11, 6, 5, 23, 16, 9, 19, 14, 6, 23, 10, 2, 8, 3,
+	2, 5, 4, 3, 9, 6, 4, 4, 3, 2, 13, 8, 6, 13, 11, 1, 13, 10, 3, 11, 6, 5, 19, 17, 4, 15, 14, 7, 13, 9, 6,
+	9, 7, 3, 9, 7, 1, 14, 3, 2, 11, 8, 2, 11, 6, 4, 13, 5, 2, 11, 5, 1, 11, 4, 1, 19, 10, 3, 21, 10, 6, 13,
+	3, 1, 15, 7, 5, 19, 18, 10, 7, 5, 3, 12, 7, 2, 7, 5, 1, 14, 9, 6, 10, 3, 2, 15, 13, 12, 12, 11, 9, 16,
+	9, 7, 12, 9, 3, 9, 5, 2, 17, 10, 6, 24, 9, 3, 17, 15, 13, 5, 4, 3, 19, 17, 8, 15, 6, 3, 19, 6, 1,
+}
+
+// newSSSSField returns GF(2^degree) with the reducing polynomial used by
+// ssss.
+func newSSSSField(degree int) (*BinaryField, error) {
+	if degree < 8 || degree > 1024 || degree%8 != 0 {
+		return nil, errors.New("ssss: security level must be a multiple of 8 between 8 and 1024 bits")
+	}
+
+	poly := new(big.Int)
+	poly.SetBit(poly, degree, 1)
+	poly.SetBit(poly, 0, 1)
+	for _, e := range ssssIrreducible[3*(degree/8-1) : 3*(degree/8)] {
+		poly.SetBit(poly, int(e), 1)
+	}
+	return newBinaryField(degree, poly), nil
+}
+
+// ssssEncipher and ssssDecipher are XTEA with an all-zero key.
+func ssssEncipher(v *[2]uint32) {
+	var sum uint32
+	const delta = 0x9e3779b9
+	for i := 0; i < 32; i++ {
+		v[0] += ((v[1]<<4 ^ v[1]>>5) + v[1]) ^ sum
+		sum += delta
+		v[1] += ((v[0]<<4 ^ v[0]>>5) + v[0]) ^ sum
+	}
+}
+
+func ssssDecipher(v *[2]uint32) {
+	var sum uint32 = 0xc6ef3720
+	const delta = 0x9e3779b9
+	for i := 0; i < 32; i++ {
+		v[1] -= ((v[0]<<4 ^ v[0]>>5) + v[0]) ^ sum
+		sum -= delta
+		v[0] -= ((v[1]<<4 ^ v[1]>>5) + v[1]) ^ sum
+	}
+}
+
+// ssssDiffuse applies (or, if decode is true, removes) the ssss diffusion
+// layer to x.
+func ssssDiffuse(x *big.Int, degree int, decode bool) *big.Int {
+	// ssss exports x as 16-bit, big-endian words, least significant word
+	// first, and permutes the first degree/8 bytes of the result.
+	l := degree / 8
+	words := (degree + 8) / 16
+	be := x.FillBytes(make([]byte, 2*words))
+	v := make([]byte, 2*words)
+	for w := 0; w < words; w++ {
+		v[2*w] = be[2*(words-w)-2]
+		v[2*w+1] = be[2*(words-w)-1]
+	}
+	if degree%16 == 8 {
+		v[l-1] = v[l]
+	}
+
+	slice := func(idx int, f func(*[2]uint32)) {
+		var b [2]uint32
+		for i := range b {
+			for j := 0; j < 4; j++ {
+				b[i] = b[i]<<8 | uint32(v[(idx+4*i+j)%l])
+			}
+		}
+		f(&b)
+		for i := range b {
+			for j := 0; j < 4; j++ {
+				v[(idx+4*i+j)%l] = byte(b[i] >> (24 - 8*j))
+			}
+		}
+	}
+
+	if !decode {
+		for i := 0; i < 40*l; i += 2 {
+			slice(i, ssssEncipher)
+		}
+	} else {
+		for i := 40*l - 2; i >= 0; i -= 2 {
+			slice(i, ssssDecipher)
+		}
+	}
+
+	if degree%16 == 8 {
+		v[l] = v[l-1]
+		v[l-1] = 0
+	}
+	for w := 0; w < words; w++ {
+		be[2*(words-w)-2] = v[2*w]
+		be[2*(words-w)-1] = v[2*w+1]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// SplitSSSS splits secret into n shares, any k of which can be combined to
+// recover it, in the format of ssss-split. The security level is 8*len(secret)
+// bits. If token is not empty, it prefixes each share. If diffusion is true,
+// the diffusion layer is applied, as ssss-split does by default. If rand is
+// nil, crypto/rand.Reader is used.
+func SplitSSSS(secret []byte, k, n int, token string, diffusion bool, rand io.Reader) ([]string, error) {
+	f, err := newSSSSField(8 * len(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	if k < 2 || n < k || big.NewInt(int64(n)).BitLen() > f.m {
+		return nil, errors.New("ssss: invalid split parameters")
+	}
+
+	if strings.Contains(token, "-") {
+		return nil, errors.New("ssss: token may not contain a hyphen")
+	}
+
+	c := make([]*big.Int, k)
+	c[0] = new(big.Int).SetBytes(secret)
+	if diffusion && f.m >= 64 {
+		c[0] = ssssDiffuse(c[0], f.m, false)
+	}
+
+	rand = defaultRand(rand)
+	buf := make([]byte, len(secret))
+	for i := 1; i < k; i++ {
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return nil, err
+		}
+		c[i] = new(big.Int).SetBytes(buf)
+	}
+
+	width := len(strconv.Itoa(n))
+	shares := make([]string, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+
+		// Horner's rule with an implicit leading coefficient of one.
+		y := new(big.Int).Set(x)
+		for j := k - 1; j > 0; j-- {
+			y = f.Mul(y.Xor(y, c[j]), x)
+		}
+		y.Xor(y, c[0])
+
+		share := fmt.Sprintf("%0*d-%0*x", width, i+1, f.m/4, y)
+		if len(token) > 0 {
+			share = token + "-" + share
+		}
+		shares[i] = share
+	}
+
+	return shares, nil
+}
+
+// JoinSSSS recovers the secret from shares in the format of ssss-split. ssss
+// shares don't record the threshold and the number of shares given must
+// equal it exactly. Any token prefix is ignored. The diffusion argument must
+// match that used when splitting.
+func JoinSSSS(shares []string, diffusion bool) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("ssss: at least two shares are required")
+	}
+
+	var f *BinaryField
+	xs := make([]*big.Int, len(shares))
+	ys := make([]*big.Int, len(shares))
+	for i, share := range shares {
+		share = strings.TrimSpace(share)
+		parts := strings.Split(share, "-")
+		if len(parts) < 2 {
+			return nil, errors.New("ssss: malformed share")
+		}
+		index, hex := parts[len(parts)-2], parts[len(parts)-1]
+
+		if f == nil {
+			var err error
+			if f, err = newSSSSField(4 * len(hex)); err != nil {
+				return nil, err
+			}
+		} else if 4*len(hex) != f.m {
+			return nil, errors.New("ssss: shares have different security levels")
+		}
+
+		x, err := strconv.ParseUint(index, 10, 32)
+		if err != nil || x == 0 || big.NewInt(int64(x)).BitLen() > f.m {
+			return nil, errors.New("ssss: invalid share index")
+		}
+		xs[i] = big.NewInt(int64(x))
+
+		var ok bool
+		if ys[i], ok = new(big.Int).SetString(hex, 16); !ok || ys[i].Sign() < 0 {
+			return nil, errors.New("ssss: invalid share value")
+		}
+
+		for j := 0; j < i; j++ {
+			if xs[j].Cmp(xs[i]) == 0 {
+				return nil, errors.New("ssss: found duplicate share")
+			}
+		}
+	}
+
+	// Remove the leading x^k term so that the remaining polynomial can be
+	// interpolated at zero.
+	k := len(shares)
+	for i := range ys {
+		t := big.NewInt(1)
+		for j := 0; j < k; j++ {
+			t = f.Mul(t, xs[i])
+		}
+		ys[i].Xor(ys[i], t)
+	}
+
+	secret := new(big.Int)
+	for i := range xs {
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			num = f.Mul(num, xs[j])
+			den = f.Mul(den, new(big.Int).Xor(xs[j], xs[i]))
+		}
+		inv, err := f.Inv(den)
+		if err != nil {
+			return nil, err
+		}
+		secret.Xor(secret, f.Mul(ys[i], f.Mul(num, inv)))
+	}
+
+	if diffusion && f.m >= 64 {
+		secret = ssssDiffuse(secret, f.m, true)
+	}
+
+	return secret.FillBytes(make([]byte, f.m/8)), nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestSSSSDiffusion(t *testing.T) {
+	for _, degree := range []int{64, 72, 128, 184, 1024} {
+		x, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(degree)))
+		y := ssssDiffuse(x, degree, false)
+		if y.BitLen() > degree {
+			t.Errorf("degree %d: diffused value is too large", degree)
+		}
+		if z := ssssDiffuse(y, degree, true); z.Cmp(x) != 0 {
+			t.Errorf("degree %d: diffusion didn't invert", degree)
+		}
+	}
+}
+
+func TestSSSS(t *testing.T) {
+	for _, secret := range []string{"x", "\x00\x01", "my secret root password"} {
+		for _, diffusion := range []bool{false, true} {
+			shares, err := SplitSSSS([]byte(secret), 3, 12, "tok", diffusion, rand.Reader)
+			if err != nil {
+				t.Fatalf("error while splitting: %s", err)
+			}
+
+			if !strings.HasPrefix(shares[0], "tok-01-") || len(shares[0]) != len("tok-01-")+2*len(secret) {
+				t.Errorf("unexpected share format: %s", shares[0])
+			}
+
+			result, err := JoinSSSS([]string{shares[11], shares[3], shares[7]}, diffusion)
+			if err != nil {
+				t.Fatalf("failed to join shares: %s", err)
+			}
+			if !bytes.Equal(result, []byte(secret)) {
+				t.Errorf("JoinSSSS returned %q, want %q", result, secret)
+			}
+		}
+	}
+}
+
+// ssssShares are the shares of "my secret root password" from the example
+// in the ssss documentation, which split it with ssss-split -t 3 -n 5 and
+// the default diffusion layer, at a 184-bit security level.
+var ssssShares = []string{
+	"1-1c41ef496eccfbeba439714085df8437236298da8dd824",
+	"2-fbc74a03a50e14ab406c225afb5f45c40ae11976d2b665",
+	"3-fa1c3a9c6df8af0779c36de6c33f6e36e989d0e0b91309",
+	"4-468de7d6eb36674c9cf008c8e8fc8c566537ad6301eb9e",
+}
+
+func TestJoinSSSSKnownAnswer(t *testing.T) {
+	const secret = "my secret root password"
+	for _, set := range [][]int{{0, 1, 2}, {3, 1, 0}, {2, 3, 0}, {1, 2, 3}} {
+		shares := make([]string, len(set))
+		for i, j := range set {
+			shares[i] = ssssShares[j]
+		}
+		result, err := JoinSSSS(shares, true)
+		if err != nil {
+			t.Fatalf("shares %v: %s", set, err)
+		}
+		if string(result) != secret {
+			t.Errorf("shares %v: JoinSSSS returned %q, want %q", set, result, secret)
+		}
+
+		// ssss-split -w prefixes each share with the token and
+		// ssss-combine ignores it.
+		for i := range shares {
+			shares[i] = "foo-" + shares[i]
+		}
+		if result, err := JoinSSSS(shares, true); err != nil || string(result) != secret {
+			t.Errorf("shares %v with a token: got %q, %v", set, result, err)
+		}
+	}
+
+	if result, _ := JoinSSSS(ssssShares[:3], false); string(result) == secret {
+		t.Error("secret was recovered without removing the diffusion layer")
+	}
+}
+
+func TestSSSSIrreducible(t *testing.T) {
+	for degree := 8; degree <= 1024; degree += 8 {
+		f, _ := newSSSSField(degree)
+		if !f.irreducible() {
+			t.Errorf("reducing polynomial for degree %d isn't irreducible", degree)
+		}
+	}
+}
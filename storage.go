@@ -0,0 +1,233 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"math"
+)
+
+// A storage shard uses the encoding of binary shares (see wire.go) with its
+// own magic and tags. The data is encrypted with AES-256-GCM under a random
+// key and the ciphertext is Reed-Solomon coded over GF(2^8): it's cut into k
+// stripes, which are the values at 1..k of a polynomial for each byte
+// position, and the fragment in shard x is the value at x. The key is shared
+// over GF(2^8) with the same x coordinates. The records other than the key
+// share and the fragment are authenticated as additional data, which can
+// include information about the data that's recovered along with it.
+const storageMagic = "SHMD"
+
+const (
+	tagStorageSetID     = 1
+	tagStorageThreshold = 2
+	tagStorageLen       = 3
+	tagStorageX         = 4
+	tagStorageKey       = 5
+	tagStorageFragment  = 6
+	tagStorageInfo      = 7
+)
+
+const storageKeyLen = 32
+
+// SplitStorage encrypts data and encodes it into n storage shards, any k of
+// which can be combined by JoinStorage to recover it, while fewer reveal
+// nothing about it beyond its length. Each shard is about 1/k the size of the
+// data, so this suits large backups spread across independent stores. n can
+// be at most 255. If rand is nil, crypto/rand.Reader is used.
+func SplitStorage(data []byte, k, n int, rand io.Reader) ([][]byte, error) {
+	return splitStorage(data, nil, k, n, rand)
+}
+
+// splitStorage is like SplitStorage, but also records info, if not nil, in
+// each shard. It's authenticated, but not encrypted.
+func splitStorage(data, info []byte, k, n int, rand io.Reader) ([][]byte, error) {
+	if k < 1 || n < k || n > 255 {
+		return nil, errors.New("invalid split parameters")
+	}
+	rand = defaultRand(rand)
+
+	key := make([]byte, storageKeyLen)
+	if _, err := io.ReadFull(rand, key); err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	var setID [16]byte
+	if _, err := io.ReadFull(rand, setID[:]); err != nil {
+		return nil, err
+	}
+
+	var header wireRecords
+	header.add(tagStorageSetID, setID[:])
+	header.addUint(tagStorageThreshold, uint64(k))
+	header.addUint(tagStorageLen, uint64(len(data)+storageOverhead))
+	if info != nil {
+		header.add(tagStorageInfo, info)
+	}
+	ciphertext := storageAEAD(key).Seal(nil, make([]byte, 12), data, header.marshalAs(storageMagic))
+
+	xs := make([]byte, n)
+	for i := range xs {
+		xs[i] = byte(i + 1)
+	}
+	keyShares, err := gf256Split(key, k, xs, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	stripes := storageStripes(ciphertext, k)
+	shards := make([][]byte, n)
+	fragment := make([]byte, len(stripes[0]))
+	for i, x := range xs {
+		if i < k {
+			copy(fragment, stripes[i])
+		} else {
+			gf256InterpolateAt(xs[:k], stripes, x, fragment)
+		}
+
+		r := append(wireRecords(nil), header...)
+		r.addUint(tagStorageX, uint64(x))
+		r.add(tagStorageKey, keyShares[i])
+		r.add(tagStorageFragment, fragment)
+		shards[i] = r.marshalAs(storageMagic)
+	}
+	return shards, nil
+}
+
+// JoinStorage recovers the data from at least k shards from SplitStorage. It
+// returns an error if the shards are from different splits or have been
+// altered.
+func JoinStorage(shards [][]byte) ([]byte, error) {
+	data, _, err := joinStorage(shards)
+	return data, err
+}
+
+// joinStorage is like JoinStorage, but also returns the info recorded by
+// splitStorage.
+func joinStorage(shards [][]byte) (data, info []byte, err error) {
+	if len(shards) == 0 {
+		return nil, nil, errors.New("no shards given")
+	}
+
+	var header []byte
+	var k, length int
+	var xs []byte
+	var keyShares, fragments [][]byte
+	for _, shard := range shards {
+		var r wireRecords
+		var x uint64
+		var key, fragment []byte
+		err := parseWireAs(storageMagic, shard, func(tag uint64, value []byte) error {
+			switch tag {
+			case tagStorageSetID, tagStorageThreshold, tagStorageLen, tagStorageInfo:
+				r.add(tag, value)
+			case tagStorageX:
+				v, err := parseWireUint(value)
+				if err != nil || v < 1 || v > 255 {
+					return errors.New("invalid shard number")
+				}
+				x = v
+			case tagStorageKey:
+				key = value
+			case tagStorageFragment:
+				fragment = value
+			default:
+				return errUnknownRecord
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if x == 0 || key == nil || fragment == nil {
+			return nil, nil, errors.New("truncated shard")
+		}
+
+		h := r.marshalAs(storageMagic)
+		if header == nil {
+			header = h
+			threshold, err := parseWireUint(r.get(tagStorageThreshold))
+			if err != nil || threshold < 1 || threshold > 255 {
+				return nil, nil, errors.New("invalid threshold")
+			}
+			l, err := parseWireUint(r.get(tagStorageLen))
+			if err != nil || l < storageOverhead || l > math.MaxInt32 {
+				return nil, nil, errors.New("invalid data length")
+			}
+			k, length = int(threshold), int(l)
+			info = r.get(tagStorageInfo)
+		} else if !bytes.Equal(h, header) {
+			return nil, nil, errors.New("shards are from different splits")
+		}
+
+		if len(key) != storageKeyLen || len(fragment) != (length+k-1)/k {
+			return nil, nil, errors.New("shard has the wrong length")
+		}
+		if bytes.IndexByte(xs, byte(x)) >= 0 {
+			return nil, nil, errors.New("found duplicate shard")
+		}
+		xs = append(xs, byte(x))
+		keyShares = append(keyShares, key)
+		fragments = append(fragments, fragment)
+	}
+
+	if len(xs) < k {
+		return nil, nil, errors.New("too few shards")
+	}
+	xs, keyShares, fragments = xs[:k], keyShares[:k], fragments[:k]
+
+	key := make([]byte, storageKeyLen)
+	defer clear(key)
+	gf256InterpolateAt(xs, keyShares, 0, key)
+
+	ciphertext := make([]byte, 0, len(fragments[0])*k)
+	stripe := make([]byte, len(fragments[0]))
+	for j := 1; j <= k; j++ {
+		gf256InterpolateAt(xs, fragments, byte(j), stripe)
+		ciphertext = append(ciphertext, stripe...)
+	}
+	ciphertext = ciphertext[:length]
+
+	data, err = storageAEAD(key).Open(nil, make([]byte, 12), ciphertext, header)
+	if err != nil {
+		return nil, nil, errors.New("shards are corrupt")
+	}
+	return data, info, nil
+}
+
+// storageOverhead is the length of the GCM tag.
+const storageOverhead = 16
+
+// storageAEAD returns AES-256-GCM keyed with key. Since the key is random,
+// it's only used once and so a zero nonce is safe.
+func storageAEAD(key []byte) cipher.AEAD {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+// storageStripes cuts ciphertext into k stripes of equal length, padding the
+// last with zeros.
+func storageStripes(ciphertext []byte, k int) [][]byte {
+	l := (len(ciphertext) + k - 1) / k
+	padded := make([]byte, l*k)
+	copy(padded, ciphertext)
+	stripes := make([][]byte, k)
+	for i := range stripes {
+		stripes[i] = padded[i*l : (i+1)*l]
+	}
+	return stripes
+}
@@ -0,0 +1,73 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSplitStorage(t *testing.T) {
+	for _, size := range []int{0, 1, 100, 4097} {
+		data := make([]byte, size)
+		rand.Read(data)
+
+		shards, err := SplitStorage(data, 3, 5, nil)
+		if err != nil {
+			t.Fatalf("error while splitting: %s", err)
+		}
+		if size > 1000 && len(shards[0]) > size/2 {
+			t.Errorf("shards of %d bytes for %d bytes of data", len(shards[0]), size)
+		}
+
+		for _, subset := range [][]int{{0, 1, 2}, {4, 3, 2}, {0, 4, 2}, {1, 3, 4, 0}} {
+			var chosen [][]byte
+			for _, i := range subset {
+				chosen = append(chosen, shards[i])
+			}
+			got, err := JoinStorage(chosen)
+			if err != nil {
+				t.Errorf("size %d, shards %v: %s", size, subset, err)
+				continue
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("size %d, shards %v: wrong data", size, subset)
+			}
+		}
+
+		if _, err := JoinStorage(shards[:2]); err == nil {
+			t.Errorf("size %d: two shards were enough", size)
+		}
+	}
+}
+
+func TestJoinStorageRejectsBadShards(t *testing.T) {
+	data := []byte("a backup of something important")
+	shards, err := SplitStorage(data, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	others, err := SplitStorage(data, 2, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := JoinStorage([][]byte{shards[0], others[1]}); err == nil {
+		t.Errorf("shards from different splits were joined")
+	}
+	if _, err := JoinStorage([][]byte{shards[0], shards[0]}); err == nil {
+		t.Errorf("duplicate shards were joined")
+	}
+
+	corrupt := append([]byte(nil), shards[1]...)
+	// The last byte of each fragment only encodes padding.
+	corrupt[len(corrupt)-2] ^= 1
+	if _, err := JoinStorage([][]byte{shards[0], corrupt}); err == nil {
+		t.Errorf("corrupt shard was accepted")
+	}
+}
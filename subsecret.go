@@ -0,0 +1,73 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// DeriveSubSecret returns the sub-secret of master for the given purpose,
+// such as "backup-2024" or "db-encryption": a value modulo modulus derived
+// with HKDF-SHA256 from master, with the purpose as the info. Sub-secrets for
+// different purposes are independent, and none reveals master or another.
+//
+// Shares of a sub-secret, from DealSubSecret, can't be combined with shares
+// of master, which remain the way to recover everything.
+func DeriveSubSecret(master, modulus *big.Int, purpose string) (*big.Int, error) {
+	if err := checkSecret(master, modulus); err != nil {
+		return nil, err
+	}
+	if len(purpose) == 0 {
+		return nil, errors.New("sub-secret purpose is empty")
+	}
+
+	size := (modulus.BitLen() + 7) / 8
+	ikm := master.FillBytes(make([]byte, size))
+	defer clear(ikm)
+	// An extra 128 bits makes the bias from reducing modulo the modulus
+	// negligible.
+	okm, err := hkdf.Key(sha256.New, ikm, nil, "shamirsplit sub-secret "+purpose, size+16)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(okm)
+	sub := new(big.Int).SetBytes(okm)
+	return sub.Mod(sub, modulus), nil
+}
+
+// DealSubSecret derives the sub-secret of master for purpose, as
+// DeriveSubSecret does, and deals it into n shares with Deal and opts, which
+// must include WithField. Unless opts include WithMetadata, the shares'
+// metadata is labeled with the purpose. Shareholders can then recover the
+// sub-secret, with JoinShares, without learning master. Master's own
+// sharing is unaffected, and dealing a sub-secret again gives new shares of
+// the same value.
+func DealSubSecret(master *big.Int, purpose string, n int, opts ...SplitOption) (*ShareSet, error) {
+	var o SplitOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Modulus == nil {
+		return nil, errors.New("no modulus given")
+	}
+
+	sub, err := DeriveSubSecret(master, o.Modulus, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if o.Metadata == nil {
+		m, err := NewMetadata(o.Threshold, purpose, o.Rand)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts[:len(opts):len(opts)], WithMetadata(m))
+	}
+	return Deal(sub, n, opts...)
+}
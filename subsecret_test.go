@@ -0,0 +1,58 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDeriveSubSecret(t *testing.T) {
+	master := big.NewInt(1234)
+	backup, err := DeriveSubSecret(master, P256Order, "backup-2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := DeriveSubSecret(master, P256Order, "backup-2024")
+	db, _ := DeriveSubSecret(master, P256Order, "db-encryption")
+	other, _ := DeriveSubSecret(big.NewInt(1235), P256Order, "backup-2024")
+	if backup.Cmp(again) != 0 {
+		t.Error("sub-secret isn't deterministic")
+	}
+	if backup.Cmp(db) == 0 || backup.Cmp(other) == 0 || backup.Cmp(master) == 0 {
+		t.Error("sub-secrets aren't distinct")
+	}
+	if backup.Sign() < 0 || backup.Cmp(P256Order) >= 0 {
+		t.Error("sub-secret is out of range")
+	}
+	if _, err := DeriveSubSecret(master, P256Order, ""); err == nil {
+		t.Error("empty purpose was accepted")
+	}
+}
+
+func TestDealSubSecret(t *testing.T) {
+	master := big.NewInt(1234)
+	masterSet, _ := Deal(master, 3, WithThreshold(2), WithField(P256Order))
+
+	set, err := DealSubSecret(master, "db-encryption", 3, WithThreshold(2), WithField(P256Order))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label := set.Shares[0].Metadata.Label; label != "db-encryption" {
+		t.Errorf("got label %q", label)
+	}
+	want, _ := DeriveSubSecret(master, P256Order, "db-encryption")
+	if got, err := JoinShares(set.Shares[1:]); err != nil || got.Cmp(want) != 0 {
+		t.Errorf("got %v, %v from sub-secret shares, want %v", got, err, want)
+	}
+	if _, err := JoinShares([]Share{masterSet.Shares[0], set.Shares[1]}); err == nil {
+		t.Error("shares of master and a sub-secret were joined")
+	}
+	if got, err := JoinShares(masterSet.Shares[:2]); err != nil || got.Cmp(master) != 0 {
+		t.Errorf("master sharing gave %v, %v", got, err)
+	}
+}
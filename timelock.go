@@ -0,0 +1,114 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// A timelocked share envelope uses the encoding of binary shares (see
+// wire.go) with its own magic and tags. It records the time at which the
+// share becomes usable, which isn't secret, and the timelock ciphertext of the
+// binary encoding of the share.
+const timelockMagic = "SHML"
+
+const (
+	tagUnlockAt           = 1
+	tagTimelockCiphertext = 2
+)
+
+// A Timelock encrypts data such that it can't be decrypted until a given
+// time, for example with tlock, which encrypts to the future round of a drand
+// beacon whose signature, once published, is the decryption key.
+type Timelock interface {
+	Lock(data []byte, unlockAt time.Time) (ciphertext []byte, err error)
+	// Unlock decrypts ciphertext, which was locked until unlockAt, or
+	// returns an error if that time hasn't yet come.
+	Unlock(ciphertext []byte, unlockAt time.Time) (data []byte, err error)
+}
+
+// ErrTimelocked is returned by UnlockShare for a share that isn't yet usable.
+var ErrTimelocked = errors.New("share is timelocked")
+
+// LockShares returns an envelope for each of shares, timelocked by tl until
+// unlockAt. Locking some of the shares of a dealing and giving them to
+// custodians, or escrow, allows policies such as a dead man's switch, where
+// the remaining shares alone are too few to recover the secret until the
+// locked ones become usable.
+func LockShares(shares []Share, tl Timelock, unlockAt time.Time) ([][]byte, error) {
+	envelopes := make([][]byte, len(shares))
+	for i := range shares {
+		data, err := shares[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ciphertext, err := tl.Lock(data, unlockAt)
+		clear(data)
+		if err != nil {
+			return nil, err
+		}
+
+		var r wireRecords
+		r.add(tagUnlockAt, binary.AppendVarint(nil, unlockAt.Unix()))
+		r.add(tagTimelockCiphertext, ciphertext)
+		envelopes[i] = r.marshalAs(timelockMagic)
+	}
+	return envelopes, nil
+}
+
+// UnlockShare unlocks an envelope from LockShares and parses the share
+// within. If tl fails to unlock it before the recorded time, the result is
+// ErrTimelocked.
+func UnlockShare(envelope []byte, tl Timelock) (s Share, err error) {
+	unlockAt, ciphertext, err := parseTimelockEnvelope(envelope)
+	if err != nil {
+		return
+	}
+
+	data, err := tl.Unlock(ciphertext, unlockAt)
+	if err != nil {
+		if time.Now().Before(unlockAt) {
+			return s, ErrTimelocked
+		}
+		return s, err
+	}
+	err = s.UnmarshalBinary(data)
+	clear(data)
+	return
+}
+
+// TimelockUnlockAt returns the time at which the share in an envelope from
+// LockShares becomes usable.
+func TimelockUnlockAt(envelope []byte) (time.Time, error) {
+	unlockAt, _, err := parseTimelockEnvelope(envelope)
+	return unlockAt, err
+}
+
+// parseTimelockEnvelope returns the unlock time and ciphertext of envelope.
+func parseTimelockEnvelope(envelope []byte) (unlockAt time.Time, ciphertext []byte, err error) {
+	err = parseWireAs(timelockMagic, envelope, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagUnlockAt:
+			t, n := binary.Varint(value)
+			if n <= 0 || n != len(value) {
+				return errors.New("invalid unlock time")
+			}
+			unlockAt = time.Unix(t, 0)
+		case tagTimelockCiphertext:
+			ciphertext = value
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err == nil && (unlockAt.IsZero() || ciphertext == nil) {
+		err = errors.New("truncated envelope")
+	}
+	return
+}
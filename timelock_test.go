@@ -0,0 +1,72 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testTimelock unlocks data once its clock reaches the unlock time. Locked
+// data is simply prefixed with a marker.
+type testTimelock struct {
+	now time.Time
+}
+
+func (tl *testTimelock) Lock(data []byte, unlockAt time.Time) ([]byte, error) {
+	return append([]byte("locked:"), data...), nil
+}
+
+func (tl *testTimelock) Unlock(ciphertext []byte, unlockAt time.Time) ([]byte, error) {
+	if tl.now.Before(unlockAt) {
+		return nil, errors.New("round not yet reached")
+	}
+	data, ok := bytes.CutPrefix(ciphertext, []byte("locked:"))
+	if !ok {
+		return nil, errors.New("not locked")
+	}
+	return bytes.Clone(data), nil
+}
+
+func TestTimelock(t *testing.T) {
+	modulus, _ := new(big.Int).SetString(modulusStr, 16)
+	secret := big.NewInt(7)
+	shares, err := SplitShares(secret, modulus, 3, 4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlockAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	tl := &testTimelock{now: time.Now()}
+	envelopes, err := LockShares(shares[2:], tl, unlockAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if at, err := TimelockUnlockAt(envelopes[0]); err != nil || !at.Equal(unlockAt) {
+		t.Errorf("got unlock time %v, %v, want %v", at, err, unlockAt)
+	}
+	if _, err := UnlockShare(envelopes[0], tl); err != ErrTimelocked {
+		t.Errorf("early unlock gave %v, want ErrTimelocked", err)
+	}
+
+	tl.now = unlockAt
+	unlocked, err := UnlockShare(envelopes[1], tl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := JoinShares([]Share{shares[0], shares[1], unlocked})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("got %s, want %s", result, secret)
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// A transcript uses the encoding of binary shares (see wire.go) with its own
+// magic. It shares the share format's tags, so that the metadata is encoded
+// identically.
+const transcriptMagic = "SHMT"
+
+// transcriptSignatureOptions separates transcript signatures from any other
+// use of the dealer's key.
+var transcriptSignatureOptions = &ed25519.Options{Context: "shamirsplit transcript"}
+
+// A Transcript is a canonical record of a dealing, for archiving as proof of
+// how and when a secret was split. It contains no secret information: only
+// the parameters, the Feldman commitments, if any, and a SHA-256 digest of
+// the binary encoding of each share, less its hint.
+type Transcript struct {
+	Modulus  *big.Int
+	Metadata Metadata
+	// Commitments is nil unless the set was dealt by SplitVerifiable.
+	Commitments  *Commitments
+	ShareDigests [][sha256.Size]byte
+	// Retired are the x coordinates of shares that have been replaced by
+	// ReplaceShare and must no longer be used.
+	Retired []*big.Int
+	// DealerKey and Signature are set by Sign.
+	DealerKey ed25519.PublicKey
+	Signature []byte
+}
+
+// NewTranscript returns the transcript of set. The metadata is taken from m,
+// if not nil, or else from the first share. The threshold and creation time
+// default to those of the set and the current time. The share digests are
+// in order of x coordinate, so that the transcript doesn't depend on the
+// order of set.Shares. The shares must be in their final form since later
+// changes, such as SignShares, change their digests.
+func NewTranscript(set *ShareSet, m *Metadata) (*Transcript, error) {
+	if set.Modulus == nil || len(set.Shares) == 0 {
+		return nil, errors.New("share set is incomplete")
+	}
+
+	t := &Transcript{Modulus: set.Modulus, Commitments: set.Commitments}
+	if m == nil {
+		m = set.Shares[0].Metadata
+	}
+	if m != nil {
+		t.Metadata = *m
+	}
+	if t.Metadata.Threshold == 0 {
+		t.Metadata.Threshold = set.Threshold
+	}
+	if t.Metadata.Created.IsZero() {
+		t.Metadata.Created = time.Now().Truncate(time.Second)
+	}
+
+	shares := append([]Share(nil), set.Shares...)
+	SortShares(shares)
+	for i := range shares {
+		data, err := marshalWithoutHint(&shares[i])
+		if err != nil {
+			return nil, err
+		}
+		t.ShareDigests = append(t.ShareDigests, sha256.Sum256(data))
+	}
+	return t, nil
+}
+
+// Contains returns true iff s is one of the shares recorded in t and hasn't
+// been retired.
+func (t *Transcript) Contains(s *Share) bool {
+	if s.X == nil || t.IsRetired(s.X) {
+		return false
+	}
+	data, err := marshalWithoutHint(s)
+	if err != nil {
+		return false
+	}
+	digest := sha256.Sum256(data)
+	for _, d := range t.ShareDigests {
+		if d == digest {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetired returns true iff the share with x coordinate x has been retired.
+func (t *Transcript) IsRetired(x *big.Int) bool {
+	for _, r := range t.Retired {
+		if r.Cmp(x) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign signs t with the dealer's key, recording the public key and the
+// signature in t.
+func (t *Transcript) Sign(key ed25519.PrivateKey) error {
+	t.DealerKey = key.Public().(ed25519.PublicKey)
+	r, err := t.records()
+	if err != nil {
+		return err
+	}
+	t.Signature, err = key.Sign(nil, r.marshalAs(transcriptMagic), transcriptSignatureOptions)
+	return err
+}
+
+// Verify returns nil iff t was signed by the holder of the private key for
+// pub.
+func (t *Transcript) Verify(pub ed25519.PublicKey) error {
+	if t.Signature == nil {
+		return errors.New("transcript isn't signed")
+	}
+	if !pub.Equal(t.DealerKey) {
+		return errors.New("transcript was signed by a different dealer")
+	}
+	r, err := t.records()
+	if err != nil {
+		return err
+	}
+	return ed25519.VerifyWithOptions(pub, r.marshalAs(transcriptMagic), t.Signature, transcriptSignatureOptions)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (t *Transcript) MarshalBinary() ([]byte, error) {
+	r, err := t.records()
+	if err != nil {
+		return nil, err
+	}
+	if t.Signature != nil {
+		r.add(tagSignature, t.Signature)
+	}
+	return r.marshalAs(transcriptMagic), nil
+}
+
+// records returns the records of the encoding of t, other than the
+// signature.
+func (t *Transcript) records() (r wireRecords, err error) {
+	if t.Modulus == nil || t.Modulus.Sign() <= 0 {
+		return nil, errors.New("transcript has no modulus")
+	}
+	if name := modulusName(t.Modulus); len(name) > 0 {
+		r.add(tagModulusName, []byte(name))
+	} else {
+		r.addInt(tagModulus, t.Modulus)
+	}
+	if err := t.Metadata.addRecords(&r); err != nil {
+		return nil, err
+	}
+	if c := t.Commitments; c != nil {
+		b, err := c.marshal()
+		if err != nil {
+			return nil, err
+		}
+		r.add(tagCommitments, b)
+	}
+	var digests []byte
+	for _, d := range t.ShareDigests {
+		digests = append(digests, d[:]...)
+	}
+	r.add(tagDigests, digests)
+	if len(t.Retired) > 0 {
+		var retired []byte
+		for _, x := range t.Retired {
+			if x == nil || x.Sign() <= 0 {
+				return nil, errors.New("invalid retired share")
+			}
+			retired = binary.AppendUvarint(retired, uint64(len(x.Bytes())))
+			retired = append(retired, x.Bytes()...)
+		}
+		r.add(tagRetired, retired)
+	}
+	if t.DealerKey != nil {
+		if len(t.DealerKey) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid dealer key")
+		}
+		r.add(tagDealerKey, t.DealerKey)
+	}
+	return
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It doesn't verify
+// the signature: use Verify for that.
+func (t *Transcript) UnmarshalBinary(data []byte) error {
+	var tr Transcript
+	var m *Metadata
+	var digests []byte
+
+	err := parseWireAs(transcriptMagic, data, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagModulus:
+			tr.Modulus = new(big.Int).SetBytes(value)
+		case tagModulusName:
+			if tr.Modulus = NamedModulus(string(value)); tr.Modulus == nil {
+				return errors.New("transcript uses an unknown modulus")
+			}
+		case tagCommitments:
+			c, err := parseCommitments(value)
+			tr.Commitments = c
+			return err
+		case tagDigests:
+			digests = value
+		case tagRetired:
+			for len(value) > 0 {
+				l, n := binary.Uvarint(value)
+				if n <= 0 || l == 0 || l > uint64(len(value)-n) {
+					return errors.New("invalid retired shares")
+				}
+				tr.Retired = append(tr.Retired, new(big.Int).SetBytes(value[n:n+int(l)]))
+				value = value[n+int(l):]
+			}
+		case tagDealerKey:
+			if len(value) != ed25519.PublicKeySize {
+				return errors.New("invalid dealer key")
+			}
+			tr.DealerKey = append(ed25519.PublicKey(nil), value...)
+		case tagSignature:
+			tr.Signature = append([]byte(nil), value...)
+		default:
+			return parseMetadataRecord(&m, tag, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if tr.Modulus == nil || len(digests) == 0 || len(digests)%sha256.Size != 0 {
+		return errors.New("transcript is incomplete")
+	}
+	for len(digests) > 0 {
+		tr.ShareDigests = append(tr.ShareDigests, [sha256.Size]byte(digests))
+		digests = digests[sha256.Size:]
+	}
+	if m != nil {
+		tr.Metadata = *m
+	}
+	if tr.Commitments != nil {
+		tr.Commitments.Epoch = tr.Metadata.Epoch
+	}
+
+	*t = tr
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/ed25519"
+	"math/big"
+	"testing"
+)
+
+func TestTranscript(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	set, err := SplitVerifiable(big.NewInt(42), q, p, big.NewInt(4), 3, 5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range set.Shares {
+		set.Shares[i].Modulus = q
+	}
+	m, _ := NewMetadata(3, "root key", nil)
+
+	tr, err := NewTranscript(set, m)
+	if err != nil {
+		t.Fatalf("error creating transcript: %s", err)
+	}
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	if err := tr.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Transcript
+	if err := parsed.UnmarshalBinary(data); err != nil {
+		t.Fatalf("error parsing transcript: %s", err)
+	}
+	if err := parsed.Verify(pub); err != nil {
+		t.Errorf("transcript failed to verify: %s", err)
+	}
+	if parsed.Metadata != *m || len(parsed.Commitments.Values) != 3 {
+		t.Errorf("transcript didn't survive marshaling")
+	}
+	for i := range set.Shares {
+		if !parsed.Contains(&set.Shares[i]) {
+			t.Errorf("transcript doesn't contain share %d", i)
+		}
+	}
+	forged := set.Shares[0]
+	forged.Y = new(big.Int).Add(forged.Y, big.NewInt(1))
+	if parsed.Contains(&forged) {
+		t.Errorf("transcript contains a forged share")
+	}
+
+	parsed.Metadata.Label = "other key"
+	if err := parsed.Verify(pub); err == nil {
+		t.Errorf("modified transcript verified")
+	}
+}
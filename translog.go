@@ -0,0 +1,344 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// A log entry uses the encoding of binary shares (see wire.go) with its own
+// magic. The transcript is included in its binary encoding, so that its
+// signature can be checked.
+const logEntryMagic = "SHMG"
+
+// A LogEntry is a record of a dealing for publication in an append-only
+// transparency log, so that custodians and auditors can later check that a
+// reconstruction recovered the secret that was dealt, from shares that were
+// part of the dealing. Entries for successive epochs of a secret are chained
+// by hash.
+//
+// The binary encoding, from MarshalBinary, is canonical and is what the log
+// should hash. The JSON encoding carries the same information for logs and
+// tools that want it; it can be converted back without loss, except for
+// records that this version of the package doesn't understand.
+type LogEntry struct {
+	Transcript Transcript
+	// Fingerprint, if not nil, is a salted hash of the secret. Like the
+	// fingerprint in shares, it allows guesses of the secret to be tested,
+	// so it must only be published for secrets with high entropy, such as
+	// keys.
+	Fingerprint *Fingerprint
+	// Previous is the LeafHash of the entry for the previous epoch of the
+	// secret, or all zeros for the first.
+	Previous [sha256.Size]byte
+}
+
+// NewLogEntry returns a log entry for the dealing recorded by t. If secret
+// is not nil, the entry includes its fingerprint and, if prev is not nil, the
+// entry follows it. If rand is nil, crypto/rand.Reader is used.
+func NewLogEntry(t *Transcript, secret *big.Int, prev *LogEntry, rand io.Reader) (*LogEntry, error) {
+	e := &LogEntry{Transcript: *t}
+	if secret != nil {
+		if t.Modulus == nil || checkSecret(secret, t.Modulus) != nil {
+			return nil, errors.New("secret must be less than split modulus")
+		}
+		e.Fingerprint = new(Fingerprint)
+		if _, err := io.ReadFull(defaultRand(rand), e.Fingerprint.Salt[:]); err != nil {
+			return nil, err
+		}
+		copy(e.Fingerprint.Digest[:], fingerprintDigest(e.Fingerprint.Salt[:], secret, t.Modulus))
+	}
+	if prev != nil {
+		if prev.Transcript.Metadata.Epoch >= t.Metadata.Epoch {
+			return nil, errors.New("entry must have a later epoch than the previous one")
+		}
+		var err error
+		if e.Previous, err = prev.LeafHash(); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// LeafHash returns the hash of e as a leaf of a Merkle tree log, as defined
+// in RFC 6962: SHA-256 of a zero byte followed by the binary encoding.
+func (e *LogEntry) LeafHash() (h [sha256.Size]byte, err error) {
+	data, err := e.MarshalBinary()
+	if err != nil {
+		return
+	}
+	return sha256.Sum256(append([]byte{0}, data...)), nil
+}
+
+// Follows returns nil iff e is the entry for a later epoch than prev and is
+// chained to it.
+func (e *LogEntry) Follows(prev *LogEntry) error {
+	h, err := prev.LeafHash()
+	if err != nil {
+		return err
+	}
+	if e.Previous != h {
+		return errors.New("entry doesn't follow the previous one")
+	}
+	if e.Transcript.Metadata.Epoch <= prev.Transcript.Metadata.Epoch {
+		return errors.New("entry doesn't have a later epoch than the previous one")
+	}
+	return nil
+}
+
+// CheckShares returns an error unless every share is one of those recorded
+// in e and, if e has commitments, is consistent with them.
+func (e *LogEntry) CheckShares(shares []Share) error {
+	for i := range shares {
+		if !e.Transcript.Contains(&shares[i]) {
+			return errors.New("share " + strconv.Itoa(i) + " isn't recorded in the log entry")
+		}
+		if c := e.Transcript.Commitments; c != nil && !c.Verify(shares[i]) {
+			return ErrCorruptShare
+		}
+	}
+	return nil
+}
+
+// CheckSecret returns ErrWrongSecret unless secret matches the fingerprint
+// and commitments, whichever e has, or an error if it has neither.
+func (e *LogEntry) CheckSecret(secret *big.Int) error {
+	t := &e.Transcript
+	if e.Fingerprint == nil && t.Commitments == nil {
+		return errors.New("log entry has nothing to check the secret against")
+	}
+	if e.Fingerprint != nil {
+		if err := e.Fingerprint.Check(secret, t.Modulus); err != nil {
+			return err
+		}
+	}
+	if c := t.Commitments; c != nil {
+		if len(c.Values) == 0 || new(big.Int).Exp(c.G, secret, c.P).Cmp(c.Values[0]) != 0 {
+			return ErrWrongSecret
+		}
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (e *LogEntry) MarshalBinary() ([]byte, error) {
+	t, err := e.Transcript.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var r wireRecords
+	r.add(tagTranscript, t)
+	if e.Fingerprint != nil {
+		r.add(tagFingerprint, e.Fingerprint.marshal())
+	}
+	if e.Previous != [sha256.Size]byte{} {
+		r.add(tagPrevious, e.Previous[:])
+	}
+	return r.marshalAs(logEntryMagic), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It doesn't verify
+// the signature of the transcript.
+func (e *LogEntry) UnmarshalBinary(data []byte) error {
+	var entry LogEntry
+	var haveTranscript bool
+	err := parseWireAs(logEntryMagic, data, func(tag uint64, value []byte) error {
+		switch tag {
+		case tagTranscript:
+			haveTranscript = true
+			return entry.Transcript.UnmarshalBinary(value)
+		case tagFingerprint:
+			f, err := parseFingerprint(value)
+			entry.Fingerprint = f
+			return err
+		case tagPrevious:
+			if len(value) != len(entry.Previous) {
+				return errors.New("invalid previous entry hash")
+			}
+			copy(entry.Previous[:], value)
+		default:
+			return errUnknownRecord
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !haveTranscript {
+		return errors.New("log entry is incomplete")
+	}
+	*e = entry
+	return nil
+}
+
+// logEntryJSON is the JSON encoding of a LogEntry. Integers of the field
+// are in hex and byte strings in hex or, for the signature, base64.
+type logEntryJSON struct {
+	SetID        string      `json:"set_id,omitempty"`
+	Epoch        uint64      `json:"epoch,omitempty"`
+	Threshold    int         `json:"threshold"`
+	Created      *time.Time  `json:"created,omitempty"`
+	NotAfter     *time.Time  `json:"not_after,omitempty"`
+	Label        string      `json:"label,omitempty"`
+	Modulus      string      `json:"modulus"`
+	Commitments  *commitJSON `json:"commitments,omitempty"`
+	ShareDigests []string    `json:"share_digests"`
+	DealerKey    string      `json:"dealer_key,omitempty"`
+	Signature    []byte      `json:"signature,omitempty"`
+	Fingerprint  *fingerJSON `json:"fingerprint,omitempty"`
+	Previous     string      `json:"previous,omitempty"`
+}
+
+type commitJSON struct {
+	P      string   `json:"p"`
+	G      string   `json:"g"`
+	Values []string `json:"values"`
+}
+
+type fingerJSON struct {
+	Salt   string `json:"salt"`
+	Digest string `json:"digest"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e *LogEntry) MarshalJSON() ([]byte, error) {
+	t := &e.Transcript
+	if t.Modulus == nil {
+		return nil, errors.New("transcript has no modulus")
+	}
+	m := &t.Metadata
+	j := logEntryJSON{
+		Epoch:     m.Epoch,
+		Threshold: m.Threshold,
+		Label:     m.Label,
+		Modulus:   t.Modulus.Text(16),
+		DealerKey: hex.EncodeToString(t.DealerKey),
+		Signature: t.Signature,
+	}
+	if m.SetID != [16]byte{} {
+		j.SetID = hex.EncodeToString(m.SetID[:])
+	}
+	if !m.Created.IsZero() {
+		created := m.Created.UTC()
+		j.Created = &created
+	}
+	if !m.NotAfter.IsZero() {
+		notAfter := m.NotAfter.UTC()
+		j.NotAfter = &notAfter
+	}
+	if c := t.Commitments; c != nil {
+		j.Commitments = &commitJSON{P: c.P.Text(16), G: c.G.Text(16)}
+		for _, v := range c.Values {
+			j.Commitments.Values = append(j.Commitments.Values, v.Text(16))
+		}
+	}
+	j.ShareDigests = make([]string, len(t.ShareDigests))
+	for i, d := range t.ShareDigests {
+		j.ShareDigests[i] = hex.EncodeToString(d[:])
+	}
+	if f := e.Fingerprint; f != nil {
+		j.Fingerprint = &fingerJSON{hex.EncodeToString(f.Salt[:]), hex.EncodeToString(f.Digest[:])}
+	}
+	if e.Previous != [sha256.Size]byte{} {
+		j.Previous = hex.EncodeToString(e.Previous[:])
+	}
+	return json.Marshal(&j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It doesn't verify the
+// signature of the transcript.
+func (e *LogEntry) UnmarshalJSON(data []byte) error {
+	var j logEntryJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	var entry LogEntry
+	t := &entry.Transcript
+	m := &t.Metadata
+	m.Epoch, m.Threshold, m.Label = j.Epoch, j.Threshold, j.Label
+	if j.SetID != "" && !decodeHexInto(m.SetID[:], j.SetID) {
+		return errors.New("invalid set ID")
+	}
+	if j.Created != nil {
+		m.Created = *j.Created
+	}
+	if j.NotAfter != nil {
+		m.NotAfter = *j.NotAfter
+	}
+	var ok bool
+	if t.Modulus, ok = new(big.Int).SetString(j.Modulus, 16); !ok || t.Modulus.Sign() <= 0 {
+		return errors.New("invalid modulus")
+	}
+	if c := j.Commitments; c != nil {
+		t.Commitments = new(Commitments)
+		for _, s := range append([]string{c.P, c.G}, c.Values...) {
+			v, ok := new(big.Int).SetString(s, 16)
+			if !ok || v.Sign() < 0 {
+				return errors.New("invalid commitment")
+			}
+			switch {
+			case t.Commitments.P == nil:
+				t.Commitments.P = v
+			case t.Commitments.G == nil:
+				t.Commitments.G = v
+			default:
+				t.Commitments.Values = append(t.Commitments.Values, v)
+			}
+		}
+		if len(t.Commitments.Values) == 0 {
+			return errors.New("invalid commitments")
+		}
+	}
+	if len(j.ShareDigests) == 0 {
+		return errors.New("log entry is incomplete")
+	}
+	t.ShareDigests = make([][sha256.Size]byte, len(j.ShareDigests))
+	for i, d := range j.ShareDigests {
+		if !decodeHexInto(t.ShareDigests[i][:], d) {
+			return errors.New("invalid share digest")
+		}
+	}
+	if j.DealerKey != "" {
+		key, err := hex.DecodeString(j.DealerKey)
+		if err != nil {
+			return errors.New("invalid dealer key")
+		}
+		t.DealerKey = key
+	}
+	t.Signature = j.Signature
+	if f := j.Fingerprint; f != nil {
+		entry.Fingerprint = new(Fingerprint)
+		if !decodeHexInto(entry.Fingerprint.Salt[:], f.Salt) || !decodeHexInto(entry.Fingerprint.Digest[:], f.Digest) {
+			return errors.New("invalid fingerprint")
+		}
+	}
+	if j.Previous != "" && !decodeHexInto(entry.Previous[:], j.Previous) {
+		return errors.New("invalid previous entry hash")
+	}
+
+	*e = entry
+	return nil
+}
+
+// decodeHexInto decodes s into dst, returning false unless it's exactly
+// the right length.
+func decodeHexInto(dst []byte, s string) bool {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(dst) {
+		return false
+	}
+	copy(dst, b)
+	return true
+}
@@ -0,0 +1,107 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestLogEntry(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	secret := big.NewInt(42)
+	set, err := SplitVerifiable(secret, q, p, big.NewInt(4), 2, 3, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMetadata(2, "root", rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Epoch = 1
+	tr, err := NewTranscript(set, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	if err := tr.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewLogEntry(tr, secret, nil, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.CheckShares(set.Shares); err != nil {
+		t.Error(err)
+	}
+	if err := e.CheckSecret(secret); err != nil {
+		t.Error(err)
+	}
+	if err := e.CheckSecret(big.NewInt(43)); err != ErrWrongSecret {
+		t.Errorf("wrong secret gave %v", err)
+	}
+	other, _ := SplitShares(secret, q, 2, 3, rand.Reader)
+	if err := e.CheckShares(other[:1]); err == nil {
+		t.Error("share from another dealing was accepted")
+	}
+
+	// Both encodings round-trip to the same canonical form.
+	want, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromBinary, fromJSON LogEntry
+	if err := fromBinary.UnmarshalBinary(want); err != nil {
+		t.Fatal(err)
+	}
+	j, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(j, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []*LogEntry{&fromBinary, &fromJSON} {
+		data, err := got.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, want) {
+			t.Errorf("round trip changed the encoding")
+		}
+		if err := got.Transcript.Verify(key.Public().(ed25519.PublicKey)); err != nil {
+			t.Errorf("transcript signature: %v", err)
+		}
+	}
+
+	// The next epoch's entry chains to this one.
+	m2 := *m
+	m2.Epoch = 2
+	tr2, err := NewTranscript(set, &m2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLogEntry(tr, nil, e, rand.Reader); err == nil {
+		t.Error("entry for the same epoch was chained")
+	}
+	e2, err := NewLogEntry(tr2, nil, e, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e2.Follows(&fromJSON); err != nil {
+		t.Error(err)
+	}
+	if err := e.Follows(e2); err == nil {
+		t.Error("chain was accepted backwards")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Share URIs have the form
+//
+//	shamir://v1/<set ID>/<x>?k=<threshold>#<share>
+//
+// where the set ID is in hex, x is in decimal and the share is the binary
+// encoding of the share in unpadded base64url. The set ID and threshold come
+// from the share's metadata and are there for the benefit of apps that
+// handle the URIs; the set ID is all zeros, and k omitted, if the share
+// doesn't record them. The share itself is authoritative.
+const uriScheme = "shamir"
+
+// EncodeShareURI returns the shamir:// URI of s, suitable for QR codes, NFC
+// tags and links.
+func EncodeShareURI(s *Share) (string, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	var setID [16]byte
+	threshold := 0
+	if m := s.Metadata; m != nil {
+		setID, threshold = m.SetID, m.Threshold
+	}
+
+	uri := uriScheme + "://v1/" + hex.EncodeToString(setID[:]) + "/" + s.X.String()
+	if threshold > 0 {
+		uri += "?k=" + strconv.Itoa(threshold)
+	}
+	return uri + "#" + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// ParseShareURI parses a URI from EncodeShareURI, checking that the set ID,
+// x coordinate and threshold in the URI match the share.
+func ParseShareURI(uri string) (s Share, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return
+	}
+	if u.Scheme != uriScheme {
+		return s, errors.New("not a shamir URI")
+	}
+	if u.Host != "v1" {
+		return s, errors.New("unsupported shamir URI version")
+	}
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) != 2 {
+		return s, errors.New("malformed shamir URI")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(u.Fragment)
+	if err != nil {
+		return s, errors.New("malformed share in shamir URI")
+	}
+	if err = s.UnmarshalBinary(data); err != nil {
+		return
+	}
+
+	var setID [16]byte
+	threshold := 0
+	if m := s.Metadata; m != nil {
+		setID, threshold = m.SetID, m.Threshold
+	}
+	x, ok := new(big.Int).SetString(parts[1], 10)
+	if parts[0] != hex.EncodeToString(setID[:]) || !ok || x.Cmp(s.X) != 0 {
+		return Share{}, errors.New("shamir URI doesn't match its share")
+	}
+	if k := u.Query().Get("k"); k != "" && k != strconv.Itoa(threshold) {
+		return Share{}, errors.New("shamir URI doesn't match its share")
+	}
+	return
+}
@@ -0,0 +1,51 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestShareURI(t *testing.T) {
+	shares, _ := SplitShares(big.NewInt(42), P256Order, 2, 3, nil)
+	m, _ := NewMetadata(2, "", nil)
+	shares[1].Metadata = m
+
+	for i := range shares {
+		uri, err := EncodeShareURI(&shares[i])
+		if err != nil {
+			t.Fatalf("error encoding URI: %s", err)
+		}
+		if !strings.HasPrefix(uri, "shamir://v1/") {
+			t.Errorf("unexpected URI %s", uri)
+		}
+		s, err := ParseShareURI(uri)
+		if err != nil {
+			t.Fatalf("error parsing %s: %s", uri, err)
+		}
+		if s.X.Cmp(shares[i].X) != 0 || s.Y.Cmp(shares[i].Y) != 0 {
+			t.Errorf("share didn't survive URI encoding")
+		}
+	}
+
+	uri, _ := EncodeShareURI(&shares[1])
+	if !strings.Contains(uri, "/2?k=2#") {
+		t.Errorf("URI %s lacks the index and threshold", uri)
+	}
+	for _, bad := range []string{
+		strings.Replace(uri, "/2?", "/3?", 1),
+		strings.Replace(uri, "k=2", "k=3", 1),
+		strings.Replace(uri, "v1", "v2", 1),
+		strings.Replace(uri, "shamir:", "https:", 1),
+	} {
+		if _, err := ParseShareURI(bad); err == nil {
+			t.Errorf("parsed bad URI %s", bad)
+		}
+	}
+}
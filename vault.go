@@ -0,0 +1,161 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+)
+
+// SplitVaultCompatible splits secret into n shares, any k of which can be
+// combined to recover it, in the format used by HashiCorp Vault's shamir
+// package for unseal keys. Each byte of the secret is shared independently
+// over GF(2^8) and each share is the sequence of y values followed by a single
+// byte containing the share's x coordinate. As in Vault, the x coordinates are
+// a random permutation of 1..255 and k must be at least two. If rand is nil,
+// crypto/rand.Reader is used.
+func SplitVaultCompatible(secret []byte, k, n int, rand io.Reader) (shares [][]byte, err error) {
+	if k < 2 || n < k || n > 255 {
+		return nil, errors.New("invalid split parameters")
+	}
+
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+
+	rand = defaultRand(rand)
+	xs, err := randomPermutation(rand, 255)
+	if err != nil {
+		return
+	}
+
+	shareXs := make([]byte, n)
+	for i := range shareXs {
+		shareXs[i] = byte(xs[i] + 1)
+	}
+	shares, err = gf256Split(secret, k, shareXs, rand)
+	if err != nil {
+		return nil, err
+	}
+	for i := range shares {
+		shares[i] = append(shares[i], shareXs[i])
+	}
+
+	return
+}
+
+// JoinVaultCompatible recovers a secret from shares produced by
+// SplitVaultCompatible or by HashiCorp Vault. At least two shares must be
+// given and, since the threshold isn't recorded in the shares, the result is
+// only correct if at least the threshold number of shares are provided.
+func JoinVaultCompatible(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least two shares are required")
+	}
+
+	l := len(shares[0])
+	if l < 2 {
+		return nil, errors.New("shares must be at least two bytes long")
+	}
+
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool)
+	for i, share := range shares {
+		if len(share) != l {
+			return nil, errors.New("shares must all be the same length")
+		}
+		x := share[l-1]
+		if x == 0 {
+			return nil, errors.New("found share with zero x coordinate")
+		}
+		if seen[x] {
+			return nil, errors.New("found duplicate share")
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	ys := make([][]byte, len(shares))
+	for i, share := range shares {
+		ys[i] = share[:l-1]
+	}
+	secret := make([]byte, l-1)
+	gf256InterpolateAt(xs, ys, 0, secret)
+	return secret, nil
+}
+
+// gf256Split shares each byte of secret over GF(2^8) with a random polynomial
+// of degree k-1, returning the y values at each of xs, which must be distinct
+// and non-zero. The sharing is done a vector at a time, but the random
+// coefficients are read in the same order as if each byte were shared in
+// turn.
+func gf256Split(secret []byte, k int, xs []byte, rand io.Reader) ([][]byte, error) {
+	random := make([]byte, len(secret)*(k-1))
+	if _, err := io.ReadFull(rand, random); err != nil {
+		return nil, err
+	}
+	a := make([][]byte, k-1)
+	for j := range a {
+		a[j] = make([]byte, len(secret))
+		for idx := range secret {
+			a[j][idx] = random[idx*(k-1)+j]
+		}
+	}
+
+	ys := make([][]byte, len(xs))
+	for i, x := range xs {
+		// Room is left for the caller to append to the share.
+		y := make([]byte, len(secret), len(secret)+1)
+		copy(y, secret)
+		xj := byte(1)
+		for j := range a {
+			xj = gf256Mul(xj, x)
+			gf256MulXorSlice(xj, a[j], y)
+		}
+		ys[i] = y
+	}
+
+	clear(random)
+	for j := range a {
+		clear(a[j])
+	}
+	return ys, nil
+}
+
+// gf256InterpolateAt sets out to the value at z of the polynomials, one for
+// each byte, that pass through the points (xs[i], ys[i]). The xs must be
+// distinct and out must be as long as each of the ys.
+func gf256InterpolateAt(xs []byte, ys [][]byte, z byte, out []byte) {
+	clear(out)
+	// out is the sum of the y vectors, each scaled by the Lagrange basis
+	// polynomial for its x coordinate evaluated at z.
+	for i, y := range ys {
+		basis := byte(1)
+		for j := range xs {
+			if i != j {
+				basis = gf256Mul(basis, gf256Mul(xs[j]^z, gf256Inv(xs[j]^xs[i])))
+			}
+		}
+		gf256MulXorSlice(basis, y, out)
+	}
+}
+
+// randomPermutation returns a uniform random permutation of [0, n).
+func randomPermutation(rand io.Reader, n int) ([]int, error) {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+
+	for i := n - 1; i > 0; i-- {
+		j, err := randomUint(rand, uint64(i+1))
+		if err != nil {
+			return nil, err
+		}
+		p[i], p[j] = p[j], p[i]
+	}
+
+	return p, nil
+}
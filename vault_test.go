@@ -0,0 +1,146 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shamirsplit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func TestGF256(t *testing.T) {
+	// From FIPS-197, section 4.2.
+	if got := gf256Mul(0x57, 0x83); got != 0xc1 {
+		t.Errorf("0x57 * 0x83 = %#x, want 0xc1", got)
+	}
+
+	for a := 1; a < 256; a++ {
+		if got := gf256Mul(byte(a), gf256Inv(byte(a))); got != 1 {
+			t.Errorf("%#x * %#x^-1 = %#x", a, a, got)
+		}
+	}
+}
+
+func TestVaultCompatible(t *testing.T) {
+	secret := []byte("vault unseal key material")
+
+	shares, err := SplitVaultCompatible(secret, 3, 5, rand.Reader)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	for _, share := range shares {
+		if len(share) != len(secret)+1 {
+			t.Fatalf("share has length %d, want %d", len(share), len(secret)+1)
+		}
+	}
+
+	for i := 0; i+3 <= len(shares); i++ {
+		result, err := JoinVaultCompatible(shares[i : i+3])
+		if err != nil {
+			t.Fatalf("failed to join shares: %s", err)
+		}
+		if !bytes.Equal(result, secret) {
+			t.Errorf("JoinVaultCompatible returned %q, want %q", result, secret)
+		}
+	}
+
+	if _, err := JoinVaultCompatible([][]byte{shares[0], shares[0]}); err == nil {
+		t.Errorf("duplicate shares were accepted")
+	}
+}
+
+func TestGF256MulXorSlice(t *testing.T) {
+	in := make([]byte, 100)
+	rand.Read(in)
+	for _, c := range []byte{0, 1, 2, 0x57, 0xff} {
+		for _, n := range []int{0, 7, 8, 16, 33, 100} {
+			out := make([]byte, n)
+			rand.Read(out)
+			want := make([]byte, n)
+			for i := range want {
+				want[i] = out[i] ^ gf256Mul(c, in[i])
+			}
+			generic := append([]byte(nil), out...)
+
+			gf256MulXorSlice(c, in[:n], out)
+			gf256MulXorGeneric(c, in[:n], generic)
+			if !bytes.Equal(out, want) || !bytes.Equal(generic, want) {
+				t.Errorf("c=%#x n=%d: got %x and %x, want %x", c, n, out, generic, want)
+			}
+		}
+	}
+}
+
+func TestVaultCompatibleKnownShares(t *testing.T) {
+	// A 2-of-n sharing of "\x2a" with the coefficient 0x57 has the value
+	// 0x2a ^ 0x57*x at x.
+	shares := [][]byte{
+		{0x2a ^ gf256Mul(0x57, 0x83), 0x83},
+		{0x2a ^ gf256Mul(0x57, 0x01), 0x01},
+	}
+
+	result, err := JoinVaultCompatible(shares)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if !bytes.Equal(result, []byte{0x2a}) {
+		t.Errorf("got %x, want 2a", result)
+	}
+}
+
+// vaultShares are the output of shamir.Split([]byte("vault unseal key
+// material"), 5, 3) from HashiCorp Vault 1.21.4.
+var vaultShares = []string{
+	"29d18084f07902868995d1ea0887ea4536011e2fabc8b452a4ae",
+	"1d242e24e63e88240bc228b82f3f17e63736a86877640b3903b9",
+	"2d97ae2e0fec655061cc847f41064fffba583c31a91e1b7cdc96",
+	"828c0c5c3aeb29f8772775873c430c249e4ef44aaf2e349137d8",
+	"567624317116fdab89230b5429f79e9b2b6e0dd244b36dd10d6c",
+}
+
+func TestJoinVaultShares(t *testing.T) {
+	shares := make([][]byte, len(vaultShares))
+	for i, s := range vaultShares {
+		shares[i], _ = hex.DecodeString(s)
+	}
+
+	want := []byte("vault unseal key material")
+	for i := 0; i < len(shares); i++ {
+		for j := i + 1; j < len(shares); j++ {
+			for k := j + 1; k < len(shares); k++ {
+				result, err := JoinVaultCompatible([][]byte{shares[i], shares[j], shares[k]})
+				if err != nil {
+					t.Fatalf("failed to join shares: %s", err)
+				}
+				if !bytes.Equal(result, want) {
+					t.Errorf("shares %d, %d and %d: got %q, want %q", i, j, k, result, want)
+				}
+			}
+		}
+	}
+
+	if result, _ := JoinVaultCompatible(shares[:2]); bytes.Equal(result, want) {
+		t.Errorf("two shares of a 3-of-5 sharing gave the secret")
+	}
+}
+
+func BenchmarkSplitVaultCompatible(b *testing.B) {
+	secret := make([]byte, 1<<20)
+	b.SetBytes(int64(len(secret)))
+	for b.Loop() {
+		SplitVaultCompatible(secret, 3, 5, nil)
+	}
+}
+
+func BenchmarkJoinVaultCompatible(b *testing.B) {
+	secret := make([]byte, 1<<20)
+	shares, _ := SplitVaultCompatible(secret, 3, 5, nil)
+	b.SetBytes(int64(len(secret)))
+	for b.Loop() {
+		JoinVaultCompatible(shares[:3])
+	}
+}
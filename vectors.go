@@ -0,0 +1,72 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/sha256"
+	"math/big"
+)
+
+// A TestVector is a known-answer test for Split and Join.
+type TestVector struct {
+	Secret  *big.Int
+	Modulus *big.Int
+	K, N    int
+	// Shares are the n shares returned by Split, with X = 1..n.
+	Shares []Share
+}
+
+// testVectorParams are the parameters of the test vectors, as
+// (log2(modulus+1), k, n). Each modulus is a Mersenne prime.
+var testVectorParams = []struct{ bits, k, n int }{
+	{61, 1, 1},
+	{61, 2, 3},
+	{127, 3, 5},
+	{127, 5, 10},
+	{521, 2, 2},
+	{521, 10, 20},
+}
+
+// TestVectors returns a deterministic set of test vectors generated from
+// seed, for checking other implementations against this one. Secrets and
+// polynomial coefficients are drawn, in that order for each vector, from the
+// AES-256-CTR keystream with key SHA-256(seed) and an all-zero IV, using the
+// same rejection sampling as Split: candidates are big-endian and have the
+// unused high bits of their first byte cleared.
+func TestVectors(seed []byte) ([]TestVector, error) {
+	key := sha256.Sum256(seed)
+	rand := newKeystreamReader(key[:])
+	one := big.NewInt(1)
+
+	vectors := make([]TestVector, len(testVectorParams))
+	for i, p := range testVectorParams {
+		modulus := new(big.Int).Lsh(one, uint(p.bits))
+		modulus.Sub(modulus, one)
+
+		secret, err := randomNumber(rand, modulus)
+		if err != nil {
+			return nil, err
+		}
+
+		shares, err := Split(secret, modulus, p.k, p.n, rand)
+		if err != nil {
+			return nil, err
+		}
+
+		v := &vectors[i]
+		v.Secret = secret
+		v.Modulus = modulus
+		v.K = p.k
+		v.N = p.n
+		v.Shares = make([]Share, p.n)
+		for j, y := range shares {
+			v.Shares[j] = Share{X: big.NewInt(int64(j + 1)), Y: y}
+		}
+	}
+
+	return vectors, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+)
+
+var updateVectors = flag.Bool("update-vectors", false, "rewrite testdata/vectors.golden")
+
+// formatVectors returns the text form of vectors, as in
+// testdata/vectors.golden: for each vector, a line with the modulus, k, n
+// and the secret, and then a line with the x and y coordinates of each share,
+// all in hex.
+func formatVectors(vectors []TestVector) string {
+	var b strings.Builder
+	for _, v := range vectors {
+		fmt.Fprintf(&b, "vector %x %d %d %x\n", v.Modulus, v.K, v.N, v.Secret)
+		for _, s := range v.Shares {
+			fmt.Fprintf(&b, "share %x %x\n", s.X, s.Y)
+		}
+	}
+	return b.String()
+}
+
+func TestTestVectorsGolden(t *testing.T) {
+	vectors, err := TestVectors([]byte("seed"))
+	if err != nil {
+		t.Fatalf("failed to generate vectors: %s", err)
+	}
+	got := formatVectors(vectors)
+
+	const golden = "testdata/vectors.golden"
+	if *updateVectors {
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("TestVectors(\"seed\") doesn't match %s", golden)
+	}
+}
+
+func TestTestVectors(t *testing.T) {
+	vectors, err := TestVectors([]byte("seed"))
+	if err != nil {
+		t.Fatalf("failed to generate vectors: %s", err)
+	}
+
+	again, _ := TestVectors([]byte("seed"))
+	other, _ := TestVectors([]byte("other seed"))
+
+	for i, v := range vectors {
+		if !v.Modulus.ProbablyPrime(20) {
+			t.Errorf("vector %d: modulus isn't prime", i)
+		}
+
+		if again[i].Secret.Cmp(v.Secret) != 0 || again[i].Shares[0].Y.Cmp(v.Shares[0].Y) != 0 {
+			t.Errorf("vector %d: not deterministic", i)
+		}
+		if other[i].Secret.Cmp(v.Secret) == 0 {
+			t.Errorf("vector %d: doesn't depend on the seed", i)
+		}
+
+		ys := make([]*big.Int, v.K)
+		numbers := make([]int, v.K)
+		for j := 0; j < v.K; j++ {
+			s := v.Shares[v.N-1-j]
+			ys[j] = s.Y
+			numbers[j] = int(s.X.Int64()) - 1
+		}
+
+		result, err := Join(ys, numbers, v.Modulus)
+		if err != nil {
+			t.Fatalf("vector %d: failed to join shares: %s", i, err)
+		}
+		if result.Cmp(v.Secret) != 0 {
+			t.Errorf("vector %d: Join returned %s, want %s", i, result, v.Secret)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Commitments are Feldman verifiable secret sharing commitments to the
+// coefficients of a sharing polynomial: Values[j] = G^a_j mod P. The modulus
+// of the split must be the order of G in the multiplicative group mod P.
+type Commitments struct {
+	P, G   *big.Int
+	Values []*big.Int
+	// Epoch is the epoch of the shares committed to, which advances with
+	// each refresh (see RefreshCommitments). Shares from other epochs
+	// don't verify, even if they're consistent with Values, so that old
+	// shares can't be replayed.
+	Epoch uint64
+}
+
+// shareEpoch returns the epoch recorded in s, or zero if none is.
+func shareEpoch(s *Share) uint64 {
+	if s.Metadata == nil {
+		return 0
+	}
+	return s.Metadata.Epoch
+}
+
+// SplitVerifiable is like Split, but also returns Feldman commitments that
+// allow each shareholder to check their share without learning the secret.
+// The modulus must be the (prime) order of g mod p. For a safe prime p = 2q+1,
+// q and g = 4 are suitable. If rand is nil, crypto/rand.Reader is used.
+func SplitVerifiable(secret, modulus, p, g *big.Int, k, n int, rand io.Reader) (set *ShareSet, err error) {
+	if k < 1 || n < k {
+		return nil, errors.New("invalid split parameters")
+	}
+	if err := checkLimits(k, n); err != nil {
+		return nil, err
+	}
+
+	if err := checkSecret(secret, modulus); err != nil {
+		return nil, err
+	}
+
+	if new(big.Int).Exp(g, modulus, p).Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("modulus is not the order of the generator")
+	}
+
+	a, err := randomPolynomial(secret, modulus, k, defaultRand(rand))
+	if err != nil {
+		return
+	}
+
+	c := &Commitments{P: p, G: g, Values: make([]*big.Int, k)}
+	for j := range a {
+		c.Values[j] = new(big.Int).Exp(g, a[j], p)
+	}
+
+	set = &ShareSet{Modulus: modulus, Threshold: k, Commitments: c}
+	set.Shares = make([]Share, n)
+	for i := 1; i <= n; i++ {
+		x := big.NewInt(int64(i))
+		set.Shares[i-1] = Share{X: x, Y: evaluatePolynomial(a, x, modulus)}
+	}
+
+	return
+}
+
+// Verify returns true iff s lies on the polynomial committed to by c and is
+// from the same epoch.
+func (c *Commitments) Verify(s Share) bool {
+	if len(c.Values) == 0 || s.X == nil || s.Y == nil || shareEpoch(&s) != c.Epoch {
+		return false
+	}
+
+	lhs := new(big.Int).Exp(c.G, s.Y, c.P)
+
+	// Evaluate the product of Values[j]^(x^j) using Horner's rule in the
+	// exponent.
+	rhs := big.NewInt(1)
+	for j := len(c.Values) - 1; j >= 0; j-- {
+		rhs.Exp(rhs, s.X, c.P)
+		rhs.Mul(rhs, c.Values[j])
+		rhs.Mod(rhs, c.P)
+	}
+
+	return lhs.Cmp(rhs) == 0
+}
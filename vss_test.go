@@ -0,0 +1,44 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestSplitVerifiable(t *testing.T) {
+	p, _ := new(big.Int).SetString(modulusStr, 16)
+	q := new(big.Int).Rsh(p, 1)
+	g := big.NewInt(4)
+
+	secret := big.NewInt(42)
+	set, err := SplitVerifiable(secret, q, p, g, 3, 5, rand.Reader)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+
+	for i, s := range set.Shares {
+		if !set.Commitments.Verify(s) {
+			t.Errorf("share %d failed to verify", i)
+		}
+	}
+
+	bad := Share{X: set.Shares[0].X, Y: new(big.Int).Add(set.Shares[0].Y, big.NewInt(1))}
+	if set.Commitments.Verify(bad) {
+		t.Errorf("corrupt share verified")
+	}
+
+	result, err := Join([]*big.Int{set.Shares[4].Y, set.Shares[1].Y, set.Shares[2].Y}, []int{4, 1, 2}, q)
+	if err != nil {
+		t.Fatalf("failed to join shares: %s", err)
+	}
+	if result.Cmp(secret) != 0 {
+		t.Errorf("Join returned wrong value (want: %s, got: %s)", secret, result)
+	}
+}
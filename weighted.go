@@ -0,0 +1,64 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// SplitWeighted splits secret among len(weights) participants so that any
+// group whose weights sum to at least threshold can recover it with
+// JoinWeighted. Participant i receives weights[i] shares: distinct points on
+// a single polynomial of degree threshold-1. If rand is nil,
+// crypto/rand.Reader is used.
+func SplitWeighted(secret, modulus *big.Int, threshold int, weights []int, rand io.Reader) ([][]Share, error) {
+	total := 0
+	for _, w := range weights {
+		if w < 0 {
+			return nil, errors.New("weights must not be negative")
+		}
+		total += w
+		if total < 0 {
+			return nil, errors.New("weights are too large")
+		}
+	}
+
+	points, err := SplitShares(secret, modulus, threshold, total, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([][]Share, len(weights))
+	for i, w := range weights {
+		shares[i], points = points[:w:w], points[w:]
+	}
+	return shares, nil
+}
+
+// JoinWeighted recovers the secret from the shares of participants whose
+// weights sum to at least the threshold passed to SplitWeighted. Shares that
+// appear more than once, such as when a participant is listed twice, are
+// only counted once.
+func JoinWeighted(participants [][]Share) (*big.Int, error) {
+	var shares []Share
+	seen := make(map[string]bool)
+	for _, p := range participants {
+		for _, s := range p {
+			if s.X == nil {
+				return nil, errors.New("share is missing coordinates")
+			}
+			if seen[string(s.X.Bytes())] {
+				continue
+			}
+			seen[string(s.X.Bytes())] = true
+			shares = append(shares, s)
+		}
+	}
+	return JoinShares(shares)
+}
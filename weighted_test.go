@@ -0,0 +1,44 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWeighted(t *testing.T) {
+	secret := big.NewInt(42)
+	// The CFO's share counts as three.
+	shares, err := SplitWeighted(secret, MODP2048, 4, []int{3, 1, 1, 1, 0}, nil)
+	if err != nil {
+		t.Fatalf("error while splitting: %s", err)
+	}
+	if len(shares[0]) != 3 || len(shares[4]) != 0 {
+		t.Fatalf("shares don't match weights")
+	}
+
+	for _, test := range []struct {
+		participants []int
+		ok           bool
+	}{
+		{[]int{0, 1}, true},
+		{[]int{1, 2, 3}, false},
+		{[]int{0, 0}, false},
+		{[]int{1, 2, 3, 4}, false},
+		{[]int{3, 0, 4}, true},
+	} {
+		var p [][]Share
+		for _, i := range test.participants {
+			p = append(p, shares[i])
+		}
+		result, err := JoinWeighted(p)
+		if ok := err == nil && result.Cmp(secret) == 0; ok != test.ok {
+			t.Errorf("participants %v: got %t, want %t", test.participants, ok, test.ok)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nobig
+
+package shamirsplit
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/bits"
+	"sort"
+	"strconv"
+)
+
+// The binary share format is:
+//
+//	magic   "SHMR"
+//	version 1 byte, currently 1
+//	records a sequence of (tag, length, value), where tag and length are
+//	        uvarints, in strictly increasing order of tag
+//
+// Everything that is ever added to a share (metadata, MACs, commitments and
+// so on) is added as a new record with a new tag. Tags are never reused or
+// given a different meaning, so this version's shares, which lack the new
+// records, remain joinable by future versions of this package. Parsers reject
+// records with tags that they don't understand, with errUnknownRecord: a new
+// record may change what the others mean, as the additive, packing,
+// hyperplane and mandatory records do, and a corrupt length can turn the
+// tail of a share into records with arbitrary tags, which would otherwise
+// hide its MAC. So every record is critical, and a share that needs a newer
+// version of this package to interpret it can't be misread by an older one.
+// The version is only incremented for changes that can't be expressed this
+// way.
+//
+// Envelopes, transcripts and the other formats that use this encoding with
+// their own magic follow the same rules.
+const (
+	wireMagic   = "SHMR"
+	wireVersion = 1
+)
+
+// errUnknownRecord is returned by parsers for records with tags that they
+// don't understand.
+var errUnknownRecord = errors.New("unknown record")
+
+// Record tags.
+const (
+	tagX           = 1
+	tagY           = 2
+	tagModulus     = 3
+	tagModulusName = 4
+	tagSecretLen   = 5
+	tagLimbs       = 6
+	tagThreshold   = 7
+	tagSetID       = 8
+	tagCreated     = 9
+	tagLabel       = 10
+	tagMACKey      = 11
+	tagMAC         = 12 // covers all other records but the hint, so it's computed last
+	tagFingerprint = 13
+	tagDealerKey   = 14
+	tagSignature   = 15
+	tagAdditive    = 16
+	tagPacking     = 17
+	tagCommitments = 18 // transcripts only
+	tagDigests     = 19 // transcripts only
+	tagNotAfter    = 20
+	tagEpoch       = 21
+	tagHyperplane  = 22
+	tagTranscript  = 23 // log entries only
+	tagPrevious    = 24 // log entries only
+	tagParticipant = 25
+	tagShares      = 26 // share sets only
+	tagRefresh     = 27 // refresh packets only
+	tagMandatory   = 28
+	tagHint        = 29 // not covered by the MAC or signature
+	tagRetired     = 30 // transcripts only
+)
+
+// ParseLimits bound the resources used in parsing shares, which may come
+// from untrusted peers.
+type ParseLimits struct {
+	// MaxSize is the maximum length in bytes of an encoded share.
+	MaxSize int
+	// MaxModulusBits is the maximum size of the modulus, and of the
+	// coordinates, of a share.
+	MaxModulusBits int
+	// MaxLimbs is the maximum number of limbs in a chunked share.
+	MaxLimbs int
+}
+
+// DefaultParseLimits are used by UnmarshalBinary and UnmarshalProto, and when
+// nil limits are given.
+var DefaultParseLimits = ParseLimits{MaxSize: 1 << 20, MaxModulusBits: 16384, MaxLimbs: 1 << 16}
+
+// A ParseError is returned for input that isn't a valid encoding.
+type ParseError struct {
+	// Offset is the position in the input of the problem, or -1 if it
+	// isn't specific to a position.
+	Offset int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Offset < 0 {
+		return "shamirsplit: invalid encoding: " + e.Err.Error()
+	}
+	return "shamirsplit: invalid encoding at offset " + strconv.Itoa(e.Offset) + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseError returns err as a *ParseError at offset, unless it already is
+// one.
+func parseError(offset int, err error) error {
+	if _, ok := err.(*ParseError); ok {
+		return err
+	}
+	return &ParseError{Offset: offset, Err: err}
+}
+
+// checkSize returns a *ParseError if data is longer than limits allow.
+func (l *ParseLimits) checkSize(data []byte) error {
+	if len(data) > l.MaxSize {
+		return &ParseError{Offset: l.MaxSize, Err: errors.New("input is too large")}
+	}
+	return nil
+}
+
+// checkInt returns an error if value, a big-endian integer, is larger than
+// limits allow.
+func (l *ParseLimits) checkInt(value []byte) error {
+	for len(value) > 0 && value[0] == 0 {
+		value = value[1:]
+	}
+	if len(value) > 0 && (len(value)-1)*8+bits.Len8(value[0]) > l.MaxModulusBits {
+		return errors.New("integer is too large")
+	}
+	return nil
+}
+
+func limitsOrDefault(l *ParseLimits) *ParseLimits {
+	if l == nil {
+		return &DefaultParseLimits
+	}
+	return l
+}
+
+// isBinaryShare returns true if data starts with the binary share magic.
+func isBinaryShare(data []byte) bool {
+	return len(data) >= len(wireMagic) && string(data[:len(wireMagic)]) == wireMagic
+}
+
+// wireRecords accumulates the records of a binary share, which may be added
+// in any order.
+type wireRecords []wireRecord
+
+type wireRecord struct {
+	tag   uint64
+	value []byte
+}
+
+func (r *wireRecords) add(tag uint64, value []byte) {
+	*r = append(*r, wireRecord{tag, value})
+}
+
+func (r *wireRecords) addInt(tag uint64, v *big.Int) {
+	r.add(tag, v.Bytes())
+}
+
+func (r *wireRecords) addUint(tag uint64, v uint64) {
+	r.add(tag, binary.AppendUvarint(nil, v))
+}
+
+// get returns the value of the record with the given tag, or nil if there
+// isn't one.
+func (r wireRecords) get(tag uint64) []byte {
+	for _, rec := range r {
+		if rec.tag == tag {
+			return rec.value
+		}
+	}
+	return nil
+}
+
+// without returns the records other than those with the given tags.
+func (r wireRecords) without(tags ...uint64) (rest wireRecords) {
+outer:
+	for _, rec := range r {
+		for _, tag := range tags {
+			if rec.tag == tag {
+				continue outer
+			}
+		}
+		rest = append(rest, rec)
+	}
+	return
+}
+
+// marshal returns the binary share containing the records.
+func (r wireRecords) marshal() []byte {
+	return r.marshalAs(wireMagic)
+}
+
+// marshalAs is like marshal, but for formats other than shares that use the
+// same encoding with a different magic.
+func (r wireRecords) marshalAs(magic string) []byte {
+	sort.SliceStable(r, func(i, j int) bool { return r[i].tag < r[j].tag })
+
+	b := append([]byte(magic), wireVersion)
+	for _, rec := range r {
+		b = binary.AppendUvarint(b, rec.tag)
+		b = binary.AppendUvarint(b, uint64(len(rec.value)))
+		b = append(b, rec.value...)
+	}
+	return b
+}
+
+func parseWireUint(value []byte) (uint64, error) {
+	v, n := binary.Uvarint(value)
+	if n <= 0 || n != len(value) {
+		return 0, errors.New("invalid integer in share")
+	}
+	return v, nil
+}
+
+// parseWire checks the header of data and calls f for each record.
+func parseWire(data []byte, f func(tag uint64, value []byte) error) error {
+	if !isBinaryShare(data) {
+		return errors.New("not a binary share")
+	}
+	return parseWireAs(wireMagic, data, f)
+}
+
+// parseWireAs is like parseWire, but for data with the given magic. Errors,
+// including those from f, are returned as a *ParseError with the offset of
+// the record concerned.
+func parseWireAs(magic string, data []byte, f func(tag uint64, value []byte) error) error {
+	if len(data) < len(magic) || string(data[:len(magic)]) != magic {
+		return &ParseError{Offset: 0, Err: errors.New("unrecognized format")}
+	}
+	offset := len(magic)
+
+	if len(data) == offset {
+		return &ParseError{Offset: offset, Err: errors.New("truncated share")}
+	}
+	if data[offset] != wireVersion {
+		return &ParseError{Offset: offset, Err: errors.New("unsupported share version")}
+	}
+	offset++
+
+	var lastTag uint64
+	for offset < len(data) {
+		start := offset
+		tag, n := binary.Uvarint(data[offset:])
+		if n <= 0 {
+			return &ParseError{Offset: start, Err: errors.New("truncated share")}
+		}
+		offset += n
+
+		if tag <= lastTag {
+			return &ParseError{Offset: start, Err: errors.New("share records out of order")}
+		}
+		lastTag = tag
+
+		l, n := binary.Uvarint(data[offset:])
+		if n <= 0 || l > uint64(len(data)-offset-n) {
+			return &ParseError{Offset: start, Err: errors.New("truncated share")}
+		}
+		offset += n
+		if err := f(tag, data[offset:offset+int(l)]); err != nil {
+			return parseError(start, err)
+		}
+		offset += int(l)
+	}
+
+	return nil
+}